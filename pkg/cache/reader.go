@@ -0,0 +1,193 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache provides typed, read-only access to the objects cached by shared informers.
+package cache
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// ErrNotSynced is returned, wrapped, by a TypedReader's Get and List if its informer cache hasn't yet synced.
+var ErrNotSynced = errors.New("the informer cache hasn't synced")
+
+// TypedReader reads objects of type T from an informer cache. The returned objects are copies so may be mutated.
+type TypedReader[T runtime.Object] interface {
+	// Get returns the object with the given namespace and name, or a NotFound error if it isn't cached. The namespace is
+	// empty for a cluster-scoped object.
+	Get(namespace, name string) (T, error)
+
+	// List returns the cached objects whose labels match the given selector.
+	List(selector labels.Selector) ([]T, error)
+
+	// HasSynced returns true once the informer cache has synced.
+	HasSynced() bool
+}
+
+type typedReader[T runtime.Object] struct {
+	informer  toolscache.SharedInformer
+	scheme    *runtime.Scheme
+	converter *resource.Converter
+}
+
+// NewTypedReader returns a TypedReader backed by the given shared informer, which may cache either objects of type T
+// or, eg for a dynamic informer, Unstructured objects. Cached objects that aren't of type T are converted via the given
+// scheme. If the scheme is nil, the global k8s Scheme is used. Example:
+//
+//	NewTypedReader[*corev1.Pod](informerFactory.Core().V1().Pods().Informer(), nil)
+func NewTypedReader[T runtime.Object](informer toolscache.SharedInformer, s *runtime.Scheme) TypedReader[T] {
+	if s == nil {
+		s = scheme.Scheme
+	}
+
+	return &typedReader[T]{
+		informer:  informer,
+		scheme:    s,
+		converter: resource.NewConverter(s),
+	}
+}
+
+func (r *typedReader[T]) Get(namespace, name string) (T, error) {
+	var zero T
+
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+
+	if !r.HasSynced() {
+		return zero, errors.Wrapf(ErrNotSynced, "unable to get %T %q", zero, key)
+	}
+
+	obj, exists, err := r.informer.GetStore().GetByKey(key)
+	if err != nil {
+		return zero, errors.Wrapf(err, "error retrieving %T %q", zero, key)
+	}
+
+	if !exists {
+		return zero, apierrors.NewNotFound(r.groupResource(), name)
+	}
+
+	return r.toTyped(obj)
+}
+
+func (r *typedReader[T]) List(selector labels.Selector) ([]T, error) {
+	var zero T
+
+	if !r.HasSynced() {
+		return nil, errors.Wrapf(ErrNotSynced, "unable to list %T", zero)
+	}
+
+	var (
+		items   []T
+		listErr error
+	)
+
+	err := toolscache.ListAll(r.informer.GetStore(), selector, func(obj interface{}) {
+		if listErr != nil {
+			return
+		}
+
+		var typed T
+
+		typed, listErr = r.toTyped(obj)
+		if listErr == nil {
+			items = append(items, typed)
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing %T", zero)
+	}
+
+	return items, listErr
+}
+
+func (r *typedReader[T]) HasSynced() bool {
+	return r.informer.HasSynced()
+}
+
+func (r *typedReader[T]) toTyped(obj interface{}) (T, error) {
+	var zero T
+
+	if typed, ok := obj.(T); ok {
+		copied, _ := typed.DeepCopyObject().(T)
+		return copied, nil
+	}
+
+	to, err := newObject[T]()
+	if err != nil {
+		return zero, err
+	}
+
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		if err := r.converter.FromUnstructured(u, to); err != nil {
+			return zero, err //nolint:wrapcheck // OK to return the error as is.
+		}
+
+		return to, nil
+	}
+
+	from, ok := obj.(runtime.Object)
+	if !ok {
+		return zero, errors.Errorf("unexpected type %T in the informer cache", obj)
+	}
+
+	if err := r.scheme.Convert(from, to, nil); err != nil {
+		return zero, errors.Wrapf(err, "error converting %T to %T", from, zero)
+	}
+
+	return to, nil
+}
+
+func (r *typedReader[T]) groupResource() schema.GroupResource {
+	obj, err := newObject[T]()
+	if err != nil {
+		return schema.GroupResource{}
+	}
+
+	kinds, _, err := r.scheme.ObjectKinds(obj)
+	if err != nil || len(kinds) == 0 {
+		return schema.GroupResource{}
+	}
+
+	plural, _ := meta.UnsafeGuessKindToResource(kinds[0])
+
+	return plural.GroupResource()
+}
+
+func newObject[T runtime.Object]() (T, error) {
+	var zero T
+
+	obj, ok := reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+	if !ok {
+		return zero, errors.Errorf("unable to instantiate %T", zero)
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,141 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/cache"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("TypedReader", func() {
+	var (
+		informer toolscache.SharedIndexInformer
+		reader   cache.TypedReader[*corev1.Pod]
+		pod      *corev1.Pod
+		other    *corev1.Pod
+		stopCh   chan struct{}
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+
+		other = test.NewPod(test.LocalNamespace)
+		other.Name = "other-pod"
+		other.Labels = map[string]string{"app": "other"}
+
+		stopCh = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		reader = cache.NewTypedReader[*corev1.Pod](informer, nil)
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+	})
+
+	testReads := func() {
+		Context("and the informer has synced", func() {
+			JustBeforeEach(func() {
+				go informer.Run(stopCh)
+				Eventually(reader.HasSynced).Should(BeTrue())
+			})
+
+			Specify("Get should return the typed object", func() {
+				actual, err := reader.Get(pod.Namespace, pod.Name)
+				Expect(err).To(Succeed())
+				Expect(actual.Name).To(Equal(pod.Name))
+				Expect(actual.Labels).To(Equal(pod.Labels))
+				Expect(actual.Spec).To(Equal(pod.Spec))
+			})
+
+			Specify("Get should return NotFound for a missing object", func() {
+				_, err := reader.Get(pod.Namespace, "missing")
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			Specify("List should return the typed objects matching the selector", func() {
+				actual, err := reader.List(labels.SelectorFromSet(other.Labels))
+				Expect(err).To(Succeed())
+				Expect(actual).To(HaveLen(1))
+				Expect(actual[0].Name).To(Equal(other.Name))
+
+				actual, err = reader.List(labels.Everything())
+				Expect(err).To(Succeed())
+				Expect(actual).To(HaveLen(2))
+			})
+
+			Specify("Get should return a copy", func() {
+				actual, err := reader.Get(pod.Namespace, pod.Name)
+				Expect(err).To(Succeed())
+
+				actual.Labels["mutated"] = "true"
+
+				actual, err = reader.Get(pod.Namespace, pod.Name)
+				Expect(err).To(Succeed())
+				Expect(actual.Labels).ToNot(HaveKey("mutated"))
+			})
+		})
+
+		Context("and the informer hasn't synced", func() {
+			It("should return a not-synced error", func() {
+				Expect(reader.HasSynced()).To(BeFalse())
+
+				_, err := reader.Get(pod.Namespace, pod.Name)
+				Expect(errors.Is(err, cache.ErrNotSynced)).To(BeTrue())
+
+				_, err = reader.List(labels.Everything())
+				Expect(errors.Is(err, cache.ErrNotSynced)).To(BeTrue())
+			})
+		})
+	}
+
+	When("backed by a typed informer", func() {
+		BeforeEach(func() {
+			informer = informers.NewSharedInformerFactory(fake.NewSimpleClientset(pod, other), 0).Core().V1().Pods().Informer()
+		})
+
+		testReads()
+	})
+
+	When("backed by a dynamic informer", func() {
+		BeforeEach(func() {
+			_, gvr := test.GetRESTMapperAndGroupVersionResourceFor(pod)
+			client := dynamicFake.NewSimpleDynamicClient(scheme.Scheme,
+				test.PrepInitialClientObjs("", "", pod, other)...)
+
+			informer = dynamicinformer.NewDynamicSharedInformerFactory(client, 0).ForResource(*gvr).Informer()
+		})
+
+		testReads()
+	})
+})
@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conditions provides helpers to manage the standard status conditions of unstructured resources.
+package conditions
+
+import (
+	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const conditionsField = "conditions"
+
+// Set sets the given condition in the resource's status.conditions, replacing an existing condition of the same type.
+// The LastTransitionTime is only updated if the condition is new or its status changed - if unset in the given
+// condition, it's set to the current time. Returns true if the resource was modified.
+func Set(obj *unstructured.Unstructured, condition metav1.Condition) bool {
+	conditions := util.ConditionsFromUnstructured(obj, util.StatusField, conditionsField)
+
+	existing := meta.FindStatusCondition(conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	} else if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+
+	switch {
+	case existing == nil:
+		conditions = append(conditions, condition)
+	case *existing == condition:
+		return false
+	default:
+		*existing = condition
+	}
+
+	util.ConditionsToUnstructured(conditions, obj, util.StatusField, conditionsField)
+
+	return true
+}
+
+// Get returns the condition of the given type from the resource's status.conditions or nil if not present.
+func Get(obj *unstructured.Unstructured, conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(util.ConditionsFromUnstructured(obj, util.StatusField, conditionsField), conditionType)
+}
+
+// Remove removes the condition of the given type from the resource's status.conditions. Returns true if the condition
+// was present.
+func Remove(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions := util.ConditionsFromUnstructured(obj, util.StatusField, conditionsField)
+	if meta.FindStatusCondition(conditions, conditionType) == nil {
+		return false
+	}
+
+	meta.RemoveStatusCondition(&conditions, conditionType)
+	util.ConditionsToUnstructured(conditions, obj, util.StatusField, conditionsField)
+
+	return true
+}
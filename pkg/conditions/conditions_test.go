@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/conditions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const readyType = "Ready"
+
+func TestConditions(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conditions Suite")
+}
+
+var _ = Describe("Conditions", func() {
+	var obj *unstructured.Unstructured
+
+	BeforeEach(func() {
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "submariner.io/v1",
+			"kind":       "Widget",
+			"metadata": map[string]interface{}{
+				"name": "test",
+			},
+		}}
+	})
+
+	newCondition := func(status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{
+			Type:    readyType,
+			Status:  status,
+			Reason:  reason,
+			Message: "Some message",
+		}
+	}
+
+	When("a new condition is set", func() {
+		It("should add it with the transition time set", func() {
+			Expect(conditions.Set(obj, newCondition(metav1.ConditionTrue, "Available"))).To(BeTrue())
+
+			c := conditions.Get(obj, readyType)
+			Expect(c).ToNot(BeNil())
+			Expect(c.Status).To(Equal(metav1.ConditionTrue))
+			Expect(c.Reason).To(Equal("Available"))
+			Expect(c.Message).To(Equal("Some message"))
+			Expect(c.LastTransitionTime.IsZero()).To(BeFalse())
+		})
+
+		It("should retain other conditions", func() {
+			other := newCondition(metav1.ConditionFalse, "NotSynced")
+			other.Type = "Synced"
+
+			conditions.Set(obj, other)
+			conditions.Set(obj, newCondition(metav1.ConditionTrue, "Available"))
+
+			Expect(conditions.Get(obj, "Synced")).ToNot(BeNil())
+			Expect(conditions.Get(obj, readyType)).ToNot(BeNil())
+		})
+	})
+
+	When("an existing condition's status is changed", func() {
+		It("should update the transition time", func() {
+			initial := newCondition(metav1.ConditionFalse, "Unavailable")
+			initial.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+			conditions.Set(obj, initial)
+
+			Expect(conditions.Set(obj, newCondition(metav1.ConditionTrue, "Available"))).To(BeTrue())
+
+			c := conditions.Get(obj, readyType)
+			Expect(c.Status).To(Equal(metav1.ConditionTrue))
+			Expect(c.Reason).To(Equal("Available"))
+			Expect(c.LastTransitionTime.Time).To(BeTemporally(">", initial.LastTransitionTime.Time))
+		})
+	})
+
+	When("an existing condition is set with the same status", func() {
+		var initial metav1.Condition
+
+		BeforeEach(func() {
+			initial = newCondition(metav1.ConditionTrue, "Available")
+			initial.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+			conditions.Set(obj, initial)
+		})
+
+		It("should not update the transition time", func() {
+			Expect(conditions.Set(obj, newCondition(metav1.ConditionTrue, "StillAvailable"))).To(BeTrue())
+
+			c := conditions.Get(obj, readyType)
+			Expect(c.Reason).To(Equal("StillAvailable"))
+			Expect(c.LastTransitionTime.Unix()).To(Equal(initial.LastTransitionTime.Unix()))
+		})
+
+		Context("and nothing else changed", func() {
+			It("should not modify the resource", func() {
+				Expect(conditions.Set(obj, newCondition(metav1.ConditionTrue, "Available"))).To(BeFalse())
+			})
+		})
+	})
+
+	When("a condition is removed", func() {
+		It("should no longer be present", func() {
+			conditions.Set(obj, newCondition(metav1.ConditionTrue, "Available"))
+
+			Expect(conditions.Remove(obj, readyType)).To(BeTrue())
+			Expect(conditions.Get(obj, readyType)).To(BeNil())
+			Expect(conditions.Remove(obj, readyType)).To(BeFalse())
+		})
+	})
+
+	When("the resource has no conditions", func() {
+		It("Get should return nil", func() {
+			Expect(conditions.Get(obj, readyType)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,127 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package event provides helpers to emit Kubernetes events.
+package event
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+type eventKey struct {
+	object    string
+	eventType string
+	reason    string
+	message   string
+}
+
+type dedupingRecorder struct {
+	delegate  record.EventRecorder
+	window    time.Duration
+	mutex     sync.Mutex
+	lastSent  map[eventKey]time.Time
+	lastPrune time.Time
+}
+
+// NewDedupingRecorder returns an EventRecorder that passes an event to the given EventRecorder only if an identical
+// event, ie for the same object with the same type, reason and message, wasn't passed within the given window. This
+// avoids spamming the event stream when the same event is emitted repeatedly, eg on each reconcile retry. An object
+// is identified by its type, namespace, name and UID.
+func NewDedupingRecorder(delegate record.EventRecorder, window time.Duration) record.EventRecorder {
+	return &dedupingRecorder{
+		delegate:  delegate,
+		window:    window,
+		lastSent:  map[eventKey]time.Time{},
+		lastPrune: time.Now(),
+	}
+}
+
+func (r *dedupingRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if r.shouldSend(object, eventType, reason, message) {
+		r.delegate.Event(object, eventType, reason, message)
+	}
+}
+
+func (r *dedupingRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.shouldSend(object, eventType, reason, fmt.Sprintf(messageFmt, args...)) {
+		r.delegate.Eventf(object, eventType, reason, messageFmt, args...)
+	}
+}
+
+func (r *dedupingRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason,
+	messageFmt string, args ...interface{},
+) {
+	if r.shouldSend(object, eventType, reason, fmt.Sprintf(messageFmt, args...)) {
+		r.delegate.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+	}
+}
+
+func (r *dedupingRecorder) shouldSend(object runtime.Object, eventType, reason, message string) bool {
+	key := eventKey{
+		object:    objectKey(object),
+		eventType: eventType,
+		reason:    reason,
+		message:   message,
+	}
+
+	now := time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pruneExpired(now)
+
+	if sent, found := r.lastSent[key]; found && now.Sub(sent) < r.window {
+		return false
+	}
+
+	r.lastSent[key] = now
+
+	return true
+}
+
+// pruneExpired removes the entries whose window has elapsed, at most once per window, so the map doesn't grow
+// unbounded with events that aren't repeated.
+func (r *dedupingRecorder) pruneExpired(now time.Time) {
+	if now.Sub(r.lastPrune) < r.window {
+		return
+	}
+
+	for key, sent := range r.lastSent {
+		if now.Sub(sent) >= r.window {
+			delete(r.lastSent, key)
+		}
+	}
+
+	r.lastPrune = now
+}
+
+func objectKey(object runtime.Object) string {
+	objMeta, err := meta.Accessor(object)
+	if err != nil {
+		return fmt.Sprintf("%T", object)
+	}
+
+	return fmt.Sprintf("%T/%s/%s/%s", object, objMeta.GetNamespace(), objMeta.GetName(), objMeta.GetUID())
+}
@@ -0,0 +1,95 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const window = 300 * time.Millisecond
+
+func TestEvent(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Event Suite")
+}
+
+var _ = Describe("DedupingRecorder", func() {
+	var (
+		delegate *record.FakeRecorder
+		recorder record.EventRecorder
+		pod      *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		delegate = record.NewFakeRecorder(100)
+		recorder = event.NewDedupingRecorder(delegate, window)
+		pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "test-ns", UID: "1234"}}
+	})
+
+	When("the same event is emitted many times within the window", func() {
+		It("should pass it to the underlying recorder once", func() {
+			for i := 0; i < 50; i++ {
+				recorder.Event(pod, corev1.EventTypeWarning, "SyncFailed", "Failed to sync")
+				recorder.Eventf(pod, corev1.EventTypeWarning, "SyncFailed", "Failed to %s", "sync")
+			}
+
+			Expect(delegate.Events).To(HaveLen(1))
+			Expect(<-delegate.Events).To(Equal("Warning SyncFailed Failed to sync"))
+		})
+	})
+
+	When("the same event is emitted after the window elapses", func() {
+		It("should pass it to the underlying recorder again", func() {
+			recorder.Event(pod, corev1.EventTypeNormal, "Synced", "Synced")
+			recorder.Event(pod, corev1.EventTypeNormal, "Synced", "Synced")
+			Expect(delegate.Events).To(HaveLen(1))
+
+			time.Sleep(window)
+
+			recorder.Event(pod, corev1.EventTypeNormal, "Synced", "Synced")
+			recorder.Event(pod, corev1.EventTypeNormal, "Synced", "Synced")
+			Expect(delegate.Events).To(HaveLen(2))
+		})
+	})
+
+	When("events differ in the object, type, reason or message", func() {
+		It("should pass each to the underlying recorder", func() {
+			other := pod.DeepCopy()
+			other.Name = "other-pod"
+
+			for i := 0; i < 10; i++ {
+				recorder.Event(pod, corev1.EventTypeWarning, "SyncFailed", "Failed to sync")
+				recorder.Event(other, corev1.EventTypeWarning, "SyncFailed", "Failed to sync")
+				recorder.Event(pod, corev1.EventTypeNormal, "SyncFailed", "Failed to sync")
+				recorder.Event(pod, corev1.EventTypeWarning, "DeleteFailed", "Failed to sync")
+				recorder.AnnotatedEventf(pod, map[string]string{"a": "b"}, corev1.EventTypeWarning, "SyncFailed", "Failed: %v", i)
+			}
+
+			Expect(delegate.Events).To(HaveLen(14))
+		})
+	})
+})
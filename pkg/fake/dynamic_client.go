@@ -21,11 +21,13 @@ package fake
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -33,20 +35,26 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/testing"
 )
 
 type DynamicClient struct {
 	*fake.FakeDynamicClient
 	namespaceableResources map[schema.GroupVersionResource]dynamic.NamespaceableResourceInterface
+	uids                   *uidCounter
 }
 
 type namespaceableResource struct {
 	dynamic.NamespaceableResourceInterface
+	fake            *testing.Fake
+	gvr             schema.GroupVersionResource
 	mutex           sync.Mutex
 	resourceClients map[string]dynamic.ResourceInterface
+	uids            *uidCounter
 }
 
 type DynamicResourceClient struct {
@@ -54,6 +62,9 @@ type DynamicResourceClient struct {
 	created                      chan string
 	updated                      chan string
 	deleted                      chan string
+	deleteOptions                sync.Map
+	listOptionsMutex             sync.Mutex
+	listOptions                  []v1.ListOptions
 	FailOnCreate                 error
 	PersistentFailOnCreate       atomic.Value
 	FailOnUpdate                 error
@@ -63,6 +74,45 @@ type DynamicResourceClient struct {
 	PersistentFailOnDelete       atomic.Value
 	FailOnGet                    error
 	PersistentFailOnGet          atomic.Value
+
+	// UIDGenerator if specified, invoked to obtain the UID to assign to a created resource. By default, UIDs are
+	// assigned sequentially via DeterministicUID, starting at 1, using a counter shared by all resource clients of the
+	// DynamicClient. The counter only advances when a create succeeds.
+	UIDGenerator func() types.UID
+	uids         *uidCounter
+
+	// MutateOnCreate if specified, invoked to mutate a resource on create prior to it being stored, eg to simulate
+	// defaulting or admission webhook injection by the API server. The returned resource reflects the mutation.
+	MutateOnCreate func(obj *unstructured.Unstructured)
+
+	conflictsMutex sync.Mutex
+	conflicts      map[string]int
+
+	// failMutex guards taking the one-shot FailOn* errors so concurrent callers don't both observe them.
+	failMutex sync.Mutex
+
+	// storeMutex serializes the reads and writes of the store made by each Create, Update and Delete, eg the resource
+	// version check on update, with each other and with Snapshot.
+	storeMutex sync.Mutex
+
+	fake      *testing.Fake
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// ReactionFunc is invoked for an action on a DynamicResourceClient's resource. The action may be modified, eg to mutate
+// the object on create or update. If handled is true, the given result is returned, otherwise the possibly modified
+// action is passed through to the reactors registered before it, ultimately reaching the built-in object tracker.
+type ReactionFunc func(action testing.Action) (handled bool, ret runtime.Object, err error)
+
+type uidCounter struct {
+	sync.Mutex
+	last uint64
+}
+
+// DeterministicUID returns the UID assigned by default to the nth resource created via a DynamicClient.
+func DeterministicUID(n uint64) types.UID {
+	return types.UID(fmt.Sprintf("00000000-0000-0000-0000-%012d", n))
 }
 
 func NewDynamicClient(scheme *runtime.Scheme, objects ...runtime.Object) *DynamicClient {
@@ -70,10 +120,12 @@ func NewDynamicClient(scheme *runtime.Scheme, objects ...runtime.Object) *Dynami
 
 	AddDeleteCollectionReactor(&f.Fake, schema.GroupVersionKind{Group: "fake-dynamic-client-group", Version: "v1", Kind: ""})
 	AddFilteringListReactor(&f.Fake)
+	AddStrategicMergePatchReactor(&f.Fake, scheme)
 
 	return &DynamicClient{
 		FakeDynamicClient:      f,
 		namespaceableResources: map[schema.GroupVersionResource]dynamic.NamespaceableResourceInterface{},
+		uids:                   &uidCounter{},
 	}
 }
 
@@ -88,7 +140,10 @@ func (f *DynamicClient) Resource(gvr schema.GroupVersionResource) dynamic.Namesp
 
 	f.namespaceableResources[gvr] = &namespaceableResource{
 		NamespaceableResourceInterface: f.FakeDynamicClient.Resource(gvr),
+		fake:                           &f.Fake,
+		gvr:                            gvr,
 		resourceClients:                map[string]dynamic.ResourceInterface{},
+		uids:                           f.uids,
 	}
 
 	return f.namespaceableResources[gvr]
@@ -108,6 +163,10 @@ func (f *namespaceableResource) Namespace(namespace string) dynamic.ResourceInte
 		created:           make(chan string, 10000),
 		updated:           make(chan string, 10000),
 		deleted:           make(chan string, 10000),
+		uids:              f.uids,
+		fake:              f.fake,
+		gvr:               f.gvr,
+		namespace:         namespace,
 	}
 
 	return f.resourceClients[namespace]
@@ -136,21 +195,53 @@ func (f *DynamicResourceClient) Create(ctx context.Context, obj *unstructured.Un
 ) (*unstructured.Unstructured, error) {
 	f.created <- obj.GetName()
 
-	fail := f.FailOnCreate
-	if fail != nil {
-		f.FailOnCreate = nil
+	if fail := f.takeFailure(&f.FailOnCreate); fail != nil {
 		return nil, fail
 	}
 
-	fail = getError(f.PersistentFailOnCreate)
+	fail := getError(f.PersistentFailOnCreate)
 	if fail != nil {
 		return nil, fail
 	}
 
-	obj.SetUID(uuid.NewUUID())
 	obj.SetResourceVersion("1")
 
-	return f.ResourceInterface.Create(ctx, obj, options, subresources...)
+	if f.MutateOnCreate != nil {
+		obj = obj.DeepCopy()
+		f.MutateOnCreate(obj)
+	}
+
+	f.storeMutex.Lock()
+	defer f.storeMutex.Unlock()
+
+	if isDryRun(options.DryRun) {
+		_, err := f.ResourceInterface.Get(ctx, obj.GetName(), v1.GetOptions{})
+		if err == nil {
+			return nil, apierrors.NewAlreadyExists(schema.GroupResource{}, obj.GetName())
+		}
+
+		obj.SetUID(f.peekUID())
+
+		return obj.DeepCopy(), nil
+	}
+
+	if f.UIDGenerator != nil {
+		obj.SetUID(f.UIDGenerator())
+		return f.ResourceInterface.Create(ctx, obj, options, subresources...)
+	}
+
+	// Only advance the UID counter if the create succeeds so the assigned UIDs form a contiguous sequence.
+	f.uids.Lock()
+	defer f.uids.Unlock()
+
+	obj.SetUID(DeterministicUID(f.uids.last + 1))
+
+	created, err := f.ResourceInterface.Create(ctx, obj, options, subresources...)
+	if err == nil {
+		f.uids.last++
+	}
+
+	return created, err
 }
 
 func (f *DynamicResourceClient) Update(ctx context.Context, obj *unstructured.Unstructured, options v1.UpdateOptions,
@@ -158,17 +249,23 @@ func (f *DynamicResourceClient) Update(ctx context.Context, obj *unstructured.Un
 ) (*unstructured.Unstructured, error) {
 	f.updated <- obj.GetName()
 
-	fail := f.FailOnUpdate
-	if fail != nil {
-		f.FailOnUpdate = nil
+	if fail := f.takeFailure(&f.FailOnUpdate); fail != nil {
 		return nil, fail
 	}
 
-	fail = getError(f.PersistentFailOnUpdate)
+	fail := getError(f.PersistentFailOnUpdate)
 	if fail != nil {
 		return nil, fail
 	}
 
+	if f.takeConflict(obj.GetName()) {
+		return nil, apierrors.NewConflict(schema.GroupResource{}, obj.GetName(),
+			fmt.Errorf("resource version %q of %q is out of date", obj.GetResourceVersion(), obj.GetName()))
+	}
+
+	f.storeMutex.Lock()
+	defer f.storeMutex.Unlock()
+
 	if f.CheckResourceVersionOnUpdate {
 		existing, _ := f.ResourceInterface.Get(ctx, obj.GetName(), v1.GetOptions{})
 		if existing != nil && existing.GetResourceVersion() != obj.GetResourceVersion() {
@@ -183,39 +280,201 @@ func (f *DynamicResourceClient) Update(ctx context.Context, obj *unstructured.Un
 		}
 	}
 
+	if isDryRun(options.DryRun) {
+		_, err := f.ResourceInterface.Get(ctx, obj.GetName(), v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		return obj.DeepCopy(), nil
+	}
+
 	return f.ResourceInterface.Update(ctx, obj, options, subresources...)
 }
 
+// UpdateStatus emulates the API server's status subresource in that only the status of the given resource is persisted -
+// any other changes are ignored.
+func (f *DynamicResourceClient) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options v1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	f.storeMutex.Lock()
+	defer f.storeMutex.Unlock()
+
+	existing, err := f.ResourceInterface.Get(ctx, obj.GetName(), v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	toUpdate := existing.DeepCopy()
+
+	status, found, _ := unstructured.NestedFieldCopy(obj.Object, "status")
+	if found {
+		toUpdate.Object["status"] = status
+	} else {
+		delete(toUpdate.Object, "status")
+	}
+
+	return f.ResourceInterface.UpdateStatus(ctx, toUpdate, options)
+}
+
+// takeFailure returns the given one-shot failure, if any, and clears it.
+func (f *DynamicResourceClient) takeFailure(fail *error) error {
+	f.failMutex.Lock()
+	defer f.failMutex.Unlock()
+
+	err := *fail
+	*fail = nil
+
+	return err
+}
+
+// Snapshot returns copies of all the resources stored for this client's resource type and namespace, ordered by namespace
+// and name. The copies are taken atomically with respect to writes via the client, so they're consistent and safe to
+// inspect while a syncer concurrently writes. Failures injected via the FailOn* fields don't apply.
+func (f *DynamicResourceClient) Snapshot() []*unstructured.Unstructured {
+	f.storeMutex.Lock()
+	list, err := f.ResourceInterface.List(context.TODO(), v1.ListOptions{})
+	f.storeMutex.Unlock()
+
+	Expect(err).To(Succeed())
+
+	snapshot := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		snapshot[i] = list.Items[i].DeepCopy()
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].GetNamespace() != snapshot[j].GetNamespace() {
+			return snapshot[i].GetNamespace() < snapshot[j].GetNamespace()
+		}
+
+		return snapshot[i].GetName() < snapshot[j].GetName()
+	})
+
+	return snapshot
+}
+
+func (f *DynamicResourceClient) peekUID() types.UID {
+	if f.UIDGenerator != nil {
+		return f.UIDGenerator()
+	}
+
+	f.uids.Lock()
+	defer f.uids.Unlock()
+
+	return DeterministicUID(f.uids.last + 1)
+}
+
+func isDryRun(dryRun []string) bool {
+	for _, v := range dryRun {
+		if v == v1.DryRunAll {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddReactor registers a ReactionFunc for the given verb ("*" for all verbs) on this client's resource and namespace.
+// It's invoked ahead of the built-in reactors and any reactors previously registered. The client is returned so calls
+// can be chained.
+func (f *DynamicResourceClient) AddReactor(verb string, reaction ReactionFunc) *DynamicResourceClient {
+	f.fake.Lock()
+	defer f.fake.Unlock()
+
+	next := f.fake.ReactionChain[0:]
+
+	chain := []testing.Reactor{&testing.SimpleReactor{
+		Verb:     verb,
+		Resource: f.gvr.Resource,
+		Reaction: func(action testing.Action) (bool, runtime.Object, error) {
+			if f.namespace != "" && action.GetNamespace() != f.namespace {
+				return false, nil, nil
+			}
+
+			handled, ret, err := reaction(action)
+			if handled {
+				return true, ret, err
+			}
+
+			ret, err = invokeReactors(action, next)
+
+			return true, ret, err
+		},
+	}}
+	f.fake.ReactionChain = append(chain, f.fake.ReactionChain...)
+
+	return f
+}
+
+// ConflictOnUpdate arms a resource version conflict for the next count updates of the named resource, independent of
+// CheckResourceVersionOnUpdate. Updates of other resources are unaffected.
+func (f *DynamicResourceClient) ConflictOnUpdate(name string, count int) {
+	f.conflictsMutex.Lock()
+	defer f.conflictsMutex.Unlock()
+
+	if f.conflicts == nil {
+		f.conflicts = map[string]int{}
+	}
+
+	f.conflicts[name] = count
+}
+
+func (f *DynamicResourceClient) takeConflict(name string) bool {
+	f.conflictsMutex.Lock()
+	defer f.conflictsMutex.Unlock()
+
+	if f.conflicts[name] <= 0 {
+		return false
+	}
+
+	f.conflicts[name]--
+
+	return true
+}
+
 func (f *DynamicResourceClient) Delete(ctx context.Context, name string,
 	options v1.DeleteOptions, // nolint:gocritic // Match K8s API
 	subresources ...string,
 ) error {
+	f.deleteOptions.Store(name, options)
 	f.deleted <- name
 
-	fail := f.FailOnDelete
-	if fail != nil {
-		f.FailOnDelete = nil
+	if fail := f.takeFailure(&f.FailOnDelete); fail != nil {
 		return fail
 	}
 
-	fail = getError(f.PersistentFailOnDelete)
+	fail := getError(f.PersistentFailOnDelete)
 	if fail != nil {
 		return fail
 	}
 
+	f.storeMutex.Lock()
+	defer f.storeMutex.Unlock()
+
 	return f.ResourceInterface.Delete(ctx, name, options, subresources...)
 }
 
+// DeleteOptionsFor returns the DeleteOptions passed with the last Delete of the given resource name or nil if it wasn't
+// deleted.
+func (f *DynamicResourceClient) DeleteOptionsFor(name string) *v1.DeleteOptions {
+	options, ok := f.deleteOptions.Load(name)
+	if !ok {
+		return nil
+	}
+
+	o := options.(v1.DeleteOptions)
+
+	return &o
+}
+
 func (f *DynamicResourceClient) Get(ctx context.Context, name string, options v1.GetOptions,
 	subresources ...string,
 ) (*unstructured.Unstructured, error) {
-	fail := f.FailOnGet
-	if fail != nil {
-		f.FailOnGet = nil
+	if fail := f.takeFailure(&f.FailOnGet); fail != nil {
 		return nil, fail
 	}
 
-	fail = getError(f.PersistentFailOnGet)
+	fail := getError(f.PersistentFailOnGet)
 	if fail != nil {
 		return nil, fail
 	}
@@ -223,6 +482,76 @@ func (f *DynamicResourceClient) Get(ctx context.Context, name string, options v1
 	return f.ResourceInterface.Get(ctx, name, options, subresources...)
 }
 
+// Patch patches the resource. As by the API server, a JSON patch whose "test" operation fails is rejected with an
+// Invalid (422) error.
+func (f *DynamicResourceClient) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options v1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	f.storeMutex.Lock()
+	obj, err := f.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+	f.storeMutex.Unlock()
+
+	if pt == types.JSONPatchType && errors.Is(err, jsonpatch.ErrTestFailed) {
+		return nil, apierrors.NewInvalid(schema.GroupKind{}, name, field.ErrorList{field.Invalid(field.NewPath("patch"), string(data),
+			err.Error())})
+	}
+
+	return obj, err //nolint:wrapcheck // No need to wrap
+}
+
+// List lists the resources. If the options specify a Limit, the list is paginated as by the API server, ie at most Limit
+// resources, ordered by namespace and name, are returned with a continue token to retrieve the next page, if any.
+func (f *DynamicResourceClient) List(ctx context.Context, options v1.ListOptions) (*unstructured.UnstructuredList, error) {
+	f.listOptionsMutex.Lock()
+	f.listOptions = append(f.listOptions, options)
+	f.listOptionsMutex.Unlock()
+
+	limit, cont := options.Limit, options.Continue
+	options.Limit, options.Continue = 0, ""
+
+	list, err := f.ResourceInterface.List(ctx, options)
+	if err != nil || limit <= 0 {
+		return list, err //nolint:wrapcheck // Return the error as is.
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].GetNamespace() != list.Items[j].GetNamespace() {
+			return list.Items[i].GetNamespace() < list.Items[j].GetNamespace()
+		}
+
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+
+	start := 0
+
+	if cont != "" {
+		start, err = strconv.Atoi(cont)
+		if err != nil || start < 0 || start > len(list.Items) {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid continue token %q", cont))
+		}
+	}
+
+	end := start + int(limit)
+	if end >= len(list.Items) {
+		end = len(list.Items)
+		list.SetContinue("")
+	} else {
+		list.SetContinue(strconv.Itoa(end))
+	}
+
+	list.Items = list.Items[start:end]
+
+	return list, nil
+}
+
+// ListOptions returns the ListOptions passed with each List call, in order.
+func (f *DynamicResourceClient) ListOptions() []v1.ListOptions {
+	f.listOptionsMutex.Lock()
+	defer f.listOptionsMutex.Unlock()
+
+	return append([]v1.ListOptions(nil), f.listOptions...)
+}
+
 func (f *DynamicResourceClient) VerifyNoCreate(name string) {
 	Consistently(f.created, 300*time.Millisecond).ShouldNot(Receive(Equal(name)), "Create was unexpectedly called")
 }
@@ -0,0 +1,280 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("DynamicResourceClient", func() {
+	var (
+		dynClient *fake.DynamicClient
+		client    *fake.DynamicResourceClient
+		pod       *corev1.Pod
+	)
+
+	podsGVR := schema.GroupVersionResource{
+		Group:    corev1.SchemeGroupVersion.Group,
+		Version:  corev1.SchemeGroupVersion.Version,
+		Resource: "pods",
+	}
+
+	BeforeEach(func() {
+		dynClient = fake.NewDynamicClient(scheme.Scheme)
+		client, _ = dynClient.Resource(podsGVR).Namespace(test.LocalNamespace).(*fake.DynamicResourceClient)
+		pod = test.NewPod(test.LocalNamespace)
+	})
+
+	Describe("AddReactor", func() {
+		When("a reactor mutates the object on create and passes through", func() {
+			BeforeEach(func() {
+				client.AddReactor("create", func(action testing.Action) (bool, runtime.Object, error) {
+					obj := action.(testing.CreateAction).GetObject().(*unstructured.Unstructured)
+					obj.SetLabels(map[string]string{"mutated": "true"})
+
+					return false, nil, nil
+				})
+			})
+
+			It("should persist the mutation so it's visible to a subsequent Get", func() {
+				test.CreateResource(client, pod)
+				Expect(test.GetPod(client, pod).Labels).To(HaveKeyWithValue("mutated", "true"))
+			})
+
+			It("should not apply to other namespaces", func() {
+				other, _ := dynClient.Resource(podsGVR).Namespace(test.RemoteNamespace).(*fake.DynamicResourceClient)
+				pod.Namespace = test.RemoteNamespace
+
+				test.CreateResource(other, pod)
+				Expect(test.GetPod(other, pod).Labels).ToNot(HaveKey("mutated"))
+			})
+		})
+
+		When("a reactor handles the action", func() {
+			BeforeEach(func() {
+				test.CreateResource(client, pod)
+
+				client.AddReactor("get", func(action testing.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewServiceUnavailable("fake")
+				}).AddReactor("delete", func(action testing.Action) (bool, runtime.Object, error) {
+					return true, nil, nil
+				})
+			})
+
+			It("should return its result without invoking the built-in reactors", func() {
+				_, err := client.Get(context.TODO(), pod.Name, metav1.GetOptions{})
+				Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+
+				Expect(client.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})).To(Succeed())
+
+				list, err := client.List(context.TODO(), metav1.ListOptions{})
+				Expect(err).To(Succeed())
+				Expect(list.Items).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("MutateOnCreate", func() {
+		BeforeEach(func() {
+			client.MutateOnCreate = func(obj *unstructured.Unstructured) {
+				obj.SetAnnotations(map[string]string{"injected": "sidecar"})
+			}
+		})
+
+		It("should return and store the mutated resource", func() {
+			created, err := client.Create(context.TODO(), test.ToUnstructured(pod), metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+			Expect(created.GetAnnotations()).To(HaveKeyWithValue("injected", "sidecar"))
+
+			Expect(test.GetPod(client, pod).Annotations).To(HaveKeyWithValue("injected", "sidecar"))
+		})
+
+		It("should not cause CreateOrUpdate to immediately re-update the resource", func() {
+			createOrUpdate := func() (util.OperationResult, error) {
+				return util.CreateOrUpdate(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+					func(existing runtime.Object) (runtime.Object, error) {
+						obj := existing.(*unstructured.Unstructured)
+						Expect(unstructured.SetNestedField(obj.Object, test.ToUnstructured(pod).Object["spec"], "spec")).To(Succeed())
+
+						return obj, nil
+					})
+			}
+
+			Expect(createOrUpdate()).To(Equal(util.OperationResultCreated))
+			Expect(test.GetPod(client, pod).Annotations).To(HaveKeyWithValue("injected", "sidecar"))
+
+			dynClient.ClearActions()
+
+			Expect(createOrUpdate()).To(Equal(util.OperationResultNone))
+
+			for _, action := range dynClient.Actions() {
+				Expect(action.GetVerb()).ToNot(Equal("update"), "Unexpected action: %#v", action)
+			}
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			for _, name := range []string{"pod-c", "pod-a", "pod-b"} {
+				p := pod.DeepCopy()
+				p.Name = name
+				test.CreateResource(client, p)
+			}
+		})
+
+		names := func(list *unstructured.UnstructuredList) []string {
+			n := []string{}
+			for i := range list.Items {
+				n = append(n, list.Items[i].GetName())
+			}
+
+			return n
+		}
+
+		When("a limit is specified", func() {
+			It("should return the resources in pages", func() {
+				list, err := client.List(context.TODO(), metav1.ListOptions{Limit: 2})
+				Expect(err).To(Succeed())
+				Expect(names(list)).To(Equal([]string{"pod-a", "pod-b"}))
+				Expect(list.GetContinue()).ToNot(BeEmpty())
+
+				list, err = client.List(context.TODO(), metav1.ListOptions{Limit: 2, Continue: list.GetContinue()})
+				Expect(err).To(Succeed())
+				Expect(names(list)).To(Equal([]string{"pod-c"}))
+				Expect(list.GetContinue()).To(BeEmpty())
+
+				Expect(client.ListOptions()).To(HaveLen(2))
+				Expect(client.ListOptions()[1].Limit).To(Equal(int64(2)))
+			})
+		})
+
+		When("no limit is specified", func() {
+			It("should return all the resources", func() {
+				list, err := client.List(context.TODO(), metav1.ListOptions{})
+				Expect(err).To(Succeed())
+				Expect(names(list)).To(ConsistOf("pod-a", "pod-b", "pod-c"))
+				Expect(list.GetContinue()).To(BeEmpty())
+			})
+		})
+
+		When("the continue token is invalid", func() {
+			It("should return a BadRequest error", func() {
+				_, err := client.List(context.TODO(), metav1.ListOptions{Limit: 2, Continue: "bogus"})
+				Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("Snapshot", func() {
+		const (
+			numWriters = 5
+			numUpdates = 20
+		)
+
+		version := func(obj *unstructured.Unstructured) int {
+			v, err := strconv.Atoi(obj.GetLabels()["version"])
+			Expect(err).To(Succeed())
+
+			return v
+		}
+
+		It("should return a consistent copy of the stored resources while they're concurrently written", func() {
+			client.CheckResourceVersionOnUpdate = true
+
+			var writers sync.WaitGroup
+
+			for i := 0; i < numWriters; i++ {
+				writers.Add(1)
+
+				go func(i int) {
+					defer GinkgoRecover()
+					defer writers.Done()
+
+					p := pod.DeepCopy()
+					p.Name = fmt.Sprintf("pod-%d", i)
+					p.Labels = map[string]string{"version": "0"}
+					test.CreateResource(client, p)
+
+					for v := 1; v <= numUpdates; v++ {
+						Expect(util.Update(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(p),
+							func(existing runtime.Object) (runtime.Object, error) {
+								existing.(*unstructured.Unstructured).SetLabels(map[string]string{"version": strconv.Itoa(v)})
+								return existing, nil
+							})).To(Succeed())
+					}
+				}(i)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				writers.Wait()
+				close(done)
+			}()
+
+			lastSeen := map[string]int{}
+
+			for finished := false; !finished; {
+				select {
+				case <-done:
+					finished = true
+				default:
+				}
+
+				snapshot := client.Snapshot()
+
+				for i, obj := range snapshot {
+					if i > 0 {
+						Expect(obj.GetName() > snapshot[i-1].GetName()).To(BeTrue(), "Snapshot is not ordered")
+					}
+
+					Expect(version(obj)).To(BeNumerically(">=", lastSeen[obj.GetName()]), "Snapshot went back in time")
+					lastSeen[obj.GetName()] = version(obj)
+
+					// Mutating the copy must not affect the store.
+					obj.SetLabels(map[string]string{"version": "-1"})
+				}
+			}
+
+			snapshot := client.Snapshot()
+			Expect(snapshot).To(HaveLen(numWriters))
+
+			for _, obj := range snapshot {
+				Expect(version(obj)).To(Equal(numUpdates))
+			}
+		})
+	})
+})
@@ -0,0 +1,88 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/testing"
+)
+
+type strategicMergePatchReactor struct {
+	scheme   *runtime.Scheme
+	reactors []testing.Reactor
+}
+
+// AddStrategicMergePatchReactor adds a reactor that applies strategic merge patches using the merge metadata of the
+// typed struct registered in the given scheme for the resource's kind, as done by the API server. The built-in object
+// tracker stores resources as Unstructured and thus can't merge lists keyed by a merge key, eg a Pod's containers.
+// Patches for kinds not registered in the scheme are passed through.
+func AddStrategicMergePatchReactor(f *testing.Fake, scheme *runtime.Scheme) {
+	r := &strategicMergePatchReactor{scheme: scheme, reactors: f.ReactionChain[0:]}
+	f.PrependReactor("patch", "*", r.react)
+}
+
+func (r *strategicMergePatchReactor) react(action testing.Action) (bool, runtime.Object, error) {
+	switch patchAction := action.(type) {
+	case testing.PatchActionImpl:
+		if patchAction.GetPatchType() != types.StrategicMergePatchType {
+			return false, nil, nil
+		}
+
+		obj, err := invokeReactors(testing.NewGetAction(action.GetResource(), action.GetNamespace(), patchAction.GetName()),
+			r.reactors)
+		if err != nil {
+			return true, nil, err
+		}
+
+		existing := test.ToUnstructured(obj)
+
+		dataStruct, err := r.scheme.New(existing.GroupVersionKind())
+		if err != nil {
+			return false, nil, nil
+		}
+
+		existingJSON, err := json.Marshal(existing)
+		if err != nil {
+			return true, nil, err
+		}
+
+		mergedJSON, err := strategicpatch.StrategicMergePatch(existingJSON, patchAction.GetPatch(), dataStruct)
+		if err != nil {
+			return true, nil, err
+		}
+
+		merged := &unstructured.Unstructured{}
+		if err := merged.UnmarshalJSON(mergedJSON); err != nil {
+			return true, nil, err
+		}
+
+		obj, err = invokeReactors(testing.NewUpdateAction(action.GetResource(), action.GetNamespace(), merged), r.reactors)
+
+		return true, obj, err
+	default:
+		return false, nil, fmt.Errorf("invalid action: %#v", action)
+	}
+}
@@ -25,19 +25,26 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/testing"
 )
 
 type WatchReactor struct {
-	mutex    sync.Mutex
-	watches  map[string]*watchDelegator
-	reactors []testing.WatchReactor
+	mutex        sync.Mutex
+	watches      map[string]*watchDelegator
+	restrictions map[string][]testing.WatchRestrictions
+	reactors     []testing.WatchReactor
 }
 
 func NewWatchReactor(f *testing.Fake) *WatchReactor {
-	r := &WatchReactor{watches: map[string]*watchDelegator{}, reactors: f.WatchReactionChain[0:]}
+	r := &WatchReactor{
+		watches:      map[string]*watchDelegator{},
+		restrictions: map[string][]testing.WatchRestrictions{},
+		reactors:     f.WatchReactionChain[0:],
+	}
+
 	chain := []testing.WatchReactor{&testing.SimpleWatchReactor{Resource: "*", Reaction: r.react}}
 	f.WatchReactionChain = append(chain, f.WatchReactionChain...)
 
@@ -45,6 +52,10 @@ func NewWatchReactor(f *testing.Fake) *WatchReactor {
 }
 
 func filterEvent(event watch.Event, restrictions *testing.WatchRestrictions) (watch.Event, bool) {
+	if event.Type == watch.Bookmark {
+		return event, true
+	}
+
 	if restrictions.Labels != nil && !restrictions.Labels.Matches(labels.Set(resource.ToMeta(event.Object).GetLabels())) {
 		return event, false
 	}
@@ -58,7 +69,7 @@ func (r *WatchReactor) react(action testing.Action) (bool, watch.Interface, erro
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
 
-		if r.watches[w.Resource.Resource] != nil {
+		if existing := r.watches[w.Resource.Resource]; existing != nil && !existing.isStopped() {
 			return true, nil, fmt.Errorf("watch for %q was already started", w.Resource.Resource)
 		}
 
@@ -76,8 +87,9 @@ func (r *WatchReactor) react(action testing.Action) (bool, watch.Interface, erro
 				return filterEvent(in, &w.WatchRestrictions)
 			})
 
-			delegator := &watchDelegator{Interface: watcher, stopped: make(chan struct{})}
+			delegator := newWatchDelegator(watcher)
 			r.watches[w.Resource.Resource] = delegator
+			r.restrictions[w.Resource.Resource] = append(r.restrictions[w.Resource.Resource], w.WatchRestrictions)
 
 			return true, delegator, err
 		}
@@ -118,12 +130,121 @@ func (r *WatchReactor) AwaitNoWatchStopped(forResource string) {
 	Consistently(r.getDelegator(forResource).stopped).ShouldNot(BeClosed(), "Watch for %q was stopped", forResource)
 }
 
+// WatchRestrictions returns the restrictions, eg the ResourceVersion, with which each watch for the given resource was
+// started, in order.
+func (r *WatchReactor) WatchRestrictions(forResource string) []testing.WatchRestrictions {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]testing.WatchRestrictions(nil), r.restrictions[forResource]...)
+}
+
+// SendEvent injects the given event into the current watch for the given resource. The event is delivered ahead of any
+// subsequently sent event or Disconnect. If the watch has ended, the event is dropped.
+func (r *WatchReactor) SendEvent(forResource string, event watch.Event) {
+	r.AwaitWatchStarted(forResource)
+	r.getDelegator(forResource).send(&event)
+}
+
+// SendBookmark injects a Bookmark event with the given resource version into the current watch for the given resource.
+func (r *WatchReactor) SendBookmark(forResource, resourceVersion string) {
+	obj := &unstructured.Unstructured{}
+	obj.SetResourceVersion(resourceVersion)
+
+	r.SendEvent(forResource, watch.Event{Type: watch.Bookmark, Object: obj})
+}
+
+// Disconnect closes the result channel of the current watch for the given resource, after any previously injected
+// events are received, to simulate a dropped connection. The watcher is expected to re-establish the watch.
+func (r *WatchReactor) Disconnect(forResource string) {
+	r.AwaitWatchStarted(forResource)
+	r.getDelegator(forResource).send(nil)
+}
+
 type watchDelegator struct {
 	watch.Interface
-	stopped chan struct{}
+	result   chan watch.Event
+	inject   chan *watch.Event
+	done     chan struct{}
+	exited   chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newWatchDelegator(watcher watch.Interface) *watchDelegator {
+	w := &watchDelegator{
+		Interface: watcher,
+		result:    make(chan watch.Event),
+		inject:    make(chan *watch.Event),
+		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	go w.forward()
+
+	return w
+}
+
+// forward is the sole writer of the result channel so injected events are delivered in order with those from the
+// underlying watch.
+func (w *watchDelegator) forward() {
+	defer close(w.exited)
+	defer close(w.result)
+
+	for {
+		var event watch.Event
+
+		select {
+		case e, ok := <-w.Interface.ResultChan():
+			if !ok {
+				return
+			}
+
+			event = e
+		case e := <-w.inject:
+			if e == nil {
+				return
+			}
+
+			event = *e
+		case <-w.done:
+			return
+		}
+
+		select {
+		case w.result <- event:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// send passes the given event, or nil to close the result channel, to the forward goroutine.
+func (w *watchDelegator) send(event *watch.Event) {
+	select {
+	case w.inject <- event:
+	case <-w.exited:
+	}
+}
+
+func (w *watchDelegator) ResultChan() <-chan watch.Event {
+	return w.result
 }
 
 func (w *watchDelegator) Stop() {
-	w.Interface.Stop()
-	close(w.stopped)
+	w.stopOnce.Do(func() {
+		w.Interface.Stop()
+		close(w.done)
+		close(w.stopped)
+	})
+}
+
+func (w *watchDelegator) isStopped() bool {
+	select {
+	case <-w.stopped:
+		return true
+	default:
+		return false
+	}
 }
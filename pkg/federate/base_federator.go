@@ -21,14 +21,19 @@ package federate
 
 import (
 	"context"
+	"sync"
 
+	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/log"
 	"github.com/submariner-io/admiral/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/dynamic"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -40,6 +45,9 @@ type baseFederator struct {
 	keepMetadataFields map[string]bool
 }
 
+// maxConcurrentDistributes is the maximum number of resources written concurrently by DistributeAll.
+const maxConcurrentDistributes = 10
+
 var logger = log.Logger{Logger: logf.Log.WithName("Federator")}
 
 func newBaseFederator(dynClient dynamic.Interface, restMapper meta.RESTMapper, targetNamespace string,
@@ -59,7 +67,43 @@ func newBaseFederator(dynClient dynamic.Interface, restMapper meta.RESTMapper, t
 	return b
 }
 
-func (f *baseFederator) Delete(obj runtime.Object) error {
+func distributeAll(ctx context.Context, distribute func(context.Context, runtime.Object) error,
+	resources []runtime.Object,
+) map[runtime.Object]error {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	errs := map[runtime.Object]error{}
+	sem := make(chan struct{}, maxConcurrentDistributes)
+
+	for i := range resources {
+		obj := resources[i]
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := distribute(ctx, obj); err != nil {
+				mutex.Lock()
+				errs[obj] = err
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+func (f *baseFederator) Delete(ctx context.Context, obj runtime.Object) error {
 	toDelete, resourceClient, err := f.toUnstructured(obj)
 	if err != nil {
 		return err
@@ -67,7 +111,35 @@ func (f *baseFederator) Delete(obj runtime.Object) error {
 
 	logger.V(log.LIBTRACE).Infof("Deleting resource: %#v", toDelete)
 
-	return resourceClient.Delete(context.TODO(), toDelete.GetName(), metav1.DeleteOptions{})
+	return resourceClient.Delete(ctx, toDelete.GetName(), DeleteOptionsFrom(ctx))
+}
+
+// DeleteAllFor deletes the resources of the given types in the target namespace, or in all namespaces if the target
+// namespace is NamespaceAll, that match the given label selector. The resources are discovered via the API server
+// rather than from local bookkeeping so those distributed prior to, eg, a restart are also deleted.
+func (f *baseFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	var errs []error
+
+	for _, gvr := range gvrs {
+		list, err := f.dynClient.Resource(gvr).Namespace(f.targetNamespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error listing %q in namespace %q", gvr.Resource, f.targetNamespace))
+			continue
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+
+			logger.V(log.LIBTRACE).Infof("Deleting resource %s/%s matching %q", obj.GetNamespace(), obj.GetName(), labelSelector)
+
+			err = f.dynClient.Resource(gvr).Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), DeleteOptionsFrom(ctx))
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "error deleting %q %s/%s", gvr.Resource, obj.GetNamespace(), obj.GetName()))
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
 }
 
 func (f *baseFederator) toUnstructured(from runtime.Object) (*unstructured.Unstructured, dynamic.ResourceInterface, error) {
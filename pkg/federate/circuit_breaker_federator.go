@@ -0,0 +1,219 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	DefaultCircuitBreakerFailureThreshold = 5
+	DefaultCircuitBreakerCooldown         = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned, wrapped, for a write that isn't attempted because the circuit of a
+// CircuitBreakerFederator is open.
+var ErrCircuitOpen = errors.New("the circuit is open")
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold the number of consecutive failed writes after which the circuit opens. By default,
+	// DefaultCircuitBreakerFailureThreshold is used.
+	FailureThreshold int
+
+	// Cooldown the duration for which the circuit stays open before a single write is let through to probe whether the
+	// destination has recovered. By default, DefaultCircuitBreakerCooldown is used.
+	Cooldown time.Duration
+
+	// Clock the clock used to time the cooldown. By default, the real clock is used.
+	Clock clock.Clock
+}
+
+// CircuitBreakerFederator is a Federator that pauses the writes to a persistently failing destination.
+type CircuitBreakerFederator interface {
+	Federator
+
+	// Healthy returns an error if the circuit is open or half-open, ie writes are paused.
+	Healthy() error
+}
+
+type circuitBreakerFederator struct {
+	Federator
+	config   CircuitBreakerConfig
+	mutex    sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+	lastErr  error
+}
+
+// NewCircuitBreakerFederator returns a CircuitBreakerFederator that delegates writes to the given Federator until
+// FailureThreshold consecutive writes fail, at which point the circuit opens: subsequent writes fail fast with
+// ErrCircuitOpen, without being attempted, so their syncer holds them in its work queue and retries them with backoff
+// rather than repeatedly writing to a destination that rejects them. After the Cooldown, the circuit is half-open: one
+// write at a time is let through to probe the destination. If it succeeds, the circuit closes and writes resume,
+// otherwise the circuit re-opens for another Cooldown. Errors that pertain to a single resource, ie not found, already
+// exists and conflicts, and context cancellation aren't considered failures.
+func NewCircuitBreakerFederator(federator Federator, config CircuitBreakerConfig) CircuitBreakerFederator {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultCircuitBreakerFailureThreshold
+	}
+
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCircuitBreakerCooldown
+	}
+
+	if config.Clock == nil {
+		config.Clock = clock.RealClock{}
+	}
+
+	return &circuitBreakerFederator{
+		Federator: federator,
+		config:    config,
+	}
+}
+
+func (f *circuitBreakerFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	return f.write(func() error {
+		return f.Federator.Distribute(ctx, obj)
+	})
+}
+
+func (f *circuitBreakerFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
+}
+
+func (f *circuitBreakerFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	result := util.OperationResultNone
+
+	err := f.write(func() error {
+		var err error
+		result, err = DistributeDryRun(ctx, f.Federator, obj)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (f *circuitBreakerFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	return f.write(func() error {
+		return f.Federator.Delete(ctx, obj)
+	})
+}
+
+func (f *circuitBreakerFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return f.write(func() error {
+		return DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...)
+	})
+}
+
+func (f *circuitBreakerFederator) Healthy() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.openedAt.IsZero() {
+		return nil
+	}
+
+	return errors.Wrapf(f.lastErr, "the circuit has been open for %v after %d consecutive write failures",
+		f.config.Clock.Since(f.openedAt).Round(time.Millisecond), f.config.FailureThreshold)
+}
+
+//nolint:wrapcheck // The delegated errors are returned as is.
+func (f *circuitBreakerFederator) write(do func() error) error {
+	isProbe, err := f.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = do()
+
+	f.record(err, isProbe)
+
+	return err
+}
+
+// acquire returns an error if the circuit is open and either the cooldown hasn't elapsed or another write is already
+// probing the destination. Otherwise, the returned bool indicates whether the write is a probe.
+func (f *circuitBreakerFederator) acquire() (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.openedAt.IsZero() {
+		return false, nil
+	}
+
+	if f.probing || f.config.Clock.Since(f.openedAt) < f.config.Cooldown {
+		return false, errors.Wrapf(ErrCircuitOpen, "deferring the write to the failing destination (last error: %v)", f.lastErr)
+	}
+
+	f.probing = true
+
+	return true, nil
+}
+
+func (f *circuitBreakerFederator) record(err error, isProbe bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if isProbe {
+		f.probing = false
+	}
+
+	if !isCircuitBreakerFailure(err) {
+		if !f.openedAt.IsZero() {
+			logger.Infof("The circuit closed after being open for %v - resuming writes",
+				f.config.Clock.Since(f.openedAt).Round(time.Millisecond))
+		}
+
+		f.failures = 0
+		f.openedAt = time.Time{}
+		f.lastErr = nil
+
+		return
+	}
+
+	f.failures++
+	f.lastErr = err
+
+	switch {
+	case isProbe:
+		logger.Warningf("The probe write failed - re-opening the circuit for %v: %v", f.config.Cooldown, err)
+
+		f.openedAt = f.config.Clock.Now()
+	case f.openedAt.IsZero() && f.failures >= f.config.FailureThreshold:
+		logger.Warningf("%d consecutive writes failed - opening the circuit for %v: %v", f.failures, f.config.Cooldown, err)
+
+		f.openedAt = f.config.Clock.Now()
+	}
+}
+
+func isCircuitBreakerFailure(err error) bool {
+	return err != nil && !apierrors.IsNotFound(err) && !apierrors.IsAlreadyExists(err) && !apierrors.IsConflict(err) &&
+		!errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,180 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+var _ = Describe("Circuit Breaker Federator", func() {
+	const (
+		threshold = 3
+		cooldown  = time.Minute
+	)
+
+	var (
+		delegate  *fake.Federator
+		f         federate.CircuitBreakerFederator
+		fakeClock *clock.FakeClock
+		failErr   error
+		numPods   int
+	)
+
+	BeforeEach(func() {
+		delegate = fake.New()
+		delegate.ResetOnFailure = false
+		fakeClock = clock.NewFakeClock(time.Now())
+		failErr = apierrors.NewInternalError(errors.New("fake error"))
+		numPods = 0
+
+		f = federate.NewCircuitBreakerFederator(delegate, federate.CircuitBreakerConfig{
+			FailureThreshold: threshold,
+			Cooldown:         cooldown,
+			Clock:            fakeClock,
+		})
+	})
+
+	distribute := func() error {
+		numPods++
+
+		pod := test.NewPod(test.LocalNamespace)
+		pod.Name = fmt.Sprintf("pod-%d", numPods)
+
+		return f.Distribute(context.TODO(), pod)
+	}
+
+	tripCircuit := func() {
+		delegate.FailOnDistribute = failErr
+
+		for i := 0; i < threshold; i++ {
+			Expect(distribute()).To(Equal(failErr))
+		}
+	}
+
+	When("fewer consecutive writes than the threshold fail", func() {
+		It("should not open the circuit", func() {
+			delegate.FailOnDistribute = failErr
+
+			for i := 0; i < threshold-1; i++ {
+				Expect(distribute()).To(Equal(failErr))
+			}
+
+			delegate.FailOnDistribute = nil
+			Expect(distribute()).To(Succeed())
+
+			delegate.FailOnDistribute = failErr
+			Expect(distribute()).To(Equal(failErr))
+
+			Expect(f.Healthy()).To(Succeed())
+			Expect(delegate.NumCalls(fake.OpDistribute)).To(Equal(threshold + 1))
+		})
+	})
+
+	When("the threshold of consecutive writes fail", func() {
+		It("should open the circuit and pause writes during the cooldown", func() {
+			tripCircuit()
+
+			Expect(f.Healthy()).ToNot(Succeed())
+
+			delegate.FailOnDistribute = nil
+
+			err := distribute()
+			Expect(errors.Is(err, federate.ErrCircuitOpen)).To(BeTrue(), "Unexpected error: %v", err)
+			Expect(f.Delete(context.TODO(), test.NewPod(test.LocalNamespace))).To(MatchError(ContainSubstring("circuit is open")))
+
+			fakeClock.Step(cooldown / 2)
+
+			Expect(errors.Is(distribute(), federate.ErrCircuitOpen)).To(BeTrue())
+			Expect(delegate.NumCalls(fake.OpDistribute)).To(Equal(threshold))
+			Expect(delegate.NumCalls(fake.OpDelete)).To(BeZero())
+		})
+	})
+
+	When("the probe write after the cooldown succeeds", func() {
+		It("should close the circuit and resume writes", func() {
+			tripCircuit()
+
+			delegate.FailOnDistribute = nil
+			fakeClock.Step(cooldown)
+
+			Expect(distribute()).To(Succeed())
+			Expect(f.Healthy()).To(Succeed())
+
+			Expect(distribute()).To(Succeed())
+			Expect(delegate.NumCalls(fake.OpDistribute)).To(Equal(threshold + 2))
+		})
+	})
+
+	When("the probe write after the cooldown fails", func() {
+		It("should re-open the circuit for another cooldown", func() {
+			tripCircuit()
+
+			fakeClock.Step(cooldown)
+
+			Expect(distribute()).To(Equal(failErr))
+			Expect(f.Healthy()).ToNot(Succeed())
+
+			delegate.FailOnDistribute = nil
+
+			Expect(errors.Is(distribute(), federate.ErrCircuitOpen)).To(BeTrue())
+
+			fakeClock.Step(cooldown)
+
+			Expect(distribute()).To(Succeed())
+			Expect(f.Healthy()).To(Succeed())
+		})
+	})
+
+	When("writes fail with errors pertaining to a single resource", func() {
+		It("should not open the circuit", func() {
+			delegate.FailOnDistribute = apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "pod", errors.New("fake"))
+
+			for i := 0; i < threshold*2; i++ {
+				Expect(distribute()).ToNot(Succeed())
+			}
+
+			Expect(f.Healthy()).To(Succeed())
+			Expect(delegate.NumCalls(fake.OpDistribute)).To(Equal(threshold * 2))
+		})
+	})
+
+	When("the circuit is open", func() {
+		It("should fail all resources passed to DistributeAll", func() {
+			tripCircuit()
+
+			pod1, pod2 := test.NewPod(test.LocalNamespace), test.NewPod(test.LocalNamespace)
+			pod2.Name = "other-pod"
+
+			Expect(federate.DistributeAll(context.TODO(), f, []runtime.Object{pod1, pod2})).To(HaveLen(2))
+		})
+	})
+})
@@ -22,6 +22,7 @@ import (
 	"context"
 
 	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,21 +40,38 @@ func NewCreateFederator(dynClient dynamic.Interface, restMapper meta.RESTMapper,
 	}
 }
 
+func (f *createFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	_, err := f.distribute(ctx, obj, nil)
+	return err
+}
+
+func (f *createFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	return f.distribute(ctx, obj, []string{metav1.DryRunAll})
+}
+
 //nolint:wrapcheck // This function is effectively a wrapper so no need to wrap errors.
-func (f *createFederator) Distribute(obj runtime.Object) error {
+func (f *createFederator) distribute(ctx context.Context, obj runtime.Object, dryRun []string) (util.OperationResult, error) {
 	logger.V(log.LIBTRACE).Infof("In Distribute for %#v", obj)
 
 	toDistribute, resourceClient, err := f.toUnstructured(obj)
 	if err != nil {
-		return err
+		return util.OperationResultNone, err
 	}
 
 	f.prepareResourceForSync(toDistribute)
 
-	_, err = resourceClient.Create(context.TODO(), toDistribute, metav1.CreateOptions{})
+	_, err = resourceClient.Create(ctx, toDistribute, metav1.CreateOptions{DryRun: dryRun})
 	if apierrors.IsAlreadyExists(err) {
-		return nil
+		return util.OperationResultNone, nil
 	}
 
-	return err
+	if err != nil {
+		return util.OperationResultNone, err
+	}
+
+	return util.OperationResultCreated, nil
+}
+
+func (f *createFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
 }
@@ -20,10 +20,12 @@ package federate
 
 import (
 	"context"
+	"strings"
 
 	"github.com/submariner-io/admiral/pkg/log"
 	"github.com/submariner-io/admiral/pkg/resource"
 	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,25 +34,67 @@ import (
 
 type createOrUpdateFederator struct {
 	*baseFederator
-	localClusterID string
+	localClusterID            string
+	lastAppliedHashAnnotation string
+	ignoredKeys               []string
+}
+
+// CreateOrUpdateFederatorOptions specifies how a create-or-update Federator writes resources.
+type CreateOrUpdateFederatorOptions struct {
+	// KeepMetadataFields the metadata fields, in addition to the name, namespace, labels and annotations, that are
+	// retained when a resource is distributed.
+	KeepMetadataFields []string
+
+	// LastAppliedHashAnnotation if specified, the key of the annotation in which Distribute records a hash of the
+	// resource it writes. On subsequent distributes, the destination resource is only written if the hash of the desired
+	// resource differs from the recorded hash, ie fields added to the destination resource by the server, eg defaulted
+	// fields, don't cause a rewrite.
+	LastAppliedHashAnnotation string
+
+	// IgnoreChangesToKeys the label and annotation key prefixes whose changes alone don't cause Distribute to rewrite an
+	// existing resource, eg for annotations that churn constantly such as reconcile timestamps. Unlike stripped fields,
+	// they're still written along with any other change.
+	IgnoreChangesToKeys []string
 }
 
 func NewCreateOrUpdateFederator(dynClient dynamic.Interface, restMapper meta.RESTMapper, targetNamespace,
 	localClusterID string, keepMetadataField ...string,
+) Federator {
+	return NewCreateOrUpdateFederatorWithOptions(dynClient, restMapper, targetNamespace, localClusterID,
+		CreateOrUpdateFederatorOptions{KeepMetadataFields: keepMetadataField})
+}
+
+// NewCreateOrUpdateFederatorWithOptions is like NewCreateOrUpdateFederator but resources are written as specified by the
+// given options.
+func NewCreateOrUpdateFederatorWithOptions(dynClient dynamic.Interface, restMapper meta.RESTMapper, targetNamespace,
+	localClusterID string, options CreateOrUpdateFederatorOptions,
 ) Federator {
 	return &createOrUpdateFederator{
-		baseFederator:  newBaseFederator(dynClient, restMapper, targetNamespace, keepMetadataField...),
-		localClusterID: localClusterID,
+		baseFederator:             newBaseFederator(dynClient, restMapper, targetNamespace, options.KeepMetadataFields...),
+		localClusterID:            localClusterID,
+		lastAppliedHashAnnotation: options.LastAppliedHashAnnotation,
+		ignoredKeys:               options.IgnoreChangesToKeys,
 	}
 }
 
+func (f *createOrUpdateFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	_, err := f.distribute(ctx, obj, util.CreateOrUpdate)
+	return err
+}
+
+func (f *createOrUpdateFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	return f.distribute(ctx, obj, util.CreateOrUpdateDryRun)
+}
+
 //nolint:wrapcheck // This function is effectively a wrapper so no need to wrap errors.
-func (f *createOrUpdateFederator) Distribute(obj runtime.Object) error {
+func (f *createOrUpdateFederator) distribute(ctx context.Context, obj runtime.Object,
+	createOrUpdate func(context.Context, resource.Interface, runtime.Object, util.MutateFn) (util.OperationResult, error),
+) (util.OperationResult, error) {
 	logger.V(log.LIBTRACE).Infof("In Distribute for %#v", obj)
 
 	toDistribute, resourceClient, err := f.toUnstructured(obj)
 	if err != nil {
-		return err
+		return util.OperationResultNone, err
 	}
 
 	if f.localClusterID != "" {
@@ -59,10 +103,76 @@ func (f *createOrUpdateFederator) Distribute(obj runtime.Object) error {
 
 	f.prepareResourceForSync(toDistribute)
 
-	_, err = util.CreateOrUpdate(context.TODO(), resource.ForDynamic(resourceClient), toDistribute,
+	hashAnnotation := f.lastAppliedHashAnnotation
+	ignoredKeys := f.ignoredKeys
+
+	hash := ""
+	if hashAnnotation != "" {
+		hash, err = util.LastAppliedHash(withoutKeys(toDistribute, ignoredKeys))
+		if err != nil {
+			return util.OperationResultNone, err
+		}
+
+		util.SetNestedField(toDistribute.Object, hash, util.MetadataField, util.AnnotationsField, hashAnnotation)
+	}
+
+	return createOrUpdate(ctx, resource.ForDynamic(resourceClient), toDistribute,
 		func(obj runtime.Object) (runtime.Object, error) {
-			return util.CopyImmutableMetadata(obj.(*unstructured.Unstructured), toDistribute), nil
+			existing := obj.(*unstructured.Unstructured)
+			if hash != "" && existing.GetAnnotations()[hashAnnotation] == hash {
+				logger.V(log.LIBTRACE).Infof("Resource %q is unchanged since last applied - not updating", existing.GetName())
+				return existing, nil
+			}
+
+			updated := util.CopyImmutableMetadata(existing, toDistribute)
+
+			if len(ignoredKeys) > 0 &&
+				equality.Semantic.DeepEqual(withoutKeys(existing, ignoredKeys), withoutKeys(updated, ignoredKeys)) {
+				logger.V(log.LIBTRACE).Infof("Resource %q only differs in ignored keys - not updating", existing.GetName())
+				return existing, nil
+			}
+
+			return updated, nil
 		})
+}
 
-	return err
+// withoutKeys returns the given resource with the labels and annotations whose keys have one of the given prefixes
+// removed. If there are no prefixes, the resource is returned as is, otherwise a copy is returned.
+func withoutKeys(obj *unstructured.Unstructured, prefixes []string) *unstructured.Unstructured {
+	if len(prefixes) == 0 {
+		return obj
+	}
+
+	obj = obj.DeepCopy()
+
+	removeKeys := func(from map[string]string) map[string]string {
+		for key := range from {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(key, prefix) {
+					delete(from, key)
+					break
+				}
+			}
+		}
+
+		if len(from) == 0 {
+			return nil
+		}
+
+		return from
+	}
+
+	if labels := obj.GetLabels(); labels != nil {
+		obj.SetLabels(removeKeys(labels))
+	}
+
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		obj.SetAnnotations(removeKeys(annotations))
+	}
+
+	return obj
+}
+
+func (f *createOrUpdateFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
 }
@@ -20,35 +20,187 @@ limitations under the License.
 package fake
 
 import (
+	"context"
+	"sort"
+	"sync"
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+const (
+	OpDistribute = "Distribute"
+	OpDelete     = "Delete"
+)
+
+// Call records an invocation of Distribute or Delete.
+type Call struct {
+	Operation string
+	Resource  runtime.Object
+	Err       error
+}
+
+// Federator is a fake Federator that records the Distribute and Delete calls in order and keeps the distributed
+// resources in an in-memory store, keyed by namespace/name, from which deleted resources are removed.
 type Federator struct {
-	distribute       chan runtime.Object
-	delete           chan runtime.Object
-	FailOnDistribute error
-	FailOnDelete     error
-	ResetOnFailure   bool
+	distribute         chan runtime.Object
+	delete             chan runtime.Object
+	deleteAllFor       chan string
+	FailOnDistribute   error
+	FailOnDelete       error
+	FailOnDeleteAllFor error
+	ResetOnFailure     bool
+
+	mutex             sync.Mutex
+	calls             []Call
+	distributed       map[string]runtime.Object
+	failDistributeFor map[string]error
+	failDeleteFor     map[string]error
 }
 
 func New() *Federator {
 	return &Federator{
-		distribute:     make(chan runtime.Object, 100),
-		delete:         make(chan runtime.Object, 100),
-		ResetOnFailure: true,
+		distribute:        make(chan runtime.Object, 100),
+		delete:            make(chan runtime.Object, 100),
+		deleteAllFor:      make(chan string, 100),
+		ResetOnFailure:    true,
+		distributed:       map[string]runtime.Object{},
+		failDistributeFor: map[string]error{},
+		failDeleteFor:     map[string]error{},
 	}
 }
 
-func (f *Federator) Distribute(resource runtime.Object) error {
-	err := f.FailOnDistribute
+func keyFor(obj runtime.Object) string {
+	objMeta := resource.ToMeta(obj)
+	if objMeta.GetNamespace() == "" {
+		return objMeta.GetName()
+	}
+
+	return objMeta.GetNamespace() + "/" + objMeta.GetName()
+}
+
+// FailDistributeFor causes Distribute to fail with the given error for the resource with the given namespace/name key
+// until it's cleared by passing a nil error.
+func (f *Federator) FailDistributeFor(key string, err error) {
+	setFailure(&f.mutex, f.failDistributeFor, key, err)
+}
+
+// FailDeleteFor causes Delete to fail with the given error for the resource with the given namespace/name key until
+// it's cleared by passing a nil error.
+func (f *Federator) FailDeleteFor(key string, err error) {
+	setFailure(&f.mutex, f.failDeleteFor, key, err)
+}
+
+func setFailure(mutex *sync.Mutex, failures map[string]error, key string, err error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if err == nil {
+		delete(failures, key)
+	} else {
+		failures[key] = err
+	}
+}
+
+// record records the call and returns the error injected for the resource, if any, updating the store on success.
+func (f *Federator) record(op string, obj runtime.Object, err error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := keyFor(obj)
+
+	if err == nil {
+		failures := f.failDistributeFor
+		if op == OpDelete {
+			failures = f.failDeleteFor
+		}
+
+		err = failures[key]
+	}
+
+	f.calls = append(f.calls, Call{Operation: op, Resource: obj, Err: err})
+
 	if err != nil {
-		if f.ResetOnFailure {
-			f.FailOnDistribute = nil
+		return err
+	}
+
+	if op == OpDelete {
+		delete(f.distributed, key)
+	} else {
+		f.distributed[key] = obj.DeepCopyObject()
+	}
+
+	return nil
+}
+
+// Calls returns the recorded Distribute and Delete calls in the order they were made.
+func (f *Federator) Calls() []Call {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+// NumCalls returns the number of recorded calls of the given operation, ie OpDistribute or OpDelete.
+func (f *Federator) NumCalls(op string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	n := 0
+
+	for i := range f.calls {
+		if f.calls[i].Operation == op {
+			n++
 		}
+	}
 
+	return n
+}
+
+// Distributed returns the resources in the store, ie successfully distributed and not since deleted, sorted by key.
+func (f *Federator) Distributed() []runtime.Object {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	keys := make([]string, 0, len(f.distributed))
+	for key := range f.distributed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	objs := make([]runtime.Object, 0, len(keys))
+	for _, key := range keys {
+		objs = append(objs, f.distributed[key].DeepCopyObject())
+	}
+
+	return objs
+}
+
+// GetDistributed returns the resource with the given namespace/name key from the store, if present.
+func (f *Federator) GetDistributed(key string) (runtime.Object, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	obj, found := f.distributed[key]
+	if !found {
+		return nil, false
+	}
+
+	return obj.DeepCopyObject(), true
+}
+
+func (f *Federator) Distribute(ctx context.Context, resource runtime.Object) error {
+	err := f.FailOnDistribute
+	if err != nil && f.ResetOnFailure {
+		f.FailOnDistribute = nil
+	}
+
+	if err = f.record(OpDistribute, resource, err); err != nil {
 		return err
 	}
 
@@ -57,17 +209,48 @@ func (f *Federator) Distribute(resource runtime.Object) error {
 	return nil
 }
 
-func (f *Federator) Delete(resource runtime.Object) error {
+func (f *Federator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, resource := range resources {
+		if err := f.Distribute(ctx, resource); err != nil {
+			errs[resource] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *Federator) DistributeDryRun(ctx context.Context, resource runtime.Object) (util.OperationResult, error) {
+	return util.OperationResultNone, nil
+}
+
+func (f *Federator) Delete(ctx context.Context, resource runtime.Object) error {
 	err := f.FailOnDelete
+	if err != nil && f.ResetOnFailure {
+		f.FailOnDelete = nil
+	}
+
+	if err = f.record(OpDelete, resource, err); err != nil {
+		return err
+	}
+
+	f.delete <- resource
+
+	return nil
+}
+
+func (f *Federator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	err := f.FailOnDeleteAllFor
 	if err != nil {
 		if f.ResetOnFailure {
-			f.FailOnDelete = nil
+			f.FailOnDeleteAllFor = nil
 		}
 
 		return err
 	}
 
-	f.delete <- resource
+	f.deleteAllFor <- labelSelector
 
 	return nil
 }
@@ -87,3 +270,7 @@ func (f *Federator) VerifyDelete(expected runtime.Object) {
 func (f *Federator) VerifyNoDelete() {
 	Consistently(f.delete, 300*time.Millisecond).ShouldNot(Receive(), "Delete was unexpectedly called")
 }
+
+func (f *Federator) VerifyDeleteAllFor(expectedLabelSelector string) {
+	Eventually(f.deleteAllFor, 5).Should(Receive(Equal(expectedLabelSelector)), "DeleteAllFor was not called")
+}
@@ -0,0 +1,97 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Federator", func() {
+	var (
+		federator  *fake.Federator
+		pod1, pod2 *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		federator = fake.New()
+		pod1 = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns"}}
+		pod2 = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "ns"}}
+	})
+
+	When("resources are distributed and deleted", func() {
+		It("should record the calls in order and store the remaining resources", func() {
+			Expect(federator.Distribute(context.TODO(), pod2)).To(Succeed())
+			Expect(federator.Distribute(context.TODO(), pod1)).To(Succeed())
+			Expect(federator.Delete(context.TODO(), pod2)).To(Succeed())
+
+			Expect(federator.Calls()).To(Equal([]fake.Call{
+				{Operation: fake.OpDistribute, Resource: pod2},
+				{Operation: fake.OpDistribute, Resource: pod1},
+				{Operation: fake.OpDelete, Resource: pod2},
+			}))
+			Expect(federator.NumCalls(fake.OpDistribute)).To(Equal(2))
+			Expect(federator.NumCalls(fake.OpDelete)).To(Equal(1))
+
+			Expect(federator.Distributed()).To(Equal([]runtime.Object{pod1}))
+
+			_, found := federator.GetDistributed("ns/pod2")
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	When("a distribute error is injected for a resource", func() {
+		It("should fail only for that resource until cleared", func() {
+			injected := errors.New("mock error")
+			federator.FailDistributeFor("ns/pod1", injected)
+
+			Expect(federator.Distribute(context.TODO(), pod1)).To(MatchError(injected))
+			Expect(federator.Distribute(context.TODO(), pod1)).To(MatchError(injected))
+			Expect(federator.Distribute(context.TODO(), pod2)).To(Succeed())
+			Expect(federator.Distributed()).To(Equal([]runtime.Object{pod2}))
+			Expect(federator.Calls()[0].Err).To(MatchError(injected))
+
+			federator.FailDistributeFor("ns/pod1", nil)
+			Expect(federator.Distribute(context.TODO(), pod1)).To(Succeed())
+
+			obj, found := federator.GetDistributed("ns/pod1")
+			Expect(found).To(BeTrue())
+			Expect(obj).To(Equal(pod1))
+		})
+	})
+
+	When("a delete error is injected for a resource", func() {
+		It("should fail and keep the resource in the store", func() {
+			Expect(federator.Distribute(context.TODO(), pod1)).To(Succeed())
+
+			federator.FailDeleteFor("ns/pod1", errors.New("mock error"))
+			Expect(federator.Delete(context.TODO(), pod1)).ToNot(Succeed())
+
+			_, found := federator.GetDistributed("ns/pod1")
+			Expect(found).To(BeTrue())
+		})
+	})
+})
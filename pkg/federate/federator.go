@@ -19,7 +19,13 @@ limitations under the License.
 package federate
 
 import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // ClusterIDLabelKey is the key for a label that may be added to federated resources to hold the ID of the cluster from
@@ -34,12 +40,89 @@ type Federator interface {
 	//
 	// If the resource was previously distributed and the given resource differs, each previous cluster will receive the
 	// updated resource.
-	Distribute(resource runtime.Object) error
+	Distribute(ctx context.Context, resource runtime.Object) error
 
 	// Delete stops distributing the given resource and deletes it from all clusters to which it was distributed.
 	// The actual deletion may occur asynchronously in which any returned error only indicates that the request
-	// failed.
-	Delete(resource runtime.Object) error
+	// failed. The DeleteOptions carried by the context via WithDeleteOptions, if any, are used for the deletion.
+	Delete(ctx context.Context, resource runtime.Object) error
+}
+
+// BulkDistributor is an optional interface implemented by a Federator that provides its own means of distributing many
+// resources at once. See DistributeAll.
+type BulkDistributor interface {
+	// DistributeAll distributes the given resources, eg when seeding a new connection with many existing resources. The
+	// resources are written concurrently with the same semantics as Distribute. A failure for one resource does not
+	// abort the others - the returned map contains an entry for each resource that failed and is empty if all succeeded.
+	DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error
+}
+
+// DryRunDistributor is an optional interface implemented by a Federator that can preview a distribution. See
+// DistributeDryRun.
+type DryRunDistributor interface {
+	// DistributeDryRun previews the distribution of the given resource. The create or update request is sent with the
+	// DryRunAll option so nothing is persisted. The returned OperationResult indicates what would have been done.
+	DistributeDryRun(ctx context.Context, resource runtime.Object) (util.OperationResult, error)
+}
+
+// BulkDeleter is an optional interface implemented by a Federator that can delete all the resources of given types that
+// match a label selector. See DeleteAllFor.
+type BulkDeleter interface {
+	// DeleteAllFor deletes all resources of the given types in the clusters to which the Federator distributes that
+	// match the given label selector, eg to clean up the resources originating from a cluster that has left. The
+	// resources are retrieved from the clusters so it doesn't matter whether they were distributed by this instance.
+	// Resources that no longer exist are ignored and errors are aggregated so a failure to delete one resource doesn't
+	// prevent deletion of the others. The DeleteOptions carried by the context via WithDeleteOptions, if any, are used
+	// for the deletions.
+	DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error
+}
+
+// DistributeAll distributes the given resources via the given Federator's DistributeAll, if it implements
+// BulkDistributor, otherwise each resource is distributed concurrently via its Distribute. The returned map contains an
+// entry for each resource that failed and is empty if all succeeded.
+func DistributeAll(ctx context.Context, federator Federator, resources []runtime.Object) map[runtime.Object]error {
+	if d, ok := federator.(BulkDistributor); ok {
+		return d.DistributeAll(ctx, resources)
+	}
+
+	return distributeAll(ctx, federator.Distribute, resources)
+}
+
+// DistributeDryRun previews the distribution of the given resource via the given Federator's DistributeDryRun. An error
+// is returned if the Federator doesn't implement DryRunDistributor.
+func DistributeDryRun(ctx context.Context, federator Federator, resource runtime.Object) (util.OperationResult, error) {
+	d, ok := federator.(DryRunDistributor)
+	if !ok {
+		return util.OperationResultNone, errors.Errorf("federator %T does not support a dry-run distribute", federator)
+	}
+
+	return d.DistributeDryRun(ctx, resource) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+// DeleteAllFor deletes all resources of the given types that match the given label selector via the given Federator's
+// DeleteAllFor. An error is returned if the Federator doesn't implement BulkDeleter.
+func DeleteAllFor(ctx context.Context, federator Federator, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	d, ok := federator.(BulkDeleter)
+	if !ok {
+		return errors.Errorf("federator %T does not support deleting all resources for a label selector", federator)
+	}
+
+	return d.DeleteAllFor(ctx, labelSelector, gvrs...) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+type deleteOptionsKey struct{}
+
+// WithDeleteOptions returns a copy of the given context carrying the given DeleteOptions to be used by a Federator's
+// Delete, eg to specify the propagation policy for dependents of the deleted resource.
+func WithDeleteOptions(ctx context.Context, options metav1.DeleteOptions) context.Context {
+	return context.WithValue(ctx, deleteOptionsKey{}, options)
+}
+
+// DeleteOptionsFrom returns the DeleteOptions carried by the given context via WithDeleteOptions or the default
+// DeleteOptions if none.
+func DeleteOptionsFrom(ctx context.Context) metav1.DeleteOptions {
+	options, _ := ctx.Value(deleteOptionsKey{}).(metav1.DeleteOptions)
+	return options
 }
 
 type noopFederator struct{}
@@ -48,10 +131,22 @@ func NewNoopFederator() Federator {
 	return &noopFederator{}
 }
 
-func (n noopFederator) Distribute(resource runtime.Object) error {
+func (n noopFederator) Distribute(ctx context.Context, resource runtime.Object) error {
+	return nil
+}
+
+func (n noopFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return map[runtime.Object]error{}
+}
+
+func (n noopFederator) DistributeDryRun(ctx context.Context, resource runtime.Object) (util.OperationResult, error) {
+	return util.OperationResultNone, nil
+}
+
+func (n noopFederator) Delete(ctx context.Context, resource runtime.Object) error {
 	return nil
 }
 
-func (n noopFederator) Delete(resource runtime.Object) error {
+func (n noopFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
 	return nil
 }
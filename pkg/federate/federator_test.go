@@ -18,7 +18,9 @@ limitations under the License.
 package federate_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -30,9 +32,11 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
 )
 
 var (
@@ -41,6 +45,11 @@ var (
 	_ = Describe("Update Federator", testUpdateFederator)
 	_ = Describe("Update Status Federator", testUpdateStatusFederator)
 	_ = Describe("Federator Delete", testDelete)
+	_ = Describe("Federator DeleteAllFor", testDeleteAllFor)
+	_ = Describe("Federator DistributeAll", testDistributeAll)
+	_ = Describe("Federator DistributeDryRun", testDistributeDryRun)
+	_ = Describe("Federator LastAppliedHashAnnotation", testLastAppliedHash)
+	_ = Describe("Federator IgnoredChangeKeys", testIgnoredChangeKeys)
 )
 
 func testCreateOrUpdateFederator() {
@@ -60,7 +69,7 @@ func testCreateOrUpdateFederator() {
 	When("the resource does not already exist in the datastore", func() {
 		Context("and a local cluster ID is specified", func() {
 			It("should create the resource with the cluster ID label", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 		})
@@ -71,7 +80,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should create the resource without the cluster ID label", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 		})
@@ -85,7 +94,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should create the resource with the Status data", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 		})
@@ -102,7 +111,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should create the resource with the OwnerReferences", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 		})
@@ -113,7 +122,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should return an error", func() {
-				Expect(f.Distribute(t.resource)).ToNot(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).ToNot(Succeed())
 			})
 		})
 
@@ -128,7 +137,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should update the resource", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 		})
@@ -142,7 +151,7 @@ func testCreateOrUpdateFederator() {
 		})
 
 		It("should update the resource", func() {
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 			t.verifyResource()
 		})
 
@@ -152,7 +161,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should retry until it succeeds", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 		})
@@ -163,7 +172,7 @@ func testCreateOrUpdateFederator() {
 			})
 
 			It("should return an error", func() {
-				Expect(f.Distribute(t.resource)).ToNot(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).ToNot(Succeed())
 			})
 		})
 	})
@@ -174,7 +183,7 @@ func testCreateOrUpdateFederator() {
 		})
 
 		It("should return an error", func() {
-			Expect(f.Distribute(t.resource)).ToNot(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).ToNot(Succeed())
 		})
 	})
 
@@ -185,7 +194,7 @@ func testCreateOrUpdateFederator() {
 		})
 
 		It("should create the resource in the source namespace", func() {
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 			t.verifyResource()
 		})
 	})
@@ -208,7 +217,7 @@ func testCreateFederator() {
 
 	When("the resource does not already exist in the datastore", func() {
 		It("create the resource", func() {
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 			t.verifyResource()
 		})
 
@@ -218,7 +227,7 @@ func testCreateFederator() {
 			})
 
 			It("should return an error", func() {
-				Expect(f.Distribute(t.resource)).ToNot(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).ToNot(Succeed())
 			})
 		})
 	})
@@ -230,7 +239,7 @@ func testCreateFederator() {
 		})
 
 		It("should succeed and not update the resource", func() {
-			Expect(f.Distribute(test.NewPodWithImage(test.LocalNamespace, "apache"))).To(Succeed())
+			Expect(f.Distribute(context.TODO(), test.NewPodWithImage(test.LocalNamespace, "apache"))).To(Succeed())
 			t.verifyResource()
 		})
 	})
@@ -261,7 +270,7 @@ func testUpdateFederator() {
 		})
 
 		It("should update the resource", func() {
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 			t.verifyResource()
 		})
 
@@ -271,7 +280,7 @@ func testUpdateFederator() {
 			})
 
 			It("should retry until it succeeds", func() {
-				Expect(f.Distribute(t.resource)).To(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 				t.verifyResource()
 			})
 
@@ -281,7 +290,7 @@ func testUpdateFederator() {
 				})
 
 				It("should return an error", func() {
-					Expect(f.Distribute(t.resource)).ToNot(Succeed())
+					Expect(f.Distribute(context.TODO(), t.resource)).ToNot(Succeed())
 				})
 			})
 		})
@@ -292,14 +301,14 @@ func testUpdateFederator() {
 			})
 
 			It("should return an error", func() {
-				Expect(f.Distribute(t.resource)).ToNot(Succeed())
+				Expect(f.Distribute(context.TODO(), t.resource)).ToNot(Succeed())
 			})
 		})
 	})
 
 	When("the resource does not exist in the datastore", func() {
 		It("should succeed", func() {
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 		})
 	})
 }
@@ -335,7 +344,7 @@ func testUpdateStatusFederator() {
 				},
 			}
 
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 			t.verifyResource()
 		})
 	})
@@ -353,7 +362,7 @@ func testUpdateStatusFederator() {
 				PodIP: "1.2.3.4",
 			}
 
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 			t.verifyResource()
 		})
 	})
@@ -374,7 +383,7 @@ func testUpdateStatusFederator() {
 			t.resource.Annotations = map[string]string{"key1": "abc"}
 			t.resource.Labels = map[string]string{"key2": "def"}
 
-			Expect(f.Distribute(t.resource)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
 
 			t.resource = prev
 
@@ -405,7 +414,7 @@ func testDelete() {
 		})
 
 		It("should delete the resource", func() {
-			Expect(f.Delete(t.resource)).To(Succeed())
+			Expect(f.Delete(context.TODO(), t.resource)).To(Succeed())
 
 			_, err := test.GetResourceAndError(t.resourceClient, t.resource)
 			Expect(apierrors.IsNotFound(err)).To(BeTrue())
@@ -417,7 +426,17 @@ func testDelete() {
 			})
 
 			It("should return an error", func() {
-				Expect(f.Delete(t.resource)).ToNot(Succeed())
+				Expect(f.Delete(context.TODO(), t.resource)).ToNot(Succeed())
+			})
+		})
+
+		Context("and DeleteOptions are specified via the context", func() {
+			It("should delete the resource with the DeleteOptions", func() {
+				policy := metav1.DeletePropagationOrphan
+				Expect(f.Delete(federate.WithDeleteOptions(context.TODO(), metav1.DeleteOptions{PropagationPolicy: &policy}),
+					t.resource)).To(Succeed())
+
+				Expect(t.resourceClient.DeleteOptionsFor(t.resource.Name)).To(Equal(&metav1.DeleteOptions{PropagationPolicy: &policy}))
 			})
 		})
 
@@ -428,7 +447,7 @@ func testDelete() {
 			})
 
 			It("should delete the resource from the source namespace", func() {
-				Expect(f.Delete(t.resource)).To(Succeed())
+				Expect(f.Delete(context.TODO(), t.resource)).To(Succeed())
 
 				_, err := test.GetResourceAndError(t.resourceClient, t.resource)
 				Expect(apierrors.IsNotFound(err)).To(BeTrue())
@@ -438,11 +457,490 @@ func testDelete() {
 
 	When("the resource does not exist in the datastore", func() {
 		It("should return NotFound error", func() {
-			Expect(apierrors.IsNotFound(f.Delete(t.resource))).To(BeTrue())
+			Expect(apierrors.IsNotFound(f.Delete(context.TODO(), t.resource))).To(BeTrue())
+		})
+	})
+}
+
+func testDeleteAllFor() {
+	var (
+		f         federate.Federator
+		t         *testDriver
+		eastPods  []*corev1.Pod
+		westPods  []*corev1.Pod
+		selector  string
+		gvr       schema.GroupVersionResource
+		newPodFor = func(name, clusterID string) *corev1.Pod {
+			pod := test.NewPod(test.LocalNamespace)
+			pod.Name = name
+			pod.Labels = map[string]string{federate.ClusterIDLabelKey: clusterID}
+
+			return pod
+		}
+	)
+
+	BeforeEach(func() {
+		t = newTestDriver()
+		t.localClusterID = ""
+		selector = federate.ClusterIDLabelKey + "=east"
+		_, podGVR := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		gvr = *podGVR
+		eastPods = []*corev1.Pod{newPodFor("east-1", "east"), newPodFor("east-2", "east"), newPodFor("east-3", "east")}
+		westPods = []*corev1.Pod{newPodFor("west-1", "west"), newPodFor("west-2", "west")}
+	})
+
+	JustBeforeEach(func() {
+		f = federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID)
+
+		for _, pod := range append(append([]*corev1.Pod{}, eastPods...), westPods...) {
+			Expect(f.Distribute(context.TODO(), pod)).To(Succeed())
+		}
+	})
+
+	verifyDeleted := func(pods []*corev1.Pod) {
+		for _, pod := range pods {
+			_, err := test.GetResourceAndError(t.resourceClient, pod)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), "Pod %q was not deleted", pod.Name)
+		}
+	}
+
+	countRemaining := func(labelSelector string) int {
+		list, err := t.resourceClient.List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+		Expect(err).To(Succeed())
+
+		return len(list.Items)
+	}
+
+	verifyNotDeleted := func(pods []*corev1.Pod) {
+		for _, pod := range pods {
+			_, err := test.GetResourceAndError(t.resourceClient, pod)
+			Expect(err).To(Succeed(), "Pod %q was unexpectedly deleted", pod.Name)
+		}
+	}
+
+	It("should delete all the distributed resources matching the selector", func() {
+		Expect(federate.DeleteAllFor(context.TODO(), f, selector, gvr)).To(Succeed())
+		verifyDeleted(eastPods)
+		verifyNotDeleted(westPods)
+	})
+
+	When("invoked on a new Federator instance, eg after a restart", func() {
+		It("should delete all the previously distributed resources matching the selector", func() {
+			f = federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID)
+			Expect(federate.DeleteAllFor(context.TODO(), f, selector, gvr)).To(Succeed())
+			verifyDeleted(eastPods)
+			verifyNotDeleted(westPods)
+		})
+	})
+
+	When("DeleteOptions are specified via the context", func() {
+		It("should delete the resources with the DeleteOptions", func() {
+			policy := metav1.DeletePropagationOrphan
+			Expect(federate.DeleteAllFor(federate.WithDeleteOptions(context.TODO(), metav1.DeleteOptions{PropagationPolicy: &policy}),
+				f, selector, gvr)).To(Succeed())
+
+			for _, pod := range eastPods {
+				Expect(t.resourceClient.DeleteOptionsFor(pod.Name)).To(Equal(&metav1.DeleteOptions{PropagationPolicy: &policy}))
+			}
+		})
+	})
+
+	When("no distributed resources match the selector", func() {
+		It("should succeed", func() {
+			Expect(federate.DeleteAllFor(context.TODO(), f, federate.ClusterIDLabelKey+"=north", gvr)).To(Succeed())
+			verifyNotDeleted(eastPods)
+			verifyNotDeleted(westPods)
+		})
+	})
+
+	When("a resource is deleted out of band", func() {
+		JustBeforeEach(func() {
+			t.resourceClient.FailOnDelete = apierrors.NewNotFound(schema.GroupResource{}, eastPods[0].Name)
+		})
+
+		It("should ignore the NotFound error", func() {
+			Expect(federate.DeleteAllFor(context.TODO(), f, selector, gvr)).To(Succeed())
+			Expect(countRemaining(selector)).To(Equal(1))
+			verifyNotDeleted(westPods)
+		})
+	})
+
+	When("delete fails for a resource", func() {
+		JustBeforeEach(func() {
+			t.resourceClient.FailOnDelete = apierrors.NewServiceUnavailable("fake")
+		})
+
+		It("should return an error and continue deleting the others", func() {
+			Expect(federate.DeleteAllFor(context.TODO(), f, selector, gvr)).ToNot(Succeed())
+			Expect(countRemaining(selector)).To(Equal(1))
+			verifyNotDeleted(westPods)
+		})
+	})
+
+	When("the Federator doesn't implement BulkDeleter", func() {
+		It("should return an error", func() {
+			Expect(federate.DeleteAllFor(context.TODO(), &basicFederator{Federator: f}, selector, gvr)).ToNot(Succeed())
+			verifyNotDeleted(eastPods)
+		})
+
+		Context("and is wrapped", func() {
+			It("should return an error", func() {
+				wrapped := federate.NewRateLimitingFederator(&basicFederator{Federator: f}, t.restMapper, nil)
+				Expect(federate.DeleteAllFor(context.TODO(), wrapped, selector, gvr)).ToNot(Succeed())
+				verifyNotDeleted(eastPods)
+			})
 		})
 	})
 }
 
+func testDistributeAll() {
+	var (
+		f         federate.Federator
+		t         *testDriver
+		resources []runtime.Object
+	)
+
+	BeforeEach(func() {
+		t = newTestDriver()
+
+		resources = nil
+
+		for i := 1; i <= 20; i++ {
+			pod := test.NewPod(test.LocalNamespace)
+			pod.Name = fmt.Sprintf("pod-%d", i)
+			resources = append(resources, pod)
+		}
+	})
+
+	JustBeforeEach(func() {
+		f = federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID)
+	})
+
+	verifyDistributed := func(objs ...runtime.Object) {
+		for _, obj := range objs {
+			pod := obj.(*corev1.Pod)
+			_, err := test.GetResourceAndError(t.resourceClient, pod)
+			Expect(err).To(Succeed(), "Pod %q was not distributed", pod.Name)
+		}
+	}
+
+	When("all the writes succeed", func() {
+		It("should distribute all the resources and return no errors", func() {
+			Expect(federate.DistributeAll(context.TODO(), f, resources)).To(BeEmpty())
+			verifyDistributed(resources...)
+		})
+	})
+
+	When("the write of one resource fails", func() {
+		var failing *corev1.Pod
+
+		BeforeEach(func() {
+			failing = resources[7].(*corev1.Pod)
+
+			t.dynClient.PrependReactor("create", "pods", func(action testing.Action) (bool, runtime.Object, error) {
+				obj := action.(testing.CreateAction).GetObject().(metav1.Object)
+				if obj.GetName() == failing.Name {
+					return true, nil, apierrors.NewServiceUnavailable("fake")
+				}
+
+				return false, nil, nil
+			})
+		})
+
+		It("should return its error and still distribute the others", func() {
+			errs := federate.DistributeAll(context.TODO(), f, resources)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs).To(HaveKey(failing))
+			Expect(apierrors.IsServiceUnavailable(errs[failing])).To(BeTrue())
+
+			verifyDistributed(append(append([]runtime.Object{}, resources[:7]...), resources[8:]...)...)
+		})
+	})
+
+	When("a resource already exists and its update initially fails due to conflict", func() {
+		BeforeEach(func() {
+			existing := test.NewPodWithImage(t.targetNamespace, "apache")
+			existing.Name = resources[0].(*corev1.Pod).Name
+			test.CreateResource(t.resourceClient, existing)
+
+			t.resourceClient.FailOnUpdate = apierrors.NewConflict(schema.GroupResource{}, "", errors.New("fake"))
+		})
+
+		It("should retry and distribute all the resources", func() {
+			Expect(federate.DistributeAll(context.TODO(), f, resources)).To(BeEmpty())
+			verifyDistributed(resources...)
+
+			t.resource = resources[0].(*corev1.Pod)
+			t.verifyResource()
+		})
+	})
+
+	When("the Federator doesn't implement BulkDistributor", func() {
+		It("should distribute each resource via Distribute", func() {
+			Expect(federate.DistributeAll(context.TODO(), &basicFederator{Federator: f}, resources)).To(BeEmpty())
+			verifyDistributed(resources...)
+		})
+	})
+}
+
+func testDistributeDryRun() {
+	var t *testDriver
+
+	BeforeEach(func() {
+		t = newTestDriver()
+	})
+
+	verifyNotPersisted := func() {
+		_, err := test.GetResourceAndError(t.resourceClient, t.resource)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	}
+
+	Context("with the CreateOrUpdate Federator", func() {
+		var f federate.Federator
+
+		JustBeforeEach(func() {
+			f = federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID)
+		})
+
+		When("the resource does not already exist in the datastore", func() {
+			It("should report Created and not persist the resource", func() {
+				Expect(federate.DistributeDryRun(context.TODO(), f, t.resource)).To(Equal(util.OperationResultCreated))
+				verifyNotPersisted()
+			})
+		})
+
+		When("the resource already exists in the datastore", func() {
+			var existing *corev1.Pod
+
+			BeforeEach(func() {
+				existing = t.resource.DeepCopy()
+				existing.SetNamespace(t.targetNamespace)
+				test.CreateResource(t.resourceClient, existing)
+				t.resource = test.NewPodWithImage(test.LocalNamespace, "apache")
+			})
+
+			It("should report Updated and not persist the update", func() {
+				Expect(federate.DistributeDryRun(context.TODO(), f, t.resource)).To(Equal(util.OperationResultUpdated))
+
+				t.resource = existing
+				t.localClusterID = ""
+				t.verifyResource()
+			})
+		})
+
+		When("create fails", func() {
+			BeforeEach(func() {
+				t.resourceClient.FailOnCreate = apierrors.NewServiceUnavailable("fake")
+			})
+
+			It("should return an error", func() {
+				_, err := federate.DistributeDryRun(context.TODO(), f, t.resource)
+				Expect(err).ToNot(Succeed())
+			})
+		})
+	})
+
+	Context("with the Create Federator", func() {
+		It("should report Created and not persist the resource", func() {
+			f := federate.NewCreateFederator(t.dynClient, t.restMapper, t.federatorNamespace)
+			Expect(federate.DistributeDryRun(context.TODO(), f, t.resource)).To(Equal(util.OperationResultCreated))
+			verifyNotPersisted()
+		})
+	})
+
+	Context("with the Update Federator", func() {
+		It("should report Updated and not persist the update", func() {
+			existing := t.resource.DeepCopy()
+			existing.SetNamespace(t.targetNamespace)
+			test.CreateResource(t.resourceClient, existing)
+
+			f := federate.NewUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, util.CopyImmutableMetadata)
+			Expect(federate.DistributeDryRun(context.TODO(), f, test.NewPodWithImage(test.LocalNamespace, "apache"))).To(
+				Equal(util.OperationResultUpdated))
+
+			t.resource = existing
+			t.localClusterID = ""
+			t.verifyResource()
+		})
+	})
+
+	Context("with a Federator that doesn't implement DryRunDistributor", func() {
+		It("should return an error and not persist the resource", func() {
+			f := &basicFederator{Federator: federate.NewCreateFederator(t.dynClient, t.restMapper, t.federatorNamespace)}
+			_, err := federate.DistributeDryRun(context.TODO(), f, t.resource)
+			Expect(err).To(HaveOccurred())
+			verifyNotPersisted()
+		})
+	})
+}
+
+func testLastAppliedHash() {
+	const hashAnnotation = "submariner-io/last-applied-hash"
+
+	var (
+		f federate.Federator
+		t *testDriver
+	)
+
+	BeforeEach(func() {
+		t = newTestDriver()
+
+		// Simulate the server defaulting a field on create.
+		t.resourceClient.MutateOnCreate = func(obj *unstructured.Unstructured) {
+			_ = unstructured.SetNestedField(obj.Object, string(corev1.RestartPolicyAlways), "spec", "restartPolicy")
+		}
+	})
+
+	JustBeforeEach(func() {
+		f = federate.NewCreateOrUpdateFederatorWithOptions(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID,
+			federate.CreateOrUpdateFederatorOptions{LastAppliedHashAnnotation: hashAnnotation})
+
+		Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+		t.dynClient.ClearActions()
+	})
+
+	numUpdates := func() int {
+		n := 0
+
+		for _, action := range t.dynClient.Actions() {
+			if action.GetVerb() == "update" {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	It("should record the hash annotation on the created resource", func() {
+		created := test.GetPod(t.resourceClient, t.resource)
+		Expect(created.Annotations).To(HaveKey(hashAnnotation))
+		Expect(created.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+	})
+
+	When("the resource is distributed again unchanged", func() {
+		It("should not rewrite the resource with server-defaulted fields", func() {
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(BeZero())
+			Expect(test.GetPod(t.resourceClient, t.resource).Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+		})
+
+		Context("by a Federator without the hash annotation", func() {
+			It("should rewrite the resource", func() {
+				f = federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				Expect(numUpdates()).To(Equal(1))
+			})
+		})
+	})
+
+	When("the resource's spec changes", func() {
+		It("should rewrite the resource with the new hash", func() {
+			prevHash := test.GetPod(t.resourceClient, t.resource).Annotations[hashAnnotation]
+
+			t.resource = test.NewPodWithImage(test.LocalNamespace, "apache")
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(Equal(1))
+
+			updated := test.GetPod(t.resourceClient, t.resource)
+			Expect(updated.Spec.Containers[0].Image).To(Equal("apache"))
+			Expect(updated.Annotations[hashAnnotation]).ToNot(Equal(prevHash))
+		})
+	})
+}
+
+func testIgnoredChangeKeys() {
+	const ignoredAnnotation = "example.io/last-reconciled"
+
+	var (
+		f       federate.Federator
+		t       *testDriver
+		options federate.CreateOrUpdateFederatorOptions
+	)
+
+	BeforeEach(func() {
+		t = newTestDriver()
+		options = federate.CreateOrUpdateFederatorOptions{IgnoreChangesToKeys: []string{"example.io/"}}
+		t.resource.Annotations[ignoredAnnotation] = "1"
+	})
+
+	JustBeforeEach(func() {
+		f = federate.NewCreateOrUpdateFederatorWithOptions(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID,
+			options)
+
+		Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+		t.dynClient.ClearActions()
+	})
+
+	numUpdates := func() int {
+		n := 0
+
+		for _, action := range t.dynClient.Actions() {
+			if action.GetVerb() == "update" {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	When("only an ignored annotation changes", func() {
+		It("should not rewrite the resource", func() {
+			t.resource.Annotations[ignoredAnnotation] = "2"
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(BeZero())
+			Expect(test.GetPod(t.resourceClient, t.resource).Annotations).To(HaveKeyWithValue(ignoredAnnotation, "1"))
+		})
+	})
+
+	When("an ignored annotation is removed", func() {
+		It("should not rewrite the resource", func() {
+			delete(t.resource.Annotations, ignoredAnnotation)
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(BeZero())
+		})
+	})
+
+	When("an ignored annotation changes along with the spec", func() {
+		It("should rewrite the resource including the ignored annotation", func() {
+			t.resource.Annotations[ignoredAnnotation] = "2"
+			t.resource.Spec.Containers[0].Image = "apache"
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(Equal(1))
+
+			updated := test.GetPod(t.resourceClient, t.resource)
+			Expect(updated.Spec.Containers[0].Image).To(Equal("apache"))
+			Expect(updated.Annotations).To(HaveKeyWithValue(ignoredAnnotation, "2"))
+		})
+	})
+
+	When("a non-ignored annotation changes", func() {
+		It("should rewrite the resource", func() {
+			t.resource.Annotations["other"] = "value"
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(Equal(1))
+		})
+	})
+
+	When("combined with the LastAppliedHashAnnotation", func() {
+		const hashAnnotation = "submariner-io/last-applied-hash"
+
+		BeforeEach(func() {
+			options.LastAppliedHashAnnotation = hashAnnotation
+		})
+
+		It("should not rewrite the resource when only an ignored annotation changes", func() {
+			t.resource.Annotations[ignoredAnnotation] = "2"
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(numUpdates()).To(BeZero())
+		})
+	})
+}
+
+// basicFederator only implements the Federator interface, hiding the optional interfaces of the wrapped Federator.
+type basicFederator struct {
+	federate.Federator
+}
+
 type testDriver struct {
 	resource           *corev1.Pod
 	localClusterID     string
@@ -0,0 +1,289 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+	"sync"
+
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// IdentityFunc maps a source resource to the identity, ie the namespace and name, of the resource distributed for it. An
+// empty namespace retains the source resource's namespace.
+type IdentityFunc func(source runtime.Object) types.NamespacedName
+
+type identityFederator struct {
+	Federator
+	identity IdentityFunc
+	mutex    sync.Mutex
+
+	// sources maps each distributed resource to the keys of the source resources distributed as it.
+	sources map[objectKey]sets.String
+
+	// identities maps the key of each distributed source resource to the resource it was last distributed as.
+	identities map[string]objectKey
+
+	// locks serializes the writes of each distributed resource.
+	locks map[objectKey]*identityLock
+}
+
+// objectKey identifies a resource by its kind as well as its namespace and name.
+type objectKey struct {
+	gvk schema.GroupVersionKind
+	id  types.NamespacedName
+}
+
+func (k objectKey) String() string {
+	return k.gvk.String() + " " + k.id.String()
+}
+
+type identityLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewIdentityFederator returns a Federator that distributes each resource under the identity returned by the given
+// IdentityFunc, eg for derived resources whose names are transformed, delegating to the given Federator. The same
+// identity is used by Delete so the distributed resource is found. Multiple source resources of the same kind may map to
+// the same identity, in which case the distributed resource is reference-counted - each Distribute overwrites it and
+// it's only deleted when the last of its sources is deleted. If a source resource's identity changes, the resource
+// previously distributed for it is released likewise. The writes of each distributed resource are serialized so a
+// reference added by a Distribute can't race with the Delete of another source. The references are tracked in memory
+// so, eg after a restart, the Delete of a source that wasn't distributed via this Federator deletes the resource at its
+// identity. DeleteAllFor is delegated and clears the references to resources of the given types.
+func NewIdentityFederator(federator Federator, identity IdentityFunc) Federator {
+	return &identityFederator{
+		Federator:  federator,
+		identity:   identity,
+		sources:    map[objectKey]sets.String{},
+		identities: map[string]objectKey{},
+		locks:      map[objectKey]*identityLock{},
+	}
+}
+
+func (f *identityFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	sourceKey := keyOf(obj).String()
+	target := f.targetOf(obj)
+
+	unlock := f.lock(target)
+
+	err := f.Federator.Distribute(ctx, withIdentity(obj, target.id))
+	if err != nil {
+		unlock()
+		return err //nolint:wrapcheck // This function is effectively a wrapper
+	}
+
+	// The reference is only recorded once the write succeeds so, if it fails after an identity change, the retry still
+	// releases the previous identity. It's recorded before the target is unlocked so a concurrent Delete of another
+	// source sees it.
+	f.mutex.Lock()
+
+	prev, found := f.identities[sourceKey]
+	f.identities[sourceKey] = target
+
+	if f.sources[target] == nil {
+		f.sources[target] = sets.NewString()
+	}
+
+	f.sources[target].Insert(sourceKey)
+
+	f.mutex.Unlock()
+
+	unlock()
+
+	if found && prev != target {
+		logger.V(log.LIBDEBUG).Infof("The identity of %q changed from %q to %q - releasing the previous resource", sourceKey,
+			prev.id, target.id)
+
+		return f.releaseAndDelete(ctx, obj, sourceKey, prev)
+	}
+
+	return nil
+}
+
+func (f *identityFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
+}
+
+func (f *identityFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	err := DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...)
+	if err != nil {
+		return err //nolint:wrapcheck // This function is effectively a wrapper
+	}
+
+	f.forget(gvrs)
+
+	return nil
+}
+
+// forget clears the references to the distributed resources of the given types. The labels of the resources as
+// written by the delegate Federator aren't known so the references to all resources of the types are cleared - a
+// subsequent Delete of a source whose resource wasn't deleted deletes it as after a restart.
+func (f *identityFederator) forget(gvrs []schema.GroupVersionResource) {
+	resources := map[schema.GroupVersionResource]bool{}
+	for _, gvr := range gvrs {
+		resources[gvr] = true
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for target := range f.sources {
+		if gvr, _ := meta.UnsafeGuessKindToResource(target.gvk); resources[gvr] {
+			delete(f.sources, target)
+		}
+	}
+
+	for sourceKey, target := range f.identities {
+		if _, found := f.sources[target]; !found {
+			delete(f.identities, sourceKey)
+		}
+	}
+}
+
+func (f *identityFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	//nolint:wrapcheck // This function is effectively a wrapper
+	return DistributeDryRun(ctx, f.Federator, withIdentity(obj, f.identityOf(obj)))
+}
+
+func (f *identityFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	sourceKey := keyOf(obj).String()
+
+	f.mutex.Lock()
+
+	target, found := f.identities[sourceKey]
+	if !found {
+		target = f.targetOf(obj)
+	}
+
+	delete(f.identities, sourceKey)
+
+	f.mutex.Unlock()
+
+	return f.releaseAndDelete(ctx, obj, sourceKey, target)
+}
+
+// releaseAndDelete removes the given source's reference to the given target resource and deletes the target if no
+// references remain.
+func (f *identityFederator) releaseAndDelete(ctx context.Context, obj runtime.Object, sourceKey string, target objectKey) error {
+	unlock := f.lock(target)
+	defer unlock()
+
+	f.mutex.Lock()
+
+	refs := f.sources[target]
+	refs.Delete(sourceKey)
+
+	last := refs.Len() == 0
+	if last {
+		delete(f.sources, target)
+	}
+
+	f.mutex.Unlock()
+
+	if !last {
+		logger.V(log.LIBDEBUG).Infof("Resource %q is still referenced by other sources - not deleting it for %q", target.id,
+			sourceKey)
+		return nil
+	}
+
+	return f.Federator.Delete(ctx, withIdentity(obj, target.id)) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+// lock acquires the lock that serializes the writes of the given target resource and returns a function to release it.
+func (f *identityFederator) lock(target objectKey) func() {
+	f.mutex.Lock()
+
+	l := f.locks[target]
+	if l == nil {
+		l = &identityLock{}
+		f.locks[target] = l
+	}
+
+	l.refs++
+
+	f.mutex.Unlock()
+
+	l.Lock()
+
+	return func() {
+		l.Unlock()
+
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		l.refs--
+		if l.refs == 0 {
+			delete(f.locks, target)
+		}
+	}
+}
+
+// targetOf returns the key of the resource distributed for the given source resource, which is of the same kind.
+func (f *identityFederator) targetOf(obj runtime.Object) objectKey {
+	return objectKey{gvk: gvkOf(obj), id: f.identityOf(obj)}
+}
+
+func (f *identityFederator) identityOf(obj runtime.Object) types.NamespacedName {
+	id := f.identity(obj)
+	if id.Namespace == "" {
+		id.Namespace = resource.ToMeta(obj).GetNamespace()
+	}
+
+	return id
+}
+
+func withIdentity(obj runtime.Object, id types.NamespacedName) runtime.Object {
+	obj = obj.DeepCopyObject()
+
+	objMeta := resource.ToMeta(obj)
+	objMeta.SetNamespace(id.Namespace)
+	objMeta.SetName(id.Name)
+
+	return obj
+}
+
+func keyOf(obj runtime.Object) objectKey {
+	objMeta := resource.ToMeta(obj)
+
+	return objectKey{gvk: gvkOf(obj), id: types.NamespacedName{Namespace: objMeta.GetNamespace(), Name: objMeta.GetName()}}
+}
+
+// gvkOf returns the kind of the given resource, as set on the resource or, for a typed resource, as registered with the
+// global k8s scheme.
+func gvkOf(obj runtime.Object) schema.GroupVersionKind {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk
+	}
+
+	if kinds, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(kinds) > 0 {
+		return kinds[0]
+	}
+
+	return schema.GroupVersionKind{}
+}
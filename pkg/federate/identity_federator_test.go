@@ -0,0 +1,292 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Identity Federator", func() {
+	const groupLabel = "example.io/group"
+
+	var (
+		f        federate.Federator
+		t        *testDriver
+		identity federate.IdentityFunc
+	)
+
+	BeforeEach(func() {
+		t = newTestDriver()
+	})
+
+	JustBeforeEach(func() {
+		f = federate.NewIdentityFederator(
+			federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID), identity)
+	})
+
+	When("the identity is a transformed name", func() {
+		BeforeEach(func() {
+			identity = func(source runtime.Object) types.NamespacedName {
+				return types.NamespacedName{Name: "derived-" + resource.ToMeta(source).GetName()}
+			}
+		})
+
+		It("should distribute and delete the resource under the transformed identity", func() {
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			test.AwaitResource(t.resourceClient, "derived-"+t.resource.Name)
+			test.AwaitNoResource(t.resourceClient, t.resource.Name)
+
+			Expect(f.Delete(context.TODO(), t.resource)).To(Succeed())
+			test.AwaitNoResource(t.resourceClient, "derived-"+t.resource.Name)
+		})
+
+		It("should not modify the source resource", func() {
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			Expect(t.resource.Name).To(Equal("test-pod"))
+			Expect(t.resource.Namespace).To(Equal(test.LocalNamespace))
+		})
+	})
+
+	When("multiple sources map to the same identity", func() {
+		var podA, podB runtime.Object
+
+		BeforeEach(func() {
+			identity = func(source runtime.Object) types.NamespacedName {
+				return types.NamespacedName{Name: "shared-" + resource.ToMeta(source).GetLabels()[groupLabel]}
+			}
+
+			podA = test.NewPod(test.LocalNamespace, test.WithName("pod-a"), test.WithLabels(map[string]string{groupLabel: "x"}))
+			podB = test.NewPod(test.LocalNamespace, test.WithName("pod-b"), test.WithLabels(map[string]string{groupLabel: "x"}))
+		})
+
+		JustBeforeEach(func() {
+			Expect(f.Distribute(context.TODO(), podA)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), podB)).To(Succeed())
+			test.AwaitResource(t.resourceClient, "shared-x")
+		})
+
+		It("should only delete the destination resource when the last source is deleted", func() {
+			Expect(f.Delete(context.TODO(), podA)).To(Succeed())
+			t.resourceClient.VerifyNoDelete("shared-x")
+			test.AwaitResource(t.resourceClient, "shared-x")
+
+			Expect(f.Delete(context.TODO(), podB)).To(Succeed())
+			test.AwaitNoResource(t.resourceClient, "shared-x")
+		})
+
+		It("should not release a reference more than once for the same source", func() {
+			Expect(f.Distribute(context.TODO(), podA)).To(Succeed())
+
+			Expect(f.Delete(context.TODO(), podA)).To(Succeed())
+			Expect(f.Delete(context.TODO(), podA)).To(Succeed())
+			t.resourceClient.VerifyNoDelete("shared-x")
+		})
+
+		Context("and a source's identity changes", func() {
+			It("should release its reference to the previous identity", func() {
+				resource.ToMeta(podA).SetLabels(map[string]string{groupLabel: "y"})
+				Expect(f.Distribute(context.TODO(), podA)).To(Succeed())
+				test.AwaitResource(t.resourceClient, "shared-y")
+				test.AwaitResource(t.resourceClient, "shared-x")
+
+				resource.ToMeta(podB).SetLabels(map[string]string{groupLabel: "y"})
+				Expect(f.Distribute(context.TODO(), podB)).To(Succeed())
+				test.AwaitNoResource(t.resourceClient, "shared-x")
+
+				Expect(f.Delete(context.TODO(), podA)).To(Succeed())
+				test.AwaitResource(t.resourceClient, "shared-y")
+
+				Expect(f.Delete(context.TODO(), podB)).To(Succeed())
+				test.AwaitNoResource(t.resourceClient, "shared-y")
+			})
+
+			It("should release its reference to the previous identity when the write initially fails", func() {
+				resource.ToMeta(podA).SetLabels(map[string]string{groupLabel: "y"})
+				t.resourceClient.FailOnCreate = errors.New("fake error")
+				Expect(f.Distribute(context.TODO(), podA)).ToNot(Succeed())
+				test.AwaitNoResource(t.resourceClient, "shared-y")
+
+				Expect(f.Distribute(context.TODO(), podA)).To(Succeed())
+				test.AwaitResource(t.resourceClient, "shared-y")
+
+				resource.ToMeta(podB).SetLabels(map[string]string{groupLabel: "y"})
+				Expect(f.Distribute(context.TODO(), podB)).To(Succeed())
+				test.AwaitNoResource(t.resourceClient, "shared-x")
+			})
+		})
+
+		Context("and all the resources are deleted via DeleteAllFor", func() {
+			It("should clear the references", func() {
+				_, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+				Expect(federate.DeleteAllFor(context.TODO(), f, "", *gvr)).To(Succeed())
+				test.AwaitNoResource(t.resourceClient, "shared-x")
+
+				Expect(f.Distribute(context.TODO(), podA)).To(Succeed())
+				test.AwaitResource(t.resourceClient, "shared-x")
+
+				Expect(f.Delete(context.TODO(), podA)).To(Succeed())
+				test.AwaitNoResource(t.resourceClient, "shared-x")
+			})
+		})
+
+		Context("and a source is deleted while another source's distribute is in progress", func() {
+			var (
+				podC    runtime.Object
+				slow    *blockingFederator
+				written chan struct{}
+			)
+
+			BeforeEach(func() {
+				podC = test.NewPod(test.LocalNamespace, test.WithName("pod-c"), test.WithLabels(map[string]string{
+					groupLabel: "x",
+					blockLabel: "true",
+				}))
+				written = make(chan struct{})
+				slow = &blockingFederator{written: written, proceed: make(chan struct{})}
+			})
+
+			JustBeforeEach(func() {
+				slow.Federator = federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID)
+				f = federate.NewIdentityFederator(slow, identity)
+
+				Expect(f.Distribute(context.TODO(), podA)).To(Succeed())
+			})
+
+			It("should not delete the destination resource", func() {
+				distributed := make(chan error, 1)
+
+				go func() {
+					distributed <- f.Distribute(context.TODO(), podC)
+				}()
+
+				Eventually(written).Should(BeClosed())
+
+				deleted := make(chan error, 1)
+
+				go func() {
+					deleted <- f.Delete(context.TODO(), podA)
+				}()
+
+				Consistently(deleted, 200*time.Millisecond).ShouldNot(Receive())
+
+				close(slow.proceed)
+
+				Eventually(distributed).Should(Receive(Succeed()))
+				Eventually(deleted).Should(Receive(Succeed()))
+				t.resourceClient.VerifyNoDelete("shared-x")
+				test.AwaitResource(t.resourceClient, "shared-x")
+			})
+		})
+	})
+
+	Context("with sources of different kinds", func() {
+		var (
+			inner   *fake.Federator
+			pod     runtime.Object
+			service runtime.Object
+		)
+
+		BeforeEach(func() {
+			pod = test.NewPod(test.LocalNamespace, test.WithName("pod-source"))
+			service = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service-source", Namespace: test.LocalNamespace}}
+		})
+
+		JustBeforeEach(func() {
+			inner = fake.New()
+			f = federate.NewIdentityFederator(inner, identity)
+
+			Expect(f.Distribute(context.TODO(), pod)).To(Succeed())
+			Expect(f.Distribute(context.TODO(), service)).To(Succeed())
+		})
+
+		When("they map to the same identity", func() {
+			BeforeEach(func() {
+				identity = func(source runtime.Object) types.NamespacedName {
+					return types.NamespacedName{Name: "shared"}
+				}
+			})
+
+			It("should reference-count each kind separately", func() {
+				Expect(f.Delete(context.TODO(), pod)).To(Succeed())
+				Expect(inner.NumCalls(fake.OpDelete)).To(Equal(1))
+				Expect(inner.Calls()[2].Resource).To(BeAssignableToTypeOf(&corev1.Pod{}))
+
+				Expect(f.Delete(context.TODO(), service)).To(Succeed())
+				Expect(inner.NumCalls(fake.OpDelete)).To(Equal(2))
+				Expect(inner.Calls()[3].Resource).To(BeAssignableToTypeOf(&corev1.Service{}))
+			})
+		})
+
+		When("they have the same namespace and name", func() {
+			BeforeEach(func() {
+				service = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "pod-source", Namespace: test.LocalNamespace}}
+
+				identity = func(source runtime.Object) types.NamespacedName {
+					if _, ok := source.(*corev1.Service); ok {
+						return types.NamespacedName{Name: "derived-service"}
+					}
+
+					return types.NamespacedName{Name: "derived-pod"}
+				}
+			})
+
+			It("should track each source separately", func() {
+				Expect(inner.NumCalls(fake.OpDelete)).To(BeZero())
+
+				Expect(f.Delete(context.TODO(), pod)).To(Succeed())
+				Expect(inner.NumCalls(fake.OpDelete)).To(Equal(1))
+				Expect(resource.ToMeta(inner.Calls()[2].Resource).GetName()).To(Equal("derived-pod"))
+			})
+		})
+	})
+})
+
+const blockLabel = "example.io/block"
+
+// blockingFederator blocks the return from Distribute of a resource with the blockLabel, after it's written, until
+// proceed is closed.
+type blockingFederator struct {
+	federate.Federator
+	written chan struct{}
+	proceed chan struct{}
+}
+
+func (f *blockingFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	err := f.Federator.Distribute(ctx, obj)
+
+	if resource.ToMeta(obj).GetLabels()[blockLabel] == "true" {
+		close(f.written)
+		<-f.proceed
+	}
+
+	return err
+}
@@ -0,0 +1,235 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// MultiClusterFederator is a Federator that distributes resources to a set of target clusters, each accessed via its
+// own Federator.
+type MultiClusterFederator interface {
+	Federator
+
+	// AddCluster adds a target cluster to which subsequent resources are distributed. If a cluster with the given ID
+	// already exists, its Federator is replaced. Resources distributed previously aren't distributed to the new cluster
+	// until they're next distributed, eg via a syncer's Resync.
+	AddCluster(clusterID string, federator Federator)
+
+	// RemoveCluster removes a target cluster. Resources previously distributed to it are not deleted.
+	RemoveCluster(clusterID string)
+
+	// DistributedTo returns the IDs of the clusters to which the given resource has been distributed and not since
+	// deleted, in sorted order.
+	DistributedTo(resource runtime.Object) []string
+}
+
+type multiClusterFederator struct {
+	mutex       sync.Mutex
+	clusters    map[string]Federator
+	distributed map[string]map[string]bool
+}
+
+func NewMultiClusterFederator() MultiClusterFederator {
+	return &multiClusterFederator{
+		clusters:    map[string]Federator{},
+		distributed: map[string]map[string]bool{},
+	}
+}
+
+func (f *multiClusterFederator) AddCluster(clusterID string, federator Federator) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.clusters[clusterID] = federator
+}
+
+func (f *multiClusterFederator) RemoveCluster(clusterID string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.clusters, clusterID)
+
+	for _, clusterIDs := range f.distributed {
+		delete(clusterIDs, clusterID)
+	}
+}
+
+func (f *multiClusterFederator) DistributedTo(obj runtime.Object) []string {
+	key, err := resourceKey(obj)
+	if err != nil {
+		return nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	clusterIDs := []string{}
+	for clusterID := range f.distributed[key] {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+
+	sort.Strings(clusterIDs)
+
+	return clusterIDs
+}
+
+func (f *multiClusterFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	key, err := resourceKey(obj)
+	if err != nil {
+		return err
+	}
+
+	return f.forEachCluster(func(clusterID string, federator Federator) error {
+		if err := federator.Distribute(ctx, obj); err != nil {
+			return errors.Wrapf(err, "error distributing to cluster %q", clusterID)
+		}
+
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		if f.distributed[key] == nil {
+			f.distributed[key] = map[string]bool{}
+		}
+
+		f.distributed[key][clusterID] = true
+
+		return nil
+	})
+}
+
+func (f *multiClusterFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
+}
+
+func (f *multiClusterFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	result := util.OperationResultNone
+
+	var mutex sync.Mutex
+
+	err := f.forEachCluster(func(clusterID string, federator Federator) error {
+		r, err := DistributeDryRun(ctx, federator, obj)
+		if err != nil {
+			return errors.Wrapf(err, "error distributing to cluster %q", clusterID)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if r == util.OperationResultCreated || (r == util.OperationResultUpdated && result == util.OperationResultNone) {
+			result = r
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Delete deletes the given resource from every target cluster. A resource that doesn't exist in a cluster is ignored
+// and errors are aggregated so a failure for one cluster doesn't prevent deletion from the others. The resource remains
+// recorded as distributed to the clusters for which deletion failed.
+func (f *multiClusterFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	key, err := resourceKey(obj)
+	if err != nil {
+		return err
+	}
+
+	return f.forEachCluster(func(clusterID string, federator Federator) error {
+		err := federator.Delete(ctx, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "error deleting from cluster %q", clusterID)
+		}
+
+		logger.V(log.LIBTRACE).Infof("Deleted resource %q from cluster %q", key, clusterID)
+
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		delete(f.distributed[key], clusterID)
+
+		if len(f.distributed[key]) == 0 {
+			delete(f.distributed, key)
+		}
+
+		return nil
+	})
+}
+
+func (f *multiClusterFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return f.forEachCluster(func(clusterID string, federator Federator) error {
+		return errors.Wrapf(DeleteAllFor(ctx, federator, labelSelector, gvrs...), "error deleting from cluster %q", clusterID)
+	})
+}
+
+// forEachCluster concurrently invokes the given function for each target cluster and aggregates the returned errors.
+func (f *multiClusterFederator) forEachCluster(fn func(clusterID string, federator Federator) error) error {
+	f.mutex.Lock()
+
+	clusters := make(map[string]Federator, len(f.clusters))
+	for clusterID, federator := range f.clusters {
+		clusters[clusterID] = federator
+	}
+
+	f.mutex.Unlock()
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  []error
+	)
+
+	for clusterID, federator := range clusters {
+		wg.Add(1)
+
+		go func(clusterID string, federator Federator) {
+			defer wg.Done()
+
+			if err := fn(clusterID, federator); err != nil {
+				mutex.Lock()
+				errs = append(errs, err)
+				mutex.Unlock()
+			}
+		}(clusterID, federator)
+	}
+
+	wg.Wait()
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func resourceKey(obj runtime.Object) (string, error) {
+	u, err := resource.ToUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+
+	return u.GroupVersionKind().GroupKind().String() + "/" + u.GetNamespace() + "/" + u.GetName(), nil
+}
@@ -0,0 +1,150 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package federate_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var _ = Describe("MultiCluster Federator", func() {
+	clusterIDs := []string{"east", "north", "west"}
+
+	var (
+		f        federate.MultiClusterFederator
+		clusters map[string]*testDriver
+	)
+
+	BeforeEach(func() {
+		f = federate.NewMultiClusterFederator()
+		clusters = map[string]*testDriver{}
+
+		for _, clusterID := range clusterIDs {
+			t := newTestDriver()
+			clusters[clusterID] = t
+			f.AddCluster(clusterID, federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, ""))
+		}
+	})
+
+	first := func() *testDriver {
+		return clusters[clusterIDs[0]]
+	}
+
+	verifyExists := func(clusterID string) {
+		_, err := test.GetResourceAndError(clusters[clusterID].resourceClient, first().resource)
+		Expect(err).To(Succeed(), "Resource not found in cluster %q", clusterID)
+	}
+
+	verifyNotExists := func(clusterID string) {
+		_, err := test.GetResourceAndError(clusters[clusterID].resourceClient, first().resource)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue(), "Resource unexpectedly found in cluster %q", clusterID)
+	}
+
+	When("a resource is distributed", func() {
+		It("should create it in every cluster", func() {
+			Expect(f.Distribute(context.TODO(), first().resource)).To(Succeed())
+
+			for _, clusterID := range clusterIDs {
+				verifyExists(clusterID)
+			}
+
+			Expect(f.DistributedTo(first().resource)).To(Equal(clusterIDs))
+		})
+	})
+
+	When("a distributed resource is deleted", func() {
+		BeforeEach(func() {
+			Expect(f.Distribute(context.TODO(), first().resource)).To(Succeed())
+		})
+
+		It("should delete it from every cluster and clear the bookkeeping", func() {
+			Expect(f.Delete(context.TODO(), first().resource)).To(Succeed())
+
+			for _, clusterID := range clusterIDs {
+				verifyNotExists(clusterID)
+			}
+
+			Expect(f.DistributedTo(first().resource)).To(BeEmpty())
+		})
+
+		Context("and it no longer exists in a cluster", func() {
+			BeforeEach(func() {
+				Expect(federate.NewCreateOrUpdateFederator(clusters["north"].dynClient, clusters["north"].restMapper,
+					clusters["north"].federatorNamespace, "").Delete(context.TODO(), first().resource)).To(Succeed())
+			})
+
+			It("should delete it from the other clusters and succeed", func() {
+				Expect(f.Delete(context.TODO(), first().resource)).To(Succeed())
+
+				for _, clusterID := range clusterIDs {
+					verifyNotExists(clusterID)
+				}
+
+				Expect(f.DistributedTo(first().resource)).To(BeEmpty())
+			})
+		})
+
+		Context("and deletion fails for a cluster", func() {
+			BeforeEach(func() {
+				clusters["west"].resourceClient.FailOnDelete = apierrors.NewServiceUnavailable("fake")
+			})
+
+			It("should delete it from the other clusters and return an error", func() {
+				err := f.Delete(context.TODO(), first().resource)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("west"))
+
+				verifyNotExists("east")
+				verifyNotExists("north")
+				verifyExists("west")
+
+				Expect(f.DistributedTo(first().resource)).To(Equal([]string{"west"}))
+			})
+		})
+	})
+
+	When("a resource is distributed with dry run", func() {
+		It("should report it would be created and not create it", func() {
+			result, err := federate.DistributeDryRun(context.TODO(), f, first().resource)
+			Expect(err).To(Succeed())
+			Expect(result).To(Equal(util.OperationResultCreated))
+
+			for _, clusterID := range clusterIDs {
+				verifyNotExists(clusterID)
+			}
+		})
+	})
+
+	When("a cluster is removed", func() {
+		It("should no longer distribute to it", func() {
+			f.RemoveCluster("north")
+
+			Expect(f.Distribute(context.TODO(), first().resource)).To(Succeed())
+
+			verifyExists("east")
+			verifyNotExists("north")
+			Expect(f.DistributedTo(first().resource)).To(Equal([]string{"east", "west"}))
+		})
+	})
+})
@@ -0,0 +1,188 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+)
+
+// OwnershipCheck returns true if the given existing resource in the destination is owned or managed by a foreign
+// controller and thus shouldn't be overwritten.
+type OwnershipCheck func(existing *unstructured.Unstructured) bool
+
+// ForeignFieldManager returns an OwnershipCheck that considers a resource foreign if it has managed fields, none of
+// which are managed by the given field manager.
+func ForeignFieldManager(fieldManager string) OwnershipCheck {
+	return func(existing *unstructured.Unstructured) bool {
+		managedFields := existing.GetManagedFields()
+		for i := range managedFields {
+			if managedFields[i].Manager == fieldManager {
+				return false
+			}
+		}
+
+		return len(managedFields) > 0
+	}
+}
+
+// ForeignOwnerLabel returns an OwnershipCheck that considers a resource foreign if it has the given owner label key
+// with a value other than the given value. A resource without the label isn't considered foreign.
+func ForeignOwnerLabel(key, value string) OwnershipCheck {
+	return func(existing *unstructured.Unstructured) bool {
+		owner, found := existing.GetLabels()[key]
+		return found && owner != value
+	}
+}
+
+type ownershipFederator struct {
+	Federator
+	client          dynamic.Interface
+	restMapper      meta.RESTMapper
+	targetNamespace string
+	isForeign       OwnershipCheck
+	onForeign       func(existing *unstructured.Unstructured)
+}
+
+// NewOwnershipFederator returns a Federator that checks the resource currently in the target namespace, if any, with
+// the given OwnershipCheck before delegating to the given Federator. A foreign-owned resource is left untouched and
+// passed to the given onForeign function, if specified, instead of being overwritten or deleted so the distributing
+// controller doesn't fight with its owner. DeleteAllFor lists the matching resources and deletes each that isn't
+// foreign-owned via the given Federator's Delete.
+func NewOwnershipFederator(federator Federator, client dynamic.Interface, restMapper meta.RESTMapper, targetNamespace string,
+	isForeign OwnershipCheck, onForeign func(existing *unstructured.Unstructured),
+) Federator {
+	return &ownershipFederator{
+		Federator:       federator,
+		client:          client,
+		restMapper:      restMapper,
+		targetNamespace: targetNamespace,
+		isForeign:       isForeign,
+		onForeign:       onForeign,
+	}
+}
+
+func (f *ownershipFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	foreign, err := f.foreignOwned(ctx, obj)
+	if err != nil || foreign {
+		return err
+	}
+
+	return f.Federator.Distribute(ctx, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *ownershipFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
+}
+
+func (f *ownershipFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	foreign, err := f.foreignOwned(ctx, obj)
+	if err != nil || foreign {
+		return err
+	}
+
+	return f.Federator.Delete(ctx, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *ownershipFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	var errs []error
+
+	for _, gvr := range gvrs {
+		list, err := f.client.Resource(gvr).Namespace(f.targetNamespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error listing %q in namespace %q", gvr.Resource, f.targetNamespace))
+			continue
+		}
+
+		for i := range list.Items {
+			existing := &list.Items[i]
+			if f.reportIfForeign(existing) {
+				continue
+			}
+
+			err = f.Federator.Delete(ctx, existing)
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "error deleting %q %s/%s", gvr.Resource, existing.GetNamespace(),
+					existing.GetName()))
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (f *ownershipFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	foreign, err := f.foreignOwned(ctx, obj)
+	if err != nil || foreign {
+		return util.OperationResultNone, err
+	}
+
+	return DistributeDryRun(ctx, f.Federator, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *ownershipFederator) foreignOwned(ctx context.Context, obj runtime.Object) (bool, error) {
+	desired, gvr, err := util.ToUnstructuredResource(obj, f.restMapper)
+	if err != nil {
+		return false, err //nolint:wrapcheck // ok to return as is
+	}
+
+	namespace := f.targetNamespace
+	if namespace == "" {
+		namespace = desired.GetNamespace()
+	}
+
+	existing, err := f.client.Resource(*gvr).Namespace(namespace).Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, errors.Wrapf(err, "error retrieving existing resource %q", desired.GetName())
+	}
+
+	return f.reportIfForeign(existing), nil
+}
+
+// reportIfForeign returns true if the given existing resource is owned by a foreign controller, passing it to the
+// onForeign function.
+func (f *ownershipFederator) reportIfForeign(existing *unstructured.Unstructured) bool {
+	if !f.isForeign(existing) {
+		return false
+	}
+
+	logger.V(log.LIBDEBUG).Infof("Existing resource %s/%s is owned by a foreign controller - not modifying it",
+		existing.GetNamespace(), existing.GetName())
+
+	if f.onForeign != nil {
+		f.onForeign(existing)
+	}
+
+	return true
+}
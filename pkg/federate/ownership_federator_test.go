@@ -0,0 +1,183 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const managedByLabel = "app.kubernetes.io/managed-by"
+
+var _ = Describe("Ownership Federator", func() {
+	var (
+		f         federate.Federator
+		t         *testDriver
+		isForeign federate.OwnershipCheck
+		existing  *corev1.Pod
+		reported  []*unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		t = newTestDriver()
+		t.localClusterID = ""
+		isForeign = federate.ForeignOwnerLabel(managedByLabel, "submariner")
+		reported = nil
+
+		existing = test.NewPodWithImage(t.targetNamespace, "apache")
+	})
+
+	JustBeforeEach(func() {
+		f = federate.NewOwnershipFederator(
+			federate.NewCreateOrUpdateFederator(t.dynClient, t.restMapper, t.federatorNamespace, t.localClusterID),
+			t.dynClient, t.restMapper, t.federatorNamespace, isForeign, func(obj *unstructured.Unstructured) {
+				reported = append(reported, obj)
+			})
+	})
+
+	verifyUntouched := func() {
+		Expect(test.GetPod(t.resourceClient, existing).Spec).To(Equal(existing.Spec))
+		Expect(reported).To(HaveLen(1))
+		Expect(reported[0].GetName()).To(Equal(existing.Name))
+	}
+
+	verifyUpdated := func() {
+		t.verifyResource()
+		Expect(reported).To(BeEmpty())
+	}
+
+	When("the resource doesn't exist in the destination", func() {
+		It("should create it", func() {
+			Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+			verifyUpdated()
+		})
+	})
+
+	Context("with the ForeignOwnerLabel check", func() {
+		When("the existing resource has a foreign owner label", func() {
+			BeforeEach(func() {
+				existing.Labels[managedByLabel] = "other"
+			})
+
+			It("should leave it untouched and report it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				verifyUntouched()
+			})
+
+			It("should return None from a dry run", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(federate.DistributeDryRun(context.TODO(), f, t.resource)).To(Equal(util.OperationResultNone))
+			})
+
+			It("should not delete it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Delete(context.TODO(), t.resource)).To(Succeed())
+				verifyUntouched()
+			})
+
+			It("should not delete it via DeleteAllFor", func() {
+				test.CreateResource(t.resourceClient, existing)
+
+				other := test.NewPod(t.targetNamespace, test.WithName("other-pod"))
+				test.CreateResource(t.resourceClient, other)
+
+				_, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+				Expect(federate.DeleteAllFor(context.TODO(), f, "", *gvr)).To(Succeed())
+
+				test.AwaitNoResource(t.resourceClient, other.Name)
+				verifyUntouched()
+			})
+		})
+
+		When("the existing resource has the expected owner label", func() {
+			BeforeEach(func() {
+				existing.Labels[managedByLabel] = "submariner"
+				t.resource.Labels[managedByLabel] = "submariner"
+			})
+
+			It("should update it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				verifyUpdated()
+			})
+
+			It("should delete it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Delete(context.TODO(), t.resource)).To(Succeed())
+				test.AwaitNoResource(t.resourceClient, existing.Name)
+				Expect(reported).To(BeEmpty())
+			})
+		})
+
+		When("the existing resource has no owner label", func() {
+			It("should update it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				verifyUpdated()
+			})
+		})
+	})
+
+	Context("with the ForeignFieldManager check", func() {
+		BeforeEach(func() {
+			isForeign = federate.ForeignFieldManager("submariner")
+		})
+
+		When("the existing resource is managed by a foreign field manager", func() {
+			BeforeEach(func() {
+				existing.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "other"}}
+			})
+
+			It("should leave it untouched and report it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				verifyUntouched()
+			})
+		})
+
+		When("the existing resource is also managed by the given field manager", func() {
+			BeforeEach(func() {
+				existing.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "other"}, {Manager: "submariner"}}
+			})
+
+			It("should update it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				verifyUpdated()
+			})
+		})
+
+		When("the existing resource has no managed fields", func() {
+			It("should update it", func() {
+				test.CreateResource(t.resourceClient, existing)
+				Expect(f.Distribute(context.TODO(), t.resource)).To(Succeed())
+				verifyUpdated()
+			})
+		})
+	})
+})
@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/util"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RateLimit specifies a token bucket rate limit: writes are permitted at QPS per second on average, with bursts of up to
+// Burst writes. A Burst less than 1 is treated as 1.
+type RateLimit struct {
+	QPS   float64
+	Burst int
+}
+
+type rateLimitingFederator struct {
+	Federator
+	restMapper meta.RESTMapper
+	limiters   map[schema.GroupVersionResource]*rate.Limiter
+}
+
+// NewRateLimitingFederator returns a Federator that limits the rate at which resources of each GroupVersionResource in
+// the given map are written, ie distributed or deleted, via the given Federator, so writes of a high-churn resource type
+// don't exhaust the API budget shared with other types. Each write waits until it's permitted by the limit of its type
+// or the context is done. Writes of other types, and DeleteAllFor, aren't limited.
+func NewRateLimitingFederator(federator Federator, restMapper meta.RESTMapper, limits map[schema.GroupVersionResource]RateLimit,
+) Federator {
+	f := &rateLimitingFederator{
+		Federator:  federator,
+		restMapper: restMapper,
+		limiters:   map[schema.GroupVersionResource]*rate.Limiter{},
+	}
+
+	for gvr, limit := range limits {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+
+		f.limiters[gvr] = rate.NewLimiter(rate.Limit(limit.QPS), burst)
+	}
+
+	return f
+}
+
+func (f *rateLimitingFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	if err := f.wait(ctx, obj); err != nil {
+		return err
+	}
+
+	return f.Federator.Distribute(ctx, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *rateLimitingFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
+}
+
+func (f *rateLimitingFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *rateLimitingFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	if err := f.wait(ctx, obj); err != nil {
+		return util.OperationResultNone, err
+	}
+
+	return DistributeDryRun(ctx, f.Federator, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *rateLimitingFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	if err := f.wait(ctx, obj); err != nil {
+		return err
+	}
+
+	return f.Federator.Delete(ctx, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *rateLimitingFederator) wait(ctx context.Context, obj runtime.Object) error {
+	_, gvr, err := util.ToUnstructuredResource(obj, f.restMapper)
+	if err != nil {
+		return err //nolint:wrapcheck // ok to return as is
+	}
+
+	limiter, ok := f.limiters[*gvr]
+	if !ok {
+		return nil
+	}
+
+	return errors.Wrapf(limiter.Wait(ctx), "error waiting for the write rate limit of %q", gvr.Resource)
+}
@@ -0,0 +1,117 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Rate Limiting Federator", func() {
+	const numWrites = 5
+
+	var (
+		delegate *fake.Federator
+		f        federate.Federator
+	)
+
+	newService := func(i int) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("service-%d", i), Namespace: test.LocalNamespace}}
+	}
+
+	newPod := func(i int) *corev1.Pod {
+		pod := test.NewPod(test.LocalNamespace)
+		pod.Name = fmt.Sprintf("pod-%d", i)
+
+		return pod
+	}
+
+	timeWrites := func(write func(obj runtime.Object) error, newObj func(i int) runtime.Object) time.Duration {
+		start := time.Now()
+
+		for i := 0; i < numWrites; i++ {
+			Expect(write(newObj(i))).To(Succeed())
+		}
+
+		return time.Since(start)
+	}
+
+	BeforeEach(func() {
+		restMapper := test.GetRESTMapperFor(&corev1.Pod{}, &corev1.Service{})
+		delegate = fake.New()
+
+		f = federate.NewRateLimitingFederator(delegate, restMapper, map[schema.GroupVersionResource]federate.RateLimit{
+			corev1.SchemeGroupVersion.WithResource("pods"): {QPS: 10, Burst: 1},
+		})
+	})
+
+	distribute := func(obj runtime.Object) error {
+		return f.Distribute(context.TODO(), obj)
+	}
+
+	It("should limit the write rate of the limited resource type", func() {
+		// With a burst of 1, each write after the first waits 100ms.
+		Expect(timeWrites(distribute, func(i int) runtime.Object {
+			return newPod(i)
+		})).To(BeNumerically(">=", (numWrites-1)*90*time.Millisecond))
+
+		Expect(timeWrites(func(obj runtime.Object) error {
+			return f.Delete(context.TODO(), obj)
+		}, func(i int) runtime.Object {
+			return newPod(i)
+		})).To(BeNumerically(">=", (numWrites-1)*90*time.Millisecond))
+
+		Expect(delegate.Distributed()).To(BeEmpty())
+		Expect(delegate.NumCalls(fake.OpDelete)).To(Equal(numWrites))
+	})
+
+	It("should not limit the write rate of other resource types", func() {
+		// Exhaust the limited type's burst.
+		Expect(distribute(newPod(0))).To(Succeed())
+
+		Expect(timeWrites(distribute, func(i int) runtime.Object {
+			return newService(i)
+		})).To(BeNumerically("<", 50*time.Millisecond))
+
+		Expect(delegate.Distributed()).To(HaveLen(numWrites + 1))
+	})
+
+	When("the context is done while waiting", func() {
+		It("should return an error without writing", func() {
+			Expect(distribute(newPod(0))).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+
+			Expect(f.Distribute(ctx, newPod(1))).ToNot(Succeed())
+			Expect(delegate.Distributed()).To(HaveLen(1))
+		})
+	})
+})
@@ -52,18 +52,37 @@ func NewUpdateStatusFederator(dynClient dynamic.Interface, restMapper meta.RESTM
 		})
 }
 
+func (f *updateFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	_, err := f.distribute(ctx, obj, func(ctx context.Context, client resource.Interface, obj runtime.Object, mutate util.MutateFn,
+	) (util.OperationResult, error) {
+		return util.OperationResultNone, util.Update(ctx, client, obj, mutate)
+	})
+
+	return err
+}
+
+func (f *updateFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	return f.distribute(ctx, obj, util.UpdateDryRun)
+}
+
 //nolint:wrapcheck // This function is effectively a wrapper so no need to wrap errors.
-func (f *updateFederator) Distribute(obj runtime.Object) error {
+func (f *updateFederator) distribute(ctx context.Context, obj runtime.Object,
+	update func(context.Context, resource.Interface, runtime.Object, util.MutateFn) (util.OperationResult, error),
+) (util.OperationResult, error) {
 	logger.V(log.LIBTRACE).Infof("In Distribute for %#v", obj)
 
 	toUpdate, resourceClient, err := f.toUnstructured(obj)
 	if err != nil {
-		return err
+		return util.OperationResultNone, err
 	}
 
 	f.prepareResourceForSync(toUpdate)
 
-	return util.Update(context.TODO(), resource.ForDynamic(resourceClient), toUpdate, func(obj runtime.Object) (runtime.Object, error) {
+	return update(ctx, resource.ForDynamic(resourceClient), toUpdate, func(obj runtime.Object) (runtime.Object, error) {
 		return f.update(obj.(*unstructured.Unstructured), toUpdate), nil
 	})
 }
+
+func (f *updateFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	return distributeAll(ctx, f.Distribute, resources)
+}
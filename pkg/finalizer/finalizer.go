@@ -20,6 +20,7 @@ package finalizer
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/resource"
@@ -83,3 +84,17 @@ func IsPresent(objMeta metav1.Object, finalizerName string) bool {
 
 	return false
 }
+
+// HasFinalizer returns true if the given object has the given finalizer. It doesn't modify the object and returns false
+// for a nil object.
+func HasFinalizer(obj runtime.Object, finalizerName string) bool {
+	if obj == nil {
+		return false
+	}
+
+	if v := reflect.ValueOf(obj); v.Kind() == reflect.Ptr && v.IsNil() {
+		return false
+	}
+
+	return IsPresent(resource.ToMeta(obj), finalizerName)
+}
@@ -194,6 +194,44 @@ var _ = Describe("Remove", func() {
 	})
 })
 
+var _ = Describe("HasFinalizer", func() {
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = newTestDriver().pod
+	})
+
+	When("the finalizer is present", func() {
+		It("should return true", func() {
+			pod.Finalizers = []string{"other-finalizer", finalizerName}
+			Expect(finalizer.HasFinalizer(pod, finalizerName)).To(BeTrue())
+		})
+	})
+
+	When("the finalizer is absent", func() {
+		It("should return false", func() {
+			pod.Finalizers = []string{"other-finalizer"}
+			Expect(finalizer.HasFinalizer(pod, finalizerName)).To(BeFalse())
+		})
+	})
+
+	When("the finalizer list is empty", func() {
+		It("should return false", func() {
+			Expect(finalizer.HasFinalizer(pod, finalizerName)).To(BeFalse())
+
+			pod.Finalizers = nil
+			Expect(finalizer.HasFinalizer(pod, finalizerName)).To(BeFalse())
+		})
+	})
+
+	When("the object is nil", func() {
+		It("should return false", func() {
+			Expect(finalizer.HasFinalizer(nil, finalizerName)).To(BeFalse())
+			Expect(finalizer.HasFinalizer((*corev1.Pod)(nil), finalizerName)).To(BeFalse())
+		})
+	})
+})
+
 type testDriver struct {
 	pod        *corev1.Pod
 	kubeClient *kubeFake.Clientset
@@ -0,0 +1,95 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gomega
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/gomega/format"
+	gomegaTypes "github.com/onsi/gomega/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+type existInClient struct {
+	client    dynamic.Interface
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// ExistInClient checks whether the named resource exists by retrieving it via the given dynamic client. The actual
+// value is the context.Context used for the retrieval or nil to use context.TODO(), eg:
+//
+//	Eventually(ctx).Should(ExistInClient(client, gvr, namespace, name))
+//
+// Use ToNot or ShouldNot to check that the resource doesn't exist. A NotFound error indicates the resource doesn't
+// exist - any other error from the retrieval fails the assertion rather than being treated as absence.
+func ExistInClient(client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string,
+) gomegaTypes.GomegaMatcher {
+	return &existInClient{
+		client:    client,
+		gvr:       gvr,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+func (m *existInClient) Match(x interface{}) (bool, error) {
+	ctx := context.TODO()
+
+	if x != nil {
+		c, ok := x.(context.Context)
+		if !ok {
+			return false, fmt.Errorf("existInClient matcher requires a context.Context or nil.  Got:\n%s", format.Object(x, 1))
+		}
+
+		ctx = c
+	}
+
+	_, err := m.client.Resource(m.gvr).Namespace(m.namespace).Get(ctx, m.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("error retrieving %s: %w", m.path(), err)
+	}
+
+	return true, nil
+}
+
+func (m *existInClient) FailureMessage(_ interface{}) string {
+	return fmt.Sprintf("Expected %s to exist, but got NotFound", m.path())
+}
+
+func (m *existInClient) NegatedFailureMessage(_ interface{}) string {
+	return fmt.Sprintf("Expected %s not to exist, but it was found", m.path())
+}
+
+func (m *existInClient) path() string {
+	if m.namespace == "" {
+		return m.gvr.Resource + "/" + m.name
+	}
+
+	return m.gvr.Resource + "/" + m.namespace + "/" + m.name
+}
@@ -0,0 +1,104 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gomega_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	. "github.com/submariner-io/admiral/pkg/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("ExistInClient", func() {
+	gvr := corev1.SchemeGroupVersion.WithResource("pods")
+
+	var (
+		client         *fake.DynamicClient
+		resourceClient *fake.DynamicResourceClient
+		pod            *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		client = fake.NewDynamicClient(scheme.Scheme)
+		resourceClient, _ = client.Resource(gvr).Namespace(test.LocalNamespace).(*fake.DynamicResourceClient)
+		pod = test.NewPod(test.LocalNamespace)
+	})
+
+	When("the resource exists", func() {
+		BeforeEach(func() {
+			test.CreateResource(resourceClient, pod)
+		})
+
+		It("should match", func() {
+			Expect(context.TODO()).To(ExistInClient(client, gvr, test.LocalNamespace, pod.Name))
+			Expect(nil).To(ExistInClient(client, gvr, test.LocalNamespace, pod.Name))
+		})
+
+		It("should report a clear message when negated", func() {
+			Expect(ExistInClient(client, gvr, test.LocalNamespace, pod.Name).NegatedFailureMessage(nil)).To(
+				Equal("Expected pods/" + test.LocalNamespace + "/" + pod.Name + " not to exist, but it was found"))
+		})
+	})
+
+	When("the resource doesn't exist", func() {
+		It("should not match", func() {
+			Expect(nil).ToNot(ExistInClient(client, gvr, test.LocalNamespace, pod.Name))
+		})
+
+		It("should report a clear message", func() {
+			m := ExistInClient(client, gvr, test.LocalNamespace, pod.Name)
+			Expect(m.Match(nil)).To(BeFalse())
+			Expect(m.FailureMessage(nil)).To(Equal("Expected pods/" + test.LocalNamespace + "/" + pod.Name +
+				" to exist, but got NotFound"))
+		})
+	})
+
+	When("retrieval fails with an error other than NotFound", func() {
+		BeforeEach(func() {
+			resourceClient.FailOnGet = apierrors.NewServiceUnavailable("fake")
+		})
+
+		It("should return a matcher error", func() {
+			_, err := ExistInClient(client, gvr, test.LocalNamespace, pod.Name).Match(nil)
+			Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+		})
+	})
+
+	When("the actual value isn't a context", func() {
+		It("should return a matcher error", func() {
+			_, err := ExistInClient(client, gvr, test.LocalNamespace, pod.Name).Match("not a context")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the resource is cluster-scoped", func() {
+		It("should omit the namespace from the message", func() {
+			nsGVR := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+			Expect(ExistInClient(client, nsGVR, "", "test").FailureMessage(nil)).To(
+				Equal("Expected namespaces/test to exist, but got NotFound"))
+		})
+	})
+})
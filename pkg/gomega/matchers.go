@@ -20,6 +20,7 @@ limitations under the License.
 package gomega
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -33,7 +34,8 @@ type containErrorSubstring struct {
 
 // ContainErrorSubstring checks whether the actual error’s error message
 // contains the expected error’s error message, not as an exact match but
-// as a substring.
+// as a substring. The errors wrapped or joined by the actual error are also
+// searched, so the message of a deeply-wrapped error still matches.
 func ContainErrorSubstring(expected error) gomegaTypes.GomegaMatcher {
 	return &containErrorSubstring{expected}
 }
@@ -44,7 +46,37 @@ func (m *containErrorSubstring) Match(x interface{}) (bool, error) {
 		return false, fmt.Errorf("containErrorSubstring matcher requires an error.  Got:\n%s", format.Object(x, 1))
 	}
 
-	return strings.Contains(actual.Error(), m.expected.Error()), nil
+	return containsErrorSubstring(actual, m.expected.Error()), nil
+}
+
+func containsErrorSubstring(err error, substr string) bool {
+	if err == nil {
+		return false
+	}
+
+	if strings.Contains(err.Error(), substr) {
+		return true
+	}
+
+	var joined []error
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		joined = e.Unwrap()
+	case interface{ Errors() []error }:
+		// eg a K8s utilerrors.Aggregate
+		joined = e.Errors()
+	default:
+		return containsErrorSubstring(errors.Unwrap(err), substr)
+	}
+
+	for _, e := range joined {
+		if containsErrorSubstring(e, substr) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (m *containErrorSubstring) FailureMessage(actual interface{}) string {
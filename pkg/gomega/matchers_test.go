@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gomega_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/submariner-io/admiral/pkg/gomega"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// opaqueError wraps an error without including its message.
+type opaqueError struct {
+	err error
+}
+
+func (e *opaqueError) Error() string {
+	return "operation failed"
+}
+
+func (e *opaqueError) Unwrap() error {
+	return e.err
+}
+
+// joinedError joins errors without including their messages.
+type joinedError struct {
+	errs []error
+}
+
+func (e *joinedError) Error() string {
+	return "multiple errors"
+}
+
+func (e *joinedError) Unwrap() []error {
+	return e.errs
+}
+
+var _ = Describe("ContainErrorSubstring", func() {
+	expected := errors.New("resource not found")
+
+	When("the actual error is a plain error", func() {
+		It("should match if its message contains the substring", func() {
+			Expect(fmt.Errorf("the resource not found in cluster")).To(ContainErrorSubstring(expected))
+		})
+
+		It("should not match if its message doesn't contain the substring", func() {
+			Expect(errors.New("something else")).ToNot(ContainErrorSubstring(expected))
+		})
+	})
+
+	When("the substring is in a wrapped error", func() {
+		It("should match", func() {
+			err := fmt.Errorf("outer: %w", &opaqueError{err: &opaqueError{err: fmt.Errorf("inner: %w", expected)}})
+			Expect(err.Error()).ToNot(ContainSubstring(expected.Error()))
+			Expect(err).To(ContainErrorSubstring(expected))
+		})
+	})
+
+	When("the substring is in one of joined errors", func() {
+		It("should match", func() {
+			err := &opaqueError{err: &joinedError{errs: []error{errors.New("first"), &opaqueError{err: expected}}}}
+			Expect(err).To(ContainErrorSubstring(expected))
+			Expect(&joinedError{errs: []error{errors.New("first")}}).ToNot(ContainErrorSubstring(expected))
+		})
+	})
+
+	When("the substring is in an aggregated error", func() {
+		It("should match", func() {
+			err := utilerrors.NewAggregate([]error{errors.New("first"), &opaqueError{err: expected}})
+			Expect(err).To(ContainErrorSubstring(expected))
+		})
+	})
+
+	When("the actual value isn't an error", func() {
+		It("should return an error", func() {
+			_, err := ContainErrorSubstring(expected).Match("not an error")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
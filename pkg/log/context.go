@@ -0,0 +1,75 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CorrelationIDKey the key under which the correlation ID is attached to log lines by the Logger carried by a context
+// via WithCorrelationID.
+const CorrelationIDKey = "correlationID"
+
+type (
+	loggerKey        struct{}
+	correlationIDKey struct{}
+)
+
+// IntoContext returns a copy of the given context carrying the given Logger, retrieved via FromContext.
+func IntoContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the Logger carried by the given context via IntoContext or, if none, a Logger that uses the
+// controller-runtime logger.
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+			return logger
+		}
+	}
+
+	return Logger{Logger: logf.Log}
+}
+
+// WithCorrelationID returns a copy of the given context carrying the given correlation ID, retrieved via
+// CorrelationIDFrom, and a Logger derived from the context's Logger that attaches the ID to every line it logs under
+// the CorrelationIDKey. This allows the interleaved log lines of concurrent operations, eg reconciles, to be correlated.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return IntoContext(context.WithValue(ctx, correlationIDKey{}, id), FromContext(ctx).WithValues(CorrelationIDKey, id))
+}
+
+// CorrelationIDFrom returns the correlation ID carried by the given context via WithCorrelationID or an empty string if
+// none.
+func CorrelationIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// NewCorrelationID returns a new random correlation ID.
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
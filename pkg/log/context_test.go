@@ -0,0 +1,88 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/log/fake"
+)
+
+var _ = Describe("Context", func() {
+	var (
+		sink *fake.Logger
+		ctx  context.Context
+	)
+
+	BeforeEach(func() {
+		sink = fake.New()
+		ctx = log.IntoContext(context.Background(), log.Logger{Logger: sink}.WithValues("syncer", "test"))
+	})
+
+	Describe("FromContext", func() {
+		It("should return the Logger stored via IntoContext", func() {
+			log.FromContext(ctx).Info("message")
+
+			Expect(sink.FindEntries("message")).To(HaveLen(1))
+			Expect(sink.FindEntries("message")[0].Value("syncer")).To(Equal("test"))
+		})
+
+		Context("with no stored Logger", func() {
+			It("should return a usable default Logger", func() {
+				Expect(log.FromContext(context.Background()).Logger).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("WithCorrelationID", func() {
+		It("should attach the correlation ID to every line logged via the context's Logger", func() {
+			first := log.WithCorrelationID(ctx, log.NewCorrelationID())
+			second := log.WithCorrelationID(ctx, log.NewCorrelationID())
+
+			log.FromContext(first).Info("first")
+			log.FromContext(first).V(log.DEBUG).Info("first")
+			log.FromContext(second).Info("second")
+
+			firstEntries := sink.FindEntries("first")
+			Expect(firstEntries).To(HaveLen(2))
+			Expect(firstEntries[0].Value(log.CorrelationIDKey)).To(Equal(log.CorrelationIDFrom(first)))
+			Expect(firstEntries[1].Value(log.CorrelationIDKey)).To(Equal(log.CorrelationIDFrom(first)))
+			Expect(firstEntries[0].Value("syncer")).To(Equal("test"))
+
+			secondEntries := sink.FindEntries("second")
+			Expect(secondEntries).To(HaveLen(1))
+			Expect(secondEntries[0].Value(log.CorrelationIDKey)).To(Equal(log.CorrelationIDFrom(second)))
+
+			Expect(log.CorrelationIDFrom(first)).ToNot(BeEmpty())
+			Expect(log.CorrelationIDFrom(first)).ToNot(Equal(log.CorrelationIDFrom(second)))
+		})
+
+		It("should not affect the parent context", func() {
+			_ = log.WithCorrelationID(ctx, "id")
+
+			Expect(log.CorrelationIDFrom(ctx)).To(BeEmpty())
+
+			log.FromContext(ctx).Info("parent")
+			Expect(sink.FindEntries("parent")[0].Value(log.CorrelationIDKey)).To(BeNil())
+		})
+	})
+})
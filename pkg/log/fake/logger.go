@@ -0,0 +1,144 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides a fake logr.Logger that records log entries for use in tests.
+package fake
+
+import (
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+// Entry is a recorded log line.
+type Entry struct {
+	Name          string
+	Level         int
+	Message       string
+	Error         error
+	KeysAndValues []interface{}
+}
+
+// Value returns the value for the given key in the entry's KeysAndValues, or nil if not present.
+func (e *Entry) Value(key string) interface{} {
+	for i := 0; i+1 < len(e.KeysAndValues); i += 2 {
+		if e.KeysAndValues[i] == key {
+			return e.KeysAndValues[i+1]
+		}
+	}
+
+	return nil
+}
+
+type sink struct {
+	mutex   sync.Mutex
+	entries []Entry
+}
+
+type Logger struct {
+	sink   *sink
+	name   string
+	level  int
+	values []interface{}
+}
+
+var _ logr.Logger = &Logger{}
+
+func New() *Logger {
+	return &Logger{sink: &sink{}}
+}
+
+// Entries returns a copy of the entries recorded by this Logger and all loggers derived from it.
+func (l *Logger) Entries() []Entry {
+	l.sink.mutex.Lock()
+	defer l.sink.mutex.Unlock()
+
+	return append([]Entry(nil), l.sink.entries...)
+}
+
+// FindEntries returns the recorded entries with the given message.
+func (l *Logger) FindEntries(msg string) []Entry {
+	var found []Entry
+
+	for _, e := range l.Entries() {
+		if e.Message == msg {
+			found = append(found, e)
+		}
+	}
+
+	return found
+}
+
+func (l *Logger) Enabled() bool {
+	return true
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.record(nil, msg, keysAndValues)
+}
+
+func (l *Logger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.record(err, msg, keysAndValues)
+}
+
+func (l *Logger) V(level int) logr.Logger {
+	c := l.clone()
+	c.level += level
+
+	return c
+}
+
+func (l *Logger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	c := l.clone()
+	c.values = append(c.values, keysAndValues...)
+
+	return c
+}
+
+func (l *Logger) WithName(name string) logr.Logger {
+	c := l.clone()
+	if c.name != "" {
+		c.name += "."
+	}
+
+	c.name += name
+
+	return c
+}
+
+func (l *Logger) clone() *Logger {
+	return &Logger{
+		sink:   l.sink,
+		name:   l.name,
+		level:  l.level,
+		values: append([]interface{}(nil), l.values...),
+	}
+}
+
+func (l *Logger) record(err error, msg string, keysAndValues []interface{}) {
+	l.sink.mutex.Lock()
+	defer l.sink.mutex.Unlock()
+
+	l.sink.entries = append(l.sink.entries, Entry{
+		Name:          l.name,
+		Level:         l.level,
+		Message:       msg,
+		Error:         err,
+		KeysAndValues: append(append([]interface{}(nil), l.values...), keysAndValues...),
+	})
+}
@@ -21,6 +21,7 @@ package kzerolog
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
@@ -38,7 +39,19 @@ const (
 	maxLenCaller = 25
 )
 
-var verbosityLevel = 0
+const (
+	// TextFormat the human friendly log format.
+	TextFormat = "text"
+
+	// JSONFormat the log format in which each entry is a JSON object, eg for log aggregation pipelines. Key/value pairs,
+	// including those added via WithValues, are serialized as fields of the object.
+	JSONFormat = "json"
+)
+
+var (
+	verbosityLevel = 0
+	logFormat      = TextFormat
+)
 
 // AddFlags register command line options for zerolog-based logging. Should be called before InitK8sLogging.
 //goland:noinspection GoUnusedExportedFunction
@@ -53,10 +66,13 @@ func AddFlags(flagset *flag.FlagSet) {
 	// avoid runtime error when klog's alsologtostderr option is enabled for the container
 	// this is the default in most of the submariner container command.
 	flagset.Bool("alsologtostderr", false, "unused - backwards compatibility for klog")
+
+	flagset.StringVar(&logFormat, "log-format", logFormat,
+		fmt.Sprintf("the format of the log output: %q or %q", TextFormat, JSONFormat))
 }
 
-// InitK8sLogging initializes a human friendly zerolog logger as the concrete logr.Logger
-// implementation in use by controller-runtime.
+// InitK8sLogging initializes a zerolog logger, in the format selected via the log-format option, as the concrete
+// logr.Logger implementation in use by controller-runtime. By default, the logger is human friendly.
 //goland:noinspection GoUnusedExportedFunction
 func InitK8sLogging() {
 	if verbosityLevel > 0 {
@@ -66,24 +82,35 @@ func InitK8sLogging() {
 	}
 
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
-	zeroLogger := createLogger()
-	logAdapter := newAdapter(&zeroLogger, verbosityLevel)
-	logf.SetLogger(logAdapter)
+	logf.SetLogger(NewLogger(os.Stderr, logFormat))
 }
 
-func createLogger() zerolog.Logger {
-	consoleWriter := &zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "2006-01-02T15:04:05.000Z07:00"}
+// NewLogger returns a zerolog-based logr.Logger that writes to the given output in the given format, either TextFormat
+// or JSONFormat. Any other format falls back to TextFormat. Entries more verbose than the level set via the v option
+// are discarded.
+func NewLogger(out io.Writer, format string) logr.Logger {
+	zeroLogger := createLogger(out, format)
+	return newAdapter(&zeroLogger, verbosityLevel, format == JSONFormat)
+}
+
+func createLogger(out io.Writer, format string) zerolog.Logger {
+	if format == JSONFormat {
+		return zerolog.New(out).With().Timestamp().Caller().Logger()
+	}
+
+	consoleWriter := &zerolog.ConsoleWriter{Out: out, TimeFormat: "2006-01-02T15:04:05.000Z07:00"}
 	consoleWriter.FormatCaller = formatCaller
 
 	return log.Output(consoleWriter).With().Caller().Logger()
 }
 
-func newAdapter(zeroLogger *zerolog.Logger, maxVerbosityLevel int) logr.Logger {
+func newAdapter(zeroLogger *zerolog.Logger, maxVerbosityLevel int, json bool) logr.Logger {
 	return &zeroLogContext{
 		zLogger:          zeroLogger,
 		prefix:           "",
 		currentVerbosity: 0,
 		maxVerbosity:     maxVerbosityLevel,
+		json:             json,
 	}
 }
 
@@ -97,6 +124,7 @@ type zeroLogContext struct {
 	currentVerbosity int
 	maxVerbosity     int
 	skipFrames       int
+	json             bool
 }
 
 func (ctx *zeroLogContext) clone() zeroLogContext {
@@ -105,6 +133,7 @@ func (ctx *zeroLogContext) clone() zeroLogContext {
 		prefix:           ctx.prefix,
 		maxVerbosity:     ctx.maxVerbosity,
 		currentVerbosity: ctx.currentVerbosity,
+		json:             ctx.json,
 	}
 }
 
@@ -155,7 +184,14 @@ func (ctx *zeroLogContext) calculateSkipFrames() int {
 }
 
 func (ctx *zeroLogContext) logEvent(evt *zerolog.Event, msg string, kvList ...interface{}) {
-	msg = truncate(ctx.prefix, maxLenLogger) + " " + msg
+	if ctx.json {
+		// The logger name is a separate field rather than a padded prefix of the message.
+		if ctx.prefix != "" {
+			evt = evt.Str("logger", ctx.prefix)
+		}
+	} else {
+		msg = truncate(ctx.prefix, maxLenLogger) + " " + msg
+	}
 
 	evt.Fields(kvList).CallerSkipFrame(ctx.calculateSkipFrames()).Msg(msg)
 }
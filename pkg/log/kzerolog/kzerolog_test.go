@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kzerolog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/log/kzerolog"
+)
+
+var _ = Describe("NewLogger", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+	})
+
+	parseEntry := func() map[string]interface{} {
+		entry := map[string]interface{}{}
+		Expect(json.Unmarshal(out.Bytes(), &entry)).To(Succeed(), "Output is not valid JSON: %s", out.String())
+
+		return entry
+	}
+
+	When("the JSON format is selected", func() {
+		It("should serialize the key/value pairs as JSON fields", func() {
+			logger := kzerolog.NewLogger(out, kzerolog.JSONFormat).WithName("Test").WithValues("cluster", "east")
+			logger.Info("synced resource", "name", "test-pod", "count", 2)
+
+			entry := parseEntry()
+			Expect(entry).To(HaveKeyWithValue("message", "synced resource"))
+			Expect(entry).To(HaveKeyWithValue("level", "info"))
+			Expect(entry).To(HaveKeyWithValue("logger", "Test"))
+			Expect(entry).To(HaveKeyWithValue("cluster", "east"))
+			Expect(entry).To(HaveKeyWithValue("name", "test-pod"))
+			Expect(entry).To(HaveKeyWithValue("count", float64(2)))
+			Expect(entry).To(HaveKey("time"))
+		})
+
+		It("should serialize errors as a JSON field", func() {
+			kzerolog.NewLogger(out, kzerolog.JSONFormat).Error(errors.New("fake error"), "sync failed")
+
+			entry := parseEntry()
+			Expect(entry).To(HaveKeyWithValue("level", "error"))
+			Expect(entry).To(HaveKeyWithValue("error", "fake error"))
+		})
+	})
+
+	When("the text format is selected", func() {
+		It("should not output JSON", func() {
+			kzerolog.NewLogger(out, kzerolog.TextFormat).WithValues("cluster", "east").Info("synced resource")
+
+			Expect(json.Valid(out.Bytes())).To(BeFalse())
+			Expect(out.String()).To(ContainSubstring("synced resource"))
+			Expect(out.String()).To(ContainSubstring("cluster="))
+		})
+	})
+})
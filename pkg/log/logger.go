@@ -80,6 +80,13 @@ func (l Logger) FatalfOnError(err error, format string, args ...interface{}) {
 	l.FatalOnError(err, fmt.Sprintf(format, args...))
 }
 
+// WithValues returns a child Logger that attaches the given key/value pairs to every message it logs. The receiver
+// Logger is unaffected.
+func (l Logger) WithValues(keysAndValues ...interface{}) Logger {
+	l.Logger = l.Logger.WithValues(keysAndValues...)
+	return l
+}
+
 func (l Logger) V(level int) Logger {
 	l.Logger = l.Logger.V(level)
 	return l
@@ -0,0 +1,77 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/log/fake"
+)
+
+var _ = Describe("Logger", func() {
+	var (
+		sink   *fake.Logger
+		logger log.Logger
+	)
+
+	BeforeEach(func() {
+		sink = fake.New()
+		logger = log.Logger{Logger: sink}
+	})
+
+	Describe("WithValues", func() {
+		It("should attach the values to every message logged by the child logger", func() {
+			child := logger.WithValues("cluster-id", "east", "resource", "ns/pod")
+
+			child.Info("first", "extra", "1")
+			child.Infof("second %d", 2)
+			child.V(log.DEBUG).Info("third")
+			child.Warning("fourth")
+			child.Error(nil, "fifth")
+
+			entries := sink.Entries()
+			Expect(entries).To(HaveLen(5))
+
+			for i := range entries {
+				Expect(entries[i].Value("cluster-id")).To(Equal("east"), "Entry %d: %s", i, entries[i].Message)
+				Expect(entries[i].Value("resource")).To(Equal("ns/pod"), "Entry %d: %s", i, entries[i].Message)
+			}
+
+			Expect(entries[0].Value("extra")).To(Equal("1"))
+			Expect(entries[1].Message).To(Equal("second 2"))
+			Expect(entries[2].Level).To(Equal(log.DEBUG))
+			Expect(entries[3].Value(log.WarningKey)).To(Equal("true"))
+		})
+
+		It("should not affect the parent logger", func() {
+			child := logger.WithValues("cluster-id", "east")
+			_ = child.WithValues("resource", "ns/pod")
+
+			logger.Info("parent")
+			child.Info("child")
+
+			Expect(sink.FindEntries("parent")).To(HaveLen(1))
+			Expect(sink.FindEntries("parent")[0].KeysAndValues).To(BeEmpty())
+
+			Expect(sink.FindEntries("child")).To(HaveLen(1))
+			Expect(sink.FindEntries("child")[0].KeysAndValues).To(Equal([]interface{}{"cluster-id", "east"}))
+		})
+	})
+})
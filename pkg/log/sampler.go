@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Sampler limits how often messages with the same caller-supplied key are logged. The first occurrence of a key is
+// always logged, after which only every Nth occurrence is logged. Once a key has not been seen for the quiet period, its
+// count is reset so the next occurrence is logged again.
+type Sampler struct {
+	every       int
+	quietPeriod time.Duration
+	mutex       sync.Mutex
+	keys        map[string]*sampledKey
+}
+
+type sampledKey struct {
+	count    int
+	lastSeen time.Time
+}
+
+// NewSampler returns a Sampler that logs the first occurrence of a key and then 1 in every occurrences, resetting after
+// the given quiet period. If every is less than 1, only the first occurrence is logged until the key is reset.
+func NewSampler(every int, quietPeriod time.Duration) *Sampler {
+	return &Sampler{
+		every:       every,
+		quietPeriod: quietPeriod,
+		keys:        map[string]*sampledKey{},
+	}
+}
+
+// Sample records an occurrence of the given key and returns true if it should be logged.
+func (s *Sampler) Sample(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	k, ok := s.keys[key]
+	if !ok || now.Sub(k.lastSeen) > s.quietPeriod {
+		k = &sampledKey{}
+		s.keys[key] = k
+	}
+
+	k.count++
+	k.lastSeen = now
+
+	if k.count == 1 {
+		return true
+	}
+
+	return s.every > 0 && (k.count-1)%s.every == 0
+}
+
+// Sampled returns a Logger that logs only if the given Sampler permits this occurrence of the key, otherwise it returns
+// a Logger that discards all messages. If the Sampler is nil, the receiver is returned.
+func (l Logger) Sampled(sampler *Sampler, key string) Logger {
+	if sampler == nil || sampler.Sample(key) {
+		return l
+	}
+
+	l.Logger = logr.Discard()
+
+	return l
+}
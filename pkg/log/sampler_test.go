@@ -0,0 +1,111 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/log/fake"
+)
+
+var _ = Describe("Sampler", func() {
+	const quietPeriod = 200 * time.Millisecond
+
+	var (
+		sink    *fake.Logger
+		logger  log.Logger
+		sampler *log.Sampler
+		every   int
+	)
+
+	BeforeEach(func() {
+		sink = fake.New()
+		logger = log.Logger{Logger: sink}
+		every = 10
+	})
+
+	JustBeforeEach(func() {
+		sampler = log.NewSampler(every, quietPeriod)
+	})
+
+	logKeyed := func(key string, n int) {
+		for i := 1; i <= n; i++ {
+			logger.Sampled(sampler, key).Error(errors.New("API server down"), fmt.Sprintf("%s sync failed: %d", key, i))
+		}
+	}
+
+	messages := func() []string {
+		var m []string
+		for _, e := range sink.Entries() {
+			m = append(m, e.Message)
+		}
+
+		return m
+	}
+
+	When("the same keyed message is logged many times", func() {
+		It("should only emit the first occurrence and then 1-in-N", func() {
+			logKeyed("pod", 25)
+			Expect(messages()).To(Equal([]string{"pod sync failed: 1", "pod sync failed: 11", "pod sync failed: 21"}))
+		})
+	})
+
+	When("different keys are logged", func() {
+		It("should sample each key independently", func() {
+			logKeyed("pod", 5)
+			logKeyed("service", 5)
+			Expect(messages()).To(Equal([]string{"pod sync failed: 1", "service sync failed: 1"}))
+		})
+	})
+
+	When("a key is quiet for the quiet period", func() {
+		It("should reset and emit the next occurrence", func() {
+			logKeyed("pod", 5)
+			time.Sleep(quietPeriod + 50*time.Millisecond)
+			logKeyed("pod", 5)
+			Expect(messages()).To(Equal([]string{"pod sync failed: 1", "pod sync failed: 1"}))
+		})
+	})
+
+	When("the sample rate is less than 1", func() {
+		BeforeEach(func() {
+			every = 0
+		})
+
+		It("should only emit the first occurrence", func() {
+			logKeyed("pod", 25)
+			Expect(messages()).To(Equal([]string{"pod sync failed: 1"}))
+		})
+	})
+
+	When("the Sampler is nil", func() {
+		It("should emit every occurrence", func() {
+			for i := 0; i < 5; i++ {
+				logger.Sampled(nil, "pod").Info("sync failed")
+			}
+
+			Expect(sink.FindEntries("sync failed")).To(HaveLen(5))
+		})
+	})
+})
@@ -0,0 +1,96 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package names provides helpers for deriving valid Kubernetes names from arbitrary input.
+package names
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// hashLen is the number of hex characters of the input hash used when a sanitized name must be shortened or replaced.
+const hashLen = 16
+
+// Sanitize converts the given input into a valid DNS-1123 label. The input is lower-cased, each invalid character is
+// replaced with '-' and leading and trailing non-alphanumeric characters are trimmed. If the result exceeds the maximum
+// label length, it's truncated and suffixed with a hash of the input to keep it unique. If nothing valid remains, a hash
+// of the input is returned. A valid DNS-1123 label is returned unchanged.
+func Sanitize(input string) string {
+	if len(validation.IsDNS1123Label(input)) == 0 {
+		return input
+	}
+
+	return sanitize(input, strings.ToLower(input), func(r rune) bool {
+		return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-'
+	}, validation.DNS1123LabelMaxLength)
+}
+
+// LabelValue converts the given input into a valid label value. Each invalid character is replaced with '-' and leading
+// and trailing non-alphanumeric characters are trimmed. If the result exceeds the maximum label value length, it's
+// truncated and suffixed with a hash of the input to keep it unique. If nothing valid remains, a hash of the input is
+// returned. A valid label value is returned unchanged.
+func LabelValue(input string) string {
+	if len(validation.IsValidLabelValue(input)) == 0 {
+		return input
+	}
+
+	return sanitize(input, input, func(r rune) bool {
+		return isAlphanumeric(r) || r == '-' || r == '_' || r == '.'
+	}, validation.LabelValueMaxLength)
+}
+
+func sanitize(input, name string, isValidRune func(r rune) bool, maxLen int) string {
+	name = strings.Map(func(r rune) rune {
+		if isValidRune(r) {
+			return r
+		}
+
+		return '-'
+	}, name)
+
+	name = trimNonAlphanumeric(name)
+
+	if name == "" {
+		return hashOf(input)
+	}
+
+	if len(name) > maxLen {
+		name = trimNonAlphanumeric(name[:maxLen-hashLen-1]) + "-" + hashOf(input)
+	}
+
+	return name
+}
+
+func trimNonAlphanumeric(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return !isAlphanumeric(r)
+	})
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func hashOf(input string) string {
+	h := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(h[:])[:hashLen]
+}
@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package names_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/names"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestNames(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Names Suite")
+}
+
+var _ = Describe("Sanitize", func() {
+	sanitize := func(input string) string {
+		name := names.Sanitize(input)
+		Expect(validation.IsDNS1123Label(name)).To(BeEmpty(), "%q sanitized to invalid name %q", input, name)
+
+		return name
+	}
+
+	When("the input is already valid", func() {
+		It("should return it unchanged", func() {
+			Expect(sanitize("my-service-1")).To(Equal("my-service-1"))
+		})
+	})
+
+	When("the input contains uppercase characters", func() {
+		It("should lowercase them", func() {
+			Expect(sanitize("MyService")).To(Equal("myservice"))
+		})
+	})
+
+	When("the input contains invalid characters", func() {
+		It("should replace them with dashes", func() {
+			Expect(sanitize("my_service.ns/name")).To(Equal("my-service-ns-name"))
+			Expect(sanitize("café")).To(Equal("caf"))
+		})
+	})
+
+	When("the input has leading and trailing non-alphanumeric characters", func() {
+		It("should trim them", func() {
+			Expect(sanitize("--my-service--")).To(Equal("my-service"))
+			Expect(sanitize("_.my-service._")).To(Equal("my-service"))
+		})
+	})
+
+	When("the input contains no valid characters", func() {
+		It("should return a stable hashed fallback", func() {
+			name := sanitize("_.*!")
+			Expect(name).ToNot(BeEmpty())
+			Expect(sanitize("_.*!")).To(Equal(name))
+			Expect(sanitize("!!!")).ToNot(Equal(name))
+		})
+	})
+
+	When("the input is empty", func() {
+		It("should return a hashed fallback", func() {
+			Expect(sanitize("")).ToNot(BeEmpty())
+		})
+	})
+
+	When("the input exceeds the maximum label length", func() {
+		It("should truncate it with a hash suffix", func() {
+			input := strings.Repeat("a", 70)
+			name := sanitize(input)
+			Expect(name).To(HaveLen(validation.DNS1123LabelMaxLength))
+			Expect(name).To(HavePrefix(strings.Repeat("a", 40)))
+			Expect(sanitize(input + "b")).ToNot(Equal(name))
+		})
+	})
+})
+
+var _ = Describe("LabelValue", func() {
+	labelValue := func(input string) string {
+		value := names.LabelValue(input)
+		Expect(validation.IsValidLabelValue(value)).To(BeEmpty(), "%q converted to invalid label value %q", input, value)
+
+		return value
+	}
+
+	When("the input is already valid", func() {
+		It("should return it unchanged", func() {
+			Expect(labelValue("East_Cluster.1")).To(Equal("East_Cluster.1"))
+			Expect(labelValue("")).To(Equal(""))
+		})
+	})
+
+	When("the input contains illegal characters", func() {
+		It("should replace them with dashes", func() {
+			Expect(labelValue("ns/name:v1")).To(Equal("ns-name-v1"))
+		})
+	})
+
+	When("the input has leading and trailing non-alphanumeric characters", func() {
+		It("should trim them", func() {
+			Expect(labelValue("_.cluster-1-._")).To(Equal("cluster-1"))
+		})
+	})
+
+	When("the input contains no valid characters", func() {
+		It("should return a hashed fallback", func() {
+			Expect(labelValue("/:*")).ToNot(BeEmpty())
+		})
+	})
+
+	When("the input exceeds the maximum label value length", func() {
+		It("should truncate it with a stable hash suffix", func() {
+			input := strings.Repeat("Ab", 40)
+			value := labelValue(input)
+			Expect(value).To(HaveLen(validation.LabelValueMaxLength))
+			Expect(value).To(HavePrefix(strings.Repeat("Ab", 20)))
+			Expect(labelValue(input)).To(Equal(value))
+			Expect(labelValue(input + "c")).ToNot(Equal(value))
+		})
+	})
+})
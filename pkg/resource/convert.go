@@ -0,0 +1,124 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Converter converts resources between their typed and unstructured representations using a scheme. The
+// GroupVersionKind of each typed resource type is cached after the first lookup so repeated conversions of the same
+// type avoid the scheme lookup. A Converter is safe for concurrent use.
+type Converter struct {
+	scheme *runtime.Scheme
+	gvks   sync.Map
+}
+
+var defaultConverter = NewConverter(scheme.Scheme)
+
+// NewConverter returns a Converter that uses the given scheme.
+func NewConverter(scheme *runtime.Scheme) *Converter {
+	return &Converter{scheme: scheme}
+}
+
+// FromUnstructured converts the given unstructured resource into the given typed resource using the global k8s
+// scheme. See Converter.FromUnstructured for more details.
+func FromUnstructured(from *unstructured.Unstructured, into runtime.Object) error {
+	return defaultConverter.FromUnstructured(from, into)
+}
+
+// ToUnstructured converts the given resource to an Unstructured. The content of an unstructured resource, eg an
+// UnstructuredList, is deep copied. A typed
+// resource must be registered in the scheme, whose GroupVersionKind is set on the returned Unstructured.
+func (c *Converter) ToUnstructured(from runtime.Object) (*unstructured.Unstructured, error) {
+	switch u := from.(type) {
+	case *unstructured.Unstructured:
+		return u.DeepCopy(), nil
+	case runtime.Unstructured:
+		return &unstructured.Unstructured{Object: runtime.DeepCopyJSON(u.UnstructuredContent())}, nil
+	}
+
+	gvk, err := c.gvkFor(from)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error converting %#v to unstructured.Unstructured", from)
+	}
+
+	to := &unstructured.Unstructured{Object: content}
+	to.SetGroupVersionKind(gvk)
+
+	return to, nil
+}
+
+// FromUnstructured converts the given Unstructured into the given resource. If the given resource is itself an
+// Unstructured, it's populated with a deep copy. Otherwise its type must be registered in the scheme and, if the
+// Unstructured specifies a kind, the kinds must match.
+func (c *Converter) FromUnstructured(from *unstructured.Unstructured, into runtime.Object) error {
+	if u, ok := into.(*unstructured.Unstructured); ok {
+		from.DeepCopyInto(u)
+		return nil
+	}
+
+	gvk, err := c.gvkFor(into)
+	if err != nil {
+		return err
+	}
+
+	if from.GetKind() != "" && from.GroupVersionKind().GroupKind() != gvk.GroupKind() {
+		return errors.Errorf("unable to convert %s %q to %T: expected kind %s", from.GroupVersionKind(), from.GetName(), into,
+			gvk.GroupKind())
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(from.Object, into)
+	if err != nil {
+		return errors.Wrapf(err, "error converting %s %q to %T", from.GroupVersionKind(), from.GetName(), into)
+	}
+
+	into.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return nil
+}
+
+func (c *Converter) gvkFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	t := reflect.TypeOf(obj)
+
+	if gvk, ok := c.gvks.Load(t); ok {
+		return gvk.(schema.GroupVersionKind), nil
+	}
+
+	gvks, _, err := c.scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, errors.Wrapf(err, "type %T is not registered in the scheme", obj)
+	}
+
+	c.gvks.Store(t, gvks[0])
+
+	return gvks[0], nil
+}
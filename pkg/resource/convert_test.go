@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Converter", func() {
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "test-ns",
+				Labels:    map[string]string{"app": "test"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "httpd", Image: "nginx"}},
+			},
+		}
+	})
+
+	When("a typed Pod is round-tripped through unstructured", func() {
+		It("should be preserved", func() {
+			u, err := resource.ToUnstructured(pod)
+			Expect(err).To(Succeed())
+			Expect(u.GetAPIVersion()).To(Equal("v1"))
+			Expect(u.GetKind()).To(Equal("Pod"))
+			Expect(u.GetName()).To(Equal(pod.Name))
+
+			actual := &corev1.Pod{}
+			Expect(resource.FromUnstructured(u, actual)).To(Succeed())
+
+			pod.APIVersion = "v1"
+			pod.Kind = "Pod"
+			Expect(actual).To(Equal(pod))
+		})
+	})
+
+	When("converting an Unstructured to an Unstructured", func() {
+		It("should deep copy it", func() {
+			u, err := resource.ToUnstructured(pod)
+			Expect(err).To(Succeed())
+
+			actual := &unstructured.Unstructured{}
+			Expect(resource.FromUnstructured(u, actual)).To(Succeed())
+			Expect(actual).To(Equal(u))
+
+			actual.SetName("other")
+			Expect(u.GetName()).To(Equal(pod.Name))
+		})
+	})
+
+	When("the type isn't registered in the scheme", func() {
+		var converter *resource.Converter
+
+		BeforeEach(func() {
+			converter = resource.NewConverter(runtime.NewScheme())
+		})
+
+		It("should return an error from ToUnstructured", func() {
+			_, err := converter.ToUnstructured(pod)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("type *v1.Pod is not registered in the scheme"))
+		})
+
+		It("should return an error from FromUnstructured", func() {
+			u, err := resource.ToUnstructured(pod)
+			Expect(err).To(Succeed())
+
+			err = converter.FromUnstructured(u, &corev1.Pod{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("type *v1.Pod is not registered in the scheme"))
+		})
+	})
+
+	When("the Unstructured's kind doesn't match the target type", func() {
+		It("should return an error", func() {
+			u, err := resource.ToUnstructured(pod)
+			Expect(err).To(Succeed())
+
+			Expect(resource.FromUnstructured(u, &corev1.Service{})).ToNot(Succeed())
+		})
+	})
+})
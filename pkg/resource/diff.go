@@ -0,0 +1,206 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Fields that aren't compared by Diff as they're either not part of the desired state or change on every write.
+var diffIgnoredFields = [][]string{
+	{"status"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+}
+
+const (
+	labelsPath      = "metadata.labels."
+	annotationsPath = "metadata.annotations."
+)
+
+// Diff returns a concise, human-readable summary of the differences between the given objects, eg
+// "spec.replicas: 3→5, added label app=x", suitable for logging and events. Field paths are listed in sorted order.
+// The status, managed fields and resource version aren't compared. A nil object is treated as empty. If the objects
+// don't differ, an empty string is returned.
+func Diff(oldObj, newObj runtime.Object) string {
+	from, err := toDiffable(oldObj)
+	if err != nil {
+		return err.Error()
+	}
+
+	to, err := toDiffable(newObj)
+	if err != nil {
+		return err.Error()
+	}
+
+	var changes []string
+
+	diffValues("", from, to, &changes)
+
+	return strings.Join(changes, ", ")
+}
+
+func toDiffable(obj runtime.Object) (map[string]interface{}, error) {
+	if obj == nil || reflect.ValueOf(obj).IsNil() {
+		return map[string]interface{}{}, nil
+	}
+
+	u, err := ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range diffIgnoredFields {
+		unstructured.RemoveNestedField(u.Object, field...)
+	}
+
+	return u.Object, nil
+}
+
+func diffValues(path string, from, to interface{}, changes *[]string) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+
+	if fromIsMap && toIsMap {
+		for _, key := range sortedKeys(fromMap, toMap) {
+			fromValue, inFrom := fromMap[key]
+			toValue, inTo := toMap[key]
+
+			switch {
+			case !inFrom:
+				diffAdded(childPath(path, key), toValue, changes)
+			case !inTo:
+				diffRemoved(childPath(path, key), fromValue, changes)
+			default:
+				diffValues(childPath(path, key), fromValue, toValue, changes)
+			}
+		}
+
+		return
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+
+	if fromIsSlice && toIsSlice {
+		if len(fromSlice) != len(toSlice) {
+			*changes = append(*changes, fmt.Sprintf("%s: %d→%d items", describePath(path), len(fromSlice), len(toSlice)))
+			return
+		}
+
+		for i := range fromSlice {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), fromSlice[i], toSlice[i], changes)
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, fmt.Sprintf("%s: %s→%s", describePath(path), formatValue(from), formatValue(to)))
+	}
+}
+
+func diffAdded(path string, value interface{}, changes *[]string) {
+	if value == nil {
+		return
+	}
+
+	if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+		for _, key := range sortedKeys(m, nil) {
+			diffAdded(childPath(path, key), m[key], changes)
+		}
+
+		return
+	}
+
+	*changes = append(*changes, fmt.Sprintf("added %s=%s", describePath(path), formatValue(value)))
+}
+
+func diffRemoved(path string, value interface{}, changes *[]string) {
+	if value == nil {
+		return
+	}
+
+	if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+		for _, key := range sortedKeys(m, nil) {
+			diffRemoved(childPath(path, key), m[key], changes)
+		}
+
+		return
+	}
+
+	*changes = append(*changes, "removed "+describePath(path))
+}
+
+func sortedKeys(m1, m2 map[string]interface{}) []string {
+	keys := make([]string, 0, len(m1)+len(m2))
+
+	for k := range m1 {
+		keys = append(keys, k)
+	}
+
+	for k := range m2 {
+		if _, found := m1[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func describePath(path string) string {
+	if strings.HasPrefix(path, labelsPath) {
+		return "label " + strings.TrimPrefix(path, labelsPath)
+	}
+
+	if strings.HasPrefix(path, annotationsPath) {
+		return "annotation " + strings.TrimPrefix(path, annotationsPath)
+	}
+
+	return path
+}
+
+func formatValue(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	return fmt.Sprint(v)
+}
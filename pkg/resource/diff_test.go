@@ -0,0 +1,117 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Diff", func() {
+	var oldObj, newObj *appsv1.Deployment
+
+	BeforeEach(func() {
+		oldObj = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "test",
+				Namespace:       "test-ns",
+				ResourceVersion: "1",
+				Labels:          map[string]string{"app": "test"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(3),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "httpd", Image: "nginx"}},
+					},
+				},
+			},
+		}
+
+		newObj = oldObj.DeepCopy()
+	})
+
+	When("a scalar field changes", func() {
+		It("should report the old and new values", func() {
+			newObj.Spec.Replicas = int32Ptr(5)
+			Expect(resource.Diff(oldObj, newObj)).To(Equal("spec.replicas: 3→5"))
+		})
+	})
+
+	When("a field in a list element changes", func() {
+		It("should report the indexed field path", func() {
+			newObj.Spec.Template.Spec.Containers[0].Image = "apache"
+			Expect(resource.Diff(oldObj, newObj)).To(Equal("spec.template.spec.containers[0].image: nginx→apache"))
+		})
+	})
+
+	When("a list's length changes", func() {
+		It("should report the item counts", func() {
+			newObj.Spec.Template.Spec.Containers = append(newObj.Spec.Template.Spec.Containers, corev1.Container{Name: "other"})
+			Expect(resource.Diff(oldObj, newObj)).To(Equal("spec.template.spec.containers: 1→2 items"))
+		})
+	})
+
+	When("a map key is added", func() {
+		It("should report the added key and value", func() {
+			newObj.Labels["tier"] = "web"
+			newObj.Annotations = map[string]string{"note": "x"}
+			Expect(resource.Diff(oldObj, newObj)).To(Equal("added annotation note=x, added label tier=web"))
+		})
+	})
+
+	When("a map key is removed", func() {
+		It("should report the removed key", func() {
+			delete(newObj.Labels, "app")
+			Expect(resource.Diff(oldObj, newObj)).To(Equal("removed label app"))
+		})
+	})
+
+	When("multiple fields change", func() {
+		It("should report them in sorted order", func() {
+			newObj.Spec.Replicas = int32Ptr(5)
+			newObj.Labels["app"] = "x"
+			Expect(resource.Diff(oldObj, newObj)).To(Equal("label app: test→x, spec.replicas: 3→5"))
+		})
+	})
+
+	When("only the status, managed fields or resource version change", func() {
+		It("should report no differences", func() {
+			newObj.ResourceVersion = "2"
+			newObj.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "test"}}
+			newObj.Status.Replicas = 3
+			Expect(resource.Diff(oldObj, newObj)).To(BeEmpty())
+		})
+	})
+
+	When("the old object is nil", func() {
+		It("should report all fields as added", func() {
+			Expect(resource.Diff(nil, &corev1.ConfigMap{Data: map[string]string{"k": "v"}})).To(
+				Equal("added apiVersion=v1, added data.k=v, added kind=ConfigMap"))
+		})
+	})
+})
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
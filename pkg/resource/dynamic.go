@@ -24,6 +24,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 )
 
@@ -53,12 +54,27 @@ func (d *dynamicType) Update(ctx context.Context, obj runtime.Object, options me
 	return d.client.Update(ctx, raw, options)
 }
 
+func (d *dynamicType) UpdateStatus(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+	raw, err := ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.client.UpdateStatus(ctx, raw, options)
+}
+
 func (d *dynamicType) Delete(ctx context.Context, name string,
 	options metav1.DeleteOptions, // nolint:gocritic // Match K8s API
 ) error {
 	return d.client.Delete(ctx, name, options)
 }
 
+func (d *dynamicType) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	options metav1.PatchOptions, subresources ...string,
+) (runtime.Object, error) {
+	return d.client.Patch(ctx, name, pt, data, options, subresources...)
+}
+
 func ForDynamic(client dynamic.ResourceInterface) Interface {
 	return &dynamicType{client: client}
 }
@@ -0,0 +1,63 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// contentHashLength the number of bytes of the SHA-256 digest used for a content hash, so the hex-encoded hash fits in
+// a label value.
+const contentHashLength = 16
+
+// ContentHash returns a deterministic hash of the given resource's meaningful content, ie excluding its metadata and
+// status which are volatile, eg the resourceVersion, or maintained by the server. Further fields to exclude may be given
+// as dot-separated paths, eg "spec.clusterIP". The hash is independent of map key ordering and is label-value safe. A
+// typed resource must be registered in the global k8s scheme, otherwise ContentHash panics.
+func ContentHash(obj runtime.Object, ignorePaths ...string) string {
+	u, err := ToUnstructured(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	content := runtime.DeepCopyJSON(u.Object)
+	delete(content, "metadata")
+	delete(content, "status")
+
+	for _, path := range ignorePaths {
+		unstructured.RemoveNestedField(content, strings.Split(path, ".")...)
+	}
+
+	// Maps are marshalled with sorted keys so the result doesn't depend on the key ordering.
+	data, err := json.Marshal(content)
+	if err != nil {
+		panic(fmt.Sprintf("error marshalling %#v: %v", content, err))
+	}
+
+	h := sha256.Sum256(data)
+
+	return hex.EncodeToString(h[:contentHashLength])
+}
@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var _ = Describe("ContentHash", func() {
+	newService := func() *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.1.2.3",
+				Ports:     []corev1.ServicePort{{Name: "http", Port: 80}},
+				Selector:  map[string]string{"app": "nginx", "tier": "web"},
+			},
+		}
+	}
+
+	When("the specs are identical but the map keys are in a different order", func() {
+		It("should return the same hash", func() {
+			obj1 := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"data":       map[string]interface{}{"a": "1", "b": "2", "c": "3"},
+			}}
+
+			data := map[string]interface{}{}
+			data["c"] = "3"
+			data["b"] = "2"
+			data["a"] = "1"
+
+			obj2 := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj2.Object["data"] = data
+			obj2.Object["kind"] = "ConfigMap"
+			obj2.Object["apiVersion"] = "v1"
+
+			Expect(resource.ContentHash(obj1)).To(Equal(resource.ContentHash(obj2)))
+		})
+	})
+
+	When("the resources differ only in their metadata and status", func() {
+		It("should return the same hash", func() {
+			svc1 := newService()
+			svc2 := newService()
+			svc2.ResourceVersion = "10"
+			svc2.Labels = map[string]string{"foo": "bar"}
+			svc2.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}}
+
+			Expect(resource.ContentHash(svc1)).To(Equal(resource.ContentHash(svc2)))
+		})
+	})
+
+	When("the specs differ", func() {
+		It("should return different hashes", func() {
+			svc1 := newService()
+			svc2 := newService()
+			svc2.Spec.Ports[0].Port = 8080
+
+			Expect(resource.ContentHash(svc1)).ToNot(Equal(resource.ContentHash(svc2)))
+		})
+	})
+
+	When("the specs differ only in an ignored field", func() {
+		It("should return the same hash", func() {
+			svc1 := newService()
+			svc2 := newService()
+			svc2.Spec.ClusterIP = "10.4.5.6"
+
+			Expect(resource.ContentHash(svc1)).ToNot(Equal(resource.ContentHash(svc2)))
+			Expect(resource.ContentHash(svc1, "spec.clusterIP")).To(Equal(resource.ContentHash(svc2, "spec.clusterIP")))
+		})
+	})
+
+	It("should return a valid label value", func() {
+		Expect(validation.IsValidLabelValue(resource.ContentHash(newService()))).To(BeEmpty())
+	})
+
+	It("should return the same hash for the typed and unstructured representations", func() {
+		svc := newService()
+		u, err := resource.ToUnstructured(svc)
+		Expect(err).To(Succeed())
+
+		Expect(resource.ContentHash(svc)).To(Equal(resource.ContentHash(u)))
+	})
+})
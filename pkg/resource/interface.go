@@ -21,22 +21,31 @@ package resource
 import (
 	"context"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type Interface interface {
 	Get(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error)
 	Create(ctx context.Context, obj runtime.Object, options metav1.CreateOptions) (runtime.Object, error)
 	Update(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error)
+	UpdateStatus(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error)
 	Delete(ctx context.Context, name string, options metav1.DeleteOptions) error
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+		subresources ...string) (runtime.Object, error)
 }
 
 type InterfaceFuncs struct {
-	GetFunc    func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error)
-	CreateFunc func(ctx context.Context, obj runtime.Object, options metav1.CreateOptions) (runtime.Object, error)
-	UpdateFunc func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error)
-	DeleteFunc func(ctx context.Context, name string, options metav1.DeleteOptions) error
+	GetFunc          func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error)
+	CreateFunc       func(ctx context.Context, obj runtime.Object, options metav1.CreateOptions) (runtime.Object, error)
+	UpdateFunc       func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error)
+	UpdateStatusFunc func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error)
+	DeleteFunc       func(ctx context.Context, name string, options metav1.DeleteOptions) error
+	PatchFunc        func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+		subresources ...string) (runtime.Object, error)
 }
 
 func (i *InterfaceFuncs) Get(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
@@ -51,8 +60,28 @@ func (i *InterfaceFuncs) Update(ctx context.Context, obj runtime.Object, options
 	return i.UpdateFunc(ctx, obj, options)
 }
 
+// UpdateStatus invokes UpdateStatusFunc if set, otherwise a MethodNotSupported error is returned.
+func (i *InterfaceFuncs) UpdateStatus(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+	if i.UpdateStatusFunc == nil {
+		return nil, apierrors.NewMethodNotSupported(schema.GroupResource{}, "updateStatus")
+	}
+
+	return i.UpdateStatusFunc(ctx, obj, options)
+}
+
 func (i *InterfaceFuncs) Delete(ctx context.Context, name string,
 	options metav1.DeleteOptions, // nolint:gocritic // Match K8s API
 ) error {
 	return i.DeleteFunc(ctx, name, options)
 }
+
+// Patch invokes PatchFunc if set, otherwise a MethodNotSupported error is returned.
+func (i *InterfaceFuncs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	options metav1.PatchOptions, subresources ...string,
+) (runtime.Object, error) {
+	if i.PatchFunc == nil {
+		return nil, apierrors.NewMethodNotSupported(schema.GroupResource{}, "patch")
+	}
+
+	return i.PatchFunc(ctx, name, pt, data, options, subresources...)
+}
@@ -27,6 +27,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -46,9 +47,17 @@ func ForDaemonSet(client kubernetes.Interface, namespace string) Interface {
 		UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
 			return client.AppsV1().DaemonSets(namespace).Update(ctx, obj.(*appsv1.DaemonSet), options)
 		},
+		UpdateStatusFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+			return client.AppsV1().DaemonSets(namespace).UpdateStatus(ctx, obj.(*appsv1.DaemonSet), options)
+		},
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.AppsV1().DaemonSets(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.AppsV1().DaemonSets(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -64,9 +73,17 @@ func ForDeployment(client kubernetes.Interface, namespace string) Interface {
 		UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
 			return client.AppsV1().Deployments(namespace).Update(ctx, obj.(*appsv1.Deployment), options)
 		},
+		UpdateStatusFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+			return client.AppsV1().Deployments(namespace).UpdateStatus(ctx, obj.(*appsv1.Deployment), options)
+		},
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.AppsV1().Deployments(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.AppsV1().Deployments(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -84,9 +101,17 @@ func ForNamespace(client kubernetes.Interface) Interface {
 		UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
 			return client.CoreV1().Namespaces().Update(ctx, obj.(*corev1.Namespace), options)
 		},
+		UpdateStatusFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+			return client.CoreV1().Namespaces().UpdateStatus(ctx, obj.(*corev1.Namespace), options)
+		},
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.CoreV1().Namespaces().Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.CoreV1().Namespaces().Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -102,9 +127,17 @@ func ForPod(client kubernetes.Interface, namespace string) Interface {
 		UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
 			return client.CoreV1().Pods(namespace).Update(ctx, obj.(*corev1.Pod), options)
 		},
+		UpdateStatusFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+			return client.CoreV1().Pods(namespace).UpdateStatus(ctx, obj.(*corev1.Pod), options)
+		},
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.CoreV1().Pods(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.CoreV1().Pods(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -120,9 +153,17 @@ func ForService(client kubernetes.Interface, namespace string) Interface {
 		UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
 			return client.CoreV1().Services(namespace).Update(ctx, obj.(*corev1.Service), options)
 		},
+		UpdateStatusFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+			return client.CoreV1().Services(namespace).UpdateStatus(ctx, obj.(*corev1.Service), options)
+		},
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.CoreV1().Services(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.CoreV1().Services(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -141,6 +182,11 @@ func ForServiceAccount(client kubernetes.Interface, namespace string) Interface
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.CoreV1().ServiceAccounts(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.CoreV1().ServiceAccounts(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -161,6 +207,11 @@ func ForClusterRole(client kubernetes.Interface) Interface {
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.RbacV1().ClusterRoles().Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.RbacV1().ClusterRoles().Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -179,6 +230,11 @@ func ForClusterRoleBinding(client kubernetes.Interface) Interface {
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.RbacV1().ClusterRoleBindings().Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.RbacV1().ClusterRoleBindings().Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -197,6 +253,11 @@ func ForRole(client kubernetes.Interface, namespace string) Interface {
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.RbacV1().Roles(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.RbacV1().Roles(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -215,6 +276,11 @@ func ForRoleBinding(client kubernetes.Interface, namespace string) Interface {
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.RbacV1().RoleBindings(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.RbacV1().RoleBindings(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
 
@@ -233,5 +299,10 @@ func ForConfigMap(client kubernetes.Interface, namespace string) Interface {
 		DeleteFunc: func(ctx context.Context, name string, options metav1.DeleteOptions) error {
 			return client.CoreV1().ConfigMaps(namespace).Delete(ctx, name, options)
 		},
+		PatchFunc: func(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+			subresources ...string,
+		) (runtime.Object, error) {
+			return client.CoreV1().ConfigMaps(namespace).Patch(ctx, name, pt, data, options, subresources...)
+		},
 	}
 }
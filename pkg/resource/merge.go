@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+// MergeMaps deep-merges the src map into the dst map, eg to partially update the spec of an unstructured destination
+// resource in a transform, and returns dst. If dst is nil, a new map is allocated and returned. For each entry in src:
+//   - if both the src and dst values are maps, they're merged recursively.
+//   - otherwise, the src value overrides the dst value, including a nil src value.
+//
+// Slices are replaced rather than merged, as there's no general way to tell whether elements in two slices correspond,
+// eg a list of containers is keyed by name whereas a list of args is positional. Values copied from src are deep
+// copied so subsequent changes to src don't affect dst. A nil src leaves dst unchanged.
+func MergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+
+		if srcIsMap && dstIsMap {
+			dst[key] = MergeMaps(dstMap, srcMap)
+		} else {
+			dst[key] = deepCopyValue(srcValue)
+		}
+	}
+
+	return dst
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			copied[key] = deepCopyValue(elem)
+		}
+
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i := range v {
+			copied[i] = deepCopyValue(v[i])
+		}
+
+		return copied
+	default:
+		return value
+	}
+}
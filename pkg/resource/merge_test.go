@@ -0,0 +1,125 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+)
+
+var _ = Describe("MergeMaps", func() {
+	When("the src has scalar values", func() {
+		It("should override the dst values and add the missing ones", func() {
+			dst := map[string]interface{}{"replicas": int64(1), "name": "nginx"}
+
+			Expect(resource.MergeMaps(dst, map[string]interface{}{"replicas": int64(3), "paused": true})).To(Equal(
+				map[string]interface{}{"replicas": int64(3), "name": "nginx", "paused": true}))
+		})
+	})
+
+	When("the src and dst have nested maps", func() {
+		It("should merge them recursively", func() {
+			dst := map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "nginx"}},
+					"spec":     map[string]interface{}{"hostname": "old"},
+				},
+			}
+
+			src := map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"tier": "web"}},
+					"spec":     map[string]interface{}{"hostname": "new"},
+				},
+			}
+
+			Expect(resource.MergeMaps(dst, src)).To(Equal(map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "nginx", "tier": "web"}},
+					"spec":     map[string]interface{}{"hostname": "new"},
+				},
+			}))
+		})
+	})
+
+	When("a src map value corresponds to a dst non-map value", func() {
+		It("should replace the dst value", func() {
+			dst := map[string]interface{}{"selector": "app=nginx"}
+
+			Expect(resource.MergeMaps(dst, map[string]interface{}{"selector": map[string]interface{}{"app": "nginx"}})).To(Equal(
+				map[string]interface{}{"selector": map[string]interface{}{"app": "nginx"}}))
+		})
+	})
+
+	When("the src and dst have slices", func() {
+		It("should replace the dst slice", func() {
+			dst := map[string]interface{}{"args": []interface{}{"--a", "--b", "--c"}}
+
+			Expect(resource.MergeMaps(dst, map[string]interface{}{"args": []interface{}{"--d"}})).To(Equal(
+				map[string]interface{}{"args": []interface{}{"--d"}}))
+		})
+	})
+
+	When("the src is subsequently modified", func() {
+		It("should not affect the dst", func() {
+			src := map[string]interface{}{
+				"ports":  []interface{}{map[string]interface{}{"port": int64(80)}},
+				"labels": map[string]interface{}{"app": "nginx"},
+			}
+
+			dst := resource.MergeMaps(map[string]interface{}{}, src)
+
+			src["ports"].([]interface{})[0].(map[string]interface{})["port"] = int64(8080)
+			src["labels"].(map[string]interface{})["app"] = "other"
+
+			Expect(dst).To(Equal(map[string]interface{}{
+				"ports":  []interface{}{map[string]interface{}{"port": int64(80)}},
+				"labels": map[string]interface{}{"app": "nginx"},
+			}))
+		})
+	})
+
+	When("the dst is nil", func() {
+		It("should return a copy of the src", func() {
+			Expect(resource.MergeMaps(nil, map[string]interface{}{"a": "1"})).To(Equal(map[string]interface{}{"a": "1"}))
+		})
+	})
+
+	When("the src is nil", func() {
+		It("should return the dst unchanged", func() {
+			Expect(resource.MergeMaps(map[string]interface{}{"a": "1"}, nil)).To(Equal(map[string]interface{}{"a": "1"}))
+		})
+	})
+
+	When("the dst and src are nil", func() {
+		It("should return an empty map", func() {
+			Expect(resource.MergeMaps(nil, nil)).To(BeEmpty())
+		})
+	})
+
+	When("a src value is nil", func() {
+		It("should override the dst value", func() {
+			dst := map[string]interface{}{"nodeName": "node1", "spec": map[string]interface{}{"a": "1"}}
+
+			Expect(resource.MergeMaps(dst, map[string]interface{}{"nodeName": nil, "spec": nil})).To(Equal(
+				map[string]interface{}{"nodeName": nil, "spec": nil}))
+		})
+	})
+})
@@ -22,6 +22,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/submariner-io/admiral/pkg/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 var _ = Describe("EnsureValidName", func() {
@@ -44,3 +49,209 @@ var _ = Describe("EnsureValidName", func() {
 		})
 	})
 })
+
+var _ = Describe("Owner reference helpers", func() {
+	var (
+		pod       *corev1.Pod
+		ownerRefs []metav1.OwnerReference
+	)
+
+	isController := true
+
+	replicaSetRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       "my-replicaset",
+		UID:        "1234",
+		Controller: &isController,
+	}
+
+	configMapRef := metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       "my-configmap",
+		UID:        "5678",
+	}
+
+	BeforeEach(func() {
+		ownerRefs = nil
+	})
+
+	JustBeforeEach(func() {
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "my-pod",
+				Namespace:       "my-ns",
+				OwnerReferences: ownerRefs,
+			},
+		}
+	})
+
+	testBoth := func(verify func(obj runtime.Object)) {
+		It("should work for a typed object", func() {
+			verify(pod)
+		})
+
+		It("should work for an unstructured object", func() {
+			obj, err := resource.ToUnstructured(pod)
+			Expect(err).To(Succeed())
+			verify(obj)
+		})
+	}
+
+	When("the object has a controller owner reference", func() {
+		BeforeEach(func() {
+			ownerRefs = []metav1.OwnerReference{replicaSetRef}
+		})
+
+		testBoth(func(obj runtime.Object) {
+			ref, found := resource.ControllerOwnerRef(obj)
+			Expect(found).To(BeTrue())
+			Expect(*ref).To(Equal(replicaSetRef))
+
+			Expect(resource.HasOwnerOfKind(obj, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))).To(BeTrue())
+			Expect(resource.HasOwnerOfKind(obj, appsv1.SchemeGroupVersion.WithKind("Deployment"))).To(BeFalse())
+		})
+	})
+
+	When("the object has a non-controller owner reference", func() {
+		BeforeEach(func() {
+			ownerRefs = []metav1.OwnerReference{configMapRef}
+		})
+
+		testBoth(func(obj runtime.Object) {
+			_, found := resource.ControllerOwnerRef(obj)
+			Expect(found).To(BeFalse())
+
+			Expect(resource.HasOwnerOfKind(obj, corev1.SchemeGroupVersion.WithKind("ConfigMap"))).To(BeTrue())
+		})
+	})
+
+	When("the object has multiple owner references", func() {
+		BeforeEach(func() {
+			ownerRefs = []metav1.OwnerReference{configMapRef, replicaSetRef}
+		})
+
+		testBoth(func(obj runtime.Object) {
+			ref, found := resource.ControllerOwnerRef(obj)
+			Expect(found).To(BeTrue())
+			Expect(*ref).To(Equal(replicaSetRef))
+
+			Expect(resource.HasOwnerOfKind(obj, corev1.SchemeGroupVersion.WithKind("ConfigMap"))).To(BeTrue())
+			Expect(resource.HasOwnerOfKind(obj, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))).To(BeTrue())
+			Expect(resource.HasOwnerOfKind(obj, corev1.SchemeGroupVersion.WithKind("Secret"))).To(BeFalse())
+		})
+	})
+
+	When("the object has no owner references", func() {
+		testBoth(func(obj runtime.Object) {
+			ref, found := resource.ControllerOwnerRef(obj)
+			Expect(found).To(BeFalse())
+			Expect(ref).To(BeNil())
+
+			Expect(resource.HasOwnerOfKind(obj, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))).To(BeFalse())
+		})
+	})
+
+	When("the owner reference has a different version of the kind", func() {
+		BeforeEach(func() {
+			ref := replicaSetRef
+			ref.APIVersion = "apps/v1beta2"
+			ownerRefs = []metav1.OwnerReference{ref}
+		})
+
+		testBoth(func(obj runtime.Object) {
+			Expect(resource.HasOwnerOfKind(obj, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("SetControllerReference", func() {
+	var (
+		owner      *appsv1.Deployment
+		controlled *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		owner = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "my-deployment", Namespace: "my-ns", UID: "1234"}}
+		controlled = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"}}
+	})
+
+	expectedRef := func() metav1.OwnerReference {
+		isTrue := true
+
+		return metav1.OwnerReference{
+			APIVersion:         "apps/v1",
+			Kind:               "Deployment",
+			Name:               owner.Name,
+			UID:                owner.UID,
+			Controller:         &isTrue,
+			BlockOwnerDeletion: &isTrue,
+		}
+	}
+
+	When("the controlled object has no controller", func() {
+		BeforeEach(func() {
+			controlled.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "my-configmap"}}
+		})
+
+		It("should add the controller reference", func() {
+			Expect(resource.SetControllerReference(owner, controlled, scheme.Scheme)).To(Succeed())
+			Expect(controlled.OwnerReferences).To(HaveLen(2))
+			Expect(controlled.OwnerReferences[1]).To(Equal(expectedRef()))
+		})
+	})
+
+	When("the controller reference is set again", func() {
+		It("should not add another reference", func() {
+			Expect(resource.SetControllerReference(owner, controlled, scheme.Scheme)).To(Succeed())
+			Expect(resource.SetControllerReference(owner, controlled, scheme.Scheme)).To(Succeed())
+			Expect(controlled.OwnerReferences).To(Equal([]metav1.OwnerReference{expectedRef()}))
+		})
+	})
+
+	When("the controlled object is unstructured", func() {
+		It("should add the controller reference", func() {
+			obj, err := resource.ToUnstructured(controlled)
+			Expect(err).To(Succeed())
+
+			Expect(resource.SetControllerReference(owner, obj, scheme.Scheme)).To(Succeed())
+			Expect(obj.GetOwnerReferences()).To(Equal([]metav1.OwnerReference{expectedRef()}))
+		})
+	})
+
+	When("the controlled object already has a different controller", func() {
+		BeforeEach(func() {
+			isTrue := true
+			controlled.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "my-replicaset",
+				Controller: &isTrue,
+			}}
+		})
+
+		It("should return an error", func() {
+			err := resource.SetControllerReference(owner, controlled, scheme.Scheme)
+			Expect(err).To(MatchError(ContainSubstring("already controlled by ReplicaSet \"my-replicaset\"")))
+			Expect(controlled.OwnerReferences).To(HaveLen(1))
+		})
+	})
+
+	When("the owner is in a different namespace", func() {
+		BeforeEach(func() {
+			owner.Namespace = "other-ns"
+		})
+
+		It("should return an error", func() {
+			Expect(resource.SetControllerReference(owner, controlled, scheme.Scheme)).To(
+				MatchError(ContainSubstring("cross-namespace")))
+		})
+	})
+
+	When("the owner type isn't registered in the scheme", func() {
+		It("should return an error", func() {
+			Expect(resource.SetControllerReference(owner, controlled, runtime.NewScheme())).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,162 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//nolint:wrapcheck // These functions are pass-through wrappers for the k8s APIs.
+package resource
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// TypedClient is the shape of the typed resource clients generated by client-go, eg PodInterface, where T is the
+// resource type and L is the corresponding list type.
+type TypedClient[T runtime.Object, L runtime.Object] interface {
+	Get(ctx context.Context, name string, options metav1.GetOptions) (T, error)
+	Create(ctx context.Context, obj T, options metav1.CreateOptions) (T, error)
+	Update(ctx context.Context, obj T, options metav1.UpdateOptions) (T, error)
+	Delete(ctx context.Context, name string, options metav1.DeleteOptions) error
+	List(ctx context.Context, options metav1.ListOptions) (L, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions,
+		subresources ...string) (T, error)
+}
+
+// TypedInterface is an Interface whose returned objects are always of type T. Objects passed to Create and Update
+// that aren't of type T, eg Unstructured, are converted via the scheme.
+type TypedInterface[T runtime.Object] interface {
+	Interface
+	List(ctx context.Context, options metav1.ListOptions) ([]T, error)
+}
+
+type typedStatusClient[T runtime.Object] interface {
+	UpdateStatus(ctx context.Context, obj T, options metav1.UpdateOptions) (T, error)
+}
+
+type typedType[T runtime.Object, L runtime.Object] struct {
+	client TypedClient[T, L]
+}
+
+// ForTyped returns a TypedInterface that delegates to the given typed client. If the client doesn't provide an
+// UpdateStatus method, UpdateStatus returns a MethodNotSupported error. Example:
+//
+//	ForTyped[*corev1.Pod, *corev1.PodList](kubeClient.CoreV1().Pods(namespace))
+func ForTyped[T runtime.Object, L runtime.Object](client TypedClient[T, L]) TypedInterface[T] {
+	return &typedType[T, L]{client: client}
+}
+
+func (t *typedType[T, L]) Get(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
+	return t.client.Get(ctx, name, options)
+}
+
+func (t *typedType[T, L]) Create(ctx context.Context, obj runtime.Object, options metav1.CreateOptions) (runtime.Object, error) {
+	typed, err := toTyped[T](obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.client.Create(ctx, typed, options)
+}
+
+func (t *typedType[T, L]) Update(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+	typed, err := toTyped[T](obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.client.Update(ctx, typed, options)
+}
+
+func (t *typedType[T, L]) UpdateStatus(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+	statusClient, ok := t.client.(typedStatusClient[T])
+	if !ok {
+		return nil, apierrors.NewMethodNotSupported(schema.GroupResource{}, "updateStatus")
+	}
+
+	typed, err := toTyped[T](obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return statusClient.UpdateStatus(ctx, typed, options)
+}
+
+func (t *typedType[T, L]) Delete(ctx context.Context, name string,
+	options metav1.DeleteOptions, // nolint:gocritic // Match K8s API
+) error {
+	return t.client.Delete(ctx, name, options)
+}
+
+func (t *typedType[T, L]) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	options metav1.PatchOptions, subresources ...string,
+) (runtime.Object, error) {
+	return t.client.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func (t *typedType[T, L]) List(ctx context.Context, options metav1.ListOptions) ([]T, error) {
+	list, err := t.client.List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error extracting items from %T", list)
+	}
+
+	typedItems := make([]T, 0, len(items))
+
+	for _, item := range items {
+		typed, err := toTyped[T](item)
+		if err != nil {
+			return nil, err
+		}
+
+		typedItems = append(typedItems, typed)
+	}
+
+	return typedItems, nil
+}
+
+func toTyped[T runtime.Object](from runtime.Object) (T, error) {
+	if typed, ok := from.(T); ok {
+		return typed, nil
+	}
+
+	var zero T
+
+	to, ok := reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+	if !ok {
+		return zero, errors.Errorf("unable to instantiate %T", zero)
+	}
+
+	err := scheme.Scheme.Convert(from, to, nil)
+	if err != nil {
+		return zero, errors.Wrapf(err, "error converting %#v to %T", from, zero)
+	}
+
+	return to, nil
+}
@@ -0,0 +1,122 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//nolint:wrapcheck // These functions are pass-through wrappers for the k8s APIs.
+package resource
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+type typedInterfaceFactory func(client kubernetes.Interface, namespace string) Interface
+
+func clusterScoped(f func(client kubernetes.Interface) Interface) typedInterfaceFactory {
+	return func(client kubernetes.Interface, _ string) Interface {
+		return f(client)
+	}
+}
+
+// Entries are sorted alphabetically by group and resource
+
+var typedInterfaceFactories = map[schema.GroupVersionKind]typedInterfaceFactory{
+	appsv1.SchemeGroupVersion.WithKind("DaemonSet"):          ForDaemonSet,
+	appsv1.SchemeGroupVersion.WithKind("Deployment"):         ForDeployment,
+	corev1.SchemeGroupVersion.WithKind("ConfigMap"):          ForConfigMap,
+	corev1.SchemeGroupVersion.WithKind("Namespace"):          clusterScoped(ForNamespace),
+	corev1.SchemeGroupVersion.WithKind("Pod"):                ForPod,
+	corev1.SchemeGroupVersion.WithKind("Service"):            ForService,
+	corev1.SchemeGroupVersion.WithKind("ServiceAccount"):     ForServiceAccount,
+	rbacv1.SchemeGroupVersion.WithKind("ClusterRole"):        clusterScoped(ForClusterRole),
+	rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"): clusterScoped(ForClusterRoleBinding),
+	rbacv1.SchemeGroupVersion.WithKind("Role"):               ForRole,
+	rbacv1.SchemeGroupVersion.WithKind("RoleBinding"):        ForRoleBinding,
+}
+
+type convertingType struct {
+	Interface
+	gvk schema.GroupVersionKind
+}
+
+// ForTypedWithGVK returns an Interface that delegates to the typed client in the given clientset for the given
+// GroupVersionKind. Objects passed to Create, Update and UpdateStatus that aren't of the typed client's type, eg
+// Unstructured, are converted via the scheme. The namespace is ignored for cluster-scoped resources. An error is
+// returned if the GroupVersionKind isn't supported.
+func ForTypedWithGVK(client kubernetes.Interface, gvk schema.GroupVersionKind, namespace string) (Interface, error) {
+	factory, ok := typedInterfaceFactories[gvk]
+	if !ok {
+		return nil, errors.Errorf("no typed client is available for GroupVersionKind %q", gvk.String())
+	}
+
+	return &convertingType{Interface: factory(client, namespace), gvk: gvk}, nil
+}
+
+func (c *convertingType) Create(ctx context.Context, obj runtime.Object, options metav1.CreateOptions) (runtime.Object, error) {
+	typed, err := c.toTyped(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Interface.Create(ctx, typed, options)
+}
+
+func (c *convertingType) Update(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+	typed, err := c.toTyped(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Interface.Update(ctx, typed, options)
+}
+
+func (c *convertingType) UpdateStatus(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+	typed, err := c.toTyped(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Interface.UpdateStatus(ctx, typed, options)
+}
+
+func (c *convertingType) toTyped(from runtime.Object) (runtime.Object, error) {
+	to, err := scheme.Scheme.New(c.gvk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error instantiating %q", c.gvk.String())
+	}
+
+	if reflect.TypeOf(from) == reflect.TypeOf(to) {
+		return from, nil
+	}
+
+	err = scheme.Scheme.Convert(from, to, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error converting %#v to %T", from, to)
+	}
+
+	return to, nil
+}
@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ForTypedWithGVK", func() {
+	var (
+		kubeClient *fake.Clientset
+		client     resource.Interface
+		pod        *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset()
+		pod = test.NewPod(test.LocalNamespace)
+
+		var err error
+
+		client, err = resource.ForTypedWithGVK(kubeClient, corev1.SchemeGroupVersion.WithKind("Pod"), test.LocalNamespace)
+		Expect(err).To(Succeed())
+	})
+
+	getPod := func() *corev1.Pod {
+		actual, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+
+		return actual
+	}
+
+	Specify("Create should create the typed resource", func() {
+		obj, err := client.Create(context.TODO(), pod, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+		Expect(obj).To(BeAssignableToTypeOf(&corev1.Pod{}))
+		Expect(getPod().Spec).To(Equal(pod.Spec))
+	})
+
+	Specify("Create should convert an Unstructured resource", func() {
+		_, err := client.Create(context.TODO(), test.ToUnstructured(pod), metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+		Expect(getPod().Spec).To(Equal(pod.Spec))
+	})
+
+	When("the resource exists", func() {
+		BeforeEach(func() {
+			_, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		})
+
+		Specify("Get should return the typed resource", func() {
+			obj, err := client.Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(obj).To(BeAssignableToTypeOf(&corev1.Pod{}))
+			Expect(obj.(*corev1.Pod).Spec).To(Equal(pod.Spec))
+		})
+
+		Specify("Update should update the resource", func() {
+			pod.Spec.Hostname = "updated"
+
+			_, err := client.Update(context.TODO(), test.ToUnstructured(pod), metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+			Expect(getPod().Spec.Hostname).To(Equal("updated"))
+		})
+
+		Specify("Delete should delete the resource", func() {
+			Expect(client.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			_, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	When("the GroupVersionKind isn't supported", func() {
+		It("should return an error identifying it", func() {
+			_, err := resource.ForTypedWithGVK(kubeClient, schema.GroupVersionKind{Group: "unknown.io", Version: "v1", Kind: "Widget"},
+				test.LocalNamespace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unknown.io/v1, Kind=Widget"))
+		})
+	})
+})
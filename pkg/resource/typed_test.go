@@ -0,0 +1,123 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("ForTyped", func() {
+	var (
+		kubeClient *fake.Clientset
+		client     resource.TypedInterface[*corev1.Pod]
+		pod        *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		kubeClient = fake.NewSimpleClientset()
+		client = resource.ForTyped[*corev1.Pod, *corev1.PodList](kubeClient.CoreV1().Pods(test.LocalNamespace))
+		pod = test.NewPod(test.LocalNamespace)
+	})
+
+	Specify("Create should create the typed resource", func() {
+		obj, err := client.Create(context.TODO(), pod, metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+		Expect(obj).To(BeAssignableToTypeOf(&corev1.Pod{}))
+
+		actual, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+		Expect(actual.Spec).To(Equal(pod.Spec))
+	})
+
+	Specify("Create should convert an Unstructured resource", func() {
+		_, err := client.Create(context.TODO(), test.ToUnstructured(pod), metav1.CreateOptions{})
+		Expect(err).To(Succeed())
+
+		actual, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+		Expect(actual.Spec).To(Equal(pod.Spec))
+	})
+
+	When("the resource exists", func() {
+		BeforeEach(func() {
+			_, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+		})
+
+		Specify("Get should return the typed resource", func() {
+			obj, err := client.Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(obj).To(BeAssignableToTypeOf(&corev1.Pod{}))
+			Expect(obj.(*corev1.Pod).Spec).To(Equal(pod.Spec))
+		})
+
+		Specify("Update should update the typed resource", func() {
+			pod.Spec.Containers[0].Image = "updated"
+
+			_, err := client.Update(context.TODO(), pod, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+
+			actual, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(actual.Spec).To(Equal(pod.Spec))
+		})
+
+		Specify("Delete should delete the resource", func() {
+			Expect(client.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			_, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		Specify("List should return the typed resources", func() {
+			list, err := client.List(context.TODO(), metav1.ListOptions{})
+			Expect(err).To(Succeed())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Name).To(Equal(pod.Name))
+		})
+
+		Specify("CreateOrUpdate should pass the typed resource to the mutate function", func() {
+			result, err := util.CreateOrUpdate(context.TODO(), client, pod, func(existing runtime.Object) (runtime.Object, error) {
+				existingPod, ok := existing.(*corev1.Pod)
+				Expect(ok).To(BeTrue())
+
+				existingPod.Spec.Containers[0].Image = "updated"
+
+				return existingPod, nil
+			})
+			Expect(err).To(Succeed())
+			Expect(result).To(Equal(util.OperationResultUpdated))
+
+			actual, err := kubeClient.CoreV1().Pods(test.LocalNamespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(actual.Spec.Containers[0].Image).To(Equal("updated"))
+		})
+	})
+})
@@ -27,22 +27,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// ToUnstructured converts the given resource to an Unstructured using the global k8s scheme. See
+// Converter.ToUnstructured for more details.
 func ToUnstructured(from runtime.Object) (*unstructured.Unstructured, error) {
-	switch f := from.(type) {
-	case *unstructured.Unstructured:
-		return f.DeepCopy(), nil
-	default:
-		to := &unstructured.Unstructured{}
-		err := scheme.Scheme.Convert(from, to, nil)
-		if err != nil {
-			return nil, errors.Wrapf(err, "error converting %#v to unstructured.Unstructured", from)
-		}
-
-		return to, nil
-	}
+	return defaultConverter.ToUnstructured(from)
 }
 
 func ToMeta(obj runtime.Object) metav1.Object {
@@ -54,6 +45,81 @@ func ToMeta(obj runtime.Object) metav1.Object {
 	return objMeta
 }
 
+// ControllerOwnerRef returns the owner reference of the given object that's marked as its managing controller, if any.
+func ControllerOwnerRef(obj runtime.Object) (*metav1.OwnerReference, bool) {
+	ref := metav1.GetControllerOf(ToMeta(obj))
+
+	return ref, ref != nil
+}
+
+// HasOwnerOfKind returns true if the given object has an owner reference, controller or not, whose group and kind match
+// the given GroupVersionKind. The version isn't compared as the same owner may be referenced via different API versions.
+func HasOwnerOfKind(obj runtime.Object, gvk schema.GroupVersionKind) bool {
+	for _, ref := range ToMeta(obj).GetOwnerReferences() {
+		if schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind() == gvk.GroupKind() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetControllerReference sets an owner reference on the controlled object that marks the given owner as its managing
+// controller, with BlockOwnerDeletion set, replacing any existing reference to the owner. The owner's GroupVersionKind
+// is resolved via the given scheme. An error is returned if the controlled object already has a different controller
+// or if the owner is namespaced in a different namespace than the controlled object.
+func SetControllerReference(owner, controlled runtime.Object, scheme *runtime.Scheme) error {
+	gvks, _, err := scheme.ObjectKinds(owner)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving the GroupVersionKind of owner %T", owner)
+	}
+
+	ownerMeta := ToMeta(owner)
+	controlledMeta := ToMeta(controlled)
+
+	if ownerMeta.GetNamespace() != "" && ownerMeta.GetNamespace() != controlledMeta.GetNamespace() {
+		return errors.Errorf("cross-namespace owner references are disallowed - owner %q is in namespace %q, controlled "+
+			"object %q is in namespace %q", ownerMeta.GetName(), ownerMeta.GetNamespace(), controlledMeta.GetName(),
+			controlledMeta.GetNamespace())
+	}
+
+	isTrue := true
+	ref := metav1.OwnerReference{
+		APIVersion:         gvks[0].GroupVersion().String(),
+		Kind:               gvks[0].Kind,
+		Name:               ownerMeta.GetName(),
+		UID:                ownerMeta.GetUID(),
+		Controller:         &isTrue,
+		BlockOwnerDeletion: &isTrue,
+	}
+
+	if existing := metav1.GetControllerOf(controlledMeta); existing != nil && !isSameOwner(existing, &ref) {
+		return errors.Errorf("object %q is already controlled by %s %q", controlledMeta.GetName(), existing.Kind, existing.Name)
+	}
+
+	refs := controlledMeta.GetOwnerReferences()
+
+	for i := range refs {
+		if isSameOwner(&refs[i], &ref) {
+			refs[i] = ref
+			controlledMeta.SetOwnerReferences(refs)
+
+			return nil
+		}
+	}
+
+	controlledMeta.SetOwnerReferences(append(refs, ref))
+
+	return nil
+}
+
+// isSameOwner returns true if the owner references refer to the same object. The version isn't compared as the same
+// owner may be referenced via different API versions.
+func isSameOwner(a, b *metav1.OwnerReference) bool {
+	return a.Name == b.Name &&
+		schema.FromAPIVersionAndKind(a.APIVersion, a.Kind).GroupKind() == schema.FromAPIVersionAndKind(b.APIVersion, b.Kind).GroupKind()
+}
+
 func EnsureValidName(name string) string {
 	// K8s only allows lower case alphanumeric characters, '-' or '.'. Regex used for validation is
 	// '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*'
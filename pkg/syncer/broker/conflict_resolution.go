@@ -0,0 +1,184 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// SourceGenerationAnnotation holds the generation of the source resource from which a broker resource was synced.
+	SourceGenerationAnnotation = "submariner-io/sourceGeneration"
+
+	// LastUpdateTimeAnnotation holds the RFC 3339 time at which a resource was last updated, as set by its owner.
+	LastUpdateTimeAnnotation = "submariner-io/lastUpdateTime"
+)
+
+// ConflictResolver is invoked prior to writing a resource to the broker when a resource with the same name already
+// exists that was synced from another cluster, ie the same logical resource was updated in two clusters. It must return
+// either the existing or the desired resource - if the existing resource is returned, the write is skipped. To avoid
+// oscillation between clusters, a resolver should return the existing resource when both have equal precedence.
+type ConflictResolver func(existing, desired *unstructured.Unstructured) *unstructured.Unstructured
+
+// HigherGenerationWins is a ConflictResolver that chooses the resource synced from the source resource with the
+// higher generation, as recorded in the SourceGenerationAnnotation.
+func HigherGenerationWins(existing, desired *unstructured.Unstructured) *unstructured.Unstructured {
+	if sourceGeneration(desired) > sourceGeneration(existing) {
+		return desired
+	}
+
+	return existing
+}
+
+// NewerLastUpdateTimeWins is a ConflictResolver that chooses the resource with the newer LastUpdateTimeAnnotation. A
+// resource without a valid annotation is considered older than one with it.
+func NewerLastUpdateTimeWins(existing, desired *unstructured.Unstructured) *unstructured.Unstructured {
+	if lastUpdateTime(desired).After(lastUpdateTime(existing)) {
+		return desired
+	}
+
+	return existing
+}
+
+func sourceGeneration(obj *unstructured.Unstructured) int64 {
+	generation, _ := strconv.ParseInt(obj.GetAnnotations()[SourceGenerationAnnotation], 10, 64)
+	return generation
+}
+
+func lastUpdateTime(obj *unstructured.Unstructured) time.Time {
+	t, _ := time.Parse(time.RFC3339, obj.GetAnnotations()[LastUpdateTimeAnnotation])
+	return t
+}
+
+type conflictResolvingFederator struct {
+	federate.Federator
+	client          dynamic.Interface
+	restMapper      meta.RESTMapper
+	targetNamespace string
+	localClusterID  string
+	resolve         ConflictResolver
+}
+
+// NewConflictResolvingFederator returns a Federator that applies the given ConflictResolver against the resource
+// currently in the target namespace, if it was synced from a cluster other than the local cluster, before delegating
+// to the given Federator. The generation of each distributed resource is recorded in the SourceGenerationAnnotation.
+func NewConflictResolvingFederator(federator federate.Federator, client dynamic.Interface, restMapper meta.RESTMapper,
+	targetNamespace, localClusterID string, resolve ConflictResolver,
+) federate.Federator {
+	return &conflictResolvingFederator{
+		Federator:       federator,
+		client:          client,
+		restMapper:      restMapper,
+		targetNamespace: targetNamespace,
+		localClusterID:  localClusterID,
+		resolve:         resolve,
+	}
+}
+
+func (f *conflictResolvingFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	desired, write, err := f.resolveConflict(ctx, obj)
+	if err != nil || !write {
+		return err
+	}
+
+	return f.Federator.Distribute(ctx, desired) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *conflictResolvingFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		if err := f.Distribute(ctx, obj); err != nil {
+			errs[obj] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *conflictResolvingFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return federate.DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *conflictResolvingFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	desired, write, err := f.resolveConflict(ctx, obj)
+	if err != nil || !write {
+		return util.OperationResultNone, err
+	}
+
+	return federate.DistributeDryRun(ctx, f.Federator, desired) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *conflictResolvingFederator) resolveConflict(ctx context.Context, obj runtime.Object,
+) (*unstructured.Unstructured, bool, error) {
+	desired, gvr, err := util.ToUnstructuredResource(obj, f.restMapper)
+	if err != nil {
+		return nil, false, err //nolint:wrapcheck // ok to return as is
+	}
+
+	if desired.GetGeneration() > 0 {
+		annotations := desired.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		annotations[SourceGenerationAnnotation] = strconv.FormatInt(desired.GetGeneration(), 10)
+		desired.SetAnnotations(annotations)
+	}
+
+	namespace := f.targetNamespace
+	if namespace == "" {
+		namespace = desired.GetNamespace()
+	}
+
+	existing, err := f.client.Resource(*gvr).Namespace(namespace).Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return desired, true, nil
+	}
+
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error retrieving existing resource %q", desired.GetName())
+	}
+
+	if existing.GetLabels()[federate.ClusterIDLabelKey] == f.localClusterID {
+		return desired, true, nil
+	}
+
+	if f.resolve(existing, desired) != desired {
+		logger.V(log.LIBDEBUG).Infof("Conflict resolution chose the existing resource %s/%s - not distributing",
+			namespace, desired.GetName())
+		return nil, false, nil
+	}
+
+	return desired, true, nil
+}
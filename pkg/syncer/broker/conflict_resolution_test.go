@@ -0,0 +1,198 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package broker_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/syncer/broker"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	metaapi "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+var _ = Describe("Conflict resolution", func() {
+	var (
+		resolver     broker.ConflictResolver
+		brokerClient dynamic.Interface
+		restMapper   metaapi.RESTMapper
+		pods         dynamic.ResourceInterface
+		east         federate.Federator
+		west         federate.Federator
+		eastPod      *corev1.Pod
+		westPod      *corev1.Pod
+	)
+
+	ctx := context.TODO()
+
+	newFederator := func(clusterID string) federate.Federator {
+		return broker.NewConflictResolvingFederator(
+			broker.NewFederator(brokerClient, restMapper, test.RemoteNamespace, clusterID),
+			brokerClient, restMapper, test.RemoteNamespace, clusterID, resolver)
+	}
+
+	brokerPod := func() *unstructured.Unstructured {
+		obj, err := pods.Get(ctx, eastPod.Name, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+
+		return obj
+	}
+
+	clusterIDOf := func(obj *unstructured.Unstructured) string {
+		return obj.GetLabels()[federate.ClusterIDLabelKey]
+	}
+
+	BeforeEach(func() {
+		resolver = broker.HigherGenerationWins
+		eastPod = test.NewPod(test.LocalNamespace)
+		westPod = test.NewPodWithImage(test.LocalNamespace, "apache")
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		restMapper = test.GetRESTMapperFor(&corev1.Pod{})
+		brokerClient = fake.NewDynamicClient(scheme)
+		pods = brokerClient.Resource(*test.GetGroupVersionResourceFor(restMapper, &corev1.Pod{})).Namespace(test.RemoteNamespace)
+
+		east = newFederator("east")
+		west = newFederator("west")
+	})
+
+	distributeAlternately := func() {
+		for i := 0; i < 3; i++ {
+			Expect(east.Distribute(ctx, eastPod)).To(Succeed())
+			Expect(west.Distribute(ctx, westPod)).To(Succeed())
+		}
+	}
+
+	When("the resource does not exist in the broker", func() {
+		It("should write it", func() {
+			eastPod.Generation = 2
+			Expect(east.Distribute(ctx, eastPod)).To(Succeed())
+			Expect(clusterIDOf(brokerPod())).To(Equal("east"))
+			Expect(brokerPod().GetAnnotations()).To(HaveKeyWithValue(broker.SourceGenerationAnnotation, "2"))
+		})
+	})
+
+	When("the existing broker resource was synced from the local cluster", func() {
+		It("should always write it", func() {
+			eastPod.Generation = 5
+			Expect(east.Distribute(ctx, eastPod)).To(Succeed())
+
+			eastPod.Generation = 1
+			eastPod.Labels = map[string]string{"updated": "true"}
+			Expect(east.Distribute(ctx, eastPod)).To(Succeed())
+			Expect(brokerPod().GetLabels()).To(HaveKeyWithValue("updated", "true"))
+		})
+	})
+
+	Context("with the HigherGenerationWins strategy", func() {
+		When("the other cluster's resource has a higher generation", func() {
+			It("should write it", func() {
+				eastPod.Generation = 1
+				westPod.Generation = 2
+
+				Expect(east.Distribute(ctx, eastPod)).To(Succeed())
+				Expect(west.Distribute(ctx, westPod)).To(Succeed())
+				Expect(clusterIDOf(brokerPod())).To(Equal("west"))
+
+				Expect(east.Distribute(ctx, eastPod)).To(Succeed())
+				Expect(clusterIDOf(brokerPod())).To(Equal("west"))
+			})
+		})
+
+		When("both resources have the same generation", func() {
+			It("should keep the existing resource and not oscillate", func() {
+				eastPod.Generation = 3
+				westPod.Generation = 3
+
+				distributeAlternately()
+				Expect(clusterIDOf(brokerPod())).To(Equal("east"))
+			})
+		})
+	})
+
+	Context("with the NewerLastUpdateTimeWins strategy", func() {
+		now := time.Now()
+
+		BeforeEach(func() {
+			resolver = broker.NewerLastUpdateTimeWins
+			eastPod.Annotations = map[string]string{broker.LastUpdateTimeAnnotation: now.Format(time.RFC3339)}
+		})
+
+		When("the other cluster's resource is newer", func() {
+			It("should write it", func() {
+				westPod.Annotations = map[string]string{broker.LastUpdateTimeAnnotation: now.Add(time.Minute).Format(time.RFC3339)}
+
+				distributeAlternately()
+				Expect(clusterIDOf(brokerPod())).To(Equal("west"))
+			})
+		})
+
+		When("the other cluster's resource is older", func() {
+			It("should not write it", func() {
+				westPod.Annotations = map[string]string{broker.LastUpdateTimeAnnotation: now.Add(-time.Minute).Format(time.RFC3339)}
+
+				distributeAlternately()
+				Expect(clusterIDOf(brokerPod())).To(Equal("east"))
+			})
+		})
+
+		When("both resources have the same time", func() {
+			It("should keep the existing resource and not oscillate", func() {
+				westPod.Annotations = eastPod.Annotations
+
+				distributeAlternately()
+				Expect(clusterIDOf(brokerPod())).To(Equal("east"))
+			})
+		})
+	})
+
+	Context("with a custom resolver", func() {
+		BeforeEach(func() {
+			resolver = func(existing, desired *unstructured.Unstructured) *unstructured.Unstructured {
+				if desired.GetLabels()["priority"] == "high" {
+					return desired
+				}
+
+				return existing
+			}
+
+			westPod.Labels = map[string]string{"priority": "high"}
+		})
+
+		It("should write the chosen resource", func() {
+			distributeAlternately()
+
+			obj := brokerPod()
+			Expect(clusterIDOf(obj)).To(Equal("west"))
+			Expect(obj.GetLabels()).To(HaveKeyWithValue("priority", "high"))
+		})
+	})
+})
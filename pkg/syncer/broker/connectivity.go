@@ -0,0 +1,187 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// connectivityFederator tracks the reachability of the broker as observed by the writes delegated to the wrapped
+// Federator. While the broker is unreachable, only one write at a time is let through to probe it - others fail fast
+// so they're re-queued with backoff by their syncer rather than each waiting on the unreachable broker, which would
+// hold up the syncer's workers and thus the processing of resources that aren't written to the broker.
+type connectivityFederator struct {
+	federate.Federator
+	mutex            sync.Mutex
+	unreachableSince time.Time
+	lastErr          error
+	probing          bool
+}
+
+func newConnectivityFederator(federator federate.Federator) *connectivityFederator {
+	return &connectivityFederator{Federator: federator}
+}
+
+func (f *connectivityFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	return f.write(func() error {
+		return f.Federator.Distribute(ctx, obj)
+	})
+}
+
+func (f *connectivityFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		if err := f.Distribute(ctx, obj); err != nil {
+			errs[obj] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *connectivityFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	result := util.OperationResultNone
+
+	err := f.write(func() error {
+		var err error
+		result, err = federate.DistributeDryRun(ctx, f.Federator, obj)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (f *connectivityFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	return f.write(func() error {
+		return f.Federator.Delete(ctx, obj)
+	})
+}
+
+func (f *connectivityFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return f.write(func() error {
+		return federate.DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...)
+	})
+}
+
+//nolint:wrapcheck // The delegated errors are returned as is.
+func (f *connectivityFederator) write(do func() error) error {
+	isProbe, err := f.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = do()
+
+	f.record(err, isProbe)
+
+	return err
+}
+
+// acquire returns an error if the broker is unreachable and another write is already probing it. Otherwise, the
+// returned bool indicates whether the write is a probe.
+func (f *connectivityFederator) acquire() (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.unreachableSince.IsZero() {
+		return false, nil
+	}
+
+	if f.probing {
+		return false, apierrors.NewServiceUnavailable(errors.Wrap(f.lastErr,
+			"the broker is unreachable - deferring the write").Error())
+	}
+
+	f.probing = true
+
+	return true, nil
+}
+
+func (f *connectivityFederator) record(err error, isProbe bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if isProbe {
+		f.probing = false
+	}
+
+	if isConnectivityError(err) {
+		if f.unreachableSince.IsZero() {
+			logger.Warningf("The broker is unreachable: %v", err)
+
+			f.unreachableSince = time.Now()
+		}
+
+		f.lastErr = err
+
+		return
+	}
+
+	if !f.unreachableSince.IsZero() {
+		logger.V(log.LIBDEBUG).Infof("The broker is reachable again after %v",
+			time.Since(f.unreachableSince).Round(time.Millisecond))
+	}
+
+	f.unreachableSince = time.Time{}
+	f.lastErr = nil
+}
+
+// unreachable returns the time since which the broker has been unreachable, zero if it's reachable, and the last error.
+func (f *connectivityFederator) unreachable() (time.Time, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.unreachableSince, f.lastErr
+}
+
+// isConnectivityError returns true if the error indicates the server couldn't be reached or didn't respond, as opposed
+// to it rejecting the request.
+func isConnectivityError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if apierrors.IsServiceUnavailable(err) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) {
+		return true
+	}
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) ||
+		utilnet.IsProbableEOF(err)
+}
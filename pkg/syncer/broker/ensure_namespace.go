@@ -0,0 +1,136 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+type ensureNamespaceFederator struct {
+	federate.Federator
+	client          dynamic.ResourceInterface
+	targetNamespace string
+	labels          map[string]string
+	ensured         sync.Map
+}
+
+// NewEnsureNamespaceFederator returns a Federator that ensures the namespace to which each resource is written exists
+// before delegating to the given Federator, creating it with the given labels if absent. The namespace is the given
+// targetNamespace or, if empty, the resource's namespace. A namespace created concurrently by another writer is
+// treated as existing and the labels of an existing namespace aren't modified. Dry runs and Delete requests are
+// delegated as is.
+func NewEnsureNamespaceFederator(federator federate.Federator, client dynamic.Interface, targetNamespace string,
+	labels map[string]string,
+) federate.Federator {
+	return &ensureNamespaceFederator{
+		Federator:       federator,
+		client:          client.Resource(namespaceGVR),
+		targetNamespace: targetNamespace,
+		labels:          labels,
+	}
+}
+
+func (f *ensureNamespaceFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	namespace := f.targetNamespace
+	if namespace == "" {
+		namespace = resource.ToMeta(obj).GetNamespace()
+	}
+
+	if err := f.ensureNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	err := f.Federator.Distribute(ctx, obj)
+	if apierrors.IsNotFound(err) {
+		// The namespace may have since been deleted so check it again on the next attempt.
+		f.ensured.Delete(namespace)
+	}
+
+	return err //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *ensureNamespaceFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		if err := f.Distribute(ctx, obj); err != nil {
+			errs[obj] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *ensureNamespaceFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	return federate.DistributeDryRun(ctx, f.Federator, obj) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *ensureNamespaceFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return federate.DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *ensureNamespaceFederator) ensureNamespace(ctx context.Context, namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+
+	if _, ok := f.ensured.Load(namespace); ok {
+		return nil
+	}
+
+	_, err := f.client.Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ns := &unstructured.Unstructured{}
+		ns.SetAPIVersion("v1")
+		ns.SetKind("Namespace")
+		ns.SetName(namespace)
+		ns.SetLabels(f.labels)
+
+		_, err = f.client.Create(ctx, ns, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			err = nil
+		}
+
+		if err == nil {
+			logger.Infof("Created namespace %q", namespace)
+		}
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "error ensuring namespace %q exists", namespace)
+	}
+
+	f.ensured.Store(namespace, true)
+
+	return nil
+}
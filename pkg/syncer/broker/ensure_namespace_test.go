@@ -0,0 +1,137 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/federate"
+	fakeFederator "github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/syncer/broker"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("EnsureNamespaceFederator", func() {
+	const targetNamespace = "target-ns"
+
+	var (
+		delegate          *fakeFederator.Federator
+		federator         federate.Federator
+		client            *fake.DynamicClient
+		initialNamespaces []runtime.Object
+		pod               *corev1.Pod
+	)
+
+	ctx := context.TODO()
+	namespacesGVR := corev1.SchemeGroupVersion.WithResource("namespaces")
+
+	BeforeEach(func() {
+		delegate = fakeFederator.New()
+		initialNamespaces = nil
+		pod = test.NewPod(targetNamespace)
+	})
+
+	JustBeforeEach(func() {
+		client = fake.NewDynamicClient(scheme.Scheme, initialNamespaces...)
+		federator = broker.NewEnsureNamespaceFederator(delegate, client, "", map[string]string{"app": "test"})
+	})
+
+	numNamespaceCreates := func() int {
+		n := 0
+
+		for _, action := range client.Actions() {
+			if action.Matches("create", "namespaces") {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	When("the target namespace doesn't exist", func() {
+		It("should create it with the configured labels prior to distributing", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+
+			ns, err := client.Resource(namespacesGVR).Get(ctx, targetNamespace, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(ns.GetLabels()).To(Equal(map[string]string{"app": "test"}))
+
+			delegate.VerifyDistribute(pod)
+		})
+
+		It("should only create it once", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+			Expect(numNamespaceCreates()).To(Equal(1))
+		})
+	})
+
+	When("the target namespace already exists", func() {
+		BeforeEach(func() {
+			initialNamespaces = []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}}
+		})
+
+		It("should not create it", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+			Expect(numNamespaceCreates()).To(BeZero())
+
+			ns, err := client.Resource(namespacesGVR).Get(ctx, targetNamespace, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(ns.GetLabels()).To(BeEmpty())
+
+			delegate.VerifyDistribute(pod)
+		})
+	})
+
+	When("the target namespace is created concurrently", func() {
+		JustBeforeEach(func() {
+			client.PrependReactor("create", "namespaces", func(_ testing.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "namespaces"}, targetNamespace)
+			})
+		})
+
+		It("should treat it as existing", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+			delegate.VerifyDistribute(pod)
+		})
+	})
+
+	When("creating the target namespace fails", func() {
+		JustBeforeEach(func() {
+			client.PrependReactor("create", "namespaces", func(_ testing.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, targetNamespace, nil)
+			})
+		})
+
+		It("should return an error and not distribute", func() {
+			Expect(federator.Distribute(ctx, pod)).ToNot(Succeed())
+			delegate.VerifyNoDistribute()
+		})
+	})
+})
@@ -0,0 +1,138 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// BookkeepingKeyPrefix the prefix shared by the keys of the labels and annotations that admiral uses for its own
+// bookkeeping, eg federate.ClusterIDLabelKey and syncer.OrigNamespaceLabelKey. These are never filtered.
+const BookkeepingKeyPrefix = "submariner-io/"
+
+type metadataFilterFederator struct {
+	federate.Federator
+	includePrefixes []string
+	excludePrefixes []string
+}
+
+// NewMetadataFilterFederator returns a Federator that removes labels and annotations from a copy of each resource
+// before delegating to the given Federator. If includePrefixes is non-empty, only keys with one of the prefixes are
+// retained. Keys with one of the excludePrefixes are then removed. Keys with the BookkeepingKeyPrefix are always
+// retained. Delete requests are delegated as is.
+func NewMetadataFilterFederator(federator federate.Federator, includePrefixes, excludePrefixes []string) federate.Federator {
+	return &metadataFilterFederator{
+		Federator:       federator,
+		includePrefixes: includePrefixes,
+		excludePrefixes: excludePrefixes,
+	}
+}
+
+func (f *metadataFilterFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	filtered, err := f.filter(obj)
+	if err != nil {
+		return err
+	}
+
+	return f.Federator.Distribute(ctx, filtered) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *metadataFilterFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		if err := f.Distribute(ctx, obj); err != nil {
+			errs[obj] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *metadataFilterFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return federate.DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *metadataFilterFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	filtered, err := f.filter(obj)
+	if err != nil {
+		return util.OperationResultNone, err
+	}
+
+	return federate.DistributeDryRun(ctx, f.Federator, filtered) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *metadataFilterFederator) filter(obj runtime.Object) (*unstructured.Unstructured, error) {
+	filtered, err := resource.ToUnstructured(obj)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // ok to return as is
+	}
+
+	if labels := filtered.GetLabels(); labels != nil {
+		filtered.SetLabels(f.filterKeys(labels))
+	}
+
+	if annotations := filtered.GetAnnotations(); annotations != nil {
+		filtered.SetAnnotations(f.filterKeys(annotations))
+	}
+
+	return filtered, nil
+}
+
+func (f *metadataFilterFederator) filterKeys(from map[string]string) map[string]string {
+	to := map[string]string{}
+
+	for key, value := range from {
+		if f.propagates(key) {
+			to[key] = value
+		}
+	}
+
+	return to
+}
+
+func (f *metadataFilterFederator) propagates(key string) bool {
+	if strings.HasPrefix(key, BookkeepingKeyPrefix) {
+		return true
+	}
+
+	if len(f.includePrefixes) > 0 && !hasAnyPrefix(key, f.includePrefixes) {
+		return false
+	}
+
+	return !hasAnyPrefix(key, f.excludePrefixes)
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type RESTConfigOptions struct {
+	// KubeConfig the path to a kubeconfig file. If neither this nor Context is specified, the in-cluster config is
+	// used when running in a pod. Otherwise, the kubeconfig is located via the default loading rules, ie the
+	// KUBECONFIG environment variable or $HOME/.kube/config.
+	KubeConfig string
+
+	// Context the name of the kubeconfig context to use. By default, the current context is used.
+	Context string
+
+	// QPS if non-zero, overrides the maximum queries per second to the API server.
+	QPS float32
+
+	// Burst if non-zero, overrides the maximum burst for throttling requests to the API server.
+	Burst int
+
+	// UserAgent if specified, overrides the User-Agent header sent with requests to the API server, eg to identify the
+	// component for server-side auditing and rate-limit attribution.
+	UserAgent string
+}
+
+// RESTConfig returns the REST config to access a cluster, auto-detecting the in-cluster config when running in a pod
+// and otherwise loading a kubeconfig.
+func RESTConfig(opts RESTConfigOptions) (*rest.Config, error) {
+	config, err := loadRESTConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.QPS != 0 {
+		config.QPS = opts.QPS
+	}
+
+	if opts.Burst != 0 {
+		config.Burst = opts.Burst
+	}
+
+	if opts.UserAgent != "" {
+		config.UserAgent = opts.UserAgent
+	}
+
+	return config, nil
+}
+
+func loadRESTConfig(opts RESTConfigOptions) (*rest.Config, error) {
+	if opts.KubeConfig == "" && opts.Context == "" {
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			return config, nil
+		}
+
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, errors.Wrap(err, "error loading in-cluster config")
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = opts.KubeConfig
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.Context}).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading kubeconfig")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,154 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package broker_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer/broker"
+	"k8s.io/client-go/rest"
+)
+
+const kubeConfigContents = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com:6443
+- name: west
+  cluster:
+    server: https://west.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: east
+  context:
+    cluster: east
+    user: admin
+- name: west
+  context:
+    cluster: west
+    user: admin
+current-context: east
+`
+
+var _ = Describe("RESTConfig", func() {
+	var (
+		opts       broker.RESTConfigOptions
+		config     *rest.Config
+		err        error
+		kubeConfig string
+	)
+
+	BeforeEach(func() {
+		file, err := os.CreateTemp("", "kubeconfig")
+		Expect(err).To(Succeed())
+
+		kubeConfig = file.Name()
+
+		_, err = file.WriteString(kubeConfigContents)
+		Expect(err).To(Succeed())
+		Expect(file.Close()).To(Succeed())
+
+		opts = broker.RESTConfigOptions{KubeConfig: kubeConfig}
+	})
+
+	AfterEach(func() {
+		_ = os.Remove(kubeConfig)
+	})
+
+	JustBeforeEach(func() {
+		config, err = broker.RESTConfig(opts)
+	})
+
+	When("a kubeconfig path is specified", func() {
+		It("should load the current context", func() {
+			Expect(err).To(Succeed())
+			Expect(config.Host).To(Equal("https://east.example.com:6443"))
+			Expect(config.BearerToken).To(Equal("secret"))
+		})
+	})
+
+	When("a context is specified", func() {
+		BeforeEach(func() {
+			opts.Context = "west"
+		})
+
+		It("should load the specified context", func() {
+			Expect(err).To(Succeed())
+			Expect(config.Host).To(Equal("https://west.example.com:6443"))
+		})
+	})
+
+	When("a non-existent context is specified", func() {
+		BeforeEach(func() {
+			opts.Context = "north"
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("QPS and Burst are specified", func() {
+		BeforeEach(func() {
+			opts.QPS = 42.5
+			opts.Burst = 99
+		})
+
+		It("should override them", func() {
+			Expect(err).To(Succeed())
+			Expect(config.QPS).To(Equal(float32(42.5)))
+			Expect(config.Burst).To(Equal(99))
+		})
+	})
+
+	When("QPS and Burst are not specified", func() {
+		It("should not override them", func() {
+			Expect(err).To(Succeed())
+			Expect(config.QPS).To(BeZero())
+			Expect(config.Burst).To(BeZero())
+		})
+	})
+
+	When("a User-Agent is specified", func() {
+		BeforeEach(func() {
+			opts.UserAgent = "test-component/v1.2.3"
+		})
+
+		It("should override it", func() {
+			Expect(err).To(Succeed())
+			Expect(config.UserAgent).To(Equal("test-component/v1.2.3"))
+		})
+	})
+
+	When("the kubeconfig path does not exist", func() {
+		BeforeEach(func() {
+			opts.KubeConfig = "/non-existent/kubeconfig"
+		})
+
+		It("should return an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,182 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer"
+	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultStripFields the fields cleared by default from local resources before they're uploaded to the broker. These
+// are either set by the source cluster's API server or describe the node on which the resource is running and thus
+// aren't meaningful in, and may confuse, receiving clusters:
+//
+//	metadata.resourceVersion, metadata.selfLink, metadata.uid, metadata.creationTimestamp, metadata.generation,
+//	metadata.managedFields, metadata.ownerReferences, status.hostIP, status.hostIPs and status.nominatedNodeName
+var DefaultStripFields = [][]string{
+	{util.MetadataField, "resourceVersion"},
+	{util.MetadataField, "selfLink"},
+	{util.MetadataField, "uid"},
+	{util.MetadataField, "creationTimestamp"},
+	{util.MetadataField, "generation"},
+	{util.MetadataField, "managedFields"},
+	{util.MetadataField, "ownerReferences"},
+	{util.StatusField, "hostIP"},
+	{util.StatusField, "hostIPs"},
+	{util.StatusField, "nominatedNodeName"},
+}
+
+// The fields needed by the syncers to track, and subsequently delete, broker resources. These are never stripped.
+var preservedFields = [][]string{
+	{util.MetadataField, "name"},
+	{util.MetadataField, "namespace"},
+	{util.MetadataField, util.LabelsField, federate.ClusterIDLabelKey},
+	{util.MetadataField, util.LabelsField, syncer.OrigNamespaceLabelKey},
+}
+
+type stripFieldsFederator struct {
+	federate.Federator
+	fields [][]string
+}
+
+// NewStripFieldsFederator returns a Federator that clears the given fields, each specified as a path of field names,
+// from a copy of each resource before delegating to the given Federator. A field that is, or contains, one needed to
+// track the resource for deletion, ie its name, namespace, cluster ID label or originating namespace label, is not
+// cleared. Delete requests are delegated as is.
+func NewStripFieldsFederator(federator federate.Federator, fields ...[]string) federate.Federator {
+	f := &stripFieldsFederator{Federator: federator}
+
+	for _, field := range fields {
+		if !isPreservedField(field) {
+			f.fields = append(f.fields, field)
+		}
+	}
+
+	return f
+}
+
+func (f *stripFieldsFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	stripped, err := f.strip(obj)
+	if err != nil {
+		return err
+	}
+
+	return f.Federator.Distribute(ctx, stripped) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *stripFieldsFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		if err := f.Distribute(ctx, obj); err != nil {
+			errs[obj] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *stripFieldsFederator) DeleteAllFor(ctx context.Context, labelSelector string, gvrs ...schema.GroupVersionResource) error {
+	return federate.DeleteAllFor(ctx, f.Federator, labelSelector, gvrs...) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *stripFieldsFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	stripped, err := f.strip(obj)
+	if err != nil {
+		return util.OperationResultNone, err
+	}
+
+	return federate.DistributeDryRun(ctx, f.Federator, stripped) //nolint:wrapcheck // This function is effectively a wrapper
+}
+
+func (f *stripFieldsFederator) strip(obj runtime.Object) (*unstructured.Unstructured, error) {
+	stripped, err := resource.ToUnstructured(obj)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // ok to return as is
+	}
+
+	for _, field := range f.fields {
+		unstructured.RemoveNestedField(stripped.Object, field...)
+	}
+
+	return stripped, nil
+}
+
+// ParseFieldPath parses a JSON path of the form "metadata.annotations.example\.com/secret" into the field names of
+// which it's comprised. Field names are separated by dots - a dot or backslash that's part of a field name is escaped
+// with a backslash. A leading dot is ignored.
+func ParseFieldPath(path string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		escaped bool
+	)
+
+	for _, c := range strings.TrimPrefix(path, ".") {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '.':
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+
+	return append(fields, current.String())
+}
+
+func isPreservedField(field []string) bool {
+	if len(field) == 0 {
+		return true
+	}
+
+	for _, preserved := range preservedFields {
+		if len(field) > len(preserved) {
+			continue
+		}
+
+		contains := true
+
+		for i := range field {
+			if field[i] != preserved[i] {
+				contains = false
+				break
+			}
+		}
+
+		if contains {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate"
+	fakeFederator "github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer"
+	"github.com/submariner-io/admiral/pkg/syncer/broker"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("StripFieldsFederator", func() {
+	var (
+		delegate  *fakeFederator.Federator
+		federator federate.Federator
+		fields    [][]string
+		pod       *corev1.Pod
+	)
+
+	ctx := context.TODO()
+
+	BeforeEach(func() {
+		delegate = fakeFederator.New()
+		fields = broker.DefaultStripFields
+
+		pod = test.NewPod(test.LocalNamespace)
+		pod.SelfLink = "/api/v1/namespaces/local-ns/pods/test-pod"
+		pod.Generation = 2
+		pod.CreationTimestamp = metav1.Now()
+		pod.Labels[federate.ClusterIDLabelKey] = "east"
+		pod.Labels[syncer.OrigNamespaceLabelKey] = test.LocalNamespace
+		pod.Status = corev1.PodStatus{
+			Phase:  corev1.PodRunning,
+			HostIP: "10.0.0.1",
+			PodIP:  "10.1.0.1",
+		}
+	})
+
+	JustBeforeEach(func() {
+		federator = broker.NewStripFieldsFederator(delegate, fields...)
+	})
+
+	toUnstructured := func() *unstructured.Unstructured {
+		obj, err := resource.ToUnstructured(pod)
+		Expect(err).To(Succeed())
+
+		return obj
+	}
+
+	When("the default fields are specified", func() {
+		It("should clear them prior to distributing", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+
+			expected := toUnstructured()
+			for _, field := range broker.DefaultStripFields {
+				unstructured.RemoveNestedField(expected.Object, field...)
+			}
+
+			delegate.VerifyDistribute(expected)
+
+			Expect(expected.GetResourceVersion()).To(BeEmpty())
+			Expect(expected.GetUID()).To(BeEmpty())
+			Expect(expected.GetSelfLink()).To(BeEmpty())
+			Expect(expected.GetGeneration()).To(BeZero())
+			Expect(expected.GetLabels()).To(HaveKeyWithValue(federate.ClusterIDLabelKey, "east"))
+			Expect(expected.GetLabels()).To(HaveKeyWithValue(syncer.OrigNamespaceLabelKey, test.LocalNamespace))
+			Expect(expected.GetLabels()).To(HaveKeyWithValue("app", "test"))
+			Expect(util.GetNestedField(expected, "status", "hostIP")).To(BeNil())
+			Expect(util.GetNestedField(expected, "status", "podIP")).To(Equal("10.1.0.1"))
+		})
+
+		It("should not modify the original resource", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+			Expect(pod.ResourceVersion).ToNot(BeEmpty())
+			Expect(pod.Status.HostIP).ToNot(BeEmpty())
+		})
+	})
+
+	When("fields needed for delete tracking are specified", func() {
+		BeforeEach(func() {
+			fields = [][]string{
+				{"metadata", "name"},
+				{"metadata", "namespace"},
+				{"metadata", "labels"},
+				{"metadata", "labels", federate.ClusterIDLabelKey},
+				{"metadata", "labels", syncer.OrigNamespaceLabelKey},
+				{"metadata", "labels", "app"},
+				{"status"},
+			}
+		})
+
+		It("should not clear them", func() {
+			Expect(federator.Distribute(ctx, pod)).To(Succeed())
+
+			expected := toUnstructured()
+			unstructured.RemoveNestedField(expected.Object, "metadata", "labels", "app")
+			unstructured.RemoveNestedField(expected.Object, "status")
+
+			delegate.VerifyDistribute(expected)
+		})
+	})
+
+	When("a resource is deleted", func() {
+		It("should delegate the resource as is", func() {
+			Expect(federator.Delete(ctx, pod)).To(Succeed())
+			delegate.VerifyDelete(pod)
+		})
+	})
+})
+
+var _ = Describe("ParseFieldPath", func() {
+	It("should split the path on unescaped dots", func() {
+		Expect(broker.ParseFieldPath("status.podIP")).To(Equal([]string{"status", "podIP"}))
+		Expect(broker.ParseFieldPath(".status.podIP")).To(Equal([]string{"status", "podIP"}))
+		Expect(broker.ParseFieldPath(`metadata.annotations.example\.com/secret`)).To(Equal(
+			[]string{"metadata", "annotations", "example.com/secret"}))
+		Expect(broker.ParseFieldPath(`spec.a\\b`)).To(Equal([]string{"spec", `a\b`}))
+	})
+})
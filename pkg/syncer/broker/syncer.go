@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/submariner-io/admiral/pkg/federate"
@@ -33,6 +34,7 @@ import (
 	"github.com/submariner-io/admiral/pkg/util"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -90,6 +92,20 @@ type ResourceConfig struct {
 
 	// SyncCounterOpts used to pass name and help text to resource syncer Gauge
 	SyncCounterOpts *prometheus.GaugeOpts
+
+	// SyncLagOpts used to pass name and help text to the resource syncer histogram that records how long local and broker
+	// resource changes take to propagate.
+	SyncLagOpts *prometheus.HistogramOpts
+
+	// ConflictResolver if specified, invoked prior to writing a local resource to the broker when the broker resource was
+	// synced from another cluster, to determine which one should be written. See HigherGenerationWins and
+	// NewerLastUpdateTimeWins for built-in strategies. By default, the local resource is always written.
+	ConflictResolver ConflictResolver
+
+	// LocalStripFields the fields, each specified as a path of field names, to clear from local resources before they're
+	// written to the broker. Fields needed to track broker resources for deletion are never cleared. If nil,
+	// DefaultStripFields is used - specify an empty slice to disable. See NewStripFieldsFederator for more details.
+	LocalStripFields [][]string
 }
 
 type SyncerConfig struct {
@@ -104,12 +120,15 @@ type SyncerConfig struct {
 	LocalNamespace string
 
 	// LocalClusterID the ID of the local cluster. This is used to avoid loops when syncing the same resources between
-	// the local and broker sources. If local resources are transformed to different broker resource types then
-	// specify an empty LocalClusterID to disable this loop protection.
+	// the local and broker sources. Resources synced to the broker are labeled with the local cluster ID via the
+	// federate.ClusterIDLabelKey label. Broker resources with the local cluster ID are not synced to the local
+	// source. Local resources with the label are not synced to the broker, as they came from another cluster. If
+	// local resources are transformed to different broker resource types then specify an empty LocalClusterID to
+	// disable this loop protection.
 	LocalClusterID string
 
 	// RestMapper used to obtain GroupVersionResources. This is optional and is provided for unit testing. If not specified,
-	// one is created from the LocalRestConfig.
+	// a cached one that periodically refreshes is created from the LocalRestConfig.
 	RestMapper meta.RESTMapper
 
 	// BrokerRestConfig the REST config used to access the broker resources to sync. If not specified and the BrokerClient
@@ -129,6 +148,59 @@ type SyncerConfig struct {
 
 	// Scheme used to convert resource objects. By default the global k8s Scheme is used.
 	Scheme *runtime.Scheme
+
+	// Log if specified, the logger passed to the underlying resource syncers. By default, the controller-runtime logger
+	// is used.
+	Log logr.Logger
+
+	// ListPageSize if non-zero, the maximum number of resources retrieved per request when the underlying resource
+	// syncers' informers list the resources to sync. See ResourceSyncerConfig.ListPageSize for more details.
+	ListPageSize int64
+
+	// InformerQPS and InformerBurst if non-zero, the rate limits of the clients via which the underlying resource
+	// syncers' informers list and watch the resources to sync, eg to throttle the load on the API servers on start.
+	// Only applicable to a client created from the LocalRestConfig or BrokerRestConfig.
+	InformerQPS   float32
+	InformerBurst int
+
+	// EnsureNamespace if true, the namespace to which each broker resource is synced in the local source is created, if
+	// absent, before the resource is written. See NewEnsureNamespaceFederator for more details.
+	EnsureNamespace bool
+
+	// EnsureNamespaceLabels the labels with which namespaces created due to EnsureNamespace are labeled.
+	EnsureNamespaceLabels map[string]string
+
+	// BrokerUnreachableThreshold the duration for which writes to the broker may continuously fail due to connectivity
+	// errors before Healthy reports an error. By default, syncer.DefaultWatchFailureThreshold is used.
+	BrokerUnreachableThreshold time.Duration
+
+	// ExcludePaths the JSON paths of fields to remove from all local resources before they're written to the broker, in
+	// addition to each ResourceConfig's LocalStripFields. Each path is a dot-separated list of field names, eg
+	// "status.podIP" - a dot that's part of a field name, eg in an annotation key, is escaped with a backslash, eg
+	// "metadata.annotations.example\.com/secret". Paths that don't exist in a resource are skipped. Fields needed to track
+	// broker resources for deletion are never removed. See ParseFieldPath for more details.
+	ExcludePaths []string
+
+	// BeforeWrite if specified, invoked with each resource just before it's written to the broker or local source by
+	// any of the resource syncers. See syncer.ResourceSyncerConfig.BeforeWrite for more details.
+	BeforeWrite syncer.BeforeWriteFunc
+
+	// MetadataIncludePrefixes if non-empty, only the labels and annotations whose keys have one of these prefixes are
+	// propagated between the local source and the broker, in either direction. MetadataExcludePrefixes the prefixes of
+	// the label and annotation keys that aren't propagated. The labels and annotations that admiral uses for its own
+	// bookkeeping, ie with the BookkeepingKeyPrefix, are always propagated. See NewMetadataFilterFederator.
+	MetadataIncludePrefixes []string
+	MetadataExcludePrefixes []string
+
+	// IgnoreChangesToKeys the label and annotation key prefixes whose changes alone don't cause the destination resource
+	// to be rewritten, eg for annotations that churn constantly. They're still propagated along with any other change.
+	// See federate.CreateOrUpdateFederatorOptions.IgnoreChangesToKeys.
+	IgnoreChangesToKeys []string
+
+	// UserAgent if specified, the User-Agent header sent with the requests of all clients created from the
+	// LocalRestConfig or BrokerRestConfig, including the informers' clients and the REST mapper's discovery client, eg to
+	// identify the component for server-side auditing and rate-limit attribution. The given REST configs aren't modified.
+	UserAgent string
 }
 
 type Syncer struct {
@@ -136,29 +208,39 @@ type Syncer struct {
 	localSyncers    map[reflect.Type]syncer.Interface
 	localFederator  federate.Federator
 	remoteFederator federate.Federator
+	connectivity    *connectivityFederator
 	brokerNamespace string
 	brokerClient    dynamic.Interface
 	localClient     dynamic.Interface
+
+	brokerUnreachableThreshold time.Duration
 }
 
 var logger = log.Logger{Logger: logf.Log.WithName("BrokerSyncer")}
 
 // NewSyncer creates a Syncer that performs bi-directional syncing of resources between a local source and a central broker.
 func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Minimal performance hit, we modify our copy
-	if len(config.ResourceConfigs) == 0 {
-		return nil, fmt.Errorf("no resources to sync")
+	if err := config.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid syncer configuration")
 	}
 
 	var err error
 
+	config.LocalRestConfig = withUserAgent(config.LocalRestConfig, config.UserAgent)
+	config.BrokerRestConfig = withUserAgent(config.BrokerRestConfig, config.UserAgent)
+
 	if config.RestMapper == nil {
-		config.RestMapper, err = util.BuildRestMapper(config.LocalRestConfig)
+		config.RestMapper, err = util.BuildCachedRestMapper(config.LocalRestConfig, util.DefaultRestMapperRefreshInterval)
 		if err != nil {
 			return nil, errors.Wrap(err, "error building the REST mapper")
 		}
 	}
 
+	var localInformerRestConfig, brokerInformerRestConfig *rest.Config
+
 	if config.LocalClient == nil {
+		localInformerRestConfig = informerRestConfig(&config, config.LocalRestConfig)
+
 		config.LocalClient, err = dynamic.NewForConfig(config.LocalRestConfig)
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating dynamic client")
@@ -169,6 +251,8 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 		if err := createBrokerClient(&config); err != nil {
 			return nil, err
 		}
+
+		brokerInformerRestConfig = informerRestConfig(&config, config.BrokerRestConfig)
 	}
 
 	brokerSyncer := &Syncer{
@@ -177,10 +261,38 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 		brokerNamespace: config.BrokerNamespace,
 		brokerClient:    config.BrokerClient,
 		localClient:     config.LocalClient,
+
+		brokerUnreachableThreshold: config.BrokerUnreachableThreshold,
+	}
+
+	if brokerSyncer.brokerUnreachableThreshold == 0 {
+		brokerSyncer.brokerUnreachableThreshold = syncer.DefaultWatchFailureThreshold
+	}
+
+	federatorOptions := federate.CreateOrUpdateFederatorOptions{IgnoreChangesToKeys: config.IgnoreChangesToKeys}
+
+	brokerSyncer.connectivity = newConnectivityFederator(federate.NewCreateOrUpdateFederatorWithOptions(config.BrokerClient,
+		config.RestMapper, config.BrokerNamespace, config.LocalClusterID, federatorOptions))
+	brokerSyncer.remoteFederator = brokerSyncer.connectivity
+	brokerSyncer.localFederator = federate.NewCreateOrUpdateFederatorWithOptions(config.LocalClient, config.RestMapper,
+		config.LocalNamespace, "", federatorOptions)
+
+	if config.EnsureNamespace {
+		brokerSyncer.localFederator = NewEnsureNamespaceFederator(brokerSyncer.localFederator, config.LocalClient,
+			config.LocalNamespace, config.EnsureNamespaceLabels)
+	}
+
+	filterMetadata := len(config.MetadataIncludePrefixes) > 0 || len(config.MetadataExcludePrefixes) > 0
+
+	localFederator := brokerSyncer.localFederator
+	if filterMetadata {
+		localFederator = NewMetadataFilterFederator(localFederator, config.MetadataIncludePrefixes, config.MetadataExcludePrefixes)
 	}
 
-	brokerSyncer.remoteFederator = NewFederator(config.BrokerClient, config.RestMapper, config.BrokerNamespace, config.LocalClusterID)
-	brokerSyncer.localFederator = NewFederator(config.LocalClient, config.RestMapper, config.LocalNamespace, "")
+	excludeFields := make([][]string, 0, len(config.ExcludePaths))
+	for _, path := range config.ExcludePaths {
+		excludeFields = append(excludeFields, ParseFieldPath(path))
+	}
 
 	for i := range config.ResourceConfigs {
 		rc := &config.ResourceConfigs[i]
@@ -197,16 +309,46 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 			prometheus.MustRegister(syncCounter)
 		}
 
+		var syncLag *prometheus.HistogramVec
+		if rc.SyncLagOpts != nil {
+			syncLag = prometheus.NewHistogramVec(*rc.SyncLagOpts, []string{syncer.DirectionLabel, syncer.SyncerNameLabel})
+			prometheus.MustRegister(syncLag)
+		}
+
+		remoteFederator := brokerSyncer.remoteFederator
+
+		stripFields := rc.LocalStripFields
+		if stripFields == nil {
+			stripFields = DefaultStripFields
+		}
+
+		stripFields = append(stripFields[:len(stripFields):len(stripFields)], excludeFields...)
+
+		if len(stripFields) > 0 {
+			remoteFederator = NewStripFieldsFederator(remoteFederator, stripFields...)
+		}
+
+		if filterMetadata {
+			remoteFederator = NewMetadataFilterFederator(remoteFederator, config.MetadataIncludePrefixes, config.MetadataExcludePrefixes)
+		}
+
+		if rc.ConflictResolver != nil {
+			remoteFederator = NewConflictResolvingFederator(remoteFederator, config.BrokerClient, config.RestMapper,
+				config.BrokerNamespace, config.LocalClusterID, rc.ConflictResolver)
+		}
+
 		localSyncer, err := syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
 			Name:                fmt.Sprintf("local -> broker for %T", rc.LocalResourceType),
 			SourceClient:        config.LocalClient,
+			ListWatchRestConfig: localInformerRestConfig,
+			ListPageSize:        config.ListPageSize,
 			SourceNamespace:     rc.LocalSourceNamespace,
 			SourceLabelSelector: rc.LocalSourceLabelSelector,
 			SourceFieldSelector: rc.LocalSourceFieldSelector,
 			LocalClusterID:      config.LocalClusterID,
 			Direction:           syncer.LocalToRemote,
 			RestMapper:          config.RestMapper,
-			Federator:           brokerSyncer.remoteFederator,
+			Federator:           remoteFederator,
 			ResourceType:        rc.LocalResourceType,
 			Transform:           rc.LocalTransform,
 			OnSuccessfulSync:    rc.LocalOnSuccessfulSync,
@@ -216,6 +358,9 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 			Scheme:              config.Scheme,
 			ResyncPeriod:        rc.LocalResyncPeriod,
 			SyncCounter:         syncCounter,
+			SyncLag:             syncLag,
+			BeforeWrite:         config.BeforeWrite,
+			Log:                 config.Log,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating local resource syncer")
@@ -233,13 +378,15 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 		remoteSyncer, err := syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
 			Name:                fmt.Sprintf("broker -> local for %T", rc.BrokerResourceType),
 			SourceClient:        config.BrokerClient,
+			ListWatchRestConfig: brokerInformerRestConfig,
+			ListPageSize:        config.ListPageSize,
 			SourceNamespace:     config.BrokerNamespace,
 			SourceLabelSelector: rc.LocalSourceLabelSelector,
 			SourceFieldSelector: rc.LocalSourceFieldSelector,
 			LocalClusterID:      config.LocalClusterID,
 			Direction:           syncer.RemoteToLocal,
 			RestMapper:          config.RestMapper,
-			Federator:           brokerSyncer.localFederator,
+			Federator:           localFederator,
 			ResourceType:        rc.BrokerResourceType,
 			Transform:           rc.BrokerTransform,
 			ResourcesEquivalent: rc.BrokerResourcesEquivalent,
@@ -247,6 +394,9 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 			Scheme:              config.Scheme,
 			ResyncPeriod:        rc.BrokerResyncPeriod,
 			SyncCounter:         syncCounter,
+			SyncLag:             syncLag,
+			BeforeWrite:         config.BeforeWrite,
+			Log:                 config.Log,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating remote resource syncer")
@@ -258,6 +408,35 @@ func NewSyncer(config SyncerConfig) (*Syncer, error) { // nolint:gocritic // Min
 	return brokerSyncer, nil
 }
 
+func informerRestConfig(config *SyncerConfig, from *rest.Config) *rest.Config {
+	if from == nil || (config.InformerQPS == 0 && config.InformerBurst == 0) {
+		return nil
+	}
+
+	restConfig := rest.CopyConfig(from)
+
+	if config.InformerQPS != 0 {
+		restConfig.QPS = config.InformerQPS
+	}
+
+	if config.InformerBurst != 0 {
+		restConfig.Burst = config.InformerBurst
+	}
+
+	return restConfig
+}
+
+func withUserAgent(from *rest.Config, userAgent string) *rest.Config {
+	if from == nil || userAgent == "" {
+		return from
+	}
+
+	restConfig := rest.CopyConfig(from)
+	restConfig.UserAgent = userAgent
+
+	return restConfig
+}
+
 func createBrokerClient(config *SyncerConfig) error {
 	_, gvr, e := util.ToUnstructuredResource(config.ResourceConfigs[0].BrokerResourceType, config.RestMapper)
 	if e != nil {
@@ -303,6 +482,8 @@ func createBrokerClient(config *SyncerConfig) error {
 		logger.Error(err, "Error accessing the broker API server")
 	}
 
+	config.BrokerRestConfig = withUserAgent(config.BrokerRestConfig, config.UserAgent)
+
 	config.BrokerClient, err = dynamic.NewForConfig(config.BrokerRestConfig)
 
 	return errors.Wrap(err, "error creating dynamic client")
@@ -342,6 +523,36 @@ func (s *Syncer) Start(stopCh <-chan struct{}) error {
 	return nil
 }
 
+// BrokerConnectivity returns an error if the last write to the broker failed due to a connectivity error, nil otherwise.
+// While the broker is unreachable, writes of local resources to the broker are retried with backoff by their syncers,
+// independently of the syncing of broker resources to the local source.
+func (s *Syncer) BrokerConnectivity() error {
+	since, err := s.connectivity.unreachable()
+	if since.IsZero() {
+		return nil
+	}
+
+	return errors.Wrapf(err, "the broker has been unreachable for %v", time.Since(since).Round(time.Millisecond))
+}
+
+// Healthy returns an aggregate of the errors reported by the underlying resource syncers' Healthy along with an error if
+// the broker has been unreachable for longer than the BrokerUnreachableThreshold.
+func (s *Syncer) Healthy() error {
+	errs := []error{}
+
+	if since, _ := s.connectivity.unreachable(); !since.IsZero() && time.Since(since) > s.brokerUnreachableThreshold {
+		errs = append(errs, s.BrokerConnectivity())
+	}
+
+	for _, syncer := range s.syncers {
+		if err := syncer.Healthy(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
 func (s *Syncer) GetBrokerFederator() federate.Federator {
 	return s.remoteFederator
 }
@@ -19,11 +19,16 @@ package broker_test
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"strings"
+	stdsync "sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/federate"
 	sync "github.com/submariner-io/admiral/pkg/syncer"
 	"github.com/submariner-io/admiral/pkg/syncer/broker"
 	"github.com/submariner-io/admiral/pkg/syncer/test"
@@ -34,6 +39,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
 )
 
 var _ = Describe("Broker Syncer", func() {
@@ -121,6 +127,96 @@ var _ = Describe("Broker Syncer", func() {
 		})
 	})
 
+	When("a BeforeWrite function is specified", func() {
+		BeforeEach(func() {
+			config.BeforeWrite = func(obj *unstructured.Unstructured, op sync.Operation) {
+				obj.SetAnnotations(map[string]string{"synced-op": op.String()})
+			}
+		})
+
+		It("should apply its mutations to resources synced to the broker datastore", func() {
+			test.CreateResource(localClient, resource)
+			obj := test.AwaitResource(brokerClient, resource.GetName())
+			Expect(obj.GetAnnotations()).To(HaveKeyWithValue("synced-op", sync.Create.String()))
+		})
+	})
+
+	When("metadata include and exclude prefixes are specified", func() {
+		BeforeEach(func() {
+			resource.Labels = map[string]string{
+				"example.com/shared":     "true",
+				"example.com/local-only": "true",
+				"app":                    "test",
+			}
+
+			resource.Annotations = map[string]string{
+				"example.com/note":                "value",
+				"noisy":                           "value",
+				broker.SourceGenerationAnnotation: "1",
+			}
+
+			config.MetadataIncludePrefixes = []string{"example.com/"}
+			config.MetadataExcludePrefixes = []string{"example.com/local-"}
+		})
+
+		verifyFiltered := func(obj *unstructured.Unstructured, clusterID string) {
+			Expect(obj.GetLabels()).To(Equal(map[string]string{
+				"example.com/shared":       "true",
+				federate.ClusterIDLabelKey: clusterID,
+			}))
+
+			Expect(obj.GetAnnotations()).To(Equal(map[string]string{
+				"example.com/note":                "value",
+				broker.SourceGenerationAnnotation: "1",
+			}))
+		}
+
+		It("should only propagate the matching and bookkeeping keys to the broker datastore", func() {
+			test.CreateResource(localClient, resource)
+			verifyFiltered(test.AwaitResource(brokerClient, resource.GetName()), config.LocalClusterID)
+		})
+
+		It("should only propagate the matching and bookkeeping keys to the local datastore", func() {
+			test.SetClusterIDLabel(resource, "remote")
+			test.CreateResource(brokerClient, resource)
+			verifyFiltered(test.AwaitResource(localClient, resource.GetName()), "remote")
+		})
+	})
+
+	When("IgnoreChangesToKeys is specified", func() {
+		const ignoredAnnotation = "example.com/last-reconciled"
+
+		BeforeEach(func() {
+			resource.Annotations[ignoredAnnotation] = "1"
+			config.IgnoreChangesToKeys = []string{"example.com/"}
+		})
+
+		JustBeforeEach(func() {
+			test.CreateResource(localClient, resource)
+			test.AwaitResource(brokerClient, resource.GetName())
+		})
+
+		It("should not rewrite the broker resource when only an ignored annotation changes", func() {
+			resource.Annotations[ignoredAnnotation] = "2"
+			test.UpdateResource(localClient, resource)
+
+			brokerClient.VerifyNoUpdate(resource.GetName())
+			Expect(test.GetResource(brokerClient, resource).GetAnnotations()).To(HaveKeyWithValue(ignoredAnnotation, "1"))
+		})
+
+		It("should rewrite the broker resource, including the ignored annotation, when another field also changes", func() {
+			resource.Annotations[ignoredAnnotation] = "2"
+			resource.Spec.Containers[0].Image = "apache"
+			test.UpdateResource(localClient, resource)
+
+			test.AwaitAndVerifyResource(brokerClient, resource.GetName(), func(obj *unstructured.Unstructured) bool {
+				return test.GetPod(brokerClient, resource).Spec.Containers[0].Image == "apache"
+			})
+
+			Expect(test.GetResource(brokerClient, resource).GetAnnotations()).To(HaveKeyWithValue(ignoredAnnotation, "2"))
+		})
+	})
+
 	When("a non-local resource is created in the local datastore", func() {
 		It("should not sync to the broker datastore", func() {
 			test.SetClusterIDLabel(resource, "remote")
@@ -162,6 +258,33 @@ var _ = Describe("Broker Syncer", func() {
 		})
 	})
 
+	When("a resource round-trips between the local and broker datastores", func() {
+		It("should not sync a local resource back down after it's updated in the broker datastore", func() {
+			test.CreateResource(localClient, resource)
+			brokerResource := test.AwaitResource(brokerClient, resource.GetName())
+			Expect(brokerResource.GetLabels()).To(HaveKeyWithValue(federate.ClusterIDLabelKey, config.LocalClusterID))
+
+			brokerResource.SetAnnotations(map[string]string{"updated": "true"})
+			_, err := brokerClient.ResourceInterface.Update(ctx, brokerResource, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+
+			localClient.VerifyNoUpdate(resource.GetName())
+		})
+
+		It("should not re-upload a non-local resource after it comes down and is updated locally", func() {
+			test.SetClusterIDLabel(resource, "remote")
+			test.CreateResource(brokerClient, resource)
+			localResource := test.AwaitResource(localClient, resource.GetName())
+			Expect(localResource.GetLabels()).To(HaveKeyWithValue(federate.ClusterIDLabelKey, "remote"))
+
+			localResource.SetAnnotations(map[string]string{"updated": "true"})
+			_, err := localClient.ResourceInterface.Update(ctx, localResource, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+
+			brokerClient.VerifyNoUpdate(resource.GetName())
+		})
+	})
+
 	When("syncing resources from all local namespaces", func() {
 		BeforeEach(func() {
 			config.ResourceConfigs[0].LocalSourceNamespace = metav1.NamespaceAll
@@ -220,8 +343,8 @@ var _ = Describe("Broker Syncer", func() {
 			transformed = test.NewPodWithImage(config.LocalNamespace, "transformed")
 			config.ResourceConfigs[0].LocalTransform = func(from runtime.Object, numRequeues int,
 				op sync.Operation,
-			) (runtime.Object, bool) {
-				return transformed, false
+			) (runtime.Object, bool, error) {
+				return transformed, false, nil
 			}
 		})
 
@@ -240,8 +363,8 @@ var _ = Describe("Broker Syncer", func() {
 			transformed = test.NewPodWithImage(config.LocalNamespace, "transformed")
 			config.ResourceConfigs[0].BrokerTransform = func(from runtime.Object, numRequeues int,
 				op sync.Operation,
-			) (runtime.Object, bool) {
-				return transformed, false
+			) (runtime.Object, bool, error) {
+				return transformed, false, nil
 			}
 		})
 
@@ -256,6 +379,62 @@ var _ = Describe("Broker Syncer", func() {
 		})
 	})
 
+	When("a local resource with node-specific Status is created in the local datastore", func() {
+		BeforeEach(func() {
+			resource.Status.HostIP = "10.0.0.1"
+			resource.Status.PodIP = "10.1.0.1"
+		})
+
+		JustBeforeEach(func() {
+			test.CreateResource(localClient, resource)
+		})
+
+		It("should strip the node-specific fields from the broker resource", func() {
+			obj := test.AwaitResource(brokerClient, resource.GetName())
+
+			Expect(util.GetNestedField(obj, "status", "hostIP")).To(BeNil())
+			Expect(util.GetNestedField(obj, "status", "podIP")).To(Equal(resource.Status.PodIP))
+			Expect(obj.GetLabels()).To(HaveKeyWithValue(federate.ClusterIDLabelKey, config.LocalClusterID))
+
+			Expect(localClient.ResourceInterface.Delete(ctx, resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			test.AwaitNoResource(brokerClient, resource.GetName())
+		})
+
+		Context("and stripping is disabled", func() {
+			BeforeEach(func() {
+				config.ResourceConfigs[0].LocalStripFields = [][]string{}
+			})
+
+			It("should not strip the node-specific fields from the broker resource", func() {
+				obj := test.AwaitResource(brokerClient, resource.GetName())
+				Expect(util.GetNestedField(obj, "status", "hostIP")).To(Equal(resource.Status.HostIP))
+			})
+		})
+
+		Context("and exclude paths are specified", func() {
+			BeforeEach(func() {
+				resource.Annotations = map[string]string{
+					"example.com/secret": "token",
+					"example.com/public": "value",
+				}
+				resource.Status.Phase = corev1.PodRunning
+
+				config.ExcludePaths = []string{"metadata.annotations.example\\.com/secret", "status.podIP", "spec.nonExistent.field"}
+			})
+
+			It("should remove the fields from the broker resource", func() {
+				obj := test.AwaitResource(brokerClient, resource.GetName())
+
+				Expect(obj.GetAnnotations()).ToNot(HaveKey("example.com/secret"))
+				Expect(obj.GetAnnotations()).To(HaveKeyWithValue("example.com/public", "value"))
+				Expect(util.GetNestedField(obj, "status", "podIP")).To(BeNil())
+				Expect(util.GetNestedField(obj, "status", "hostIP")).To(BeNil())
+				Expect(util.GetNestedField(obj, "status", "phase")).To(Equal(string(resource.Status.Phase)))
+				Expect(obj.GetLabels()).To(HaveKeyWithValue(federate.ClusterIDLabelKey, config.LocalClusterID))
+			})
+		})
+	})
+
 	When("a local resource's Status is updated in the local datastore", func() {
 		JustBeforeEach(func() {
 			test.CreateResource(localClient, resource)
@@ -412,6 +591,50 @@ var _ = Describe("Broker Syncer", func() {
 		})
 	})
 
+	When("the broker is unreachable", func() {
+		BeforeEach(func() {
+			config.BrokerUnreachableThreshold = 100 * time.Millisecond
+		})
+
+		JustBeforeEach(func() {
+			brokerClient.PersistentFailOnCreate.Store("connection reset by peer")
+			test.CreateResource(localClient, resource)
+
+			Eventually(syncer.BrokerConnectivity).Should(HaveOccurred())
+		})
+
+		It("should continue syncing broker resources to the local datastore", func() {
+			brokerResource := test.NewPodWithImage(config.BrokerNamespace, "nginx")
+			brokerResource.Name = "broker-pod"
+			test.SetClusterIDLabel(brokerResource, "remote")
+
+			// Create via the underlying client to bypass the simulated outage.
+			test.CreateResource(brokerClient.ResourceInterface, brokerResource)
+
+			test.AwaitResource(localClient, brokerResource.GetName())
+			Expect(syncer.BrokerConnectivity()).To(HaveOccurred())
+		})
+
+		It("should report unhealthy after the threshold", func() {
+			Eventually(syncer.Healthy).Should(MatchError(ContainSubstring("the broker has been unreachable")))
+		})
+
+		Context("and subsequently becomes reachable", func() {
+			It("should retry the writes to the broker datastore and recover", func() {
+				brokerClient.PersistentFailOnCreate.Store("")
+
+				Eventually(func() error {
+					_, err := brokerClient.Get(ctx, resource.GetName(), metav1.GetOptions{})
+					return err
+				}, 5).Should(Succeed())
+
+				test.VerifyResource(brokerClient, resource, config.BrokerNamespace, config.LocalClusterID)
+				Expect(syncer.BrokerConnectivity()).To(Succeed())
+				Expect(syncer.Healthy()).To(Succeed())
+			})
+		})
+	})
+
 	When("GetBrokerFederatorFor is called", func() {
 		It("should return the Federator", func() {
 			Expect(syncer.GetBrokerFederator()).ToNot(BeNil())
@@ -447,3 +670,95 @@ var _ = Describe("Broker Syncer", func() {
 		})
 	})
 })
+
+type userAgentCapturer struct {
+	mutex      stdsync.Mutex
+	userAgents map[string][]string
+}
+
+func (c *userAgentCapturer) wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		c.mutex.Lock()
+		c.userAgents[req.URL.Host] = append(c.userAgents[req.URL.Host], req.Header.Get("User-Agent"))
+		c.mutex.Unlock()
+
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{"Content-Type": []string{runtime.ContentTypeJSON}},
+			Body: io.NopCloser(strings.NewReader(`{"kind":"Status","apiVersion":"v1","status":"Failure",` +
+				`"reason":"NotFound","code":404}`)),
+			Request: req,
+		}, nil
+	})
+}
+
+func (c *userAgentCapturer) get(host string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string(nil), c.userAgents[host]...)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var _ = Describe("Broker Syncer User-Agent", func() {
+	const userAgent = "test-component/v1.2.3"
+
+	var (
+		config   *broker.SyncerConfig
+		capturer *userAgentCapturer
+	)
+
+	BeforeEach(func() {
+		capturer = &userAgentCapturer{userAgents: map[string][]string{}}
+
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		config = &broker.SyncerConfig{
+			LocalRestConfig:  &rest.Config{Host: "http://local", WrapTransport: capturer.wrap},
+			BrokerRestConfig: &rest.Config{Host: "http://broker", WrapTransport: capturer.wrap},
+			LocalNamespace:   test.LocalNamespace,
+			LocalClusterID:   "east",
+			BrokerNamespace:  test.RemoteNamespace,
+			RestMapper:       restMapper,
+			UserAgent:        userAgent,
+			ResourceConfigs: []broker.ResourceConfig{
+				{
+					LocalSourceNamespace: test.LocalNamespace,
+					LocalResourceType:    &corev1.Pod{},
+					BrokerResourceType:   &corev1.Pod{},
+				},
+			},
+		}
+	})
+
+	It("should send the configured User-Agent with requests to the local source and broker", func() {
+		syncer, err := broker.NewSyncer(*config)
+		Expect(err).To(Succeed())
+
+		_, err = syncer.GetLocalClient().Resource(corev1.SchemeGroupVersion.WithResource("pods")).Namespace(test.LocalNamespace).Get(
+			context.TODO(), "any", metav1.GetOptions{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(capturer.get("local")).ToNot(BeEmpty())
+		Expect(capturer.get("broker")).ToNot(BeEmpty())
+
+		for _, host := range []string{"local", "broker"} {
+			for _, actual := range capturer.get(host) {
+				Expect(actual).To(Equal(userAgent), "Unexpected User-Agent for host %q", host)
+			}
+		}
+	})
+
+	It("should not modify the given REST configs", func() {
+		_, err := broker.NewSyncer(*config)
+		Expect(err).To(Succeed())
+
+		Expect(config.LocalRestConfig.UserAgent).To(BeEmpty())
+		Expect(config.BrokerRestConfig.UserAgent).To(BeEmpty())
+	})
+})
@@ -0,0 +1,152 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Validate checks the SyncerConfig for missing required fields and incompatible combinations of options. All the
+// problems found are returned in an aggregate error, or nil if there are none. NewSyncer calls Validate so a
+// misconfigured Syncer fails on construction rather than on Start.
+func (c *SyncerConfig) Validate() error {
+	errs := []error{}
+
+	if len(c.ResourceConfigs) == 0 {
+		errs = append(errs, errors.New("no resources to sync - at least one ResourceConfig is required"))
+	}
+
+	if c.LocalClient == nil && c.LocalRestConfig == nil {
+		errs = append(errs, errors.New("either a LocalClient or a LocalRestConfig is required"))
+	}
+
+	if c.RestMapper == nil && c.LocalRestConfig == nil {
+		errs = append(errs, errors.New("a LocalRestConfig is required to build the REST mapper when no RestMapper is specified"))
+	}
+
+	if c.ListPageSize < 0 {
+		errs = append(errs, fmt.Errorf("ListPageSize %d must not be negative", c.ListPageSize))
+	}
+
+	if c.InformerQPS < 0 {
+		errs = append(errs, fmt.Errorf("InformerQPS %v must not be negative", c.InformerQPS))
+	}
+
+	if c.InformerBurst < 0 {
+		errs = append(errs, fmt.Errorf("InformerBurst %d must not be negative", c.InformerBurst))
+	}
+
+	if c.BrokerUnreachableThreshold < 0 {
+		errs = append(errs, fmt.Errorf("BrokerUnreachableThreshold %v must not be negative", c.BrokerUnreachableThreshold))
+	}
+
+	if len(c.EnsureNamespaceLabels) > 0 && !c.EnsureNamespace {
+		errs = append(errs, errors.New("EnsureNamespaceLabels are specified but EnsureNamespace is disabled"))
+	}
+
+	for _, path := range c.ExcludePaths {
+		for _, field := range ParseFieldPath(path) {
+			if field == "" {
+				errs = append(errs, fmt.Errorf("ExcludePaths entry %q has an empty field name", path))
+				break
+			}
+		}
+	}
+
+	for _, prefix := range c.MetadataIncludePrefixes {
+		for _, excluded := range c.MetadataExcludePrefixes {
+			if prefix == excluded {
+				errs = append(errs, fmt.Errorf("metadata prefix %q is both included and excluded", prefix))
+			}
+		}
+	}
+
+	metricNames := map[string]int{}
+
+	for i := range c.ResourceConfigs {
+		errs = append(errs, c.ResourceConfigs[i].validate(i, metricNames)...)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (rc *ResourceConfig) validate(index int, metricNames map[string]int) []error {
+	errs := []error{}
+
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("ResourceConfigs[%d]: %s", index, fmt.Sprintf(format, args...)))
+	}
+
+	if rc.LocalResourceType == nil {
+		addErr("a LocalResourceType is required")
+	}
+
+	if rc.BrokerResourceType == nil {
+		addErr("a BrokerResourceType is required")
+	}
+
+	if _, err := labels.Parse(rc.LocalSourceLabelSelector); err != nil {
+		addErr("invalid LocalSourceLabelSelector %q: %v", rc.LocalSourceLabelSelector, err)
+	}
+
+	if _, err := fields.ParseSelector(rc.LocalSourceFieldSelector); err != nil {
+		addErr("invalid LocalSourceFieldSelector %q: %v", rc.LocalSourceFieldSelector, err)
+	}
+
+	if rc.LocalResyncPeriod < 0 {
+		addErr("LocalResyncPeriod %v must not be negative", rc.LocalResyncPeriod)
+	}
+
+	if rc.BrokerResyncPeriod < 0 {
+		addErr("BrokerResyncPeriod %v must not be negative", rc.BrokerResyncPeriod)
+	}
+
+	checkMetric := func(field string, opts *prometheus.Opts) {
+		if strings.TrimSpace(opts.Name) == "" {
+			addErr("%s must specify a Name", field)
+			return
+		}
+
+		name := prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+		if prev, exists := metricNames[name]; exists {
+			addErr("%s metric name %q is already used by ResourceConfigs[%d]", field, name, prev)
+		} else {
+			metricNames[name] = index
+		}
+	}
+
+	if rc.SyncCounterOpts != nil {
+		checkMetric("SyncCounterOpts", (*prometheus.Opts)(rc.SyncCounterOpts))
+	}
+
+	if rc.SyncLagOpts != nil {
+		checkMetric("SyncLagOpts", &prometheus.Opts{
+			Namespace: rc.SyncLagOpts.Namespace, Subsystem: rc.SyncLagOpts.Subsystem, Name: rc.SyncLagOpts.Name,
+		})
+	}
+
+	return errs
+}
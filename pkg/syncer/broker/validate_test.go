@@ -0,0 +1,145 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/syncer/broker"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("SyncerConfig Validate", func() {
+	var config *broker.SyncerConfig
+
+	BeforeEach(func() {
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		config = &broker.SyncerConfig{
+			LocalClient:     fake.NewDynamicClient(runtime.NewScheme()),
+			BrokerClient:    fake.NewDynamicClient(runtime.NewScheme()),
+			RestMapper:      restMapper,
+			LocalNamespace:  test.LocalNamespace,
+			BrokerNamespace: test.RemoteNamespace,
+			ResourceConfigs: []broker.ResourceConfig{
+				{
+					LocalSourceNamespace: test.LocalNamespace,
+					LocalResourceType:    &corev1.Pod{},
+					BrokerResourceType:   &corev1.Pod{},
+				},
+			},
+		}
+	})
+
+	assertInvalid := func(expected ...string) {
+		err := config.Validate()
+		Expect(err).To(HaveOccurred())
+
+		for _, s := range expected {
+			Expect(err.Error()).To(ContainSubstring(s))
+		}
+
+		_, err = broker.NewSyncer(*config)
+		Expect(err).To(HaveOccurred())
+	}
+
+	When("the configuration is valid", func() {
+		It("should succeed", func() {
+			Expect(config.Validate()).To(Succeed())
+		})
+	})
+
+	When("no ResourceConfigs are specified", func() {
+		It("should return an error", func() {
+			config.ResourceConfigs = nil
+			assertInvalid("no resources to sync")
+		})
+	})
+
+	When("neither a LocalClient nor a LocalRestConfig is specified", func() {
+		It("should return an error", func() {
+			config.LocalClient = nil
+			assertInvalid("either a LocalClient or a LocalRestConfig is required")
+		})
+	})
+
+	When("a ResourceConfig is missing a resource type", func() {
+		It("should return an error", func() {
+			config.ResourceConfigs[0].BrokerResourceType = nil
+			assertInvalid("ResourceConfigs[0]: a BrokerResourceType is required")
+		})
+	})
+
+	When("a ResourceConfig has an invalid label selector", func() {
+		It("should return an error", func() {
+			config.ResourceConfigs[0].LocalSourceLabelSelector = "foo in (bar"
+			assertInvalid("ResourceConfigs[0]: invalid LocalSourceLabelSelector")
+		})
+	})
+
+	When("EnsureNamespaceLabels are specified without EnsureNamespace", func() {
+		It("should return an error", func() {
+			config.EnsureNamespaceLabels = map[string]string{"app": "test"}
+			assertInvalid("EnsureNamespace is disabled")
+		})
+	})
+
+	When("an ExcludePaths entry has an empty field name", func() {
+		It("should return an error", func() {
+			config.ExcludePaths = []string{"status..podIP"}
+			assertInvalid(`ExcludePaths entry "status..podIP"`)
+		})
+	})
+
+	When("a metadata prefix is both included and excluded", func() {
+		It("should return an error", func() {
+			config.MetadataIncludePrefixes = []string{"example.com/"}
+			config.MetadataExcludePrefixes = []string{"example.com/"}
+			assertInvalid(`metadata prefix "example.com/" is both included and excluded`)
+		})
+	})
+
+	When("ResourceConfigs specify the same metric name", func() {
+		It("should return an error", func() {
+			config.ResourceConfigs[0].SyncCounterOpts = &prometheus.GaugeOpts{Name: "sync_counter"}
+			config.ResourceConfigs = append(config.ResourceConfigs, config.ResourceConfigs[0])
+			assertInvalid(`ResourceConfigs[1]: SyncCounterOpts metric name "sync_counter" is already used by ResourceConfigs[0]`)
+		})
+	})
+
+	When("there are multiple problems", func() {
+		It("should report all of them", func() {
+			config.LocalClient = nil
+			config.ListPageSize = -1
+			config.ResourceConfigs[0].LocalResourceType = nil
+			config.ResourceConfigs[0].LocalSourceFieldSelector = "metadata.name"
+			config.ResourceConfigs[0].BrokerResyncPeriod = -1
+
+			assertInvalid("either a LocalClient or a LocalRestConfig is required",
+				"ListPageSize -1 must not be negative",
+				"ResourceConfigs[0]: a LocalResourceType is required",
+				"ResourceConfigs[0]: invalid LocalSourceFieldSelector",
+				"ResourceConfigs[0]: BrokerResyncPeriod -1ns must not be negative")
+		})
+	})
+})
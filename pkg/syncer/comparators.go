@@ -0,0 +1,150 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"sync"
+
+	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ComparatorRegistry maps resource kinds to the ResourceEquivalenceFunc used to compare resources of that kind. Kinds
+// are matched by group and kind - the version isn't compared as the same resource may be served via different API
+// versions.
+type ComparatorRegistry struct {
+	mutex       sync.RWMutex
+	comparators map[schema.GroupKind]ResourceEquivalenceFunc
+}
+
+// DefaultComparators is the ComparatorRegistry consulted by resource syncers if ResourceSyncerConfig.Comparators isn't
+// specified. It's initialized with the built-in comparators - see NewComparatorRegistry.
+var DefaultComparators = NewComparatorRegistry()
+
+// NewComparatorRegistry returns a ComparatorRegistry initialized with built-in comparators for Services, which ignore the
+// allocated clusterIP(s), Secrets, which compare the type and data, ConfigMaps, which compare the data and binaryData,
+// and EndpointSlices, which compare the address type, endpoints and ports. All also compare the labels and annotations.
+func NewComparatorRegistry() *ComparatorRegistry {
+	r := &ComparatorRegistry{comparators: map[schema.GroupKind]ResourceEquivalenceFunc{}}
+
+	r.Register(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, ServicesEquivalent)
+	r.Register(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, fieldsEquivalent("type", "data", "stringData"))
+	r.Register(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, fieldsEquivalent("data", "binaryData"))
+	r.Register(schema.GroupVersionKind{Group: "discovery.k8s.io", Version: "v1", Kind: "EndpointSlice"},
+		fieldsEquivalent("addressType", "endpoints", "ports"))
+
+	return r
+}
+
+// RegisterComparator registers the given ResourceEquivalenceFunc for the given kind in the DefaultComparators, replacing
+// any existing one.
+func RegisterComparator(gvk schema.GroupVersionKind, comparator ResourceEquivalenceFunc) {
+	DefaultComparators.Register(gvk, comparator)
+}
+
+// Register registers the given ResourceEquivalenceFunc for the given kind, replacing any existing one.
+func (r *ComparatorRegistry) Register(gvk schema.GroupVersionKind, comparator ResourceEquivalenceFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.comparators[gvk.GroupKind()] = comparator
+}
+
+// Lookup returns the ResourceEquivalenceFunc registered for the given kind, if any.
+func (r *ComparatorRegistry) Lookup(gvk schema.GroupVersionKind) (ResourceEquivalenceFunc, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	comparator, found := r.comparators[gvk.GroupKind()]
+
+	return comparator, found
+}
+
+// Equivalent compares the given resources using the ResourceEquivalenceFunc registered for their kind. Unknown kinds
+// fall back to DefaultResourcesEquivalent, ie the labels, annotations and spec are compared. This function may be
+// specified as a ResourceSyncerConfig.ResourcesEquivalent function.
+func (r *ComparatorRegistry) Equivalent(obj1, obj2 *unstructured.Unstructured) bool {
+	if comparator, found := r.Lookup(obj1.GroupVersionKind()); found {
+		return comparator(obj1, obj2)
+	}
+
+	return DefaultResourcesEquivalent(obj1, obj2)
+}
+
+// onlySystemMetadataChangedOrEquivalent returns a ResourceEquivalenceFunc that extends OnlySystemMetadataChanged with
+// the comparator registered for the kind, if any. As with OnlySystemMetadataChanged, identical resources aren't
+// considered equivalent. This is the default ResourcesEquivalent function.
+func onlySystemMetadataChangedOrEquivalent(registry *ComparatorRegistry) ResourceEquivalenceFunc {
+	return func(obj1, obj2 *unstructured.Unstructured) bool {
+		if equality.Semantic.DeepEqual(obj1, obj2) {
+			return false
+		}
+
+		if OnlySystemMetadataChanged(obj1, obj2) {
+			return true
+		}
+
+		comparator, found := registry.Lookup(obj1.GroupVersionKind())
+
+		return found && comparator(obj1, obj2)
+	}
+}
+
+// serviceAllocatedFields are assigned by the API server on creation.
+var serviceAllocatedFields = []string{"clusterIP", "clusterIPs"}
+
+// ServicesEquivalent returns true if the Services' labels, annotations and specs are equal, ignoring the clusterIP(s)
+// allocated by the API server.
+func ServicesEquivalent(obj1, obj2 *unstructured.Unstructured) bool {
+	return metadataEquivalent(obj1, obj2) &&
+		equality.Semantic.DeepEqual(serviceSpecWithoutAllocatedFields(obj1), serviceSpecWithoutAllocatedFields(obj2))
+}
+
+func serviceSpecWithoutAllocatedFields(obj *unstructured.Unstructured) map[string]interface{} {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+
+	for _, field := range serviceAllocatedFields {
+		delete(spec, field)
+	}
+
+	return spec
+}
+
+func fieldsEquivalent(fields ...string) ResourceEquivalenceFunc {
+	return func(obj1, obj2 *unstructured.Unstructured) bool {
+		if !metadataEquivalent(obj1, obj2) {
+			return false
+		}
+
+		for _, field := range fields {
+			if !equality.Semantic.DeepEqual(util.GetNestedField(obj1, field), util.GetNestedField(obj2, field)) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+func metadataEquivalent(obj1, obj2 *unstructured.Unstructured) bool {
+	return equality.Semantic.DeepEqual(obj1.GetLabels(), obj2.GetLabels()) &&
+		equality.Semantic.DeepEqual(obj1.GetAnnotations(), obj2.GetAnnotations())
+}
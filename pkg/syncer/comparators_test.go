@@ -0,0 +1,175 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("ComparatorRegistry", func() {
+	var (
+		registry *syncer.ComparatorRegistry
+		oldObj   runtime.Object
+		newObj   runtime.Object
+	)
+
+	BeforeEach(func() {
+		registry = syncer.NewComparatorRegistry()
+	})
+
+	equivalent := func() bool {
+		return registry.Equivalent(toUnstructured(oldObj), toUnstructured(newObj))
+	}
+
+	When("comparing Services", func() {
+		var oldService, newService *corev1.Service
+
+		BeforeEach(func() {
+			oldService = &corev1.Service{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "nginx",
+					Namespace: test.LocalNamespace,
+					Labels:    map[string]string{"app": "nginx"},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}
+
+			newService = oldService.DeepCopy()
+			newService.Spec.ClusterIP = "10.1.2.3"
+
+			oldObj = oldService
+			newObj = newService
+		})
+
+		Context("that differ only in the clusterIP", func() {
+			It("should return true", func() {
+				Expect(equivalent()).To(BeTrue())
+			})
+		})
+
+		Context("whose ports differ", func() {
+			It("should return false", func() {
+				newService.Spec.Ports[0].Port = 8080
+				Expect(equivalent()).To(BeFalse())
+			})
+		})
+
+		Context("whose labels differ", func() {
+			It("should return false", func() {
+				newService.Labels = map[string]string{"app": "other"}
+				Expect(equivalent()).To(BeFalse())
+			})
+		})
+	})
+
+	When("comparing ConfigMaps whose binaryData differs", func() {
+		It("should return false", func() {
+			configMap := &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: test.LocalNamespace},
+				Data:       map[string]string{"key": "value"},
+			}
+
+			oldObj = configMap.DeepCopy()
+
+			configMap.BinaryData = map[string][]byte{"bin": []byte("data")}
+			newObj = configMap
+
+			Expect(equivalent()).To(BeFalse())
+		})
+	})
+
+	When("comparing resources of an unknown kind", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			pod = test.NewPod(test.LocalNamespace)
+			pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+			oldObj = pod.DeepCopy()
+			newObj = pod
+		})
+
+		Context("whose specs are equal", func() {
+			It("should return true", func() {
+				pod.Status.Phase = corev1.PodRunning
+				Expect(equivalent()).To(BeTrue())
+			})
+		})
+
+		Context("whose specs differ", func() {
+			It("should return false", func() {
+				pod.Spec.Containers[0].Image = "other"
+				Expect(equivalent()).To(BeFalse())
+			})
+		})
+	})
+
+	When("a comparator is registered for a kind", func() {
+		var invokedWith *unstructured.Unstructured
+
+		BeforeEach(func() {
+			invokedWith = nil
+			service := &corev1.Service{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: test.LocalNamespace},
+			}
+
+			oldObj = service.DeepCopy()
+
+			service.Spec.Type = corev1.ServiceTypeNodePort
+			newObj = service
+
+			registry.Register(corev1.SchemeGroupVersion.WithKind("Service"), func(obj1, obj2 *unstructured.Unstructured) bool {
+				invokedWith = obj1
+				return true
+			})
+		})
+
+		It("should override the built-in comparator", func() {
+			Expect(equivalent()).To(BeTrue())
+			Expect(invokedWith).ToNot(BeNil())
+
+			comparator, found := registry.Lookup(corev1.SchemeGroupVersion.WithKind("Service"))
+			Expect(found).To(BeTrue())
+			Expect(comparator).ToNot(BeNil())
+		})
+
+		It("should not affect the DefaultComparators", func() {
+			Expect(syncer.DefaultComparators.Equivalent(toUnstructured(oldObj), toUnstructured(newObj))).To(BeFalse())
+		})
+	})
+})
+
+func toUnstructured(obj runtime.Object) *unstructured.Unstructured {
+	u, err := resource.ToUnstructured(obj)
+	Expect(err).To(Succeed())
+
+	return u
+}
@@ -0,0 +1,164 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	resourceUtil "github.com/submariner-io/admiral/pkg/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+const CompressedFieldAnnotationPrefix = "submariner-io/compressed-"
+
+// CompressedFieldAnnotation returns the annotation key under which the field at the given path is stored when
+// compressed.
+func CompressedFieldAnnotation(fieldPath ...string) string {
+	return CompressedFieldAnnotationPrefix + strings.Join(fieldPath, ".")
+}
+
+// NewCompressTransform returns a TransformFunc that gzips the field at the given path and stores it base64-encoded under
+// the CompressedFieldAnnotation, removing the field. Resources whose JSON-encoded field is smaller than threshold bytes
+// are passed through unchanged. Use NewInflateTransform on the receiving side to restore the field.
+func NewCompressTransform(threshold int, fieldPath ...string) TransformFunc {
+	return func(from runtime.Object, _ int, _ Operation) (runtime.Object, bool, error) {
+		obj, err := resourceUtil.ToUnstructured(from)
+		if err != nil {
+			return nil, false, err //nolint:wrapcheck // Let the caller wrap it
+		}
+
+		compressed, err := CompressField(obj, threshold, fieldPath...)
+		if err != nil || !compressed {
+			return from, false, err
+		}
+
+		return obj, false, nil
+	}
+}
+
+// NewInflateTransform returns a TransformFunc that restores a field compressed by NewCompressTransform. Resources
+// without the CompressedFieldAnnotation are passed through unchanged.
+func NewInflateTransform(fieldPath ...string) TransformFunc {
+	return func(from runtime.Object, _ int, _ Operation) (runtime.Object, bool, error) {
+		obj, err := resourceUtil.ToUnstructured(from)
+		if err != nil {
+			return nil, false, err //nolint:wrapcheck // Let the caller wrap it
+		}
+
+		inflated, err := InflateField(obj, fieldPath...)
+		if err != nil || !inflated {
+			return from, false, err
+		}
+
+		return obj, false, nil
+	}
+}
+
+// CompressField compresses the field at the given path in place as described by NewCompressTransform. It returns true
+// if the field was compressed.
+func CompressField(obj *unstructured.Unstructured, threshold int, fieldPath ...string) (bool, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(obj.Object, fieldPath...)
+	if err != nil || !found {
+		return false, errors.Wrapf(err, "error retrieving field %q", strings.Join(fieldPath, "."))
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "error marshalling field %q", strings.Join(fieldPath, "."))
+	}
+
+	if len(data) < threshold {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	_, err = writer.Write(data)
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err != nil {
+		return false, errors.Wrapf(err, "error compressing field %q", strings.Join(fieldPath, "."))
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[CompressedFieldAnnotation(fieldPath...)] = base64.StdEncoding.EncodeToString(buf.Bytes())
+	obj.SetAnnotations(annotations)
+
+	unstructured.RemoveNestedField(obj.Object, fieldPath...)
+
+	return true, nil
+}
+
+// InflateField restores the field at the given path in place from its CompressedFieldAnnotation, removing the
+// annotation. It returns true if the field was inflated.
+func InflateField(obj *unstructured.Unstructured, fieldPath ...string) (bool, error) {
+	annotations := obj.GetAnnotations()
+	key := CompressedFieldAnnotation(fieldPath...)
+
+	encoded, found := annotations[key]
+	if !found {
+		return false, nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false, errors.Wrapf(err, "error decoding annotation %q", key)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return false, errors.Wrapf(err, "error decompressing annotation %q", key)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return false, errors.Wrapf(err, "error decompressing annotation %q", key)
+	}
+
+	// The apimachinery JSON decoder preserves integers as int64, as in the original unstructured object.
+	var value interface{}
+	if err := utiljson.Unmarshal(data, &value); err != nil {
+		return false, errors.Wrapf(err, "error unmarshalling annotation %q", key)
+	}
+
+	if err := unstructured.SetNestedField(obj.Object, value, fieldPath...); err != nil {
+		return false, errors.Wrapf(err, "error setting field %q", strings.Join(fieldPath, "."))
+	}
+
+	delete(annotations, key)
+	obj.SetAnnotations(annotations)
+
+	return true, nil
+}
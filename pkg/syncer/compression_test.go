@@ -0,0 +1,143 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package syncer_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Compression", func() {
+	var obj *unstructured.Unstructured
+
+	BeforeEach(func() {
+		endpoints := []interface{}{}
+		for i := 0; i < 50; i++ {
+			endpoints = append(endpoints, map[string]interface{}{
+				"addresses":  []interface{}{fmt.Sprintf("10.253.1.%d", i)},
+				"conditions": map[string]interface{}{"ready": i%2 == 0},
+				"hostname":   fmt.Sprintf("host-%d", i),
+				"weight":     int64(i),
+				"ratio":      float64(i) + 0.5,
+			})
+		}
+
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "discovery.k8s.io/v1beta1",
+			"kind":       "EndpointSlice",
+			"metadata": map[string]interface{}{
+				"name":        "test-slice",
+				"namespace":   test.LocalNamespace,
+				"annotations": map[string]interface{}{"foo": "bar"},
+			},
+			"addressType": "IPv4",
+			"endpoints":   endpoints,
+		}}
+	})
+
+	When("a field above the threshold is compressed and inflated", func() {
+		It("should restore the original object", func() {
+			original := obj.DeepCopy()
+
+			compressed, err := syncer.CompressField(obj, 100, "endpoints")
+			Expect(err).To(Succeed())
+			Expect(compressed).To(BeTrue())
+
+			_, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "endpoints")
+			Expect(found).To(BeFalse())
+			Expect(obj.GetAnnotations()).To(HaveKey(syncer.CompressedFieldAnnotation("endpoints")))
+
+			inflated, err := syncer.InflateField(obj, "endpoints")
+			Expect(err).To(Succeed())
+			Expect(inflated).To(BeTrue())
+			Expect(obj).To(Equal(original))
+		})
+	})
+
+	When("a field below the threshold is compressed", func() {
+		It("should not change the object", func() {
+			original := obj.DeepCopy()
+
+			compressed, err := syncer.CompressField(obj, 1024*1024, "endpoints")
+			Expect(err).To(Succeed())
+			Expect(compressed).To(BeFalse())
+			Expect(obj).To(Equal(original))
+		})
+	})
+
+	When("an object without the compressed annotation is inflated", func() {
+		It("should not change the object", func() {
+			original := obj.DeepCopy()
+
+			inflated, err := syncer.InflateField(obj, "endpoints")
+			Expect(err).To(Succeed())
+			Expect(inflated).To(BeFalse())
+			Expect(obj).To(Equal(original))
+		})
+	})
+
+	When("the compressed annotation is corrupt", func() {
+		It("should return an error", func() {
+			obj.SetAnnotations(map[string]string{syncer.CompressedFieldAnnotation("endpoints"): "not-gzip"})
+
+			_, err := syncer.InflateField(obj, "endpoints")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("transform functions", func() {
+		var pod *corev1.Pod
+
+		BeforeEach(func() {
+			pod = test.NewPod(test.LocalNamespace)
+		})
+
+		When("a typed resource is compressed and inflated", func() {
+			It("should round-trip losslessly", func() {
+				compressed, _, err := syncer.NewCompressTransform(0, "spec", "containers")(pod, 0, syncer.Create)
+				Expect(err).To(Succeed())
+				Expect(compressed.(*unstructured.Unstructured).GetAnnotations()).To(
+					HaveKey(syncer.CompressedFieldAnnotation("spec", "containers")))
+
+				inflated, _, err := syncer.NewInflateTransform("spec", "containers")(compressed, 0, syncer.Create)
+				Expect(err).To(Succeed())
+
+				actual := &corev1.Pod{}
+				Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(
+					inflated.(*unstructured.Unstructured).Object, actual)).To(Succeed())
+				Expect(actual.Spec).To(Equal(pod.Spec))
+				Expect(actual.ObjectMeta).To(Equal(pod.ObjectMeta))
+			})
+		})
+
+		When("a typed resource is below the threshold", func() {
+			It("should pass it through unchanged", func() {
+				result, _, err := syncer.NewCompressTransform(1024*1024, "spec", "containers")(pod, 0, syncer.Update)
+				Expect(err).To(Succeed())
+				Expect(result).To(BeIdenticalTo(pod))
+			})
+		})
+	})
+})
@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Dependency configures an auxiliary resource type on which the syncer's resources depend, eg a ConfigMap that they
+// reference. See ResourceSyncerConfig.Dependencies.
+type Dependency struct {
+	// ResourceType the type of the auxiliary resources to watch.
+	ResourceType runtime.Object
+
+	// Namespace the namespace from which to watch the auxiliary resources. Default is the SourceNamespace.
+	Namespace string
+
+	// IndexName the name of the index, registered via ResourceSyncerConfig.Indexers, that maps each of the syncer's
+	// resources to the values identifying the auxiliary resources it depends on.
+	IndexName string
+
+	// IndexValue if specified, invoked with a deleted auxiliary resource to obtain the value that its dependents are
+	// indexed by. Default is the auxiliary resource's key in namespace/name form, or its name if it's cluster-scoped.
+	IndexValue func(dependency *unstructured.Unstructured) string
+}
+
+// initDependencies creates an informer for each configured Dependency that enqueues the resources depending on each
+// deleted auxiliary resource.
+func (r *resourceSyncer) initDependencies(client dynamic.Interface) error {
+	r.dependencyInformers = make([]cache.Controller, 0, len(r.config.Dependencies))
+
+	for i := range r.config.Dependencies {
+		dependency := &r.config.Dependencies[i]
+
+		_, gvr, err := util.ToUnstructuredResource(dependency.ResourceType, r.config.RestMapper)
+		if err != nil {
+			return errors.Wrapf(err, "syncer %q: error resolving the dependency resource type %T", r.config.Name,
+				dependency.ResourceType)
+		}
+
+		namespace := dependency.Namespace
+		if namespace == "" {
+			namespace = r.config.SourceNamespace
+		}
+
+		resourceClient := client.Resource(*gvr).Namespace(namespace)
+
+		//nolint:wrapcheck // These are wrapper functions.
+		_, informer := cache.NewInformer(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return resourceClient.List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return resourceClient.Watch(context.TODO(), options)
+			},
+		}, &unstructured.Unstructured{}, 0, cache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj interface{}) {
+				r.onDependencyDeleted(dependency, obj)
+			},
+		})
+
+		r.dependencyInformers = append(r.dependencyInformers, informer)
+	}
+
+	return nil
+}
+
+func (r *resourceSyncer) onDependencyDeleted(dependency *Dependency, obj interface{}) {
+	var deleted *unstructured.Unstructured
+
+	switch t := obj.(type) {
+	case *unstructured.Unstructured:
+		deleted = t
+	case cache.DeletedFinalStateUnknown:
+		deleted, _ = t.Obj.(*unstructured.Unstructured)
+	}
+
+	if deleted == nil {
+		return
+	}
+
+	key, _ := cache.MetaNamespaceKeyFunc(deleted)
+
+	value := key
+	if dependency.IndexValue != nil {
+		value = dependency.IndexValue(deleted)
+	}
+
+	dependents, err := r.store.ByIndex(dependency.IndexName, value)
+	if err != nil {
+		r.log.Errorf(err, "Syncer %q: error retrieving the dependents of deleted %s %q", r.config.Name, deleted.GetKind(), key)
+		return
+	}
+
+	for _, dependent := range dependents {
+		dependentKey, _ := cache.MetaNamespaceKeyFunc(dependent)
+
+		r.log.V(log.LIBDEBUG).Infof("Syncer %q: enqueueing %q dependent on deleted %s %q", r.config.Name, dependentKey,
+			deleted.GetKind(), key)
+
+		r.enqueue(cache.ExplicitKey(dependentKey), dependentKey, Update)
+	}
+}
+
+func (r *resourceSyncer) dependenciesSynced() bool {
+	for _, informer := range r.dependencyInformers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+
+	return true
+}
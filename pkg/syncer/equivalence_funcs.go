@@ -36,6 +36,37 @@ func ResourcesNotEquivalent(obj1, obj2 *unstructured.Unstructured) bool {
 	return false
 }
 
+// systemMetadataFields are maintained by the API server and change without any change to the resource content.
+var systemMetadataFields = []string{"resourceVersion", "generation", "managedFields"}
+
+// OnlySystemMetadataChanged returns true if the resources differ only in the system-maintained resourceVersion,
+// generation or managedFields metadata, which don't affect the synced resource. Identical resources, eg from a
+// periodic resync, aren't considered equivalent so they're still processed.
+func OnlySystemMetadataChanged(obj1, obj2 *unstructured.Unstructured) bool {
+	if equality.Semantic.DeepEqual(obj1, obj2) {
+		return false
+	}
+
+	return equality.Semantic.DeepEqual(withoutSystemMetadata(obj1), withoutSystemMetadata(obj2))
+}
+
+func withoutSystemMetadata(obj *unstructured.Unstructured) map[string]interface{} {
+	metadata, _, _ := unstructured.NestedMap(obj.Object, util.MetadataField)
+
+	for _, field := range systemMetadataFields {
+		delete(metadata, field)
+	}
+
+	content := make(map[string]interface{}, len(obj.Object))
+	for k, v := range obj.Object {
+		content[k] = v
+	}
+
+	content[util.MetadataField] = metadata
+
+	return content
+}
+
 func AreSpecsEquivalent(obj1, obj2 *unstructured.Unstructured) bool {
 	return equality.Semantic.DeepEqual(util.GetSpec(obj1), util.GetSpec(obj2))
 }
@@ -0,0 +1,41 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import "github.com/submariner-io/admiral/pkg/log"
+
+// consumeExternalTriggers enqueues the keys mapped from each external trigger until the stop channel is closed or the
+// triggers channel is closed.
+func (r *resourceSyncer) consumeExternalTriggers(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case trigger, ok := <-r.config.ExternalTriggers:
+			if !ok {
+				r.log.V(log.LIBDEBUG).Infof("Syncer %q: the external triggers channel was closed", r.config.Name)
+				return
+			}
+
+			for _, key := range r.config.ExternalTriggerKeys(trigger) {
+				r.EnqueueKey(key)
+			}
+		}
+	}
+}
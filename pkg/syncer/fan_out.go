@@ -0,0 +1,237 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/federate"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// syncFanOut invokes the FanOutTransform function for the given source resource and writes the derived resources
+// downstream. The created, previous and deleted caches are left to the caller.
+func (r *resourceSyncer) syncFanOut(ctx context.Context, source *unstructured.Unstructured, key string, op Operation,
+	started time.Time,
+) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	converted := r.convertForTransform(source)
+	if converted == nil {
+		return false, nil
+	}
+
+	var (
+		derived []runtime.Object
+		requeue bool
+	)
+
+	err := r.recoverPanic(ctx, key, "transform", func() error {
+		var err error
+
+		derived, requeue, err = r.config.FanOutTransform(converted, r.workQueue.NumRequeues(key), op)
+
+		return err
+	})
+	if err != nil {
+		return r.syncFailed(ctx, source, key, op, errors.Wrapf(err, "error transforming resource %q", key))
+	}
+
+	if len(derived) == 0 {
+		logger.V(log.LIBDEBUG).Infof("Syncer %q: fan-out transform function returned no resources - not syncing - requeue: %v",
+			r.config.Name, requeue)
+		return requeue, nil
+	}
+
+	toWrite, err := r.toFanOutUnstructured(source, derived)
+	if err != nil {
+		logger.Errorf(err, "Syncer %q: error converting fan-out transform function result", r.config.Name)
+		return false, nil
+	}
+
+	// Sort a copy so the slice returned by the FanOutTransform function isn't modified.
+	derived = append([]runtime.Object(nil), derived...)
+	sortFanOut(derived, toWrite)
+
+	var written map[*unstructured.Unstructured]error
+
+	err = r.recoverPanic(ctx, key, "fan-out", func() error {
+		if op == Delete {
+			written = r.deleteFanOut(ctx, toWrite)
+		} else {
+			for i := range toWrite {
+				toWrite[i] = r.beforeWrite(toWrite[i], op)
+			}
+
+			written = r.distributeFanOut(ctx, toWrite)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return r.syncFailed(ctx, source, key, op, err)
+	}
+
+	// Report the results keyed by the resources returned from the FanOutTransform function.
+	results := FanOutResults{Ordered: derived, Errors: make(map[runtime.Object]error, len(derived))}
+	for i := range derived {
+		results.Errors[derived[i]] = written[toWrite[i]]
+	}
+
+	if r.config.OnFanOutResult != nil {
+		r.config.OnFanOutResult(converted, op, results)
+	}
+
+	var errs []error
+
+	for i, resource := range toWrite {
+		if err := results.Errors[derived[i]]; err != nil {
+			errs = append(errs, errors.Wrapf(err, "resource %q", resource.GetName()))
+		} else {
+			r.onSuccessfulSync(ctx, resource, derived[i], op)
+		}
+	}
+
+	if len(errs) > 0 {
+		if r.isStopping() {
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: fan-out of resource %q interrupted by stop - not re-queueing: %v",
+				r.config.Name, key, utilerrors.NewAggregate(errs))
+			return false, nil
+		}
+
+		return r.syncFailed(ctx, source, key, op, errors.Wrapf(utilerrors.NewAggregate(errs),
+			"error syncing %d of %d resources derived from resource %q", len(errs), len(derived), key))
+	}
+
+	r.recordSyncMetrics(op, started)
+	r.recordSyncLag(source, key, op)
+
+	logger.V(log.LIBDEBUG).Info(fmt.Sprintf("Syncer %q successfully synced %d resources derived from %q", r.config.Name,
+		len(derived), source.GetName()), "key", key)
+
+	return requeue, nil
+}
+
+// toFanOutUnstructured converts the derived resources to Unstructured, preserving the source resource's cluster ID
+// label and labeling each with its originating namespace as for a single transformed resource.
+func (r *resourceSyncer) toFanOutUnstructured(source *unstructured.Unstructured, derived []runtime.Object,
+) ([]*unstructured.Unstructured, error) {
+	clusterID, _ := getClusterIDLabel(source)
+	result := make([]*unstructured.Unstructured, len(derived))
+
+	for i := range derived {
+		u, err := r.converter.ToUnstructured(derived[i])
+		if err != nil {
+			return nil, err //nolint:wrapcheck // No need to wrap
+		}
+
+		if clusterID != "" {
+			_ = unstructured.SetNestedField(u.Object, clusterID, util.MetadataField, util.LabelsField, federate.ClusterIDLabelKey)
+		}
+
+		result[i] = r.withContentHashLabel(r.withOrigNamespaceLabel(u))
+	}
+
+	return result, nil
+}
+
+// sortFanOut sorts the derived resources, and their converted counterparts at the same indexes, by GroupVersionKind,
+// then namespace and name so they're reported, and deleted, in a reproducible order.
+func sortFanOut(derived []runtime.Object, converted []*unstructured.Unstructured) {
+	sort.Sort(&fanOutSorter{derived: derived, converted: converted})
+}
+
+type fanOutSorter struct {
+	derived   []runtime.Object
+	converted []*unstructured.Unstructured
+}
+
+func (s *fanOutSorter) Len() int {
+	return len(s.converted)
+}
+
+func (s *fanOutSorter) Less(i, j int) bool {
+	a, b := s.converted[i], s.converted[j]
+
+	gvkA, gvkB := a.GroupVersionKind(), b.GroupVersionKind()
+	if gvkA.Group != gvkB.Group {
+		return gvkA.Group < gvkB.Group
+	}
+
+	if gvkA.Version != gvkB.Version {
+		return gvkA.Version < gvkB.Version
+	}
+
+	if gvkA.Kind != gvkB.Kind {
+		return gvkA.Kind < gvkB.Kind
+	}
+
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+
+	return a.GetName() < b.GetName()
+}
+
+func (s *fanOutSorter) Swap(i, j int) {
+	s.derived[i], s.derived[j] = s.derived[j], s.derived[i]
+	s.converted[i], s.converted[j] = s.converted[j], s.converted[i]
+}
+
+func (r *resourceSyncer) distributeFanOut(ctx context.Context, resources []*unstructured.Unstructured,
+) map[*unstructured.Unstructured]error {
+	toDistribute := make([]runtime.Object, len(resources))
+	for i := range resources {
+		toDistribute[i] = resources[i]
+	}
+
+	failed := federate.DistributeAll(ctx, r.config.Federator, toDistribute)
+
+	results := make(map[*unstructured.Unstructured]error, len(resources))
+	for _, resource := range resources {
+		results[resource] = failed[resource]
+	}
+
+	return results
+}
+
+func (r *resourceSyncer) deleteFanOut(ctx context.Context, resources []*unstructured.Unstructured,
+) map[*unstructured.Unstructured]error {
+	results := make(map[*unstructured.Unstructured]error, len(resources))
+
+	for _, resource := range resources {
+		err := r.config.Federator.Delete(r.deleteContext(ctx), resource)
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+
+		results[resource] = err
+	}
+
+	return results
+}
@@ -0,0 +1,79 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/finalizer"
+	"github.com/submariner-io/admiral/pkg/log"
+	resourceUtil "github.com/submariner-io/admiral/pkg/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CleanupFunc is invoked when a source resource carrying the syncer's Finalizer is being deleted, eg to clean up
+// remote state associated with the resource. If an error is returned, the Finalizer is retained and the resource is
+// re-queued, subject to the IsRetryable function, to retry the cleanup.
+type CleanupFunc func(obj runtime.Object) error
+
+// isFinalizing returns true if the syncer manages a finalizer that the resource carries while being deleted.
+func (r *resourceSyncer) isFinalizing(resource *unstructured.Unstructured) bool {
+	return r.config.Finalizer != "" && resource.GetDeletionTimestamp() != nil &&
+		finalizer.IsPresent(resource, r.config.Finalizer)
+}
+
+// reconcileFinalizer adds the Finalizer to the source resource if it's not being deleted. Otherwise, if the resource
+// carries the Finalizer, the OnCleanup function is invoked and then the Finalizer is removed to let the deletion
+// proceed. The returned bool indicates whether the resource is being deleted, in which case it shouldn't be synced.
+func (r *resourceSyncer) reconcileFinalizer(ctx context.Context, resource *unstructured.Unstructured, key string,
+) (bool, error) {
+	client := resourceUtil.ForDynamic(r.config.SourceClient.Resource(*r.gvr).Namespace(resource.GetNamespace()))
+
+	if resource.GetDeletionTimestamp() == nil {
+		_, err := finalizer.Add(ctx, client, resource, r.config.Finalizer)
+
+		return false, err //nolint:wrapcheck // OK to return the error as is.
+	}
+
+	if !r.isFinalizing(resource) {
+		return true, nil
+	}
+
+	logger := log.FromContext(ctx)
+
+	logger.V(log.LIBDEBUG).Infof("Syncer %q: cleaning up deleted resource %q", r.config.Name, key)
+
+	if r.config.OnCleanup != nil {
+		converted, err := r.convert(resource)
+		if err != nil {
+			return true, err
+		}
+
+		err = r.recoverPanic(ctx, key, "cleanup", func() error {
+			return r.config.OnCleanup(converted)
+		})
+		if err != nil {
+			return true, errors.Wrapf(err, "error cleaning up resource %q", key)
+		}
+	}
+
+	return true, finalizer.Remove(ctx, client, resource, r.config.Finalizer) //nolint:wrapcheck // OK to return the error as is.
+}
@@ -0,0 +1,205 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	DefaultWatchFailureThreshold = 2 * time.Minute
+	DefaultQueueDrainThreshold   = 5 * time.Minute
+)
+
+// healthState tracks the conditions reported by Healthy.
+type healthState struct {
+	mutex sync.Mutex
+	clock clock.Clock
+
+	// watchFailingSince the time the first of the current run of consecutive list/watch failures occurred, zero if
+	// the watch is established.
+	watchFailingSince time.Time
+	lastWatchErr      error
+
+	// queueDrainedAt the last time the work queue was observed to be empty.
+	queueDrainedAt time.Time
+
+	// lastResourceVersion the highest resource version seen via a list or watch event and lastEventTime the time the last
+	// watch event, including a bookmark, was received.
+	lastResourceVersion string
+	lastEventTime       time.Time
+}
+
+func (h *healthState) listWatchFailed(err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.watchFailingSince.IsZero() {
+		h.watchFailingSince = h.clock.Now()
+	}
+
+	h.lastWatchErr = err
+}
+
+// resourceVersionSeen records the given resource version if it's higher than the last one seen. Resource versions are
+// opaque so, if either isn't an integer, as they are when backed by etcd, the given one is deemed the latest.
+func (h *healthState) resourceVersionSeen(resourceVersion string) {
+	if resourceVersion == "" {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	last, err1 := strconv.ParseUint(h.lastResourceVersion, 10, 64)
+	latest, err2 := strconv.ParseUint(resourceVersion, 10, 64)
+
+	if err1 != nil || err2 != nil || latest > last {
+		h.lastResourceVersion = resourceVersion
+	}
+}
+
+func (h *healthState) eventReceived(event *watch.Event) {
+	h.mutex.Lock()
+	h.lastEventTime = h.clock.Now()
+	h.mutex.Unlock()
+
+	if event.Type == watch.Error {
+		return
+	}
+
+	if objMeta, err := meta.Accessor(event.Object); err == nil {
+		h.resourceVersionSeen(objMeta.GetResourceVersion())
+	}
+}
+
+// observeEvents returns a watch.Interface that records each event received from the given watch.Interface.
+func (h *healthState) observeEvents(w watch.Interface) watch.Interface {
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		h.eventReceived(&event)
+		return event, true
+	})
+}
+
+func (r *resourceSyncer) LastResourceVersion() string {
+	r.health.mutex.Lock()
+	defer r.health.mutex.Unlock()
+
+	return r.health.lastResourceVersion
+}
+
+func (r *resourceSyncer) LastEventTime() time.Time {
+	r.health.mutex.Lock()
+	defer r.health.mutex.Unlock()
+
+	return r.health.lastEventTime
+}
+
+// isResourceVersionExpired returns true if the error indicates the resource version from which a list or watch was
+// requested is too old, ie "410 Gone". The informer's reflector handles it by relisting.
+func isResourceVersionExpired(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
+// watchEstablished resets the failure state. A successful list alone doesn't reset it as no events are received
+// until the subsequent watch is established.
+func (h *healthState) watchEstablished() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.watchFailingSince = time.Time{}
+	h.lastWatchErr = nil
+}
+
+func (h *healthState) queueDrained() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.queueDrainedAt = h.clock.Now()
+}
+
+// checkQueueDrained records if the work queue is currently empty. It's invoked before a resource is queued so the time
+// the queue was last empty is known when it's subsequently checked.
+func (r *resourceSyncer) checkQueueDrained() {
+	if r.workQueue != nil && r.workQueue.Len() == 0 {
+		r.health.queueDrained()
+	}
+}
+
+func (r *resourceSyncer) Healthy() error {
+	r.checkQueueDrained()
+
+	watchThreshold := r.config.WatchFailureThreshold
+	if watchThreshold == 0 {
+		watchThreshold = DefaultWatchFailureThreshold
+	}
+
+	queueThreshold := r.config.QueueDrainThreshold
+	if queueThreshold == 0 {
+		queueThreshold = DefaultQueueDrainThreshold
+	}
+
+	r.health.mutex.Lock()
+	defer r.health.mutex.Unlock()
+
+	if !r.health.watchFailingSince.IsZero() {
+		if failing := r.health.clock.Since(r.health.watchFailingSince); failing > watchThreshold {
+			return errors.Wrapf(r.health.lastWatchErr, "syncer %q: the watch of the source resources has been failing for %v",
+				r.config.Name, failing.Round(time.Millisecond))
+		}
+	}
+
+	if r.circuitBreaker != nil {
+		if err := r.circuitBreaker.Healthy(); err != nil {
+			return errors.Wrapf(err, "syncer %q: writes to the destination are paused", r.config.Name)
+		}
+	}
+
+	if r.workQueue == nil {
+		return nil
+	}
+
+	if notDrained := r.health.clock.Since(r.health.queueDrainedAt); notDrained > queueThreshold {
+		return fmt.Errorf("syncer %q: the work queue hasn't drained for %v - current depth: %d", r.config.Name,
+			notDrained.Round(time.Millisecond), r.workQueue.Len())
+	}
+
+	return nil
+}
+
+func (r *resourceSyncer) Ready() error {
+	if r.informer == nil {
+		return r.errResourceTypeUnavailable()
+	}
+
+	if !r.informer.HasSynced() {
+		return fmt.Errorf("syncer %q: the informer cache hasn't synced", r.config.Name)
+	}
+
+	return nil
+}
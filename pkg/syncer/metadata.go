@@ -0,0 +1,131 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type (
+	listFunc  func(ctx context.Context, options metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	watchFunc func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+)
+
+// metadataListWatch returns list and watch functions that retrieve only the metadata of the source resources in the
+// given namespace via the MetadataClient. The PartialObjectMetadata is converted to an Unstructured of the given kind
+// with only its metadata populated so the rest of the syncer handles it like a full resource.
+func (r *resourceSyncer) metadataListWatch(gvr *schema.GroupVersionResource, gvk schema.GroupVersionKind, namespace string,
+) (listFunc, watchFunc) {
+	client := r.config.MetadataClient.Resource(*gvr).Namespace(namespace)
+
+	list := func(ctx context.Context, options metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		metadataList, err := client.List(ctx, options)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // The caller handles it.
+		}
+
+		list := &unstructured.UnstructuredList{Items: make([]unstructured.Unstructured, 0, len(metadataList.Items))}
+		list.SetResourceVersion(metadataList.ResourceVersion)
+		list.SetContinue(metadataList.Continue)
+
+		for i := range metadataList.Items {
+			obj, err := metadataToUnstructured(&metadataList.Items[i], gvk)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Items = append(list.Items, *obj)
+		}
+
+		return list, nil
+	}
+
+	watchFn := func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+		w, err := client.Watch(ctx, options)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // The caller handles it.
+		}
+
+		return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+			metadata, ok := event.Object.(*metav1.PartialObjectMetadata)
+			if !ok {
+				return event, true
+			}
+
+			obj, err := metadataToUnstructured(metadata, gvk)
+			if err != nil {
+				r.log.Errorf(err, "Syncer %q: unable to convert watched resource %q", r.config.Name, metadata.Name)
+				return event, false
+			}
+
+			event.Object = obj
+
+			return event, true
+		}), nil
+	}
+
+	return list, watchFn
+}
+
+func metadataToUnstructured(from *metav1.PartialObjectMetadata, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	metadata, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&from.ObjectMeta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error converting the metadata of %q", from.Name)
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": metadata}}
+	obj.SetGroupVersionKind(gvk)
+
+	return obj, nil
+}
+
+func (r *resourceSyncer) GetLiveResource(name, namespace string) (runtime.Object, bool, error) {
+	if r.gvr == nil {
+		return nil, false, r.errResourceTypeUnavailable()
+	}
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	obj, err := r.config.SourceClient.Resource(*r.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error retrieving resource %q", name)
+	}
+
+	converted, err := r.convert(obj)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return converted, true, nil
+}
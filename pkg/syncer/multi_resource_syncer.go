@@ -0,0 +1,245 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+const resourceKeySeparator = "|"
+
+type MultiResourceSyncerConfig struct {
+	// Name of this syncer used for logging and the shared work queue.
+	Name string
+
+	// ResourceConfigs specifies the configuration for each resource type to sync. Each resource type has its own
+	// informer and transform but all share a single work queue and workers. The type of ResourceType must be unique.
+	// If a config's Name is empty, it defaults to this syncer's name qualified by the resource type. The
+	// ResourceTypeWaitTimeout of each ResourceConfig is ignored.
+	ResourceConfigs []ResourceSyncerConfig
+
+	// MaxQueueDepth is the maximum number of keys that can be waiting in the shared work queue. Once reached, the
+	// informers block until keys are processed. A value of 0 means unbounded. The MaxQueueDepth of each ResourceConfig
+	// is ignored.
+	MaxQueueDepth int
+
+	// MaxConcurrentReconciles the number of workers that concurrently process the shared work queue. The same resource is
+	// never processed by more than one worker at a time. Default is 1. The MaxConcurrentReconciles of each ResourceConfig
+	// is ignored.
+	MaxConcurrentReconciles int
+}
+
+type MultiInterface interface {
+	Start(stopCh <-chan struct{}) error
+
+	// AwaitStopped waits for all resource syncers to stop. AwaitStopped should not be called on the individual resource
+	// syncers returned by ForResource.
+	AwaitStopped()
+
+	// ForResource returns the resource syncer for the given resource type or nil if it's not configured.
+	ForResource(resourceType runtime.Object) Interface
+}
+
+type multiResourceSyncer struct {
+	config    MultiResourceSyncerConfig
+	workQueue workqueue.Interface
+	syncers   map[string]*resourceSyncer
+	byType    map[string]*resourceSyncer
+	stopped   chan struct{}
+}
+
+// prefixedQueue wraps the shared work queue for a single resource syncer, qualifying its keys with the resource's
+// prefix so the shared worker can route them back. The shared queue's lifecycle is managed by the multiResourceSyncer
+// so Run and ShutDown are no-ops.
+type prefixedQueue struct {
+	workqueue.Interface
+	prefix string
+}
+
+// NewMultiResourceSyncer creates a syncer for several resource types that share a single work queue. Each queued key
+// encodes the resource's GroupResource which the shared worker uses to route it to the appropriate resource syncer.
+func NewMultiResourceSyncer(config *MultiResourceSyncerConfig) (MultiInterface, error) {
+	m := &multiResourceSyncer{
+		config:    *config,
+		workQueue: workqueue.NewBounded(config.Name, config.MaxQueueDepth),
+		syncers:   map[string]*resourceSyncer{},
+		byType:    map[string]*resourceSyncer{},
+		stopped:   make(chan struct{}),
+	}
+
+	for i := range config.ResourceConfigs {
+		rc := config.ResourceConfigs[i]
+		rc.MaxQueueDepth = 0
+
+		// The resource type must be resolved up front to route the shared work queue's keys.
+		rc.ResourceTypeWaitTimeout = 0
+
+		if rc.Name == "" {
+			rc.Name = fmt.Sprintf("%s for %T", config.Name, rc.ResourceType)
+		}
+
+		typeName := fmt.Sprintf("%T", rc.ResourceType)
+		if _, exists := m.byType[typeName]; exists {
+			return nil, fmt.Errorf("duplicate resource type %s", typeName)
+		}
+
+		var prefix string
+
+		syncer, err := newResourceSyncer(&rc, func(gvr *schema.GroupVersionResource) workqueue.Interface {
+			prefix = gvr.GroupResource().String() + resourceKeySeparator
+			return &prefixedQueue{Interface: m.workQueue, prefix: prefix}
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating resource syncer for %s", typeName)
+		}
+
+		if _, exists := m.syncers[prefix]; exists {
+			return nil, fmt.Errorf("duplicate resource %q", strings.TrimSuffix(prefix, resourceKeySeparator))
+		}
+
+		m.syncers[prefix] = syncer
+		m.byType[typeName] = syncer
+	}
+
+	return m, nil
+}
+
+func (m *multiResourceSyncer) Start(stopCh <-chan struct{}) error {
+	// With a bounded queue, the informers block once the queue is full so the queue must be processed for the caches
+	// to sync.
+	if m.config.MaxQueueDepth > 0 {
+		runWorkers(m.workQueue, stopCh, m.config.MaxConcurrentReconciles, m.processNextWorkItem)
+	}
+
+	for _, syncer := range m.syncers {
+		if err := syncer.Start(stopCh); err != nil {
+			return err
+		}
+	}
+
+	if m.config.MaxQueueDepth <= 0 {
+		runWorkers(m.workQueue, stopCh, m.config.MaxConcurrentReconciles, m.processNextWorkItem)
+	}
+
+	go func() {
+		for _, syncer := range m.syncers {
+			syncer.AwaitStopped()
+		}
+
+		m.workQueue.ShutDown()
+		close(m.stopped)
+	}()
+
+	return nil
+}
+
+func (m *multiResourceSyncer) AwaitStopped() {
+	<-m.stopped
+}
+
+func (m *multiResourceSyncer) ForResource(resourceType runtime.Object) Interface {
+	syncer, ok := m.byType[fmt.Sprintf("%T", resourceType)]
+	if !ok {
+		return nil
+	}
+
+	return syncer
+}
+
+func (m *multiResourceSyncer) processNextWorkItem(key, _, _ string) (bool, error) {
+	prefix, resourceKey, found := strings.Cut(key, resourceKeySeparator)
+	if !found {
+		return false, fmt.Errorf("work queue key %q does not identify a resource type", key)
+	}
+
+	syncer, ok := m.syncers[prefix+resourceKeySeparator]
+	if !ok {
+		return false, fmt.Errorf("no resource syncer found for work queue key %q", key)
+	}
+
+	ns, name, err := cache.SplitMetaNamespaceKey(resourceKey)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid work queue key %q", key)
+	}
+
+	return syncer.processNextWorkItem(resourceKey, name, ns)
+}
+
+func (q *prefixedQueue) Enqueue(obj interface{}) {
+	if key, ok := q.keyFor(obj); ok {
+		q.Interface.Enqueue(key)
+	}
+}
+
+func (q *prefixedQueue) EnqueueWithPriority(obj interface{}, priority int) {
+	if key, ok := q.keyFor(obj); ok {
+		q.Interface.EnqueueWithPriority(key, priority)
+	}
+}
+
+func (q *prefixedQueue) EnqueueAfter(obj interface{}, delay time.Duration) {
+	if key, ok := q.keyFor(obj); ok {
+		q.Interface.EnqueueAfter(key, delay)
+	}
+}
+
+func (q *prefixedQueue) keyFor(obj interface{}) (cache.ExplicitKey, bool) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return "", false
+	}
+
+	return cache.ExplicitKey(q.prefix + key), true
+}
+
+func (q *prefixedQueue) NumRequeues(key string) int {
+	return q.Interface.NumRequeues(q.prefix + key)
+}
+
+// PendingKeys returns the pending keys of the shared queue for this queue's resource type, without the prefix.
+func (q *prefixedQueue) PendingKeys() []string {
+	keys := []string{}
+
+	for _, key := range q.Interface.PendingKeys() {
+		if strings.HasPrefix(key, q.prefix) {
+			keys = append(keys, strings.TrimPrefix(key, q.prefix))
+		}
+	}
+
+	return keys
+}
+
+func (q *prefixedQueue) Run(_ <-chan struct{}, _ workqueue.ProcessFunc) {
+}
+
+func (q *prefixedQueue) ShutDown() {
+}
+
+func (q *prefixedQueue) AwaitWorkers() {
+}
@@ -0,0 +1,211 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package syncer_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/syncer"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	fakeClient "k8s.io/client-go/dynamic/fake"
+)
+
+type transformed struct {
+	obj         runtime.Object
+	numRequeues int
+}
+
+var _ = Describe("Multi Resource Syncer", func() {
+	var (
+		config           syncer.MultiResourceSyncerConfig
+		multiSyncer      syncer.MultiInterface
+		federator        *fake.Federator
+		podClient        dynamic.ResourceInterface
+		serviceClient    dynamic.ResourceInterface
+		podTransformed   chan transformed
+		svcTransformed   chan transformed
+		failServiceOnce  bool
+		stopCh           chan struct{}
+		pod              *corev1.Pod
+		service          *corev1.Service
+		newTransformFunc func(ch chan transformed, fail *bool) syncer.TransformFunc
+	)
+
+	newTransformFunc = func(ch chan transformed, fail *bool) syncer.TransformFunc {
+		return func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+			ch <- transformed{obj: from, numRequeues: numRequeues}
+
+			if fail != nil && *fail {
+				*fail = false
+				return nil, false, errors.New("fake transform error")
+			}
+
+			return from, false, nil
+		}
+	}
+
+	BeforeEach(func() {
+		federator = fake.New()
+		podTransformed = make(chan transformed, 100)
+		svcTransformed = make(chan transformed, 100)
+		failServiceOnce = false
+		stopCh = make(chan struct{})
+		pod = test.NewPod(test.LocalNamespace)
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-service",
+				Namespace: test.LocalNamespace,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		restMapper := test.GetRESTMapperFor(&corev1.Pod{}, &corev1.Service{})
+		sourceClient := fakeClient.NewSimpleDynamicClient(scheme)
+
+		podClient = sourceClient.Resource(*test.GetGroupVersionResourceFor(restMapper, &corev1.Pod{})).Namespace(test.LocalNamespace)
+		serviceClient = sourceClient.Resource(*test.GetGroupVersionResourceFor(restMapper, &corev1.Service{})).
+			Namespace(test.LocalNamespace)
+
+		config = syncer.MultiResourceSyncerConfig{
+			Name: "multi",
+			ResourceConfigs: []syncer.ResourceSyncerConfig{
+				{
+					SourceClient:    sourceClient,
+					SourceNamespace: test.LocalNamespace,
+					RestMapper:      restMapper,
+					Federator:       federator,
+					ResourceType:    &corev1.Pod{},
+					Transform:       newTransformFunc(podTransformed, nil),
+					Scheme:          scheme,
+				},
+				{
+					SourceClient:    sourceClient,
+					SourceNamespace: test.LocalNamespace,
+					RestMapper:      restMapper,
+					Federator:       federator,
+					ResourceType:    &corev1.Service{},
+					Transform:       newTransformFunc(svcTransformed, &failServiceOnce),
+					Scheme:          scheme,
+				},
+			},
+		}
+
+		var err error
+		multiSyncer, err = syncer.NewMultiResourceSyncer(&config)
+		Expect(err).To(Succeed())
+
+		Expect(multiSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		multiSyncer.AwaitStopped()
+	})
+
+	When("resources of each configured type are created", func() {
+		It("should route each to its own transform function and distribute it", func() {
+			test.CreateResource(podClient, pod)
+
+			var t transformed
+			Eventually(podTransformed).Should(Receive(&t))
+			Expect(t.obj).To(BeAssignableToTypeOf(&corev1.Pod{}))
+			Expect(t.obj.(*corev1.Pod).Name).To(Equal(pod.Name))
+			federator.VerifyDistribute(test.GetResource(podClient, pod))
+
+			test.CreateResource(serviceClient, service)
+
+			Eventually(svcTransformed).Should(Receive(&t))
+			Expect(t.obj).To(BeAssignableToTypeOf(&corev1.Service{}))
+			Expect(t.obj.(*corev1.Service).Name).To(Equal(service.Name))
+			federator.VerifyDistribute(test.GetResource(serviceClient, service))
+
+			Consistently(podTransformed).ShouldNot(Receive())
+		})
+	})
+
+	When("a transform function initially fails", func() {
+		BeforeEach(func() {
+			failServiceOnce = true
+		})
+
+		It("should re-queue the resource with its own requeue count", func() {
+			test.CreateResource(serviceClient, service)
+
+			var t transformed
+			Eventually(svcTransformed).Should(Receive(&t))
+			initial := t.numRequeues
+			Expect(initial).To(BeNumerically(">", 0))
+
+			Eventually(svcTransformed).Should(Receive(&t))
+			Expect(t.numRequeues).To(Equal(initial + 1))
+			federator.VerifyDistribute(test.GetResource(serviceClient, service))
+
+			Expect(podTransformed).ShouldNot(Receive())
+		})
+	})
+
+	Specify("ForResource should return the resource syncer for the given type", func() {
+		test.CreateResource(serviceClient, service)
+		Eventually(svcTransformed).Should(Receive())
+
+		serviceSyncer := multiSyncer.ForResource(&corev1.Service{})
+		Expect(serviceSyncer).ToNot(BeNil())
+
+		obj, exists, err := serviceSyncer.GetResource(service.Name, service.Namespace)
+		Expect(err).To(Succeed())
+		Expect(exists).To(BeTrue())
+		Expect(obj).To(BeAssignableToTypeOf(&corev1.Service{}))
+
+		Expect(multiSyncer.ForResource(&corev1.ConfigMap{})).To(BeNil())
+	})
+})
+
+var _ = Describe("NewMultiResourceSyncer", func() {
+	When("the same resource type is configured more than once", func() {
+		It("should return an error", func() {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			restMapper := test.GetRESTMapperFor(&corev1.Pod{})
+			rc := syncer.ResourceSyncerConfig{
+				SourceClient: fakeClient.NewSimpleDynamicClient(scheme),
+				RestMapper:   restMapper,
+				Federator:    fake.New(),
+				ResourceType: &corev1.Pod{},
+				Scheme:       scheme,
+			}
+
+			_, err := syncer.NewMultiResourceSyncer(&syncer.MultiResourceSyncerConfig{
+				Name:            "multi",
+				ResourceConfigs: []syncer.ResourceSyncerConfig{rc, rc},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
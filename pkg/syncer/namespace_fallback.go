@@ -0,0 +1,139 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// namespaceFallback tracks whether listing and watching has fallen back to the FallbackNamespace.
+type namespaceFallback struct {
+	mutex      sync.Mutex
+	fallenBack bool
+}
+
+// listWatchFor returns the functions that list and watch the source resources in the given namespace.
+func (r *resourceSyncer) listWatchFor(sourceClient dynamic.Interface, gvr *schema.GroupVersionResource,
+	gvk schema.GroupVersionKind, namespace string,
+) (listFunc, watchFunc) {
+	if r.config.MetadataClient != nil {
+		return r.metadataListWatch(gvr, gvk, namespace)
+	}
+
+	client := sourceClient.Resource(*gvr).Namespace(namespace)
+
+	return client.List, client.Watch
+}
+
+// withNamespaceFallback returns list and watch functions that delegate to the given functions for all namespaces until
+// either returns Forbidden, after which they permanently delegate to the given functions for the FallbackNamespace.
+func (r *resourceSyncer) withNamespaceFallback(listAll listFunc, watchAll watchFunc, listFallback listFunc,
+	watchFallback watchFunc,
+) (listFunc, watchFunc) {
+	list := func(ctx context.Context, options metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		if !r.hasFallenBack() {
+			list, err := listAll(ctx, options)
+			if !apierrors.IsForbidden(err) {
+				return list, err
+			}
+
+			r.fallBack("list", err)
+		}
+
+		return listFallback(ctx, options)
+	}
+
+	watchFn := func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+		if r.hasFallenBack() {
+			return watchFallback(ctx, options)
+		}
+
+		w, err := watchAll(ctx, options)
+		if !apierrors.IsForbidden(err) {
+			return w, err
+		}
+
+		r.fallBack("watch", err)
+
+		// The cache was listed from all namespaces so fail the watch with an expired resource version to relist from
+		// the fallback namespace.
+		return nil, apierrors.NewResourceExpired(fmt.Sprintf("falling back to namespace %q", r.config.FallbackNamespace))
+	}
+
+	return list, watchFn
+}
+
+func (r *resourceSyncer) hasFallenBack() bool {
+	r.fallback.mutex.Lock()
+	defer r.fallback.mutex.Unlock()
+
+	return r.fallback.fallenBack
+}
+
+func (r *resourceSyncer) fallBack(verb string, err error) {
+	r.log.Warningf("Syncer %q is not permitted to %s %q in all namespaces - falling back to namespace %q: %v",
+		r.config.Name, verb, r.gvr.GroupResource().String(), r.config.FallbackNamespace, err)
+
+	r.fallback.mutex.Lock()
+	defer r.fallback.mutex.Unlock()
+
+	r.fallback.fallenBack = true
+}
+
+// listWatchNamespace returns the namespace in which the source resources are currently listed and watched.
+func (r *resourceSyncer) listWatchNamespace() string {
+	if r.hasFallenBack() {
+		return r.config.FallbackNamespace
+	}
+
+	return r.config.SourceNamespace
+}
+
+// permissionError wraps a Forbidden error from listing or watching the source resources with the missing permission.
+func (r *resourceSyncer) permissionError(err error, verb string) error {
+	if !apierrors.IsForbidden(err) {
+		return err
+	}
+
+	resource := r.gvr.GroupResource().String()
+
+	switch {
+	case r.hasFallenBack():
+		return errors.Wrapf(err, "syncer %q is not permitted to %s %q in all namespaces or in the fallback namespace %q - "+
+			"grant the list and watch verbs on %q via a ClusterRole or a Role in namespace %q", r.config.Name, verb, resource,
+			r.config.FallbackNamespace, resource, r.config.FallbackNamespace)
+	case r.config.SourceNamespace == metav1.NamespaceAll:
+		return errors.Wrapf(err, "syncer %q is not permitted to %s %q in all namespaces - grant the list and watch "+
+			"verbs on %q via a ClusterRole or specify a FallbackNamespace", r.config.Name, verb, resource, resource)
+	default:
+		return errors.Wrapf(err, "syncer %q is not permitted to %s %q in namespace %q - grant the list and watch verbs "+
+			"on %q via a Role in namespace %q", r.config.Name, verb, resource, r.config.SourceNamespace, resource,
+			r.config.SourceNamespace)
+	}
+}
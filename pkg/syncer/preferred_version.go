@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resolveResourceType resolves the GroupVersionResource of the ResourceType via the RestMapper. If UsePreferredVersion
+// is set, the version is the preferred version of the ResourceType's group rather than the ResourceType's version.
+func (r *resourceSyncer) resolveResourceType() (*schema.GroupVersionResource, error) {
+	if !r.config.UsePreferredVersion {
+		_, gvr, err := util.ToUnstructuredResource(r.config.ResourceType, r.config.RestMapper)
+		return gvr, err //nolint:wrapcheck // OK to return the error as is.
+	}
+
+	resourceType, err := r.converter.ToUnstructured(r.config.ResourceType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "syncer %q: error determining the kind of the resource type", r.config.Name)
+	}
+
+	r.resourceGVK = resourceType.GroupVersionKind()
+
+	mapping, err := r.config.RestMapper.RESTMapping(r.resourceGVK.GroupKind())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting the REST mapping for the preferred version of %#v", r.resourceGVK.GroupKind())
+	}
+
+	if mapping.GroupVersionKind.Version != r.resourceGVK.Version {
+		r.log.Infof("Syncer %q: using the preferred version %q of resource type %q", r.config.Name,
+			mapping.GroupVersionKind.Version, r.resourceGVK)
+	}
+
+	return &mapping.Resource, nil
+}
+
+// toResourceTypeVersion converts the given resource, if read at a version other than the ResourceType's, to the
+// ResourceType's version via the Scheme. If the resource is already at the ResourceType's version or its version isn't
+// registered, it's returned as is.
+func (r *resourceSyncer) toResourceTypeVersion(from *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if from.GroupVersionKind() == r.resourceGVK {
+		return from, nil
+	}
+
+	converted, err := r.config.Scheme.ConvertToVersion(from, r.resourceGVK.GroupVersion())
+	if runtime.IsNotRegisteredError(err) {
+		return from, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "Syncer %q: error converting %#v to version %q", r.config.Name, from,
+			r.resourceGVK.GroupVersion())
+	}
+
+	return r.converter.ToUnstructured(converted) //nolint:wrapcheck // OK to return the error as is.
+}
@@ -0,0 +1,104 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"strings"
+
+	"github.com/submariner-io/admiral/pkg/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+type PruneConfig struct {
+	// Client the client used to list the resources in the destination.
+	Client dynamic.Interface
+
+	// Namespace the namespace in the destination in which to list resources. If empty, all namespaces are listed.
+	Namespace string
+
+	// OwnerLabelSelector the label selector identifying the resources in the destination owned by the syncer, eg
+	// "submariner-io/clusterID=east". Resources not matching the selector are never pruned. Required.
+	OwnerLabelSelector string
+}
+
+// pruneOnSync deletes, via the Federator, the owned resources in the destination whose source resource no longer
+// exists, ie was deleted while the syncer wasn't running. Destination resources are matched to their source resource
+// by name and by namespace, as determined by the SourceNamespace or, for all namespaces, the OrigNamespaceLabelKey.
+func (r *resourceSyncer) pruneOnSync() {
+	if ok := cache.WaitForCacheSync(r.stopCh, r.informer.HasSynced); !ok {
+		r.log.Error(nil, "Unable to prune - failed to wait for informer cache to sync")
+		return
+	}
+
+	config := r.config.PruneOnSync
+
+	list, err := config.Client.Resource(*r.gvr).Namespace(config.Namespace).List(r.ctx, metav1.ListOptions{
+		LabelSelector: config.OwnerLabelSelector,
+	})
+	if err != nil {
+		r.log.Errorf(err, "Syncer %q: unable to prune - error listing destination resources", r.config.Name)
+		return
+	}
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+		key := r.sourceKeyFor(obj)
+
+		if _, exists, _ := r.store.GetByKey(key); exists {
+			continue
+		}
+
+		// After falling back to the FallbackNamespace, source resources in other namespaces aren't cached so their
+		// absence doesn't mean they no longer exist.
+		if ns := r.listWatchNamespace(); ns != r.config.SourceNamespace && !strings.HasPrefix(key, ns+"/") {
+			continue
+		}
+
+		r.log.Infof("Syncer %q pruning destination resource %s/%s - source resource %q no longer exists", r.config.Name,
+			obj.GetNamespace(), obj.GetName(), key)
+
+		err := r.config.Federator.Delete(r.deleteContext(r.ctx), obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			r.log.Errorf(err, "Syncer %q: error pruning destination resource %s/%s", r.config.Name, obj.GetNamespace(),
+				obj.GetName())
+		}
+	}
+
+	r.log.V(log.LIBDEBUG).Infof("Syncer %q finished pruning %d owned destination resources", r.config.Name, len(list.Items))
+}
+
+func (r *resourceSyncer) sourceKeyFor(obj *unstructured.Unstructured) string {
+	ns := r.config.SourceNamespace
+	if ns == metav1.NamespaceAll {
+		ns = obj.GetLabels()[OrigNamespaceLabelKey]
+		if ns == "" {
+			ns = obj.GetNamespace()
+		}
+	}
+
+	if ns == "" {
+		return obj.GetName()
+	}
+
+	return ns + "/" + obj.GetName()
+}
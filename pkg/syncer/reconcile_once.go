@@ -0,0 +1,169 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/util"
+	"github.com/submariner-io/admiral/pkg/workqueue"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ReconcileOnce synchronously syncs the given source resource for the given operation without starting an informer or
+// workers, eg for one-shot syncs from CLI tools or deterministic unit testing of a Transform function. The resource is
+// filtered and transformed as it would be by a started syncer with the given config and then distributed via the
+// Federator or, for a Delete operation, deleted. The config is validated as for NewResourceSyncer. Metrics aren't
+// recorded and a re-queue requested by the Transform function is ignored.
+//
+// The returned OperationResult is OperationResultCreated or OperationResultUpdated for a distributed Create or Update
+// respectively, OperationResultDeleted for a Delete or OperationResultNone if the resource was skipped or, on Delete,
+// didn't exist.
+func ReconcileOnce(ctx context.Context, config *ResourceSyncerConfig, obj runtime.Object, op Operation) (util.OperationResult, error) {
+	r, err := newOneShotSyncer(ctx, config)
+	if err != nil {
+		return util.OperationResultNone, err
+	}
+
+	defer r.workQueue.ShutDown()
+
+	resource, err := r.converter.ToUnstructured(obj)
+	if err != nil {
+		return util.OperationResultNone, err //nolint:wrapcheck // Already wrapped.
+	}
+
+	return r.reconcileOnce(resource, op)
+}
+
+// RunOnce synchronously syncs all the source resources currently matching the given config, as a Create each, as would
+// a started syncer for its initial list, and returns once they've all been processed, eg for one-shot jobs such as a
+// migration tool. The resources are listed and then reconciled in turn as by ReconcileOnce - no informer or watch is
+// started. A failure to sync one resource doesn't prevent the others from being synced. The failures are returned as
+// an aggregate error, which is nil if all succeeded.
+func RunOnce(ctx context.Context, config *ResourceSyncerConfig) error {
+	r, err := newOneShotSyncer(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	defer r.workQueue.ShutDown()
+
+	options := metav1.ListOptions{
+		LabelSelector: config.SourceLabelSelector,
+		FieldSelector: config.SourceFieldSelector,
+		Limit:         config.ListPageSize,
+	}
+
+	var errs []error
+
+	for {
+		list, err := r.resourceClient.List(ctx, options)
+		if err != nil {
+			return errors.Wrap(err, "error listing the resources to sync")
+		}
+
+		for i := range list.Items {
+			if _, err := r.reconcileOnce(&list.Items[i], Create); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		options.Continue = list.GetContinue()
+		if options.Continue == "" {
+			break
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func newOneShotSyncer(ctx context.Context, config *ResourceSyncerConfig) (*resourceSyncer, error) {
+	// Avoid registering metrics that won't be recorded.
+	c := *config
+	c.SyncCounterOpts, c.SyncDurationOpts, c.LastSyncTimeOpts, c.SyncErrorsOpts, c.SkippedCounterOpts = nil, nil, nil, nil, nil
+
+	// There's no Start to defer resolving the resource type to.
+	c.ResourceTypeWaitTimeout = 0
+
+	r, err := newResourceSyncer(&c, func(_ *schema.GroupVersionResource) workqueue.Interface {
+		return workqueue.New(c.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.ctx = log.IntoContext(ctx, r.log)
+
+	return r, nil
+}
+
+func (r *resourceSyncer) reconcileOnce(resource *unstructured.Unstructured, op Operation) (util.OperationResult, error) {
+	key, _ := cache.MetaNamespaceKeyFunc(resource)
+
+	if !r.shouldProcess(resource, op) || !r.shouldSync(r.ctx, resource, op) {
+		return util.OperationResultNone, nil
+	}
+
+	resource, transformed, _, err := r.transform(r.ctx, resource, key, op)
+	if err != nil {
+		return util.OperationResultNone, errors.Wrapf(err, "error transforming resource %q", key)
+	}
+
+	if resource == nil {
+		return util.OperationResultNone, nil
+	}
+
+	if op == Delete {
+		err = r.config.Federator.Delete(r.deleteContext(r.ctx), resource)
+		if apierrors.IsNotFound(err) {
+			return util.OperationResultNone, nil
+		}
+
+		if err != nil {
+			return util.OperationResultNone, errors.Wrapf(err, "error deleting resource %q", key)
+		}
+
+		r.onSuccessfulSync(r.ctx, resource, transformed, op)
+
+		return util.OperationResultDeleted, nil
+	}
+
+	resource = r.beforeWrite(r.withContentHashLabel(r.withOrigNamespaceLabel(resource)), op)
+
+	err = r.config.Federator.Distribute(r.ctx, resource)
+	if err != nil {
+		return util.OperationResultNone, errors.Wrapf(err, "error distributing resource %q", key)
+	}
+
+	r.onSuccessfulSync(r.ctx, resource, transformed, op)
+
+	if op == Create {
+		return util.OperationResultCreated, nil
+	}
+
+	return util.OperationResultUpdated, nil
+}
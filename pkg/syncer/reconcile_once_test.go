@@ -0,0 +1,234 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/federate/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	fakeClient "k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("ReconcileOnce", func() {
+	var (
+		config    *syncer.ResourceSyncerConfig
+		federator *fake.Federator
+		pod       *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		federator = fake.New()
+		pod = test.NewPod(test.LocalNamespace)
+
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceNamespace: test.LocalNamespace,
+			ResourceType:    &corev1.Pod{},
+			Direction:       syncer.LocalToRemote,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			Scheme:          runtime.NewScheme(),
+		}
+
+		Expect(corev1.AddToScheme(config.Scheme)).To(Succeed())
+
+		config.SourceClient = fakeClient.NewSimpleDynamicClient(config.Scheme)
+	})
+
+	reconcile := func(op syncer.Operation) (util.OperationResult, error) {
+		return syncer.ReconcileOnce(context.TODO(), config, pod, op)
+	}
+
+	When("a resource is created", func() {
+		It("should distribute it and return Created", func() {
+			Expect(reconcile(syncer.Create)).To(Equal(util.OperationResultCreated))
+			federator.VerifyDistribute(test.ToUnstructured(pod))
+		})
+	})
+
+	When("a resource is updated and a transform function is specified", func() {
+		var transformed *corev1.Pod
+
+		BeforeEach(func() {
+			transformed = test.NewPodWithImage(test.LocalNamespace, "transformed")
+
+			config.Transform = func(_ runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				return transformed, false, nil
+			}
+		})
+
+		It("should distribute the transformed resource and return Updated", func() {
+			Expect(reconcile(syncer.Update)).To(Equal(util.OperationResultUpdated))
+			federator.VerifyDistribute(test.ToUnstructured(transformed))
+		})
+	})
+
+	When("the transform function skips the resource", func() {
+		BeforeEach(func() {
+			config.Transform = func(_ runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				return nil, false, nil
+			}
+		})
+
+		It("should not distribute it and return None", func() {
+			Expect(reconcile(syncer.Create)).To(Equal(util.OperationResultNone))
+			federator.VerifyNoDistribute()
+		})
+	})
+
+	When("a resource is deleted", func() {
+		It("should delete it and return Deleted", func() {
+			Expect(reconcile(syncer.Delete)).To(Equal(util.OperationResultDeleted))
+			federator.VerifyDelete(test.ToUnstructured(pod))
+		})
+	})
+
+	When("the resource has the cluster ID label of another cluster", func() {
+		It("should not distribute it", func() {
+			test.SetClusterIDLabel(pod, "remote")
+			Expect(reconcile(syncer.Create)).To(Equal(util.OperationResultNone))
+			federator.VerifyNoDistribute()
+		})
+	})
+
+	When("distribute fails", func() {
+		BeforeEach(func() {
+			federator.FailOnDistribute = errors.New("fake error")
+		})
+
+		It("should return an error", func() {
+			_, err := reconcile(syncer.Create)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("RunOnce", func() {
+	var (
+		config    *syncer.ResourceSyncerConfig
+		federator *fake.Federator
+		pods      []*corev1.Pod
+		synced    []string
+	)
+
+	BeforeEach(func() {
+		federator = fake.New()
+		pods = nil
+		synced = nil
+
+		for _, name := range []string{"pod-a", "bad-pod", "pod-b"} {
+			pod := test.NewPod(test.LocalNamespace)
+			pod.Name = name
+			pods = append(pods, pod)
+		}
+
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceNamespace: test.LocalNamespace,
+			ResourceType:    &corev1.Pod{},
+			Direction:       syncer.LocalToRemote,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			Scheme:          runtime.NewScheme(),
+			Transform: func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				if from.(*corev1.Pod).Name == "bad-pod" {
+					return nil, false, errors.New("bad pod")
+				}
+
+				return from, false, nil
+			},
+			OnSuccessfulSync: func(obj runtime.Object, _ syncer.Operation) {
+				synced = append(synced, resource.ToMeta(obj).GetName())
+			},
+		}
+
+		Expect(corev1.AddToScheme(config.Scheme)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		initObjs := []runtime.Object{}
+		for _, pod := range pods {
+			initObjs = append(initObjs, pod)
+		}
+
+		config.SourceClient = fakeClient.NewSimpleDynamicClient(config.Scheme,
+			test.PrepInitialClientObjs("", "", initObjs...)...)
+	})
+
+	When("one of the initial resources fails to sync", func() {
+		It("should sync the others and return an aggregate error containing only the failure", func() {
+			err := syncer.RunOnce(context.TODO(), config)
+			Expect(err).To(HaveOccurred())
+
+			var agg utilerrors.Aggregate
+			Expect(errors.As(err, &agg)).To(BeTrue())
+			Expect(agg.Errors()).To(HaveLen(1))
+			Expect(agg.Errors()[0].Error()).To(ContainSubstring("bad-pod"))
+
+			Expect(synced).To(ConsistOf("pod-a", "pod-b"))
+		})
+	})
+
+	When("distributing one of the initial resources fails", func() {
+		BeforeEach(func() {
+			config.Transform = nil
+			federator.FailDistributeFor(test.LocalNamespace+"/pod-b", errors.New("mock distribute error"))
+		})
+
+		It("should distribute the others and return an aggregate error containing the failure", func() {
+			err := syncer.RunOnce(context.TODO(), config)
+			Expect(err).To(MatchError(ContainSubstring("mock distribute error")))
+
+			Expect(federator.NumCalls(fake.OpDistribute)).To(Equal(3))
+
+			var names []string
+			for _, obj := range federator.Distributed() {
+				names = append(names, resource.ToMeta(obj).GetName())
+			}
+
+			Expect(names).To(Equal([]string{"bad-pod", "pod-a"}))
+			Expect(synced).To(ConsistOf("bad-pod", "pod-a"))
+		})
+	})
+
+	When("all the initial resources sync successfully", func() {
+		BeforeEach(func() {
+			pods = pods[:1]
+		})
+
+		It("should return nil", func() {
+			Expect(syncer.RunOnce(context.TODO(), config)).To(Succeed())
+			Expect(synced).To(ConsistOf(pods[0].Name))
+		})
+	})
+})
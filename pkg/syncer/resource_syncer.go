@@ -22,9 +22,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/submariner-io/admiral/pkg/federate"
@@ -36,19 +38,33 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	k8sworkqueue "k8s.io/client-go/util/workqueue"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const OrigNamespaceLabelKey = "submariner-io/originatingNamespace"
 
+const (
+	resourceTypeRetryInitialDelay = 100 * time.Millisecond
+	resourceTypeRetryMaxDelay     = 5 * time.Second
+)
+
+// SyncDirection the direction in which a syncer's resources flow, set via ResourceSyncerConfig.Direction. It determines
+// how the cluster ID label is handled and is recorded in the DirectionLabel of the sync metrics.
 type SyncDirection int
 
 const (
+	// Resources are synced without regard to the cluster ID label.
 	None SyncDirection = iota
 
 	// Resources are synced from a local source to a remote source.
@@ -71,12 +87,24 @@ func (d SyncDirection) String() string {
 	return "unknown"
 }
 
+// Operation the kind of event being processed for a resource. It's passed to the TransformFunc, ShouldProcessFunc,
+// OnSuccessfulSyncFunc and Priority functions and is recorded in the OperationLabel of the sync metrics.
 type Operation int
 
 const (
+	// The resource was created in the source or, on Start, retrieved by the initial list.
 	Create Operation = iota
+
+	// The resource was updated in the source or re-queued via Resync or, unless DistinguishResync is set, the periodic
+	// resync.
 	Update
+
+	// The resource was deleted from the source or, via Reconcile, found to be missing from the source.
 	Delete
+
+	// The resource was re-queued by the informer's periodic resync without having changed. Only passed if
+	// ResourceSyncerConfig.DistinguishResync is set, otherwise Update is passed.
+	Resync
 )
 
 func (o Operation) String() string {
@@ -87,6 +115,8 @@ func (o Operation) String() string {
 		return "update"
 	case Delete:
 		return "delete"
+	case Resync:
+		return "resync"
 	}
 
 	return "unknown"
@@ -96,12 +126,64 @@ const (
 	DirectionLabel  = "direction"
 	OperationLabel  = "operation"
 	SyncerNameLabel = "syncer_name"
+	ReasonLabel     = "reason"
 )
 
-// TransformFunc is invoked prior to syncing to transform the resource or evaluate if it should be synced.
-// If nil is returned, the resource is not synced and, if the second return value is true, the resource is re-queued
-// to be retried later.
-type TransformFunc func(from runtime.Object, numRequeues int, op Operation) (runtime.Object, bool)
+// SkipReason the reason a resource was filtered out and not synced. It's passed to the OnSkippedFunc and recorded in
+// the ReasonLabel of the skipped metrics.
+type SkipReason string
+
+const (
+	// The ShouldProcess function returned false.
+	SkipReasonPredicate SkipReason = "predicate"
+
+	// The resource is being deleted and SkipTerminating is set.
+	SkipReasonTerminating SkipReason = "terminating"
+
+	// The cluster ID label indicates the resource originated from a source that shouldn't be synced in the Direction.
+	SkipReasonOrigin SkipReason = "origin"
+)
+
+// TransformFunc is invoked prior to syncing to transform the resource or evaluate if it should be synced. The return
+// values are interpreted as follows:
+//   - A non-nil object and nil error: the returned object is synced. If the second return value is true, the resource
+//     is also re-queued to be transformed and synced again later.
+//   - A nil object and nil error: the resource is intentionally skipped, ie nothing is written downstream and no error
+//     is reported. For a Delete operation, this means a previously synced resource is not deleted. If the second return
+//     value is true, the resource is re-queued with a delay to be retried later.
+//   - A non-nil error: the resource is not synced and is re-queued to be retried with rate-limited backoff. The other
+//     return values are ignored.
+type TransformFunc func(from runtime.Object, numRequeues int, op Operation) (runtime.Object, bool, error)
+
+// TransformWithPreviousFunc is a TransformFunc that's also passed the previous version of the resource for an Update
+// operation, as delivered by the informer's update notification, eg to only sync when a specific field transitions.
+// For Create and Delete operations, previous is nil.
+type TransformWithPreviousFunc func(from, previous runtime.Object, numRequeues int, op Operation) (runtime.Object, bool, error)
+
+// FanOutTransformFunc is a TransformFunc that derives any number of resources from the source resource, eg a copy per
+// target namespace. The return values are interpreted as for a TransformFunc where an empty slice is equivalent to a
+// nil object.
+type FanOutTransformFunc func(from runtime.Object, numRequeues int, op Operation) ([]runtime.Object, bool, error)
+
+// FanOutResults the results of writing the resources derived by a FanOutTransformFunc.
+type FanOutResults struct {
+	// Ordered the derived resources sorted by GroupVersionKind, then namespace and name, so the order is reproducible
+	// regardless of the order returned by the FanOutTransformFunc. On Delete, it's the order in which they were deleted
+	// but, on Create and Update, the Federator may write them concurrently so it doesn't imply the write order.
+	Ordered []runtime.Object
+
+	// Errors maps each derived resource to the result of writing it, a nil error indicating success.
+	Errors map[runtime.Object]error
+}
+
+// OnFanOutResultFunc is invoked after the resources derived by a FanOutTransformFunc are written downstream with the
+// result for each derived resource.
+type OnFanOutResultFunc func(source runtime.Object, op Operation, results FanOutResults)
+
+// TransformWithContextFunc is a TransformFunc that's also passed a context that's cancelled when the TransformTimeout
+// elapses or the syncer is stopped.
+type TransformWithContextFunc func(ctx context.Context, from runtime.Object, numRequeues int, op Operation) (runtime.Object,
+	bool, error)
 
 // OnSuccessfulSyncFunc is invoked after a successful sync operation.
 type OnSuccessfulSyncFunc func(synced runtime.Object, op Operation)
@@ -110,6 +192,12 @@ type ResourceEquivalenceFunc func(obj1, obj2 *unstructured.Unstructured) bool
 
 type ShouldProcessFunc func(obj *unstructured.Unstructured, op Operation) bool
 
+// OnSkippedFunc is invoked when a resource is filtered out and not synced.
+type OnSkippedFunc func(obj *unstructured.Unstructured, op Operation, reason SkipReason)
+
+// BeforeWriteFunc is invoked with a resource just before it's written downstream and may mutate it.
+type BeforeWriteFunc func(obj *unstructured.Unstructured, op Operation)
+
 type ResourceSyncerConfig struct {
 	// Name of this syncer used for logging.
 	Name string
@@ -117,9 +205,25 @@ type ResourceSyncerConfig struct {
 	// SourceClient the client used to obtain the resources to sync.
 	SourceClient dynamic.Interface
 
-	// SourceNamespace the namespace of the resources to sync.
+	// ListWatchRestConfig if specified, used to create the client via which the informer lists and watches the resources
+	// to sync in lieu of the SourceClient, eg with a lower QPS and Burst to throttle the load on the API server on start.
+	ListWatchRestConfig *rest.Config
+
+	// ListPageSize if non-zero, the maximum number of resources retrieved per request when the informer lists the
+	// resources to sync. A smaller page size spreads the initial list of a large resource set over more requests.
+	// By default, the client-go default page size is used.
+	ListPageSize int64
+
+	// SourceNamespace the namespace of the resources to sync. It's ignored if the ResourceType is cluster-scoped, as
+	// determined via the RestMapper.
 	SourceNamespace string
 
+	// FallbackNamespace if specified and the SourceNamespace is all namespaces, the namespace in which the resources are
+	// listed and watched instead if the syncer isn't permitted to list or watch them in all namespaces, eg if it's only
+	// granted a Role rather than a ClusterRole. Once fallen back, only resources in this namespace are synced for the
+	// lifetime of the syncer and any previously listed from other namespaces are dropped from the cache.
+	FallbackNamespace string
+
 	// SourceLabelSelector optional selector to restrict the resources to sync by their labels.
 	SourceLabelSelector string
 
@@ -137,38 +241,264 @@ type ResourceSyncerConfig struct {
 	// local resources from the remote source.
 	Direction SyncDirection
 
+	// MetadataClient if specified, the source resources are listed and watched via this client so only their metadata is
+	// retrieved and cached, which significantly reduces memory use for syncers that only act on labels and annotations.
+	// The resources passed to the Transform and other functions then only have their metadata populated. If the full
+	// resource is required, eg once the Transform function decides to act on it, it can be retrieved via
+	// Interface.GetLiveResource. Note that equivalence functions that compare the spec or status, eg AreSpecsEquivalent,
+	// aren't applicable in this mode as the cached resources don't contain them.
+	MetadataClient metadata.Interface
+
 	// RestMapper used to obtain GroupVersionResources.
 	RestMapper meta.RESTMapper
 
+	// ResourceTypeWaitTimeout if non-zero and the ResourceType isn't yet known to the RestMapper, eg its CRD isn't yet
+	// established, NewResourceSyncer doesn't fail. Instead, Start waits, retrying with backoff, up to this period for
+	// the ResourceType to become available before starting the informer. If the RestMapper has a Reset method, eg a
+	// DeferredDiscoveryRESTMapper, it's invoked before each retry to refresh its discovery information. Until the
+	// ResourceType is available, the methods that access the informer cache or the source return an error and Reconcile
+	// and Resync do nothing. Default is 0.
+	ResourceTypeWaitTimeout time.Duration
+
+	// VerifyOnStart if true, Start synchronously attempts to list and watch the source resources before starting the
+	// informer and returns an error if either fails due to a fatal misconfiguration, ie the resource type isn't served
+	// (NotFound), access is denied (Forbidden or Unauthorized) or a selector is invalid (BadRequest or Invalid). Other
+	// errors are deemed transient and are retried in the background by the informer as usual.
+	VerifyOnStart bool
+
+	// UsePreferredVersion if true, the source resources are listed and watched at the preferred version of the
+	// ResourceType's group, as resolved by the RestMapper, eg via discovery, rather than at the ResourceType's version.
+	// This avoids missing resources when the ResourceType's version isn't, or is no longer, served, eg while a CRD served
+	// at multiple versions is being upgraded. Resources read at a different version are converted to the ResourceType's
+	// version via the Scheme, which requires both versions and the conversion between them to be registered. An
+	// Unstructured resource whose version isn't registered is passed as is. Default is false.
+	UsePreferredVersion bool
+
 	// Federator used to perform the syncing.
 	Federator federate.Federator
 
+	// WriteRateLimits if specified, the token bucket rate limits, per destination GroupVersionResource, of the writes
+	// performed via the Federator, eg to limit a high-churn resource type to a fraction of the shared API budget. This
+	// complements the work queue's rate limit, which governs the rate at which resources are processed regardless of
+	// type. The RestMapper is used to determine the GroupVersionResource of each written resource. See
+	// federate.NewRateLimitingFederator.
+	WriteRateLimits map[schema.GroupVersionResource]federate.RateLimit
+
+	// CircuitBreaker if specified, the writes performed via the Federator are paused for a cooldown after consecutive
+	// failures, eg while the destination is persistently rejecting them, in which case the resources are held in the
+	// work queue and Healthy reports an error until a probe write succeeds. If the CircuitBreakerConfig's Clock isn't
+	// specified, the syncer's Clock is used. See federate.NewCircuitBreakerFederator.
+	CircuitBreaker *federate.CircuitBreakerConfig
+
 	// ResourceType the type of the resources to sync.
 	ResourceType runtime.Object
 
 	// Transform function used to transform resources prior to syncing.
 	Transform TransformFunc
 
+	// TransformWithPrevious if specified, used instead of the Transform function when the transformation depends on
+	// what changed on update. If several updates are coalesced before the resource is processed, the previous version
+	// is the one prior to the first update. The same previous version is passed on each retry until processing succeeds.
+	TransformWithPrevious TransformWithPreviousFunc
+
+	// FanOutTransform if specified, used instead of the Transform and TransformWithPrevious functions to derive several
+	// resources from each source resource. The derived resources are sorted by GroupVersionKind, then namespace and name,
+	// so they're reported in a reproducible order. On Create and Update, they're written together via
+	// federate.DistributeAll rather than one at a time, possibly concurrently. On Delete, each derived resource is deleted
+	// in order. If any write fails, the source resource is handled as a failed sync, ie retried or dead-lettered, and all
+	// of its derived resources are written again on retry.
+	FanOutTransform FanOutTransformFunc
+
+	// OnFanOutResult if specified, invoked with the per-resource results each time the resources derived by the
+	// FanOutTransform function are written.
+	OnFanOutResult OnFanOutResultFunc
+
+	// TransformWithContext if specified, used instead of the Transform and TransformWithPrevious functions when the
+	// transformation makes external calls that should be aborted if the TransformTimeout elapses or the syncer is
+	// stopped, as signalled via the passed context.
+	TransformWithContext TransformWithContextFunc
+
+	// TransformTimeout if non-zero, the maximum time the TransformWithContext function may take. On timeout, the context
+	// passed to the TransformWithContext function is cancelled and, once it returns, the resource is re-queued to be
+	// retried with rate-limited backoff, as for a transform error. The function must therefore honor the context. This
+	// requires TransformWithContext and doesn't apply to the DeleteTransform function. Default is 0, ie no timeout.
+	TransformTimeout time.Duration
+
+	// DeleteTransform if specified, used instead of the Transform and TransformWithPrevious functions for a Delete
+	// operation. It only needs to compute the identity, ie the name and namespace, of the downstream resource to delete
+	// from the deleted source resource, eg if the Transform function derives a different name. The return values are
+	// interpreted as for a TransformFunc.
+	DeleteTransform TransformFunc
+
+	// ReadOnlyTransform if true, the Transform function promises not to mutate the resource passed to it. If the
+	// ResourceType is Unstructured, the resource from the informer cache is then passed as is rather than a copy and,
+	// if it's returned as is, it's synced without copying. By default, the Transform function is passed its own copy.
+	ReadOnlyTransform bool
+
+	// TypedTransform if true and the ResourceType is Unstructured, resources whose kind is registered in the Scheme, eg a
+	// CRD type, are passed to the transform functions in their typed representation while resources of unregistered
+	// kinds are passed as Unstructured. This takes precedence over ReadOnlyTransform. Default is false.
+	TypedTransform bool
+
 	// OnSuccessfulSync function invoked after a successful sync operation.
 	OnSuccessfulSync OnSuccessfulSyncFunc
 
+	// EnqueueRelated if specified, invoked after a resource is successfully processed, ie created, updated or deleted,
+	// with the source resource. The returned keys, in namespace/name form, identify dependent resources of the same type
+	// that are queued to be re-processed as updates, eg resources that reference the changed resource. The changed
+	// resource's own key is ignored and keys of resources not in the informer cache are skipped when processed.
+	EnqueueRelated func(changed *unstructured.Unstructured) []string
+
+	// Dependencies the auxiliary resource types on which the resources depend, eg ConfigMaps that they reference. The
+	// auxiliary resources are watched via the SourceClient and, when one is deleted, the resources that depend on it, as
+	// found via the Dependency's index, are queued to be re-processed as updates, as for EnqueueRelated.
+	Dependencies []Dependency
+
+	// IsRetryable if specified, invoked when syncing a resource fails to determine if it should be re-queued and retried
+	// with backoff. If not, the resource is dropped and passed to the OnDeadLetter function. Default is IsRetryableError.
+	IsRetryable func(err error) bool
+
+	// OnDeadLetter if specified, invoked with the source resource when syncing it fails with an error that's not
+	// retryable.
+	OnDeadLetter func(resource *unstructured.Unstructured, op Operation, err error)
+
+	// OnCacheSynced if specified, invoked once, asynchronously, when the informer cache first syncs after Start, even if
+	// it's empty. The resources from the initial list may be processed concurrently. The context is cancelled when the
+	// syncer is stopped.
+	OnCacheSynced func(ctx context.Context)
+
+	// ExternalTriggers if specified, a channel of external signals, eg messages received from a message queue, that cause
+	// resources to be processed in addition to the informer events. Each trigger is mapped to the namespace/name keys of
+	// the resources to process via the ExternalTriggerKeys function and each key is enqueued as for EnqueueKey, ie with the
+	// same de-duplication and rate limiting as informer events. The channel is consumed from Start until the syncer is
+	// stopped or the channel is closed.
+	ExternalTriggers <-chan interface{}
+
+	// ExternalTriggerKeys maps an external trigger to the keys of the resources to process. Required if
+	// ExternalTriggers is specified.
+	ExternalTriggerKeys func(trigger interface{}) []string
+
+	// PruneOnSync if specified, after the informer cache first syncs on Start, the resources in the destination owned by
+	// the syncer whose source resource no longer exists, eg deleted while the syncer wasn't running, are deleted via the
+	// Federator. The destination resources must be of the same type as the ResourceType.
+	PruneOnSync *PruneConfig
+
+	// DeletePropagationPolicy if specified, the propagation policy used when deleting a resource via the Federator, eg
+	// Foreground to delete the dependents of the destination resource before the resource itself. By default, the
+	// destination server's default policy for the resource type applies.
+	DeletePropagationPolicy *metav1.DeletionPropagation
+
+	// ContentHashLabel if specified, the key of the label with which each synced resource is stamped with a hash of its
+	// content, as computed by resource.ContentHash, eg for quick change detection and debugging.
+	ContentHashLabel string
+
+	// ContentHashIgnorePaths the dot-separated paths of fields, in addition to the metadata and status, excluded from
+	// the hash stamped in the ContentHashLabel. See resource.ContentHash.
+	ContentHashIgnorePaths []string
+
+	// BeforeWrite if specified, invoked with a copy of each resource just before it's distributed to the Federator, ie
+	// after the transform and the labeling done by the syncer. It may mutate the resource to stamp bookkeeping metadata
+	// uniformly, eg a sync timestamp annotation, rather than in each transform function. It's not invoked for deletes.
+	BeforeWrite BeforeWriteFunc
+
 	// ResourcesEquivalent function to compare two resources for equivalence. This is invoked on an update notification
 	// to compare the old and new resources. If true is returned, the update is ignored, otherwise the update is processed.
-	// By default all updates are processed.
+	// By default, updates that only change the resourceVersion, generation or managedFields are ignored, as per
+	// OnlySystemMetadataChanged, as are updates deemed equivalent by the comparator registered for the resource kind in
+	// the Comparators. Specify ResourcesNotEquivalent to process all updates.
 	ResourcesEquivalent ResourceEquivalenceFunc
 
+	// Comparators the ComparatorRegistry consulted by the default ResourcesEquivalent function. By default,
+	// DefaultComparators is used.
+	Comparators *ComparatorRegistry
+
 	// ShouldProcess function invoked to determine if a resource should be processed.
 	ShouldProcess ShouldProcessFunc
 
+	// SkipTerminating if true, created and updated resources that are being deleted, ie have a deletion timestamp set,
+	// aren't synced. The subsequent delete is still processed. Default is false.
+	SkipTerminating bool
+
+	// Finalizer if specified, the finalizer added to source resources on their first sync so the OnCleanup function
+	// is invoked before they're deleted. When a resource carrying the finalizer is being deleted, ie has a deletion
+	// timestamp set, it isn't synced - instead the OnCleanup function is invoked and then the finalizer is removed to
+	// let the deletion proceed. If OnCleanup fails, the finalizer is retained and the cleanup is retried.
+	Finalizer string
+
+	// OnCleanup function invoked to clean up a source resource being deleted that carries the Finalizer.
+	OnCleanup CleanupFunc
+
+	// OnSkipped if specified, invoked when a resource is filtered out by the ShouldProcess function, SkipTerminating or
+	// the cluster ID label, eg to diagnose why a resource isn't syncing. Resources not matching the SourceLabelSelector
+	// are filtered by the API server so they're never observed.
+	OnSkipped OnSkippedFunc
+
 	// WaitForCacheSync if true, waits for the informer cache to sync on Start. Default is true.
 	WaitForCacheSync *bool
 
-	// Scheme used to convert resource objects. By default the global k8s Scheme is used.
+	// ProcessOnStart if false, the resources from the initial list on Start populate the informer cache but are not
+	// processed until they're subsequently updated or Resync is called. Default is true.
+	ProcessOnStart *bool
+
+	// Scheme used to convert resource objects to and from the ResourceType and the types passed to, and returned by, the
+	// transform functions. Types that aren't registered in the global k8s Scheme, eg CRD types, must be registered in it.
+	// By default the global k8s Scheme is used.
 	Scheme *runtime.Scheme
 
 	// ResyncPeriod if non-zero, the period at which resources will be re-synced regardless if anything changed. Default is 0.
 	ResyncPeriod time.Duration
 
+	// DistinguishResync if true, resources re-queued by the periodic resync that haven't changed, ie whose update
+	// notification has the same old and new resourceVersion, are processed with the Resync Operation rather than Update,
+	// eg so a TransformFunc can skip redundant external work. A subsequent genuine update of the resource before it's
+	// processed results in the Update Operation. Default is false.
+	DistinguishResync bool
+
+	// Debounce if non-zero, the period to wait after a created or updated resource is first queued before processing it.
+	// Any further updates to the resource within the period are coalesced so only its latest state is synced. Deletes
+	// are not delayed. Default is 0.
+	Debounce time.Duration
+
+	// MaxQueueDepth if non-zero, the maximum number of resources that may be queued waiting to be processed. Once
+	// reached, informer event notifications block until a queued resource is processed, bounding memory use on a large
+	// initial list. In this case, processing starts before the informer cache has synced. Default is 0 (unbounded).
+	MaxQueueDepth int
+
+	// WorkQueue if specified, the queue that backs the syncer's work queue in lieu of the default in-memory queue, eg a
+	// persistent or distributed queue for crash-durable processing. It must not be shared with another syncer. The
+	// Priority function isn't supported with a custom WorkQueue. See workqueue.NewWithWorkQueue.
+	WorkQueue workqueue.WorkQueue
+
+	// WatchFailureThreshold the period for which listing or watching the source resources may fail consecutively before
+	// the syncer is reported as unhealthy by Healthy. Default is DefaultWatchFailureThreshold.
+	WatchFailureThreshold time.Duration
+
+	// QueueDrainThreshold the period for which the work queue may be continuously non-empty before the syncer is reported
+	// as unhealthy by Healthy, eg if resources are persistently failing to sync. Default is DefaultQueueDrainThreshold.
+	QueueDrainThreshold time.Duration
+
+	// Clock the clock used for the health thresholds, the sync duration and last sync time metrics and the backoff while
+	// waiting for the ResourceType to become available. By default, the real clock is used.
+	Clock clock.Clock
+
+	// Priority if specified, invoked when a resource is queued to classify it. Resources with a positive priority are
+	// placed in a high priority lane and processed before all others, eg so deletes aren't held up behind a backlog of
+	// routine updates. High priority creates and updates aren't debounced.
+	Priority func(key string, op Operation) int
+
+	// PriorityFairness the maximum number of consecutive high priority resources processed while normal resources are
+	// waiting, which prevents starvation of the normal lane. Only applicable if Priority is specified. Default is
+	// workqueue.DefaultPriorityFairness.
+	PriorityFairness int
+
+	// Indexers optional custom indexers, keyed by index name, to add to the informer cache. Resources are retrieved
+	// from an index via ByIndex.
+	Indexers cache.Indexers
+
+	// MaxConcurrentReconciles the number of workers that concurrently process queued resources. The same resource is
+	// never processed by more than one worker at a time. Default is 1.
+	MaxConcurrentReconciles int
+
 	// SyncCounterOpts if specified, used to create a gauge to record counter metrics.
 	// Alternatively the gauge can be created directly and passed via the SyncCounter field,
 	// in which case SyncCounterOpts is ignored.
@@ -176,34 +506,189 @@ type ResourceSyncerConfig struct {
 
 	// SyncCounter if specified, used to record counter metrics.
 	SyncCounter *prometheus.GaugeVec
+
+	// SyncDurationOpts if specified, used to create a histogram to record how long it takes to sync each resource, from
+	// dequeue to completion of the downstream write. Alternatively the histogram can be created directly and passed via
+	// the SyncDuration field, in which case SyncDurationOpts is ignored.
+	SyncDurationOpts *prometheus.HistogramOpts
+
+	// SyncDuration if specified, used to record sync duration metrics. The histogram must have the DirectionLabel and
+	// SyncerNameLabel labels.
+	SyncDuration *prometheus.HistogramVec
+
+	// SyncLagOpts if specified, used to create a histogram to record the propagation lag of each resource change, from
+	// when the change was made to completion of the downstream write. The time of the change is the latest of the
+	// source resource's managed fields times and its creation timestamp or, for a deletion, its deletion timestamp, as
+	// recorded by the API server. These aren't reliable for resources without them, for changes made before the syncer
+	// started, eg on the initial list, for a resync or for a resource queued via EnqueueKey, in which cases the time the
+	// change was first queued is used instead. Alternatively the histogram can be created directly and passed via the
+	// SyncLag field, in which case SyncLagOpts is ignored.
+	SyncLagOpts *prometheus.HistogramOpts
+
+	// SyncLag if specified, used to record sync lag metrics. The histogram must have the DirectionLabel and
+	// SyncerNameLabel labels.
+	SyncLag *prometheus.HistogramVec
+
+	// LastSyncTimeOpts if specified, used to create a gauge to record the Unix time of the last successful sync.
+	// Alternatively the gauge can be created directly and passed via the LastSyncTime field, in which case
+	// LastSyncTimeOpts is ignored.
+	LastSyncTimeOpts *prometheus.GaugeOpts
+
+	// LastSyncTime if specified, used to record the last successful sync time. The gauge must have the DirectionLabel
+	// and SyncerNameLabel labels.
+	LastSyncTime *prometheus.GaugeVec
+
+	// SyncErrorsOpts if specified, used to create a counter to record failed syncs, including those due to a panic in
+	// the Transform function or the Federator. Alternatively the counter can be created directly and passed via the
+	// SyncErrors field, in which case SyncErrorsOpts is ignored.
+	SyncErrorsOpts *prometheus.CounterOpts
+
+	// SyncErrors if specified, used to record failed sync metrics. The counter must have the DirectionLabel,
+	// OperationLabel and SyncerNameLabel labels.
+	SyncErrors *prometheus.CounterVec
+
+	// SkippedCounterOpts if specified, used to create a counter to record resources filtered out and not synced.
+	// Alternatively the counter can be created directly and passed via the SkippedCounter field, in which case
+	// SkippedCounterOpts is ignored.
+	SkippedCounterOpts *prometheus.CounterOpts
+
+	// SkippedCounter if specified, used to record skipped resource metrics. The counter must have the DirectionLabel,
+	// OperationLabel, ReasonLabel and SyncerNameLabel labels.
+	SkippedCounter *prometheus.CounterVec
+
+	// WorkQueueMetrics if true, the depth of the syncer's work queue, the total number of resources added and re-queued
+	// and how long processing takes are exported via metrics labeled by syncer name. See WorkQueueDepthMetricName,
+	// WorkQueueAddsMetricName, WorkQueueRetriesMetricName and WorkQueueWorkDurationMetricName.
+	WorkQueueMetrics bool
+
+	// MetricsRegisterer used to register the metrics created from the SyncCounterOpts, SyncDurationOpts, SyncLagOpts,
+	// LastSyncTimeOpts, SyncErrorsOpts and SkippedCounterOpts and the work queue metrics. By default, the
+	// prometheus.DefaultRegisterer is used.
+	MetricsRegisterer prometheus.Registerer
+
+	// TracerProvider if specified, used to create a span for each reconcile covering the transform and downstream write,
+	// with the SpanResourceAttribute, SpanDirectionAttribute, SpanOperationAttribute and SpanResultAttribute attributes.
+	// The span is linked to the trace context carried in the resource's TraceParentAnnotation, if any. By default, no
+	// spans are created. See TracerProvider for how to adapt an OpenTelemetry TracerProvider.
+	TracerProvider TracerProvider
+
+	// Log if specified, the logger used by the syncer. Log lines carry the syncer name and, where applicable, the resource
+	// key as structured fields. The lines logged while processing a resource also carry a correlation ID, under the
+	// log.CorrelationIDKey, that's unique to each reconcile. The context passed to the Federator and
+	// TransformWithContext function carries the reconcile's logger, retrieved via log.FromContext. By default, the
+	// controller-runtime logger is used.
+	Log logr.Logger
 }
 
 type resourceSyncer struct {
-	workQueue   workqueue.Interface
-	informer    cache.Controller
-	store       cache.Store
-	config      ResourceSyncerConfig
-	deleted     sync.Map
-	created     sync.Map
-	stopped     chan struct{}
-	syncCounter *prometheus.GaugeVec
-	stopCh      <-chan struct{}
-	log         log.Logger
+	workQueue      workqueue.Interface
+	gvr            *schema.GroupVersionResource
+	informer       cache.Controller
+	store          cache.Indexer
+	config         ResourceSyncerConfig
+	deleted        sync.Map
+	created        sync.Map
+	previous       sync.Map
+	resynced       sync.Map
+	enqueued       sync.Map
+	unprocessed    sync.Map
+	listed         bool
+	stopped        chan struct{}
+	syncCounter    *prometheus.GaugeVec
+	syncDuration   *prometheus.HistogramVec
+	syncLag        *prometheus.HistogramVec
+	changesQueued  sync.Map
+	startedAt      time.Time
+	lastSyncTime   *prometheus.GaugeVec
+	syncErrors     *prometheus.CounterVec
+	skipped        *prometheus.CounterVec
+	resourceClient dynamic.ResourceInterface
+	listResources  listFunc
+	watchResources watchFunc
+	newWorkQueue   func(gvr *schema.GroupVersionResource) workqueue.Interface
+	stopCh         <-chan struct{}
+	ctx            context.Context
+	health         healthState
+	fallback       namespaceFallback
+	tracer         Tracer
+	converter      *resourceUtil.Converter
+	resourceGVK    schema.GroupVersionKind
+	log            log.Logger
+	circuitBreaker federate.CircuitBreakerFederator
+
+	dependencyInformers []cache.Controller
 }
 
 func NewResourceSyncer(config *ResourceSyncerConfig) (Interface, error) {
+	return newResourceSyncer(config, func(_ *schema.GroupVersionResource) workqueue.Interface {
+		var metricsProvider k8sworkqueue.MetricsProvider
+		if config.WorkQueueMetrics {
+			metricsProvider = newWorkQueueMetricsProvider(metricsRegistererFor(config))
+		}
+
+		if config.WorkQueue != nil {
+			return workqueue.NewWithWorkQueue(config.Name, config.MaxQueueDepth, config.WorkQueue, metricsProvider)
+		}
+
+		if config.Priority != nil {
+			return workqueue.NewPriority(config.Name, config.MaxQueueDepth, config.PriorityFairness, metricsProvider)
+		}
+
+		if metricsProvider != nil {
+			return workqueue.NewBoundedWithMetrics(config.Name, config.MaxQueueDepth, metricsProvider)
+		}
+
+		return workqueue.NewBounded(config.Name, config.MaxQueueDepth)
+	})
+}
+
+func newResourceSyncer(config *ResourceSyncerConfig, newWorkQueue func(gvr *schema.GroupVersionResource) workqueue.Interface,
+) (*resourceSyncer, error) {
 	syncer := &resourceSyncer{
 		config:  *config,
 		stopped: make(chan struct{}),
-		log:     log.Logger{Logger: logf.Log.WithName("ResourceSyncer")},
 	}
 
+	baseLogger := config.Log
+	if baseLogger == nil {
+		baseLogger = logf.Log.WithName("ResourceSyncer")
+	}
+
+	syncer.log = log.Logger{Logger: baseLogger}.WithValues("syncer", config.Name)
+
 	if syncer.config.Scheme == nil {
 		syncer.config.Scheme = scheme.Scheme
 	}
 
+	if syncer.config.Clock == nil {
+		syncer.config.Clock = clock.RealClock{}
+	}
+
+	syncer.health.clock = syncer.config.Clock
+
+	if len(syncer.config.WriteRateLimits) > 0 {
+		syncer.config.Federator = federate.NewRateLimitingFederator(syncer.config.Federator, syncer.config.RestMapper,
+			syncer.config.WriteRateLimits)
+	}
+
+	if syncer.config.CircuitBreaker != nil {
+		breakerConfig := *syncer.config.CircuitBreaker
+		if breakerConfig.Clock == nil {
+			breakerConfig.Clock = syncer.config.Clock
+		}
+
+		syncer.circuitBreaker = federate.NewCircuitBreakerFederator(syncer.config.Federator, breakerConfig)
+		syncer.config.Federator = syncer.circuitBreaker
+	}
+
+	syncer.converter = resourceUtil.NewConverter(syncer.config.Scheme)
+
+	if syncer.config.Comparators == nil {
+		syncer.config.Comparators = DefaultComparators
+	}
+
 	if syncer.config.ResourcesEquivalent == nil {
-		syncer.config.ResourcesEquivalent = ResourcesNotEquivalent
+		syncer.config.ResourcesEquivalent = onlySystemMetadataChangedOrEquivalent(syncer.config.Comparators)
 	}
 
 	if syncer.config.WaitForCacheSync == nil {
@@ -211,85 +696,421 @@ func NewResourceSyncer(config *ResourceSyncerConfig) (Interface, error) {
 		syncer.config.WaitForCacheSync = &wait
 	}
 
-	_, gvr, err := util.ToUnstructuredResource(config.ResourceType, config.RestMapper)
+	if syncer.config.ProcessOnStart == nil {
+		process := true
+		syncer.config.ProcessOnStart = &process
+	}
+
+	gvr, err := syncer.resolveResourceType()
+	if err != nil {
+		if config.ResourceTypeWaitTimeout <= 0 || !meta.IsNoMatchError(errors.Cause(err)) {
+			return nil, err //nolint:wrapcheck // OK to return the error as is.
+		}
+
+		syncer.log.V(log.LIBDEBUG).Infof("Syncer %q: resource type %T isn't yet available - deferring to Start: %v",
+			config.Name, config.ResourceType, err)
+	}
+
+	if config.TransformTimeout > 0 && config.TransformWithContext == nil {
+		return nil, fmt.Errorf("syncer %q: a TransformWithContext function is required with a TransformTimeout", config.Name)
+	}
+
+	if config.ExternalTriggers != nil && config.ExternalTriggerKeys == nil {
+		return nil, fmt.Errorf("syncer %q: an ExternalTriggerKeys function is required with ExternalTriggers", config.Name)
+	}
+
+	for i := range config.Dependencies {
+		if _, ok := config.Indexers[config.Dependencies[i].IndexName]; !ok {
+			return nil, fmt.Errorf("syncer %q: the index %q of the dependency on %T isn't registered via Indexers", config.Name,
+				config.Dependencies[i].IndexName, config.Dependencies[i].ResourceType)
+		}
+	}
+
+	if config.PruneOnSync != nil {
+		if config.PruneOnSync.OwnerLabelSelector == "" {
+			return nil, fmt.Errorf("syncer %q: an owner label selector is required to prune", config.Name)
+		}
+
+		if _, err := labels.Parse(config.PruneOnSync.OwnerLabelSelector); err != nil {
+			return nil, errors.Wrapf(err, "syncer %q: invalid owner label selector", config.Name)
+		}
+	}
+
+	if config.SourceLabelSelector != "" {
+		if _, err := labels.Parse(config.SourceLabelSelector); err != nil {
+			return nil, errors.Wrapf(err, "syncer %q: invalid source label selector", config.Name)
+		}
+	}
+
+	syncer.initMetrics()
+
+	if config.TracerProvider != nil {
+		syncer.tracer = config.TracerProvider.Tracer(TracerName)
+	}
+
+	syncer.newWorkQueue = newWorkQueue
+
+	if gvr != nil {
+		if err := syncer.initForResource(gvr); err != nil {
+			return nil, err
+		}
+	}
+
+	return syncer, nil
+}
+
+// initForResource creates the work queue and informer for the given resolved resource type.
+func (r *resourceSyncer) initForResource(gvr *schema.GroupVersionResource) error {
+	r.gvr = gvr
+	r.workQueue = r.newWorkQueue(gvr)
+
+	resourceType, err := r.converter.ToUnstructured(r.config.ResourceType)
 	if err != nil {
-		return nil, err //nolint:wrapcheck // OK to return the error as is.
+		return errors.Wrapf(err, "syncer %q: error determining the kind of the resource type", r.config.Name)
+	}
+
+	sourceClient := r.config.SourceClient
+
+	if r.config.ListWatchRestConfig != nil {
+		sourceClient, err = dynamic.NewForConfig(r.config.ListWatchRestConfig)
+		if err != nil {
+			return errors.Wrapf(err, "syncer %q: error creating the list/watch client", r.config.Name)
+		}
+	}
+
+	// A cluster-scoped resource type can't be listed or watched in a namespace.
+	if r.config.SourceNamespace != metav1.NamespaceAll {
+		namespaced, err := util.IsNamespaced(r.config.RestMapper, resourceType.GroupVersionKind())
+		if err == nil && !namespaced {
+			r.log.Warningf("Syncer %q: resource type %q is cluster-scoped - ignoring the SourceNamespace %q", r.config.Name,
+				gvr.Resource, r.config.SourceNamespace)
+
+			r.config.SourceNamespace = metav1.NamespaceAll
+		}
+	}
+
+	r.resourceClient = sourceClient.Resource(*gvr).Namespace(r.config.SourceNamespace)
+
+	listResources, watchResources := r.listWatchFor(sourceClient, gvr, resourceType.GroupVersionKind(), r.config.SourceNamespace)
+
+	if r.config.SourceNamespace == metav1.NamespaceAll && r.config.FallbackNamespace != "" {
+		listFallback, watchFallback := r.listWatchFor(sourceClient, gvr, resourceType.GroupVersionKind(), r.config.FallbackNamespace)
+		listResources, watchResources = r.withNamespaceFallback(listResources, watchResources, listFallback, watchFallback)
+	}
+
+	r.listResources, r.watchResources = listResources, watchResources
+
+	//nolint:wrapcheck // These are wrapper functions.
+	r.store, r.informer = cache.NewIndexerInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = r.config.SourceLabelSelector
+			options.FieldSelector = r.config.SourceFieldSelector
+
+			if r.config.ListPageSize > 0 {
+				options.Limit = r.config.ListPageSize
+			}
+
+			list, err := listResources(context.TODO(), options)
+
+			switch {
+			case err == nil:
+				r.health.resourceVersionSeen(list.GetResourceVersion())
+				r.onList(list)
+			case isResourceVersionExpired(err):
+				// The reflector immediately relists from the latest resource version so this isn't a failure.
+				r.log.V(log.LIBDEBUG).Infof("Syncer %q: list from resource version %q expired - relisting", r.config.Name,
+					options.ResourceVersion)
+			default:
+				err = r.permissionError(err, "list")
+				r.health.listWatchFailed(err)
+			}
+
+			return list, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = r.config.SourceLabelSelector
+			options.FieldSelector = r.config.SourceFieldSelector
+
+			// Bookmarks advance the resource version from which the watch resumes after a disconnect, avoiding a relist.
+			options.AllowWatchBookmarks = true
+
+			w, err := watchResources(context.TODO(), options)
+
+			switch {
+			case err == nil:
+				r.health.watchEstablished()
+				w = r.health.observeEvents(w)
+			case isResourceVersionExpired(err):
+				// The resource version from which to resume is too old, eg after a long disconnect. The reflector relists
+				// and resumes watching from the new resource version so this isn't a failure. The same is done if the
+				// watch is subsequently closed with a 410 Gone error event.
+				r.log.V(log.LIBDEBUG).Infof("Syncer %q: watch from resource version %q expired - relisting", r.config.Name,
+					options.ResourceVersion)
+			default:
+				err = r.permissionError(err, "watch")
+				r.health.listWatchFailed(err)
+			}
+
+			return w, err
+		},
+	}, &unstructured.Unstructured{}, r.config.ResyncPeriod, cache.ResourceEventHandlerFuncs{
+		AddFunc:    r.onCreate,
+		UpdateFunc: r.onUpdate,
+		DeleteFunc: r.onDelete,
+	}, r.config.Indexers)
+
+	return r.initDependencies(sourceClient)
+}
+
+func metricsRegistererFor(config *ResourceSyncerConfig) prometheus.Registerer {
+	if config.MetricsRegisterer != nil {
+		return config.MetricsRegisterer
 	}
 
-	if syncer.config.SyncCounter != nil {
-		syncer.syncCounter = syncer.config.SyncCounter
-	} else if syncer.config.SyncCounterOpts != nil {
-		syncer.syncCounter = prometheus.NewGaugeVec(
-			*syncer.config.SyncCounterOpts,
+	return prometheus.DefaultRegisterer
+}
+
+func (r *resourceSyncer) initMetrics() {
+	registerer := metricsRegistererFor(&r.config)
+
+	if r.config.SyncCounter != nil {
+		r.syncCounter = r.config.SyncCounter
+	} else if r.config.SyncCounterOpts != nil {
+		r.syncCounter = prometheus.NewGaugeVec(
+			*r.config.SyncCounterOpts,
 			[]string{
 				DirectionLabel,
 				OperationLabel,
 				SyncerNameLabel,
 			},
 		)
-		prometheus.MustRegister(syncer.syncCounter)
+		registerer.MustRegister(r.syncCounter)
 	}
 
-	syncer.workQueue = workqueue.New(config.Name)
+	if r.config.SyncDuration != nil {
+		r.syncDuration = r.config.SyncDuration
+	} else if r.config.SyncDurationOpts != nil {
+		r.syncDuration = prometheus.NewHistogramVec(*r.config.SyncDurationOpts, []string{DirectionLabel, SyncerNameLabel})
+		registerer.MustRegister(r.syncDuration)
+	}
 
-	resourceClient := config.SourceClient.Resource(*gvr).Namespace(config.SourceNamespace)
+	if r.config.SyncLag != nil {
+		r.syncLag = r.config.SyncLag
+	} else if r.config.SyncLagOpts != nil {
+		r.syncLag = prometheus.NewHistogramVec(*r.config.SyncLagOpts, []string{DirectionLabel, SyncerNameLabel})
+		registerer.MustRegister(r.syncLag)
+	}
 
-	//nolint:wrapcheck // These are wrapper functions.
-	syncer.store, syncer.informer = cache.NewInformer(&cache.ListWatch{
-		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-			options.LabelSelector = config.SourceLabelSelector
-			options.FieldSelector = config.SourceFieldSelector
-			return resourceClient.List(context.TODO(), options)
-		},
-		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-			options.LabelSelector = config.SourceLabelSelector
-			options.FieldSelector = config.SourceFieldSelector
-			return resourceClient.Watch(context.TODO(), options)
-		},
-	}, &unstructured.Unstructured{}, config.ResyncPeriod, cache.ResourceEventHandlerFuncs{
-		AddFunc:    syncer.onCreate,
-		UpdateFunc: syncer.onUpdate,
-		DeleteFunc: syncer.onDelete,
-	})
+	if r.config.LastSyncTime != nil {
+		r.lastSyncTime = r.config.LastSyncTime
+	} else if r.config.LastSyncTimeOpts != nil {
+		r.lastSyncTime = prometheus.NewGaugeVec(*r.config.LastSyncTimeOpts, []string{DirectionLabel, SyncerNameLabel})
+		registerer.MustRegister(r.lastSyncTime)
+	}
 
-	return syncer, nil
+	if r.config.SyncErrors != nil {
+		r.syncErrors = r.config.SyncErrors
+	} else if r.config.SyncErrorsOpts != nil {
+		r.syncErrors = prometheus.NewCounterVec(*r.config.SyncErrorsOpts, []string{DirectionLabel, OperationLabel, SyncerNameLabel})
+		registerer.MustRegister(r.syncErrors)
+	}
+
+	if r.config.SkippedCounter != nil {
+		r.skipped = r.config.SkippedCounter
+	} else if r.config.SkippedCounterOpts != nil {
+		r.skipped = prometheus.NewCounterVec(*r.config.SkippedCounterOpts,
+			[]string{DirectionLabel, OperationLabel, ReasonLabel, SyncerNameLabel})
+		registerer.MustRegister(r.skipped)
+	}
+}
+
+func (r *resourceSyncer) recordSyncMetrics(op Operation, started time.Time) {
+	if r.syncCounter != nil {
+		r.syncCounter.With(prometheus.Labels{
+			DirectionLabel:  r.config.Direction.String(),
+			OperationLabel:  op.String(),
+			SyncerNameLabel: r.config.Name,
+		}).Inc()
+	}
+
+	labels := prometheus.Labels{
+		DirectionLabel:  r.config.Direction.String(),
+		SyncerNameLabel: r.config.Name,
+	}
+
+	if r.syncDuration != nil {
+		r.syncDuration.With(labels).Observe(r.config.Clock.Since(started).Seconds())
+	}
+
+	if r.lastSyncTime != nil {
+		r.lastSyncTime.With(labels).Set(float64(r.config.Clock.Now().UnixNano()) / 1e9)
+	}
 }
 
 func (r *resourceSyncer) Start(stopCh <-chan struct{}) error {
 	r.log.V(log.LIBDEBUG).Infof("Starting syncer %q", r.config.Name)
 
 	r.stopCh = stopCh
+	r.startedAt = r.config.Clock.Now()
+
+	if r.informer == nil {
+		gvr, err := r.awaitResourceType(stopCh)
+		if err == nil {
+			err = r.initForResource(gvr)
+		}
+
+		if err != nil {
+			close(r.stopped)
+			return err
+		}
+	}
+
+	if r.config.VerifyOnStart {
+		if err := r.verifyListWatch(); err != nil {
+			close(r.stopped)
+			return err
+		}
+	}
+
+	r.health.queueDrained()
+
+	// The context passed to the Federator is cancelled on stop so in-progress downstream calls abort promptly.
+	var cancel context.CancelFunc
+	r.ctx, cancel = context.WithCancel(log.IntoContext(context.Background(), r.log))
 
 	go func() {
 		defer func() {
 			r.stopped <- struct{}{}
 			r.log.V(log.LIBDEBUG).Infof("Syncer %q stopped", r.config.Name)
 		}()
+		defer r.workQueue.AwaitWorkers()
 		defer r.workQueue.ShutDown()
+		defer cancel()
 
 		r.informer.Run(stopCh)
 	}()
 
+	for _, informer := range r.dependencyInformers {
+		go informer.Run(stopCh)
+	}
+
+	if r.config.OnCacheSynced != nil {
+		go r.notifyCacheSynced()
+	}
+
+	if r.config.PruneOnSync != nil {
+		go r.pruneOnSync()
+	}
+
+	if r.config.ExternalTriggers != nil {
+		go r.consumeExternalTriggers(stopCh)
+	}
+
+	// With a bounded queue, the informer blocks once the queue is full so the queue must be processed for the cache to sync.
+	if r.config.MaxQueueDepth > 0 {
+		runWorkers(r.workQueue, stopCh, r.config.MaxConcurrentReconciles, r.processNextWorkItem)
+	}
+
 	if *r.config.WaitForCacheSync {
 		r.log.V(log.LIBDEBUG).Infof("Syncer %q waiting for informer cache to sync", r.config.Name)
 
-		if ok := cache.WaitForCacheSync(stopCh, r.informer.HasSynced); !ok {
+		if ok := cache.WaitForCacheSync(stopCh, r.informer.HasSynced, r.dependenciesSynced); !ok {
 			return fmt.Errorf("failed to wait for informer cache to sync")
 		}
 	}
 
-	r.workQueue.Run(stopCh, r.processNextWorkItem)
+	if r.config.MaxQueueDepth <= 0 {
+		runWorkers(r.workQueue, stopCh, r.config.MaxConcurrentReconciles, r.processNextWorkItem)
+	}
 
 	r.log.V(log.LIBDEBUG).Infof("Syncer %q started", r.config.Name)
 
 	return nil
 }
 
+// awaitResourceType retries resolving the ResourceType via the RestMapper, with backoff, until it's available, the
+// ResourceTypeWaitTimeout elapses or the stop channel is closed.
+func (r *resourceSyncer) awaitResourceType(stopCh <-chan struct{}) (*schema.GroupVersionResource, error) {
+	deadline := r.config.Clock.Now().Add(r.config.ResourceTypeWaitTimeout)
+	delay := resourceTypeRetryInitialDelay
+
+	for {
+		if resettable, ok := r.config.RestMapper.(interface{ Reset() }); ok {
+			resettable.Reset()
+		}
+
+		gvr, err := r.resolveResourceType()
+		if err == nil {
+			r.log.V(log.LIBDEBUG).Infof("Syncer %q: resource type %T is now available", r.config.Name, r.config.ResourceType)
+			return gvr, nil
+		}
+
+		if !meta.IsNoMatchError(errors.Cause(err)) {
+			return nil, err //nolint:wrapcheck // OK to return the error as is.
+		}
+
+		remaining := deadline.Sub(r.config.Clock.Now())
+		if remaining <= 0 {
+			return nil, errors.Wrapf(err, "syncer %q: timed out waiting for resource type %T to become available",
+				r.config.Name, r.config.ResourceType)
+		}
+
+		if delay > remaining {
+			delay = remaining
+		}
+
+		r.log.V(log.LIBDEBUG).Infof("Syncer %q: resource type %T isn't yet available - retrying in %v",
+			r.config.Name, r.config.ResourceType, delay)
+
+		select {
+		case <-stopCh:
+			return nil, fmt.Errorf("syncer %q: stopped while waiting for resource type %T to become available",
+				r.config.Name, r.config.ResourceType)
+		case <-r.config.Clock.After(delay):
+		}
+
+		delay *= 2
+		if delay > resourceTypeRetryMaxDelay {
+			delay = resourceTypeRetryMaxDelay
+		}
+	}
+}
+
+// runWorkers starts the given number of workers, at least one, processing the work queue. The work queue guarantees a
+// key is never processed by more than one worker at a time.
+func runWorkers(queue workqueue.Interface, stopCh <-chan struct{}, numWorkers int, process workqueue.ProcessFunc) {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	for i := 0; i < numWorkers; i++ {
+		queue.Run(stopCh, process)
+	}
+}
+
+func (r *resourceSyncer) PendingKeys() []string {
+	if r.workQueue == nil {
+		return []string{}
+	}
+
+	return r.workQueue.PendingKeys()
+}
+
 func (r *resourceSyncer) AwaitStopped() {
 	<-r.stopped
 }
 
+// errResourceTypeUnavailable returns the error for an operation that requires the informer while the resource type
+// isn't yet available, ie until Start resolves it when ResourceTypeWaitTimeout is specified.
+func (r *resourceSyncer) errResourceTypeUnavailable() error {
+	return fmt.Errorf("syncer %q: the resource type %T isn't yet available", r.config.Name, r.config.ResourceType)
+}
+
 func (r *resourceSyncer) GetResource(name, namespace string) (runtime.Object, bool, error) {
+	if r.informer == nil {
+		return nil, false, r.errResourceTypeUnavailable()
+	}
+
 	obj, exists, err := r.store.GetByKey(namespace + "/" + name)
 	if err != nil {
 		return nil, false, errors.Wrap(err, "error retrieving resource")
@@ -308,6 +1129,14 @@ func (r *resourceSyncer) GetResource(name, namespace string) (runtime.Object, bo
 }
 
 func (r *resourceSyncer) ListResources() ([]runtime.Object, error) {
+	return r.ListResourcesBySelector(labels.Everything())
+}
+
+func (r *resourceSyncer) ListResourcesBySelector(selector labels.Selector) ([]runtime.Object, error) {
+	if r.informer == nil {
+		return nil, r.errResourceTypeUnavailable()
+	}
+
 	if ok := cache.WaitForCacheSync(r.stopCh, r.informer.HasSynced); !ok {
 		return nil, fmt.Errorf("failed to wait for informer cache to sync")
 	}
@@ -316,6 +1145,10 @@ func (r *resourceSyncer) ListResources() ([]runtime.Object, error) {
 	retObjects := make([]runtime.Object, 0, len(list))
 
 	for _, obj := range list {
+		if !selector.Matches(labels.Set(obj.(*unstructured.Unstructured).GetLabels())) {
+			continue
+		}
+
 		converted, err := r.convert(obj.(*unstructured.Unstructured))
 		if err != nil {
 			return nil, err
@@ -327,7 +1160,31 @@ func (r *resourceSyncer) ListResources() ([]runtime.Object, error) {
 	return retObjects, nil
 }
 
+func (r *resourceSyncer) ByIndex(indexName, value string) ([]*unstructured.Unstructured, error) {
+	if r.informer == nil {
+		return nil, r.errResourceTypeUnavailable()
+	}
+
+	list, err := r.store.ByIndex(indexName, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving resources by index %q", indexName)
+	}
+
+	retObjects := make([]*unstructured.Unstructured, 0, len(list))
+	for _, obj := range list {
+		retObjects = append(retObjects, r.assertUnstructured(obj).DeepCopy())
+	}
+
+	return retObjects, nil
+}
+
 func (r *resourceSyncer) Reconcile(resourceLister func() []runtime.Object) {
+	if r.informer == nil {
+		r.log.Warningf("Syncer %q: unable to reconcile - the resource type %T isn't yet available", r.config.Name,
+			r.config.ResourceType)
+		return
+	}
+
 	go func() {
 		if ok := cache.WaitForCacheSync(r.stopCh, r.informer.HasSynced); !ok {
 			r.log.Error(nil, "Unable to reconcile - failed to wait for informer cache to sync")
@@ -384,124 +1241,425 @@ func (r *resourceSyncer) Reconcile(resourceLister func() []runtime.Object) {
 				continue
 			}
 
-			obj, _ := resourceUtil.ToUnstructured(resource)
+			obj, _ := r.converter.ToUnstructured(resource)
 			r.deleted.Store(key, obj)
+			r.enqueue(obj, key, Delete)
+		}
+	}()
+}
+
+func (r *resourceSyncer) notifyCacheSynced() {
+	if ok := cache.WaitForCacheSync(r.stopCh, r.informer.HasSynced); !ok {
+		r.log.V(log.LIBDEBUG).Infof("Syncer %q stopped before the informer cache synced", r.config.Name)
+		return
+	}
+
+	r.log.V(log.LIBDEBUG).Infof("Syncer %q: invoking OnCacheSynced function", r.config.Name)
+
+	r.config.OnCacheSynced(r.ctx)
+}
+
+func (r *resourceSyncer) Resync() {
+	if r.informer == nil {
+		r.log.Warningf("Syncer %q: unable to resync - the resource type %T isn't yet available", r.config.Name,
+			r.config.ResourceType)
+		return
+	}
+
+	go func() {
+		if ok := cache.WaitForCacheSync(r.stopCh, r.informer.HasSynced); !ok {
+			r.log.Error(nil, "Unable to resync - failed to wait for informer cache to sync")
+			return
+		}
+
+		list := r.store.List()
+
+		r.log.V(log.LIBDEBUG).Infof("Syncer %q re-queueing %d resources for resync", r.config.Name, len(list))
+
+		r.checkQueueDrained()
+
+		for _, obj := range list {
 			r.workQueue.Enqueue(obj)
 		}
 	}()
 }
 
+func (r *resourceSyncer) Enqueue(namespace, name string) {
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+
+	r.EnqueueKey(key)
+}
+
+func (r *resourceSyncer) EnqueueKey(key string) {
+	if r.workQueue == nil {
+		r.log.Warningf("Syncer %q: unable to enqueue %q - the resource type %T isn't yet available", r.config.Name, key,
+			r.config.ResourceType)
+		return
+	}
+
+	r.log.V(log.LIBDEBUG).Infof("Syncer %q: enqueueing %q externally", r.config.Name, key)
+
+	r.enqueued.Store(key, true)
+	r.enqueue(cache.ExplicitKey(key), key, Update)
+}
+
 func (r *resourceSyncer) processNextWorkItem(key, name, ns string) (bool, error) {
+	// All log lines for this reconcile carry the same correlation ID, via the Logger in the context.
+	ctx := log.WithCorrelationID(r.ctx, log.NewCorrelationID())
+
+	span := r.startSpan(ctx, key)
+
+	requeue, err := r.syncKey(ctx, key, name, ns)
+
+	span.end(requeue, err)
+
+	if !requeue {
+		r.changesQueued.Delete(key)
+	}
+
+	return requeue, err
+}
+
+func (r *resourceSyncer) syncKey(ctx context.Context, key, name, ns string) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	started := r.config.Clock.Now()
+
+	_, enqueued := r.enqueued.LoadAndDelete(key)
+
 	obj, exists, err := r.store.GetByKey(key)
 	if err != nil {
 		return true, errors.Wrapf(err, "error retrieving resource %q", key)
 	}
 
 	if !exists {
-		return r.handleDeleted(key)
+		if _, deleted := r.deleted.Load(key); deleted || !enqueued {
+			return r.handleDeleted(ctx, key, started)
+		}
+
+		// The externally enqueued resource may not be in the cache yet, eg if it was just created, so get it live.
+		live, err := r.config.SourceClient.Resource(*r.gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: enqueued resource %q not found", r.config.Name, key)
+			return false, nil
+		}
+
+		if err != nil {
+			return true, errors.Wrapf(err, "error retrieving enqueued resource %q", key)
+		}
+
+		obj = live
 	}
 
 	resource := r.assertUnstructured(obj)
 
 	op := Update
-	_, found := r.created.Load(key)
-	if found {
+	_, resynced := r.resynced.LoadAndDelete(key)
+
+	if deleted, found := r.deleted.LoadAndDelete(key); found {
+		// The resource was deleted and re-created before the delete was processed. The destination resource wasn't
+		// deleted so, rather than deleting and re-creating it, which would leave a transient gap, reconcile it to the
+		// re-created resource via an update.
+		logger.V(log.LIBDEBUG).Infof("Syncer %q: resource %q was re-created (UID %q -> %q) - coalescing the delete and create "+
+			"into an update", r.config.Name, key, resourceUtil.ToMeta(deleted.(runtime.Object)).GetUID(), resource.GetUID())
+
+		r.created.Delete(key)
+	} else if _, found := r.created.Load(key); found {
 		op = Create
+	} else if resynced {
+		op = Resync
 	}
 
-	r.log.V(log.LIBTRACE).Infof("Syncer %q retrieved %sd resource %q: %#v", r.config.Name, op, resource.GetName(), resource)
+	logger.V(log.LIBTRACE).Infof("Syncer %q retrieved %sd resource %q: %#v", r.config.Name, op, resource.GetName(), resource)
 
-	if !r.shouldSync(resource) {
+	if !r.shouldSync(ctx, resource, op) {
 		r.created.Delete(key)
+		r.previous.Delete(key)
+
 		return false, nil
 	}
 
-	resource, transformed, requeue := r.transform(resource, key, op)
-	if resource != nil {
-		if r.config.SourceNamespace == metav1.NamespaceAll && resource.GetNamespace() != "" {
-			resource = resource.DeepCopy()
-			_ = unstructured.SetNestedField(resource.Object, resource.GetNamespace(),
-				util.MetadataField, util.LabelsField, OrigNamespaceLabelKey)
+	if r.config.Finalizer != "" {
+		deleting, err := r.reconcileFinalizer(ctx, resource, key)
+		if err != nil {
+			return r.syncFailed(ctx, resource, key, op, err)
 		}
 
-		r.log.V(log.LIBDEBUG).Infof("Syncer %q syncing resource %q", r.config.Name, resource.GetName())
+		if deleting {
+			r.created.Delete(key)
+			r.previous.Delete(key)
 
-		err = r.config.Federator.Distribute(resource)
-		if err != nil {
-			return true, errors.Wrapf(err, "error distributing resource %q", key)
+			return false, nil
 		}
+	}
+
+	source := resource
+
+	if r.config.FanOutTransform != nil {
+		requeue, err := r.syncFanOut(ctx, source, key, op, started)
+		if err == nil && !requeue {
+			r.created.Delete(key)
+			r.previous.Delete(key)
+			r.enqueueRelated(ctx, source, key)
+		}
+
+		return requeue, err
+	}
 
-		r.onSuccessfulSync(resource, transformed, op)
+	resource, transformed, requeue, err := r.transform(ctx, resource, key, op)
+	if err != nil {
+		return r.syncFailed(ctx, source, key, op, errors.Wrapf(err, "error transforming resource %q", key))
+	}
+
+	if resource != nil {
+		resource = r.withContentHashLabel(r.withOrigNamespaceLabel(resource))
+
+		logger.V(log.LIBDEBUG).Info(fmt.Sprintf("Syncer %q syncing resource %q", r.config.Name, resource.GetName()), "key", key)
+
+		err = r.recoverPanic(ctx, key, "distribute", func() error {
+			resource = r.beforeWrite(resource, op)
+			return r.config.Federator.Distribute(ctx, resource)
+		})
+		if err != nil && r.isStopping() {
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: distribute of resource %q interrupted by stop - not re-queueing: %v",
+				r.config.Name, key, err)
+			return false, nil
+		}
 
-		if r.syncCounter != nil {
-			r.syncCounter.With(prometheus.Labels{
-				DirectionLabel:  r.config.Direction.String(),
-				OperationLabel:  op.String(),
-				SyncerNameLabel: r.config.Name,
-			}).Inc()
+		if err != nil {
+			return r.syncFailed(ctx, source, key, op, errors.Wrapf(err, "error distributing resource %q", key))
 		}
 
-		r.log.V(log.LIBDEBUG).Infof("Syncer %q successfully synced %q", r.config.Name, resource.GetName())
+		r.onSuccessfulSync(ctx, resource, transformed, op)
+		r.recordSyncMetrics(op, started)
+		r.recordSyncLag(source, key, op)
+
+		logger.V(log.LIBDEBUG).Info(fmt.Sprintf("Syncer %q successfully synced %q", r.config.Name, resource.GetName()), "key", key)
 	}
 
 	if !requeue {
 		r.created.Delete(key)
+		r.previous.Delete(key)
+		r.enqueueRelated(ctx, source, key)
 	}
 
 	return requeue, nil
 }
 
-func (r *resourceSyncer) handleDeleted(key string) (bool, error) {
-	r.log.V(log.LIBDEBUG).Infof("Syncer %q informed of deleted resource %q", r.config.Name, key)
+func (r *resourceSyncer) handleDeleted(ctx context.Context, key string, started time.Time) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	logger.V(log.LIBDEBUG).Infof("Syncer %q informed of deleted resource %q", r.config.Name, key)
+
+	r.previous.Delete(key)
 
 	obj, found := r.deleted.Load(key)
 	if !found {
-		r.log.V(log.LIBDEBUG).Infof("Syncer %q: resource %q not found in deleted object cache", r.config.Name, key)
+		logger.V(log.LIBDEBUG).Infof("Syncer %q: resource %q not found in deleted object cache", r.config.Name, key)
 		return false, nil
 	}
 
 	r.deleted.Delete(key)
+	r.created.Delete(key)
 
 	deletedResource := r.assertUnstructured(obj)
-	if !r.shouldSync(deletedResource) {
+	if !r.shouldSync(ctx, deletedResource, Delete) {
 		return false, nil
 	}
 
-	resource, transformed, requeue := r.transform(deletedResource, key, Delete)
+	if r.config.FanOutTransform != nil {
+		requeue, err := r.syncFanOut(ctx, deletedResource, key, Delete, started)
+		if err == nil {
+			if requeue {
+				r.deleted.Store(key, deletedResource)
+			} else {
+				r.enqueueRelated(ctx, deletedResource, key)
+			}
+		}
+
+		return requeue, err
+	}
+
+	resource, transformed, requeue, err := r.transform(ctx, deletedResource, key, Delete)
+	if err != nil {
+		return r.syncFailed(ctx, deletedResource, key, Delete, errors.Wrapf(err, "error transforming deleted resource %q", key))
+	}
+
 	if resource != nil {
-		r.log.V(log.LIBDEBUG).Infof("Syncer %q deleting resource %q: %#v", r.config.Name, resource.GetName(), resource)
+		logger.V(log.LIBDEBUG).Infof("Syncer %q deleting resource %q: %#v", r.config.Name, resource.GetName(), resource)
 
-		err := r.config.Federator.Delete(resource)
+		err = r.recoverPanic(ctx, key, "delete", func() error {
+			return r.config.Federator.Delete(r.deleteContext(ctx), resource)
+		})
 		if apierrors.IsNotFound(err) {
-			r.log.V(log.LIBDEBUG).Infof("Syncer %q: resource %q not found - ignoring", r.config.Name, resource.GetName())
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: resource %q not found - ignoring", r.config.Name, resource.GetName())
+			r.enqueueRelated(ctx, deletedResource, key)
+
 			return false, nil
 		}
 
-		if err != nil {
-			r.deleted.Store(key, deletedResource)
-			return true, errors.Wrapf(err, "error deleting resource %q", key)
+		if err != nil && r.isStopping() {
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: delete of resource %q interrupted by stop - not re-queueing: %v",
+				r.config.Name, key, err)
+			return false, nil
 		}
 
-		r.onSuccessfulSync(resource, transformed, Delete)
-
-		if r.syncCounter != nil {
-			r.syncCounter.With(prometheus.Labels{
-				DirectionLabel:  r.config.Direction.String(),
-				OperationLabel:  Delete.String(),
-				SyncerNameLabel: r.config.Name,
-			}).Inc()
+		if err != nil {
+			return r.syncFailed(ctx, deletedResource, key, Delete, errors.Wrapf(err, "error deleting resource %q", key))
 		}
 
-		r.log.V(log.LIBDEBUG).Infof("Syncer %q successfully deleted %q", r.config.Name, resource.GetName())
+		r.onSuccessfulSync(ctx, resource, transformed, Delete)
+		r.recordSyncMetrics(Delete, started)
+		r.recordSyncLag(deletedResource, key, Delete)
+
+		logger.V(log.LIBDEBUG).Infof("Syncer %q successfully deleted %q", r.config.Name, resource.GetName())
 	}
 
 	if requeue {
 		r.deleted.Store(key, deletedResource)
+	} else {
+		r.enqueueRelated(ctx, deletedResource, key)
 	}
 
 	return requeue, nil
 }
 
+func (r *resourceSyncer) enqueueRelated(ctx context.Context, changed *unstructured.Unstructured, changedKey string) {
+	logger := log.FromContext(ctx)
+
+	if r.config.EnqueueRelated == nil {
+		return
+	}
+
+	for _, key := range r.config.EnqueueRelated(changed) {
+		if key == changedKey {
+			continue
+		}
+
+		logger.V(log.LIBDEBUG).Infof("Syncer %q: enqueueing %q related to changed resource %q", r.config.Name, key, changedKey)
+
+		r.enqueue(cache.ExplicitKey(key), key, Update)
+	}
+}
+
+// withContentHashLabel returns a copy of the given resource labeled with its content hash if the ContentHashLabel is
+// specified, otherwise the resource is returned as is.
+func (r *resourceSyncer) withContentHashLabel(resource *unstructured.Unstructured) *unstructured.Unstructured {
+	if r.config.ContentHashLabel == "" {
+		return resource
+	}
+
+	hash := resourceUtil.ContentHash(resource, r.config.ContentHashIgnorePaths...)
+
+	resource = resource.DeepCopy()
+	_ = unstructured.SetNestedField(resource.Object, hash, util.MetadataField, util.LabelsField, r.config.ContentHashLabel)
+
+	return resource
+}
+
+// withOrigNamespaceLabel returns a copy of the given resource labeled with its originating namespace if syncing from
+// all namespaces, otherwise the resource is returned as is.
+func (r *resourceSyncer) withOrigNamespaceLabel(resource *unstructured.Unstructured) *unstructured.Unstructured {
+	if r.config.SourceNamespace != metav1.NamespaceAll || resource.GetNamespace() == "" {
+		return resource
+	}
+
+	resource = resource.DeepCopy()
+	_ = unstructured.SetNestedField(resource.Object, resource.GetNamespace(), util.MetadataField, util.LabelsField,
+		OrigNamespaceLabelKey)
+
+	return resource
+}
+
+// beforeWrite returns a copy of the given resource mutated by the BeforeWrite function if specified, otherwise the
+// resource is returned as is.
+func (r *resourceSyncer) beforeWrite(resource *unstructured.Unstructured, op Operation) *unstructured.Unstructured {
+	if r.config.BeforeWrite == nil {
+		return resource
+	}
+
+	resource = resource.DeepCopy()
+	r.config.BeforeWrite(resource, op)
+
+	return resource
+}
+
+// recoverPanic invokes the given function, converting a panic into an error so one bad resource doesn't take down the
+// worker goroutine. The resource is then re-queued with backoff like any other failure.
+func (r *resourceSyncer) recoverPanic(ctx context.Context, key, what string, f func() error) (err error) {
+	logger := log.FromContext(ctx)
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = errors.Errorf("%s of resource %q panicked: %v", what, key, p)
+			logger.Errorf(err, "Syncer %q: recovered from panic:\n%s", r.config.Name, debug.Stack())
+		}
+	}()
+
+	return f()
+}
+
+// syncFailed determines if the resource should be re-queued after the given error. A terminal error isn't retried and
+// the resource is passed to the OnDeadLetter function, if specified. Either way, the error is returned to be reported.
+func (r *resourceSyncer) syncFailed(ctx context.Context, resource *unstructured.Unstructured, key string, op Operation, err error,
+) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if r.syncErrors != nil {
+		r.syncErrors.With(prometheus.Labels{
+			DirectionLabel:  r.config.Direction.String(),
+			OperationLabel:  op.String(),
+			SyncerNameLabel: r.config.Name,
+		}).Inc()
+	}
+
+	isRetryable := r.config.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableError
+	}
+
+	if isRetryable(err) {
+		if op == Delete {
+			r.deleted.Store(key, resource)
+		}
+
+		return true, err
+	}
+
+	logger.V(log.LIBDEBUG).Infof("Syncer %q: terminal error for resource %q - not re-queueing", r.config.Name, key)
+
+	r.created.Delete(key)
+	r.previous.Delete(key)
+
+	if r.config.OnDeadLetter != nil {
+		r.config.OnDeadLetter(resource, op, err)
+	}
+
+	return false, err
+}
+
+// IsRetryableError is the default ResourceSyncerConfig.IsRetryable function. Errors indicating an invalid or forbidden
+// request, which won't succeed if retried, are terminal. All others, eg conflicts, unavailability and timeouts, are
+// retryable.
+func IsRetryableError(err error) bool {
+	return !apierrors.IsInvalid(err) && !apierrors.IsBadRequest(err) && !apierrors.IsForbidden(err)
+}
+
+func (r *resourceSyncer) isStopping() bool {
+	return r.ctx.Err() != nil
+}
+
+func (r *resourceSyncer) deleteContext(ctx context.Context) context.Context {
+	if r.config.DeletePropagationPolicy == nil {
+		return ctx
+	}
+
+	return federate.WithDeleteOptions(ctx, metav1.DeleteOptions{PropagationPolicy: r.config.DeletePropagationPolicy})
+}
+
 func (r *resourceSyncer) convertNoError(from interface{}) runtime.Object {
 	converted, err := r.convert(from)
 	if err != nil {
@@ -512,6 +1670,18 @@ func (r *resourceSyncer) convertNoError(from interface{}) runtime.Object {
 }
 
 func (r *resourceSyncer) convert(from interface{}) (runtime.Object, error) {
+	// Converting Unstructured to Unstructured shares the underlying content so make a deep copy to isolate the result
+	// from the informer cache.
+	if u, ok := from.(*unstructured.Unstructured); ok {
+		if _, ok := r.config.ResourceType.(*unstructured.Unstructured); ok {
+			if r.config.UsePreferredVersion {
+				return r.toResourceTypeVersion(u.DeepCopy())
+			}
+
+			return u.DeepCopy(), nil
+		}
+	}
+
 	converted := r.config.ResourceType.DeepCopyObject()
 	err := r.config.Scheme.Convert(from, converted, nil)
 
@@ -519,30 +1689,57 @@ func (r *resourceSyncer) convert(from interface{}) (runtime.Object, error) {
 }
 
 //nolint:interfacer //false positive for "`from` can be `k8s.io/apimachinery/pkg/runtime.Object`" as it returns 'from' as Unstructured
-func (r *resourceSyncer) transform(from *unstructured.Unstructured, key string,
+func (r *resourceSyncer) transform(ctx context.Context, from *unstructured.Unstructured, key string,
 	op Operation,
-) (*unstructured.Unstructured, runtime.Object, bool) {
-	if r.config.Transform == nil {
-		return from, nil, false
+) (*unstructured.Unstructured, runtime.Object, bool, error) {
+	logger := log.FromContext(ctx)
+
+	useDeleteTransform := op == Delete && r.config.DeleteTransform != nil
+
+	if r.config.Transform == nil && r.config.TransformWithPrevious == nil && r.config.TransformWithContext == nil &&
+		!useDeleteTransform {
+		if r.config.UsePreferredVersion {
+			converted, err := r.toResourceTypeVersion(from)
+			if err != nil {
+				logger.Errorf(err, "Syncer %q: error converting resource %q", r.config.Name, key)
+				return nil, nil, false, nil
+			}
+
+			return converted, nil, false, nil
+		}
+
+		return from, nil, false, nil
 	}
 
 	clusterID, _ := getClusterIDLabel(from)
 
-	converted := r.convertNoError(from)
-	if converted == nil {
-		return nil, nil, false
+	var converted runtime.Object
+	if _, ok := r.config.ResourceType.(*unstructured.Unstructured); ok && r.config.ReadOnlyTransform && !r.config.TypedTransform {
+		converted = from
+	} else if converted = r.convertForTransform(from); converted == nil {
+		return nil, nil, false, nil
+	}
+
+	transformed, requeue, err := r.invokeTransform(ctx, key, converted, op, useDeleteTransform)
+	if err != nil {
+		return nil, nil, false, err
 	}
 
-	transformed, requeue := r.config.Transform(converted, r.workQueue.NumRequeues(key), op)
 	if transformed == nil {
-		r.log.V(log.LIBDEBUG).Infof("Syncer %q: transform function returned nil - not syncing - requeue: %v", r.config.Name, requeue)
-		return nil, nil, requeue
+		logger.V(log.LIBDEBUG).Infof("Syncer %q: transform function returned nil - not syncing - requeue: %v", r.config.Name, requeue)
+		return nil, nil, requeue, nil
+	}
+
+	if transformed == from {
+		// The read-only transform returned the cached resource as is so it already has the cluster ID label. The
+		// OnSuccessfulSync function is passed its own copy.
+		return from, nil, requeue, nil
 	}
 
-	result, err := resourceUtil.ToUnstructured(transformed)
+	result, err := r.converter.ToUnstructured(transformed)
 	if err != nil {
-		r.log.Errorf(err, "Syncer %q: error converting transform function result", r.config.Name)
-		return nil, nil, false
+		logger.Errorf(err, "Syncer %q: error converting transform function result", r.config.Name)
+		return nil, nil, false, nil
 	}
 
 	// Preserve the cluster ID label
@@ -550,10 +1747,25 @@ func (r *resourceSyncer) transform(from *unstructured.Unstructured, key string,
 		_ = unstructured.SetNestedField(result.Object, clusterID, util.MetadataField, util.LabelsField, federate.ClusterIDLabelKey)
 	}
 
-	return result, transformed, requeue
+	return result, transformed, requeue, nil
+}
+
+func (r *resourceSyncer) previousFor(key string, op Operation) runtime.Object {
+	if op != Update {
+		return nil
+	}
+
+	obj, found := r.previous.Load(key)
+	if !found {
+		return nil
+	}
+
+	return r.convertForTransform(obj.(*unstructured.Unstructured))
 }
 
-func (r *resourceSyncer) onSuccessfulSync(resource, converted runtime.Object, op Operation) {
+func (r *resourceSyncer) onSuccessfulSync(ctx context.Context, resource, converted runtime.Object, op Operation) {
+	logger := log.FromContext(ctx)
+
 	if r.config.OnSuccessfulSync == nil {
 		return
 	}
@@ -565,37 +1777,82 @@ func (r *resourceSyncer) onSuccessfulSync(resource, converted runtime.Object, op
 		}
 	}
 
-	r.log.V(log.LIBTRACE).Infof("Syncer %q: invoking OnSuccessfulSync function with: %#v", r.config.Name, converted)
+	logger.V(log.LIBTRACE).Infof("Syncer %q: invoking OnSuccessfulSync function with: %#v", r.config.Name, converted)
 
 	r.config.OnSuccessfulSync(converted, op)
 }
 
-func (r *resourceSyncer) onCreate(resource interface{}) {
-	if !r.shouldProcess(resource.(*unstructured.Unstructured), Create) {
+// onList records the resources from the initial list as unprocessed if they shouldn't be processed on start. This is
+// only invoked from the informer's reflector goroutine.
+func (r *resourceSyncer) onList(list *unstructured.UnstructuredList) {
+	if r.listed {
 		return
 	}
 
+	r.listed = true
+
+	if *r.config.ProcessOnStart {
+		return
+	}
+
+	for i := range list.Items {
+		key, _ := cache.MetaNamespaceKeyFunc(&list.Items[i])
+		r.unprocessed.Store(key, true)
+	}
+}
+
+func (r *resourceSyncer) onCreate(resource interface{}) {
 	key, _ := cache.MetaNamespaceKeyFunc(resource)
+
+	if _, found := r.unprocessed.LoadAndDelete(key); found {
+		r.log.V(log.LIBDEBUG).Infof("Syncer %q: not processing resource %q from the initial list", r.config.Name, key)
+		return
+	}
+
+	if !r.shouldProcess(resource.(*unstructured.Unstructured), Create) {
+		return
+	}
 	v := true
 	r.created.Store(key, &v)
-	r.workQueue.Enqueue(resource)
+	r.enqueueDebounced(resource, Create)
 }
 
 func (r *resourceSyncer) onUpdate(oldObj, newObj interface{}) {
-	if !r.shouldProcess(newObj.(*unstructured.Unstructured), Update) {
-		return
-	}
-
 	oldResource := r.assertUnstructured(oldObj)
 	newResource := r.assertUnstructured(newObj)
 
+	op := Update
+	if r.config.DistinguishResync && oldResource.GetResourceVersion() == newResource.GetResourceVersion() {
+		op = Resync
+	}
+
+	if !r.shouldProcess(newResource, op) {
+		return
+	}
+
 	if r.config.ResourcesEquivalent(oldResource, newResource) {
 		r.log.V(log.LIBTRACE).Infof("Syncer %q: objects equivalent on update - not queueing resource\nOLD: %#v\nNEW: %#v",
 			r.config.Name, oldResource, newResource)
 		return
 	}
 
-	r.workQueue.Enqueue(newObj)
+	key, _ := cache.MetaNamespaceKeyFunc(oldObj)
+
+	if logger := r.log.V(log.LIBDEBUG); logger.Enabled() {
+		logger.Infof("Syncer %q: resource %q updated: %s", r.config.Name, key, resourceUtil.Diff(oldResource, newResource))
+	}
+
+	if r.config.TransformWithPrevious != nil {
+		r.previous.LoadOrStore(key, oldResource)
+	}
+
+	if op == Resync {
+		r.resynced.Store(key, true)
+	} else {
+		r.resynced.Delete(key)
+	}
+
+	r.enqueueDebounced(newObj, op)
 }
 
 func (r *resourceSyncer) onDelete(obj interface{}) {
@@ -622,18 +1879,70 @@ func (r *resourceSyncer) onDelete(obj interface{}) {
 	key, _ := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 	r.deleted.Store(key, resource)
 
-	r.workQueue.Enqueue(obj)
+	r.enqueue(obj, key, Delete)
+}
+
+func (r *resourceSyncer) enqueueDebounced(obj interface{}, op Operation) {
+	r.checkQueueDrained()
+
+	key, _ := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	r.changeQueued(key, false)
+
+	priority := r.priority(key, op)
+	if r.config.Debounce > 0 && priority <= 0 {
+		r.workQueue.EnqueueAfter(obj, r.config.Debounce)
+		return
+	}
+
+	r.workQueue.EnqueueWithPriority(obj, priority)
+}
+
+func (r *resourceSyncer) enqueue(obj interface{}, key string, op Operation) {
+	r.checkQueueDrained()
+	r.changeQueued(key, true)
+	r.workQueue.EnqueueWithPriority(obj, r.priority(key, op))
+}
+
+func (r *resourceSyncer) priority(key string, op Operation) int {
+	if r.config.Priority == nil {
+		return 0
+	}
+
+	return r.config.Priority(key, op)
 }
 
 func (r *resourceSyncer) shouldProcess(resource *unstructured.Unstructured, op Operation) bool {
+	if r.config.SkipTerminating && op != Delete && resource.GetDeletionTimestamp() != nil && !r.isFinalizing(resource) {
+		r.onSkipped(resource, op, SkipReasonTerminating)
+		return false
+	}
+
 	if r.config.ShouldProcess != nil && !r.config.ShouldProcess(resource, op) {
+		r.onSkipped(resource, op, SkipReasonPredicate)
 		return false
 	}
 
 	return true
 }
 
-func (r *resourceSyncer) shouldSync(resource *unstructured.Unstructured) bool {
+func (r *resourceSyncer) onSkipped(resource *unstructured.Unstructured, op Operation, reason SkipReason) {
+	if r.skipped != nil {
+		r.skipped.With(prometheus.Labels{
+			DirectionLabel:  r.config.Direction.String(),
+			OperationLabel:  op.String(),
+			ReasonLabel:     string(reason),
+			SyncerNameLabel: r.config.Name,
+		}).Inc()
+	}
+
+	if r.config.OnSkipped != nil {
+		r.config.OnSkipped(resource, op, reason)
+	}
+}
+
+func (r *resourceSyncer) shouldSync(ctx context.Context, resource *unstructured.Unstructured, op Operation) bool {
+	logger := log.FromContext(ctx)
+
 	clusterID, found := getClusterIDLabel(resource)
 
 	switch r.config.Direction {
@@ -641,15 +1950,19 @@ func (r *resourceSyncer) shouldSync(resource *unstructured.Unstructured) bool {
 		if found {
 			// This is the local -> remote case - only sync local resources w/o the label, assuming any resource with the
 			// label originated from a remote source.
-			r.log.V(log.LIBDEBUG).Infof("Syncer %q: found cluster ID label %q - not syncing resource %q", r.config.Name,
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: found cluster ID label %q - not syncing resource %q", r.config.Name,
 				clusterID, resource.GetName())
+			r.onSkipped(resource, op, SkipReasonOrigin)
+
 			return false
 		}
 	case RemoteToLocal:
 		if r.config.LocalClusterID != "" && (!found || clusterID == r.config.LocalClusterID) {
 			// This is the remote -> local case - do not sync local resources
-			r.log.V(log.LIBDEBUG).Infof("Syncer %q: cluster ID label %q not present or matches local cluster ID %q - not syncing resource %q",
+			logger.V(log.LIBDEBUG).Infof("Syncer %q: cluster ID label %q not present or matches local cluster ID %q - not syncing resource %q",
 				r.config.Name, clusterID, r.config.LocalClusterID, resource.GetName())
+			r.onSkipped(resource, op, SkipReasonOrigin)
+
 			return false
 		}
 	case None:
@@ -0,0 +1,85 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func BenchmarkTransform(b *testing.B) {
+	b.Run("Copying", func(b *testing.B) {
+		benchmarkTransform(b, false)
+	})
+
+	b.Run("ReadOnly", func(b *testing.B) {
+		benchmarkTransform(b, true)
+	})
+}
+
+func benchmarkTransform(b *testing.B, readOnly bool) {
+	containers := []interface{}{}
+	for i := 0; i < 100; i++ {
+		containers = append(containers, map[string]interface{}{
+			"name":  fmt.Sprintf("container-%d", i),
+			"image": fmt.Sprintf("image-%d", i),
+			"env":   []interface{}{map[string]interface{}{"name": "FOO", "value": "bar"}},
+		})
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "test-pod", "namespace": "test-ns"},
+		"spec":       map[string]interface{}{"containers": containers},
+	}}
+
+	r := &resourceSyncer{
+		config: ResourceSyncerConfig{
+			Name:              "bench",
+			ResourceType:      &unstructured.Unstructured{},
+			Scheme:            scheme.Scheme,
+			ReadOnlyTransform: readOnly,
+			Transform: func(from runtime.Object, numRequeues int, op Operation) (runtime.Object, bool, error) {
+				return from, false, nil
+			},
+		},
+		workQueue: workqueue.New("bench"),
+		log:       log.Logger{Logger: logr.Discard()},
+	}
+
+	ctx := log.IntoContext(context.Background(), r.log)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := r.transform(ctx, obj, "test-ns/test-pod", Update); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
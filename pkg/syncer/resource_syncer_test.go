@@ -20,15 +20,26 @@ package syncer_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	dynamicfake "github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/federate"
 	"github.com/submariner-io/admiral/pkg/federate/fake"
 	. "github.com/submariner-io/admiral/pkg/gomega"
+	"github.com/submariner-io/admiral/pkg/log"
+	logfake "github.com/submariner-io/admiral/pkg/log/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
 	"github.com/submariner-io/admiral/pkg/syncer"
 	"github.com/submariner-io/admiral/pkg/syncer/test"
+	tests "github.com/submariner-io/admiral/pkg/test"
 	"github.com/submariner-io/admiral/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -36,11 +47,23 @@ import (
 	metaapi "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/dynamic"
 	fakeClient "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	k8sworkqueue "k8s.io/client-go/util/workqueue"
 )
 
 var _ = Describe("Resource Syncer", func() {
@@ -52,12 +75,69 @@ var _ = Describe("Resource Syncer", func() {
 	})
 
 	Describe("With Transform Function", testTransformFunction)
+	Describe("Operations and Direction", func() {
+		Context("local -> remote", func() {
+			testOperationsAndDirection(test.LocalNamespace, "", "", syncer.LocalToRemote)
+		})
+
+		Context("remote -> local", func() {
+			testOperationsAndDirection(test.RemoteNamespace, "local", "remote", syncer.RemoteToLocal)
+		})
+	})
+	Describe("With Unstructured Transform Function", testUnstructuredTransformFunction)
+	Describe("With Typed Transform", testTypedTransform)
+	Describe("With TransformWithPrevious Function", testTransformWithPrevious)
 	Describe("With OnSuccessfulSync Function", testOnSuccessfulSyncFunction)
 	Describe("With ShouldProcess Function", testShouldProcessFunction)
+	Describe("Skipped Resources", testSkipped)
 	Describe("Sync Errors", testSyncErrors)
 	Describe("Update Suppression", testUpdateSuppression)
 	Describe("GetResource", testGetResource)
 	Describe("ListResources", testListResources)
+	Describe("Resync", testResync)
+	Describe("Distinguish Resync", testDistinguishResync)
+	Describe("Process On Start", testProcessOnStart)
+	Describe("OnCacheSynced", testOnCacheSynced)
+	Describe("Prune On Sync", testPruneOnSync)
+	Describe("Delete Propagation Policy", testDeletePropagationPolicy)
+	Describe("Last Applied Hash Annotation", testLastAppliedHashAnnotation)
+	Describe("Content Hash Label", testContentHashLabel)
+	Describe("Before Write", testBeforeWrite)
+	Describe("System Metadata Only Update", testSystemMetadataOnlyUpdate)
+	Describe("With a MultiClusterFederator", testMultiClusterFederator)
+	Describe("ByIndex", testByIndex)
+	Describe("Debounce", testDebounce)
+	Describe("Priority", testPriority)
+	Describe("Max Queue Depth", testMaxQueueDepth)
+	Describe("List Page Size", testListPageSize)
+	Describe("Watch Bookmarks", testWatchBookmarks)
+	Describe("Last Resource Version", testLastResourceVersion)
+	Describe("Max Concurrent Reconciles", testMaxConcurrentReconciles)
+	Describe("Stop Cancellation", testStopCancellation)
+	Describe("Sync Metrics", testSyncMetrics)
+	Describe("Sync Lag", testSyncLag)
+	Describe("Work Queue Metrics", testWorkQueueMetrics)
+	Describe("Panic Recovery", testPanicRecovery)
+	Describe("Logger", testLogger)
+	Describe("Health", testHealth)
+	Describe("Resource Version Expired", testResourceVersionExpired)
+	Describe("Namespace Fallback", testNamespaceFallback)
+	Describe("EnqueueRelated", testEnqueueRelated)
+	Describe("Dependencies", testDependencies)
+	Describe("Fan-out Transform", testFanOutTransform)
+	Describe("Delete Transform", testDeleteTransform)
+	Describe("Finalizer", testFinalizer)
+	Describe("External Enqueue", testExternalEnqueue)
+	Describe("Pending Keys", testPendingKeys)
+	Describe("Custom Work Queue", testCustomWorkQueue)
+	Describe("Delete and Re-create", testDeleteAndRecreate)
+	Describe("Transform Timeout", testTransformTimeout)
+	Describe("Tracing", testTracing)
+	Describe("Resource Type Wait", testResourceTypeWait)
+	Describe("Verify On Start", testVerifyOnStart)
+	Describe("Metadata Only", testMetadataOnly)
+	Describe("Cluster-scoped Resource Type", testClusterScoped)
+	Describe("Preferred Version", testPreferredVersion)
 })
 
 func testLocalToRemote() {
@@ -156,6 +236,240 @@ func testRemoteToLocalWithoutLocalClusterID() {
 	})
 }
 
+type testWidget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              testWidgetSpec `json:"spec,omitempty"`
+}
+
+type testWidgetSpec struct {
+	Size int64 `json:"size,omitempty"`
+}
+
+func (w *testWidget) DeepCopyObject() runtime.Object {
+	c := *w
+	w.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+
+	return &c
+}
+
+func testTypedTransform() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	widgetGVK := schema.GroupVersionKind{Group: "test.submariner.io", Version: "v1", Kind: "Widget"}
+
+	var (
+		transformed chan runtime.Object
+		gvk         schema.GroupVersionKind
+	)
+
+	BeforeEach(func() {
+		transformed = make(chan runtime.Object, 10)
+		gvk = widgetGVK
+
+		d.config.Scheme.AddKnownTypeWithName(widgetGVK, &testWidget{})
+		d.config.TypedTransform = true
+		d.config.Transform = func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+			transformed <- from
+			return from, false, nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetName("test-widget")
+		obj.SetNamespace(test.LocalNamespace)
+		Expect(unstructured.SetNestedField(obj.Object, int64(3), "spec", "size")).To(Succeed())
+
+		test.CreateResource(d.sourceClient, obj)
+	})
+
+	setResourceType := func() {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		d.config.ResourceType = obj
+	}
+
+	When("the resource kind is registered in the Scheme", func() {
+		BeforeEach(setResourceType)
+
+		It("should pass the typed resource to the transform function", func() {
+			var obj runtime.Object
+			Eventually(transformed).Should(Receive(&obj))
+			Expect(obj).To(BeAssignableToTypeOf(&testWidget{}))
+			Expect(obj.(*testWidget).Spec.Size).To(Equal(int64(3)))
+
+			Eventually(d.federator.Distributed).Should(HaveLen(1))
+		})
+	})
+
+	When("the resource kind isn't registered in the Scheme", func() {
+		BeforeEach(func() {
+			gvk = schema.GroupVersionKind{Group: "test.submariner.io", Version: "v1", Kind: "Gadget"}
+			setResourceType()
+		})
+
+		It("should pass the unstructured resource to the transform function", func() {
+			var obj runtime.Object
+			Eventually(transformed).Should(Receive(&obj))
+			Expect(obj).To(BeAssignableToTypeOf(&unstructured.Unstructured{}))
+			Expect(obj.(*unstructured.Unstructured).GetKind()).To(Equal("Gadget"))
+		})
+	})
+}
+
+func testTransformWithPrevious() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	type invocation struct {
+		op       syncer.Operation
+		previous *corev1.Pod
+	}
+
+	var invocations chan invocation
+
+	BeforeEach(func() {
+		invocations = make(chan invocation, 20)
+
+		d.config.TransformWithPrevious = func(from, previous runtime.Object, numRequeues int, op syncer.Operation,
+		) (runtime.Object, bool, error) {
+			defer GinkgoRecover()
+
+			var prevPod *corev1.Pod
+
+			if previous != nil {
+				var ok bool
+				prevPod, ok = previous.(*corev1.Pod)
+				Expect(ok).To(BeTrue(), "Expected a Pod object: %#v", previous)
+			}
+
+			invocations <- invocation{op: op, previous: prevPod}
+
+			// Only sync when the image transitions.
+			if prevPod != nil && prevPod.Spec.Containers[0].Image == from.(*corev1.Pod).Spec.Containers[0].Image {
+				return nil, false, nil
+			}
+
+			return from, false, nil
+		}
+
+		d.addInitialResource(d.resource)
+	})
+
+	AfterEach(func() {
+		d.config.TransformWithPrevious = nil
+	})
+
+	JustBeforeEach(func() {
+		d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+	})
+
+	When("a resource is created in the datastore", func() {
+		It("should pass a nil previous resource", func() {
+			var i invocation
+			Eventually(invocations).Should(Receive(&i))
+			Expect(i.op).To(Equal(syncer.Create))
+			Expect(i.previous).To(BeNil())
+		})
+	})
+
+	When("a resource is updated in the datastore", func() {
+		JustBeforeEach(func() {
+			Eventually(invocations).Should(Receive())
+		})
+
+		It("should pass the previous resource from the update notification", func() {
+			updated := test.UpdateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, "updated"))
+
+			var i invocation
+			Eventually(invocations).Should(Receive(&i))
+			Expect(i.op).To(Equal(syncer.Update))
+			Expect(i.previous).ToNot(BeNil())
+			Expect(i.previous.Spec.Containers[0].Image).To(Equal(d.resource.Spec.Containers[0].Image))
+
+			d.federator.VerifyDistribute(updated)
+		})
+
+		Context("and the transform function skips it based on the previous resource", func() {
+			It("should not distribute the resource", func() {
+				d.resource.Labels["updated"] = "true"
+				test.UpdateResource(d.sourceClient, d.resource)
+
+				var i invocation
+				Eventually(invocations).Should(Receive(&i))
+				Expect(i.op).To(Equal(syncer.Update))
+				Expect(i.previous).ToNot(BeNil())
+				Expect(i.previous.Labels).ToNot(HaveKey("updated"))
+
+				d.federator.VerifyNoDistribute()
+			})
+		})
+	})
+}
+
+func testUnstructuredTransformFunction() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var transformed chan *unstructured.Unstructured
+
+	BeforeEach(func() {
+		transformed = make(chan *unstructured.Unstructured, 10)
+		d.config.ResourceType = test.ToUnstructured(&corev1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		})
+		d.config.ReadOnlyTransform = false
+		d.addInitialResource(d.resource)
+	})
+
+	getCached := func() *unstructured.Unstructured {
+		obj, exists, err := d.syncer.GetResource(d.resource.Name, d.resource.Namespace)
+		Expect(err).To(Succeed())
+		Expect(exists).To(BeTrue())
+
+		return obj.(*unstructured.Unstructured)
+	}
+
+	When("the transform function mutates its input", func() {
+		BeforeEach(func() {
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+				obj := from.(*unstructured.Unstructured)
+				obj.SetLabels(map[string]string{"mutated": "true"})
+				transformed <- obj
+
+				return obj, false, nil
+			}
+		})
+
+		It("should be passed an isolated copy", func() {
+			var obj *unstructured.Unstructured
+			Eventually(transformed).Should(Receive(&obj))
+			Expect(obj.GetLabels()).To(HaveKeyWithValue("mutated", "true"))
+
+			d.federator.VerifyDistribute(obj)
+			Expect(getCached().GetLabels()).ToNot(HaveKey("mutated"))
+		})
+	})
+
+	When("the transform function is read-only and returns its input", func() {
+		BeforeEach(func() {
+			d.config.ReadOnlyTransform = true
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+				transformed <- from.(*unstructured.Unstructured)
+				return from, false, nil
+			}
+		})
+
+		It("should be passed the cached resource and sync it", func() {
+			var obj *unstructured.Unstructured
+			Eventually(transformed).Should(Receive(&obj))
+			Expect(obj).To(Equal(getCached()))
+
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+		})
+	})
+}
+
 func testTransformFunction() {
 	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
 	ctx := context.TODO()
@@ -171,7 +485,7 @@ func testTransformFunction() {
 		transformed = test.NewPodWithImage(d.config.SourceNamespace, "transformed")
 		requeue = false
 
-		d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool) {
+		d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
 			defer GinkgoRecover()
 			atomic.AddInt32(&invocationCount, 1)
 			pod, ok := from.(*corev1.Pod)
@@ -179,7 +493,7 @@ func testTransformFunction() {
 			Expect(equality.Semantic.DeepDerivative(d.resource.Spec, pod.Spec)).To(BeTrue(),
 				"Expected:\n%#v\n to be equivalent to: \n%#v", pod.Spec, d.resource.Spec)
 			expOperation <- op
-			return transformed, requeue
+			return transformed, requeue, nil
 		}
 	})
 
@@ -291,10 +605,10 @@ func testTransformFunction() {
 
 	When("the transform function returns nil with no re-queue", func() {
 		BeforeEach(func() {
-			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool) {
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
 				atomic.AddInt32(&invocationCount, 1)
 				expOperation <- op
-				return nil, false
+				return nil, false, nil
 			}
 		})
 
@@ -332,7 +646,7 @@ func testTransformFunction() {
 		BeforeEach(func() {
 			transformFuncRet = &atomic.Value{}
 			transformFuncRet.Store(nilResource)
-			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool) {
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
 				var ret runtime.Object
 				v := transformFuncRet.Load()
 				if v != nilResource {
@@ -341,7 +655,7 @@ func testTransformFunction() {
 
 				transformFuncRet.Store(transformed)
 				expOperation <- op
-				return ret, true
+				return ret, true, nil
 			}
 		})
 
@@ -369,6 +683,149 @@ func testTransformFunction() {
 			})
 		})
 	})
+
+	When("the transform function skips an update to a previously synced resource", func() {
+		BeforeEach(func() {
+			d.addInitialResource(d.resource)
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+				expOperation <- op
+
+				if op == syncer.Update {
+					return nil, false, nil
+				}
+
+				return transformed, false, nil
+			}
+		})
+
+		It("should neither distribute nor delete the previously synced resource", func() {
+			d.federator.VerifyDistribute(test.ToUnstructured(transformed))
+			Eventually(expOperation).Should(Receive(Equal(syncer.Create)))
+
+			test.UpdateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, "apache"))
+			Eventually(expOperation).Should(Receive(Equal(syncer.Update)))
+			d.federator.VerifyNoDistribute()
+			d.federator.VerifyNoDelete()
+			Consistently(d.handledError, 300*time.Millisecond).ShouldNot(Receive(), "Error was unexpectedly logged")
+		})
+	})
+
+	When("the transform function initially returns an error", func() {
+		var expectedErr error
+
+		BeforeEach(func() {
+			expectedErr = errors.New("fake transform error")
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+				expOperation <- op
+
+				if atomic.AddInt32(&invocationCount, 1) == 1 {
+					return transformed, false, expectedErr
+				}
+
+				return transformed, false, nil
+			}
+		})
+
+		Context("and a resource is created in the datastore", func() {
+			It("should report the error and eventually distribute the transformed resource", func() {
+				test.CreateResource(d.sourceClient, d.resource)
+				Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+				d.federator.VerifyDistribute(test.ToUnstructured(transformed))
+				Eventually(expOperation).Should(Receive(Equal(syncer.Create)))
+				Eventually(expOperation).Should(Receive(Equal(syncer.Create)))
+			})
+		})
+
+		Context("and a resource is deleted in the datastore", func() {
+			BeforeEach(func() {
+				d.addInitialResource(d.resource)
+			})
+
+			It("should report the error and eventually delete the resource", func() {
+				Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+				d.federator.VerifyDistribute(test.ToUnstructured(transformed))
+
+				atomic.StoreInt32(&invocationCount, 0)
+
+				Expect(d.sourceClient.Delete(ctx, d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+				Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+				d.federator.VerifyDelete(test.ToUnstructured(transformed))
+			})
+		})
+	})
+}
+
+func testOperationsAndDirection(sourceNamespace, localClusterID, resourceClusterID string, direction syncer.SyncDirection) {
+	d := newTestDiver(sourceNamespace, localClusterID, direction)
+
+	type handlerOp struct {
+		handler string
+		op      syncer.Operation
+	}
+
+	var (
+		ops      chan handlerOp
+		registry *prometheus.Registry
+	)
+
+	BeforeEach(func() {
+		ops = make(chan handlerOp, 20)
+		registry = prometheus.NewRegistry()
+
+		test.SetClusterIDLabel(d.resource, resourceClusterID)
+
+		d.config.MetricsRegisterer = registry
+		d.config.SyncCounterOpts = &prometheus.GaugeOpts{Name: "sync_counter"}
+		d.config.ShouldProcess = func(_ *unstructured.Unstructured, op syncer.Operation) bool {
+			ops <- handlerOp{"ShouldProcess", op}
+			return true
+		}
+		d.config.Transform = func(from runtime.Object, _ int, op syncer.Operation) (runtime.Object, bool, error) {
+			ops <- handlerOp{"Transform", op}
+			return from, false, nil
+		}
+		d.config.OnSuccessfulSync = func(_ runtime.Object, op syncer.Operation) {
+			ops <- handlerOp{"OnSuccessfulSync", op}
+		}
+	})
+
+	verifyOperation := func(op syncer.Operation) {
+		for _, handler := range []string{"ShouldProcess", "Transform", "OnSuccessfulSync"} {
+			Eventually(ops).Should(Receive(Equal(handlerOp{handler, op})))
+		}
+
+		Eventually(func() map[string]string {
+			families, err := registry.Gather()
+			Expect(err).To(Succeed())
+
+			for _, family := range families {
+				for _, m := range family.GetMetric() {
+					labels := map[string]string{}
+					for _, l := range m.GetLabel() {
+						labels[l.GetName()] = l.GetValue()
+					}
+
+					if labels[syncer.OperationLabel] == op.String() {
+						return labels
+					}
+				}
+			}
+
+			return nil
+		}).Should(HaveKeyWithValue(syncer.DirectionLabel, direction.String()))
+	}
+
+	It("should pass the correct Operation to each handler and record the direction", func() {
+		test.CreateResource(d.sourceClient, d.resource)
+		verifyOperation(syncer.Create)
+
+		d.resource.Spec.Hostname = "updated"
+		test.UpdateResource(d.sourceClient, d.resource)
+		verifyOperation(syncer.Update)
+
+		Expect(d.sourceClient.Delete(context.TODO(), d.resource.Name, metav1.DeleteOptions{})).To(Succeed())
+		verifyOperation(syncer.Delete)
+	})
 }
 
 func testOnSuccessfulSyncFunction() {
@@ -433,8 +890,8 @@ func testOnSuccessfulSyncFunction() {
 		When("a resource is successfully created in the datastore", func() {
 			BeforeEach(func() {
 				expResource = test.NewPodWithImage(d.config.SourceNamespace, "transformed")
-				d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool) {
-					return expResource, false
+				d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+					return expResource, false, nil
 				}
 			})
 
@@ -587,64 +1044,289 @@ func testShouldProcessFunction() {
 	})
 }
 
-func testSyncErrors() {
+func testSkipped() {
 	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
-	ctx := context.TODO()
 
-	var expectedErr error
+	var (
+		registry *prometheus.Registry
+		skipped  chan syncer.SkipReason
+	)
 
 	BeforeEach(func() {
-		expectedErr = errors.New("fake error")
+		registry = prometheus.NewRegistry()
+		skipped = make(chan syncer.SkipReason, 100)
+
+		d.config.MetricsRegisterer = registry
+		d.config.SkippedCounterOpts = &prometheus.CounterOpts{
+			Name: "skipped_total",
+		}
+
+		d.config.OnSkipped = func(obj *unstructured.Unstructured, op syncer.Operation, reason syncer.SkipReason) {
+			Expect(obj.GetName()).To(Equal(d.resource.Name))
+			Expect(op).To(Equal(syncer.Create))
+			skipped <- reason
+		}
+
+		d.config.SourceLabelSelector = ""
+		d.config.ShouldProcess = nil
+		d.config.SkipTerminating = false
 	})
 
-	When("distribute initially fails", func() {
+	skippedCount := func(reason syncer.SkipReason) float64 {
+		families, err := registry.Gather()
+		Expect(err).To(Succeed())
+
+		for _, family := range families {
+			if family.GetName() != "skipped_total" {
+				continue
+			}
+
+			for _, m := range family.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == syncer.ReasonLabel && l.GetValue() == string(reason) {
+						return m.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+
+		return 0
+	}
+
+	verifySkipped := func(reason syncer.SkipReason) {
+		Eventually(skipped, 5).Should(Receive(Equal(reason)))
+		Expect(skippedCount(reason)).To(Equal(1.0))
+		d.federator.VerifyNoDistribute()
+	}
+
+	When("a resource is deleted after its labels were changed to no longer match the source label selector", func() {
 		BeforeEach(func() {
-			d.federator.FailOnDistribute = expectedErr
+			d.config.SourceLabelSelector = "app=test"
 		})
 
-		It("should log the error and retry until it succeeds", func() {
-			d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
-			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+		// The API server delivers a resource whose labels no longer match the selector as deleted, with its new labels.
+		It("should not skip its deletion", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			Eventually(func() int {
+				return d.federator.NumCalls(fake.OpDistribute)
+			}, 5).Should(Equal(1))
+
+			d.resource.Labels = map[string]string{"app": "other"}
+			test.UpdateResource(d.sourceClient, d.resource)
+
+			Expect(d.sourceClient.Delete(context.TODO(), d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			Eventually(func() int {
+				return d.federator.NumCalls(fake.OpDelete)
+			}, 5).Should(Equal(1))
+			Consistently(skipped).ShouldNot(Receive())
 		})
 	})
 
-	When("delete initially fails", func() {
+	When("the ShouldProcess function returns false", func() {
 		BeforeEach(func() {
-			d.federator.FailOnDelete = expectedErr
-			d.addInitialResource(d.resource)
+			d.config.ShouldProcess = func(_ *unstructured.Unstructured, _ syncer.Operation) bool {
+				return false
+			}
 		})
 
-		It("should log the error and retry until it succeeds", func() {
-			expected := test.GetResource(d.sourceClient, d.resource)
-			d.federator.VerifyDistribute(expected)
-
-			Expect(d.sourceClient.Delete(ctx, d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
-			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
-			d.federator.VerifyDelete(expected)
+		It("should record it as skipped by the predicate", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			verifySkipped(syncer.SkipReasonPredicate)
 		})
 	})
 
-	When("delete fails with not found", func() {
+	When("a resource is terminating and SkipTerminating is set", func() {
 		BeforeEach(func() {
-			d.federator.FailOnDelete = apierrors.NewNotFound(schema.GroupResource{}, "not found")
-			d.addInitialResource(d.resource)
+			d.config.SkipTerminating = true
+			now := metav1.Now()
+			d.resource.DeletionTimestamp = &now
 		})
 
-		It("should not log the error nor retry", func() {
-			expected := test.GetResource(d.sourceClient, d.resource)
-			d.federator.VerifyDistribute(expected)
-
-			Expect(d.sourceClient.Delete(ctx, d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
-			Consistently(d.handledError, 300*time.Millisecond).ShouldNot(Receive(), "Error was unexpectedly logged")
+		It("should record it as skipped as terminating", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			verifySkipped(syncer.SkipReasonTerminating)
 		})
 	})
-}
 
-func testUpdateSuppression() {
-	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+	When("a resource is terminating and SkipTerminating isn't set", func() {
+		BeforeEach(func() {
+			now := metav1.Now()
+			d.resource.DeletionTimestamp = &now
+		})
 
-	BeforeEach(func() {
-		d.addInitialResource(d.resource)
+		It("should distribute it", func() {
+			d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
+			Consistently(skipped).ShouldNot(Receive())
+		})
+	})
+
+	When("a resource's cluster ID label indicates it originated remotely", func() {
+		It("should record it as skipped by origin", func() {
+			test.CreateResource(d.sourceClient, test.SetClusterIDLabel(d.resource, "remote"))
+			verifySkipped(syncer.SkipReasonOrigin)
+		})
+	})
+
+	When("a resource isn't filtered", func() {
+		It("should not record it as skipped", func() {
+			d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
+			Consistently(skipped).ShouldNot(Receive())
+		})
+	})
+
+	When("the source label selector is invalid", func() {
+		It("should return an error", func() {
+			config := d.config
+			config.SourceLabelSelector = "app in ("
+			_, err := syncer.NewResourceSyncer(&config)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func testSyncErrors() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+	ctx := context.TODO()
+
+	var expectedErr error
+
+	BeforeEach(func() {
+		expectedErr = errors.New("fake error")
+	})
+
+	When("distribute initially fails", func() {
+		BeforeEach(func() {
+			d.federator.FailOnDistribute = expectedErr
+		})
+
+		It("should log the error and retry until it succeeds", func() {
+			d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
+			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+		})
+	})
+
+	When("delete initially fails", func() {
+		BeforeEach(func() {
+			d.federator.FailOnDelete = expectedErr
+			d.addInitialResource(d.resource)
+		})
+
+		It("should log the error and retry until it succeeds", func() {
+			expected := test.GetResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(expected)
+
+			Expect(d.sourceClient.Delete(ctx, d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+			d.federator.VerifyDelete(expected)
+		})
+	})
+
+	When("delete fails with not found", func() {
+		BeforeEach(func() {
+			d.federator.FailOnDelete = apierrors.NewNotFound(schema.GroupResource{}, "not found")
+			d.addInitialResource(d.resource)
+		})
+
+		It("should not log the error nor retry", func() {
+			expected := test.GetResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(expected)
+
+			Expect(d.sourceClient.Delete(ctx, d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			Consistently(d.handledError, 300*time.Millisecond).ShouldNot(Receive(), "Error was unexpectedly logged")
+		})
+	})
+
+	When("distribute fails with a terminal error", func() {
+		var deadLetters chan syncer.Operation
+
+		BeforeEach(func() {
+			expectedErr = apierrors.NewBadRequest("fake")
+			d.federator.FailOnDistribute = expectedErr
+			deadLetters = make(chan syncer.Operation, 10)
+			d.config.OnDeadLetter = func(resource *unstructured.Unstructured, op syncer.Operation, err error) {
+				defer GinkgoRecover()
+
+				Expect(resource.GetName()).To(Equal(d.resource.GetName()))
+				Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+				deadLetters <- op
+			}
+		})
+
+		AfterEach(func() {
+			d.config.OnDeadLetter = nil
+			d.config.IsRetryable = nil
+		})
+
+		It("should log the error, invoke the dead-letter function and not retry", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+			Eventually(deadLetters).Should(Receive(Equal(syncer.Create)))
+			d.federator.VerifyNoDistribute()
+		})
+
+		Context("and a custom IsRetryable function is specified that retries it", func() {
+			BeforeEach(func() {
+				d.config.IsRetryable = func(err error) bool {
+					return true
+				}
+			})
+
+			It("should retry until it succeeds", func() {
+				d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
+				Consistently(deadLetters).ShouldNot(Receive())
+			})
+		})
+	})
+
+	When("delete fails with a terminal error", func() {
+		BeforeEach(func() {
+			d.federator.FailOnDelete = apierrors.NewForbidden(schema.GroupResource{}, "fake", errors.New("fake"))
+			d.addInitialResource(d.resource)
+		})
+
+		It("should not retry", func() {
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+
+			Expect(d.sourceClient.Delete(ctx, d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			Eventually(d.handledError, 5).Should(Receive())
+			d.federator.VerifyNoDelete()
+		})
+	})
+
+	When("distribute fails with a service unavailable error", func() {
+		BeforeEach(func() {
+			expectedErr = apierrors.NewServiceUnavailable("fake")
+			d.federator.FailOnDistribute = expectedErr
+		})
+
+		It("should retry until it succeeds", func() {
+			d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
+			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(expectedErr)))
+		})
+	})
+}
+
+var _ = Describe("IsRetryableError", func() {
+	It("should classify errors correctly", func() {
+		gr := schema.GroupResource{}
+
+		Expect(syncer.IsRetryableError(apierrors.NewBadRequest("fake"))).To(BeFalse())
+		Expect(syncer.IsRetryableError(apierrors.NewInvalid(schema.GroupKind{}, "fake", nil))).To(BeFalse())
+		Expect(syncer.IsRetryableError(apierrors.NewForbidden(gr, "fake", errors.New("fake")))).To(BeFalse())
+		Expect(syncer.IsRetryableError(fmt.Errorf("wrapped: %w", apierrors.NewBadRequest("fake")))).To(BeFalse())
+
+		Expect(syncer.IsRetryableError(apierrors.NewConflict(gr, "fake", errors.New("fake")))).To(BeTrue())
+		Expect(syncer.IsRetryableError(apierrors.NewServiceUnavailable("fake"))).To(BeTrue())
+		Expect(syncer.IsRetryableError(apierrors.NewTimeoutError("fake", 1))).To(BeTrue())
+		Expect(syncer.IsRetryableError(errors.New("fake"))).To(BeTrue())
+	})
+})
+
+func testUpdateSuppression() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	BeforeEach(func() {
+		d.addInitialResource(d.resource)
 	})
 
 	JustBeforeEach(func() {
@@ -768,6 +1450,64 @@ func testUpdateSuppression() {
 	})
 }
 
+func testSystemMetadataOnlyUpdate() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		destClient *dynamicfake.DynamicResourceClient
+		synced     chan syncer.Operation
+	)
+
+	BeforeEach(func() {
+		dynClient := dynamicfake.NewDynamicClient(d.config.Scheme)
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(d.config.ResourceType)
+		destClient, _ = dynClient.Resource(*gvr).Namespace(test.RemoteNamespace).(*dynamicfake.DynamicResourceClient)
+
+		synced = make(chan syncer.Operation, 10)
+		d.config.OnSuccessfulSync = func(_ runtime.Object, op syncer.Operation) {
+			synced <- op
+		}
+
+		d.config.Federator = federate.NewCreateOrUpdateFederator(dynClient, restMapper, test.RemoteNamespace, "")
+		d.addInitialResource(d.resource)
+	})
+
+	When("an update differing only in the resourceVersion is received", func() {
+		It("should not process it or write to the destination", func() {
+			test.AwaitResource(destClient, d.resource.Name)
+			Eventually(synced).Should(Receive(Equal(syncer.Create)))
+
+			updated := test.GetResource(d.sourceClient, d.resource)
+			updated.SetResourceVersion("100")
+			_, err := d.sourceClient.Update(context.TODO(), updated, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+
+			destClient.VerifyNoUpdate(d.resource.Name)
+			Consistently(synced).ShouldNot(Receive())
+		})
+	})
+
+	When("an update deemed equivalent by the comparator registered for the kind is received", func() {
+		BeforeEach(func() {
+			d.config.Comparators = syncer.NewComparatorRegistry()
+			d.config.Comparators.Register(corev1.SchemeGroupVersion.WithKind("Pod"), syncer.AreSpecsEquivalent)
+		})
+
+		It("should not process it or write to the destination", func() {
+			test.AwaitResource(destClient, d.resource.Name)
+			Eventually(synced).Should(Receive(Equal(syncer.Create)))
+
+			updated := test.GetResource(d.sourceClient, d.resource)
+			updated.SetLabels(map[string]string{"new": "label"})
+			_, err := d.sourceClient.Update(context.TODO(), updated, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+
+			destClient.VerifyNoUpdate(d.resource.Name)
+			Consistently(synced).ShouldNot(Receive())
+		})
+	})
+}
+
 func testGetResource() {
 	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
 
@@ -807,6 +1547,7 @@ func testListResources() {
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "apache-pod",
 				Namespace: d.resource.Namespace,
+				Labels:    map[string]string{"app": "apache"},
 			},
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{
@@ -837,72 +1578,3886 @@ func testListResources() {
 			delete(expected, meta.GetName())
 		}
 	})
+
+	When("a label selector is specified", func() {
+		It("should return only the matching resources", func() {
+			list, err := d.syncer.ListResourcesBySelector(labels.SelectorFromSet(map[string]string{"app": "apache"}))
+			Expect(err).To(Succeed())
+			Expect(list).To(HaveLen(1))
+			Expect(list[0]).To(BeAssignableToTypeOf(&corev1.Pod{}))
+			Expect(list[0].(*corev1.Pod).Name).To(Equal(resource2.Name))
+
+			list, err = d.syncer.ListResourcesBySelector(labels.SelectorFromSet(map[string]string{"app": "nginx"}))
+			Expect(err).To(Succeed())
+			Expect(list).To(BeEmpty())
+		})
+	})
 }
 
-type testDriver struct {
-	config             syncer.ResourceSyncerConfig
-	syncer             syncer.Interface
-	sourceClient       dynamic.ResourceInterface
-	federator          *fake.Federator
-	initialResources   []runtime.Object
-	stopCh             chan struct{}
-	resource           *corev1.Pod
-	savedErrorHandlers []func(error)
-	handledError       chan error
+func testByIndex() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	const (
+		indexName       = "owner"
+		ownerAnnotation = "example.io/owner"
+	)
+
+	BeforeEach(func() {
+		d.config.Indexers = cache.Indexers{
+			indexName: func(obj interface{}) ([]string, error) {
+				owner, ok := obj.(*unstructured.Unstructured).GetAnnotations()[ownerAnnotation]
+				if !ok {
+					return nil, nil
+				}
+
+				return []string{owner}, nil
+			},
+		}
+
+		for i, owner := range []string{"alice", "bob", "alice", ""} {
+			pod := test.NewPod(d.config.SourceNamespace)
+			pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+
+			if owner != "" {
+				pod.Annotations = map[string]string{ownerAnnotation: owner}
+			}
+
+			d.addInitialResource(pod)
+		}
+	})
+
+	It("should return exactly the resources matching the index value", func() {
+		names := func(value string) []string {
+			list, err := d.syncer.ByIndex(indexName, value)
+			Expect(err).To(Succeed())
+
+			names := []string{}
+			for _, obj := range list {
+				names = append(names, obj.GetName())
+			}
+
+			return names
+		}
+
+		Eventually(func() []string {
+			return names("alice")
+		}).Should(ConsistOf(d.resource.Name+"-0", d.resource.Name+"-2"))
+
+		Expect(names("bob")).To(ConsistOf(d.resource.Name + "-1"))
+		Expect(names("carol")).To(BeEmpty())
+	})
+
+	When("the index does not exist", func() {
+		It("should return an error", func() {
+			_, err := d.syncer.ByIndex("unknown", "alice")
+			Expect(err).To(HaveOccurred())
+		})
+	})
 }
 
-func newTestDiver(sourceNamespace, localClusterID string, syncDirection syncer.SyncDirection) *testDriver {
-	resourceType := &corev1.Pod{}
-	d := &testDriver{
-		config: syncer.ResourceSyncerConfig{
-			Name:            "test",
-			SourceNamespace: sourceNamespace,
-			LocalClusterID:  localClusterID,
-			ResourceType:    resourceType,
-			Direction:       syncDirection,
-		},
-	}
+func testProcessOnStart() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		transformed chan string
+		pods        []*corev1.Pod
+	)
 
 	BeforeEach(func() {
-		d.federator = fake.New()
-		d.config.Federator = d.federator
-		d.initialResources = nil
-		d.resource = test.NewPod(sourceNamespace)
-		d.stopCh = make(chan struct{})
-		d.savedErrorHandlers = utilruntime.ErrorHandlers
-		d.handledError = make(chan error, 1000)
-		d.config.Scheme = runtime.NewScheme()
-		d.config.Transform = nil
-		d.config.OnSuccessfulSync = nil
-		d.config.ResourcesEquivalent = nil
+		transformed = make(chan string, 10)
+		processOnStart := false
+		d.config.ProcessOnStart = &processOnStart
+		d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+			transformed <- from.(*corev1.Pod).Name
+			return from, false, nil
+		}
 
-		err := corev1.AddToScheme(d.config.Scheme)
-		Expect(err).To(Succeed())
+		pods = nil
+
+		for i := 0; i < 2; i++ {
+			pod := test.NewPod(d.config.SourceNamespace)
+			pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+			pods = append(pods, pod)
+			d.addInitialResource(pod)
+		}
 	})
 
-	JustBeforeEach(func() {
-		initObjs := test.PrepInitialClientObjs(d.config.SourceNamespace, "", d.initialResources...)
+	When("ProcessOnStart is false", func() {
+		It("should not process the initial resources until they're updated", func() {
+			Consistently(transformed, 300*time.Millisecond).ShouldNot(Receive())
 
-		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(d.config.ResourceType)
+			_, exists, err := d.syncer.GetResource(pods[0].Name, pods[0].Namespace)
+			Expect(err).To(Succeed())
+			Expect(exists).To(BeTrue())
 
-		d.config.RestMapper = restMapper
-		d.config.SourceClient = fakeClient.NewSimpleDynamicClient(d.config.Scheme, initObjs...)
+			pods[1].Spec.Containers[0].Image = "apache"
+			test.UpdateResource(d.sourceClient, pods[1])
 
-		d.sourceClient = d.config.SourceClient.Resource(*gvr).Namespace(d.config.SourceNamespace)
+			Eventually(transformed).Should(Receive(Equal(pods[1].Name)))
+			Consistently(transformed, 300*time.Millisecond).ShouldNot(Receive())
+		})
 
-		var err error
-		d.syncer, err = syncer.NewResourceSyncer(&d.config)
-		Expect(err).To(Succeed())
+		It("should process the initial resources on Resync", func() {
+			Consistently(transformed, 300*time.Millisecond).ShouldNot(Receive())
 
-		utilruntime.ErrorHandlers = append(utilruntime.ErrorHandlers, func(err error) {
-			d.handledError <- err
+			d.syncer.Resync()
+
+			names := []string{}
+
+			for range pods {
+				var name string
+				Eventually(transformed).Should(Receive(&name))
+				names = append(names, name)
+			}
+
+			Expect(names).To(ConsistOf(pods[0].Name, pods[1].Name))
 		})
 
-		Expect(d.syncer.Start(d.stopCh)).To(Succeed())
+		It("should process newly created resources", func() {
+			pod := test.NewPod(d.config.SourceNamespace)
+			pod.Name = "new-pod"
+			test.CreateResource(d.sourceClient, pod)
+
+			Eventually(transformed).Should(Receive(Equal(pod.Name)))
+		})
 	})
+}
+
+func testResync() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	BeforeEach(func() {
+		d.addInitialResource(d.resource)
+	})
+
+	When("a resync is requested", func() {
+		It("should re-distribute the cached resources", func() {
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+
+			d.syncer.Resync()
+
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+		})
+	})
+}
+
+func testDistinguishResync() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var ops chan syncer.Operation
+
+	BeforeEach(func() {
+		ops = make(chan syncer.Operation, 100)
+
+		d.config.ResyncPeriod = 100 * time.Millisecond
+		d.config.DistinguishResync = true
+		d.config.Transform = func(from runtime.Object, _ int, op syncer.Operation) (runtime.Object, bool, error) {
+			ops <- op
+			return from, false, nil
+		}
+
+		d.addInitialResource(d.resource)
+	})
+
+	JustBeforeEach(func() {
+		Eventually(ops).Should(Receive(Equal(syncer.Create)))
+	})
+
+	When("a resource is re-queued by the periodic resync", func() {
+		It("should pass the Resync operation", func() {
+			Eventually(ops).Should(Receive(Equal(syncer.Resync)))
+		})
+	})
+
+	When("a resource is genuinely updated", func() {
+		It("should pass the Update operation", func() {
+			test.UpdateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, "apache"))
+			Eventually(ops).Should(Receive(Equal(syncer.Update)))
+		})
+	})
+
+	When("DistinguishResync isn't set", func() {
+		BeforeEach(func() {
+			d.config.DistinguishResync = false
+		})
+
+		It("should pass the Update operation on the periodic resync", func() {
+			Consistently(ops, 300*time.Millisecond).ShouldNot(Receive(Equal(syncer.Resync)))
+			Eventually(ops).Should(Receive(Equal(syncer.Update)))
+		})
+	})
+}
+
+func testDebounce() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var syncCount int32
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&syncCount, 0)
+		d.config.Debounce = 500 * time.Millisecond
+		d.config.OnSuccessfulSync = func(synced runtime.Object, op syncer.Operation) {
+			atomic.AddInt32(&syncCount, 1)
+		}
+	})
+
+	verifySyncCount := func(expected int) {
+		Consistently(func() int {
+			return int(atomic.LoadInt32(&syncCount))
+		}).Should(Equal(expected))
+	}
+
+	updateRepeatedly := func() *unstructured.Unstructured {
+		var updated *unstructured.Unstructured
+
+		for i := 0; i < 5; i++ {
+			d.resource.Spec.Hostname = fmt.Sprintf("host-%d", i)
+			updated = test.UpdateResource(d.sourceClient, d.resource)
+		}
+
+		return updated
+	}
+
+	When("a resource is created and updated multiple times within the debounce period", func() {
+		It("should only distribute the latest state", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(updateRepeatedly())
+			verifySyncCount(1)
+		})
+	})
+
+	When("an existing resource is updated multiple times within the debounce period", func() {
+		BeforeEach(func() {
+			d.addInitialResource(d.resource)
+		})
+
+		It("should only distribute the latest state", func() {
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+			d.federator.VerifyDistribute(updateRepeatedly())
+			verifySyncCount(2)
+		})
+
+		Context("and then deleted within the debounce period", func() {
+			It("should delete it and not distribute the updates", func() {
+				expected := test.GetResource(d.sourceClient, d.resource)
+				d.federator.VerifyDistribute(expected)
+
+				updated := updateRepeatedly()
+				Expect(d.sourceClient.Delete(context.TODO(), d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+
+				d.federator.VerifyDelete(updated)
+				d.federator.VerifyNoDistribute()
+				verifySyncCount(2)
+			})
+		})
+	})
+}
+
+func testMaxQueueDepth() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	const numResources = 5
+
+	var syncCount int32
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&syncCount, 0)
+		d.config.MaxQueueDepth = 2
+		d.config.OnSuccessfulSync = func(synced runtime.Object, op syncer.Operation) {
+			atomic.AddInt32(&syncCount, 1)
+		}
+
+		for i := 0; i < numResources; i++ {
+			pod := test.NewPod(d.config.SourceNamespace)
+			pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+			d.addInitialResource(pod)
+		}
+	})
+
+	When("the number of initial resources exceeds the max queue depth", func() {
+		It("should start and sync all of them", func() {
+			Eventually(func() int {
+				return int(atomic.LoadInt32(&syncCount))
+			}, 5).Should(Equal(numResources))
+		})
+	})
+}
+
+func testListPageSize() {
+	var (
+		resourceClient *dynamicfake.DynamicResourceClient
+		config         syncer.ResourceSyncerConfig
+		stopCh         chan struct{}
+	)
+
+	BeforeEach(func() {
+		pods := []runtime.Object{}
+
+		for i := 0; i < 5; i++ {
+			pod := test.NewPod(test.LocalNamespace)
+			pod.Name = fmt.Sprintf("pod-%d", i)
+			pods = append(pods, pod)
+		}
+
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		dynClient := dynamicfake.NewDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pods...)...)
+		resourceClient, _ = dynClient.Resource(*gvr).Namespace(test.LocalNamespace).(*dynamicfake.DynamicResourceClient)
+
+		config = syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    dynClient,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       fake.New(),
+			ResourceType:    &corev1.Pod{},
+			ListPageSize:    2,
+		}
+
+		stopCh = make(chan struct{})
+	})
+
+	It("should list the resources in pages of the configured size", func() {
+		resourceSyncer, err := syncer.NewResourceSyncer(&config)
+		Expect(err).To(Succeed())
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+
+		defer func() {
+			close(stopCh)
+			resourceSyncer.AwaitStopped()
+		}()
+
+		list, err := resourceSyncer.ListResources()
+		Expect(err).To(Succeed())
+		Expect(list).To(HaveLen(5))
+
+		options := resourceClient.ListOptions()
+		Expect(len(options)).To(BeNumerically(">=", 3))
+
+		for i := range options[:3] {
+			Expect(options[i].Limit).To(Equal(config.ListPageSize))
+		}
+
+		Expect(options[0].Continue).To(BeEmpty())
+		Expect(options[1].Continue).ToNot(BeEmpty())
+		Expect(options[2].Continue).ToNot(BeEmpty())
+	})
+}
+
+func testWatchBookmarks() {
+	var (
+		resourceClient *dynamicfake.DynamicResourceClient
+		watchReactor   *dynamicfake.WatchReactor
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+	)
+
+	BeforeEach(func() {
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		dynClient := dynamicfake.NewDynamicClient(scheme.Scheme)
+		watchReactor = dynamicfake.NewWatchReactor(&dynClient.Fake)
+		resourceClient, _ = dynClient.Resource(*gvr).Namespace(test.LocalNamespace).(*dynamicfake.DynamicResourceClient)
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    dynClient,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       fake.New(),
+			ResourceType:    &corev1.Pod{},
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("a bookmark is received before the watch is disconnected", func() {
+		It("should resume the watch from the bookmarked resource version without relisting", func() {
+			watchReactor.AwaitWatchStarted("pods")
+			Expect(watchReactor.WatchRestrictions("pods")).To(HaveLen(1))
+
+			watchReactor.SendBookmark("pods", "999")
+			watchReactor.Disconnect("pods")
+
+			Eventually(func() []testing.WatchRestrictions {
+				return watchReactor.WatchRestrictions("pods")
+			}, 5).Should(HaveLen(2))
+
+			Expect(watchReactor.WatchRestrictions("pods")[1].ResourceVersion).To(Equal("999"))
+			Expect(resourceClient.ListOptions()).To(HaveLen(1))
+		})
+	})
+}
+
+func testLastResourceVersion() {
+	var (
+		watchReactor   *dynamicfake.WatchReactor
+		fakeClock      *clock.FakeClock
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+	)
+
+	BeforeEach(func() {
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		dynClient := dynamicfake.NewDynamicClient(scheme.Scheme)
+		watchReactor = dynamicfake.NewWatchReactor(&dynClient.Fake)
+		fakeClock = clock.NewFakeClock(time.Now())
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    dynClient,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       fake.New(),
+			ResourceType:    &corev1.Pod{},
+			Clock:           fakeClock,
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	It("should advance on each watch event, including bookmarks", func() {
+		watchReactor.AwaitWatchStarted("pods")
+		Expect(resourceSyncer.LastEventTime()).To(BeZero())
+
+		sendPod := func(eventType watch.EventType, name, resourceVersion string) {
+			pod := test.NewPod(test.LocalNamespace)
+			pod.Name = name
+			pod.ResourceVersion = resourceVersion
+
+			watchReactor.SendEvent("pods", watch.Event{Type: eventType, Object: test.ToUnstructured(pod)})
+		}
+
+		verify := func(resourceVersion string) {
+			Eventually(resourceSyncer.LastResourceVersion).Should(Equal(resourceVersion))
+			Eventually(resourceSyncer.LastEventTime).Should(Equal(fakeClock.Now()))
+		}
+
+		fakeClock.Step(time.Second)
+		sendPod(watch.Added, "pod-1", "10")
+		verify("10")
+
+		fakeClock.Step(time.Second)
+		sendPod(watch.Modified, "pod-1", "11")
+		verify("11")
+
+		fakeClock.Step(time.Second)
+		watchReactor.SendBookmark("pods", "20")
+		verify("20")
+
+		fakeClock.Step(time.Second)
+		sendPod(watch.Deleted, "pod-1", "25")
+		verify("25")
+	})
+}
+
+func testHealth() {
+	var (
+		client         *fakeClient.FakeDynamicClient
+		federator      *fake.Federator
+		config         *syncer.ResourceSyncerConfig
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		watchFailing   int32
+	)
+
+	BeforeEach(func() {
+		client = fakeClient.NewSimpleDynamicClient(scheme.Scheme,
+			test.PrepInitialClientObjs(test.LocalNamespace, "", test.NewPod(test.LocalNamespace))...)
+
+		atomic.StoreInt32(&watchFailing, 0)
+		client.PrependWatchReactor("*", func(_ testing.Action) (bool, watch.Interface, error) {
+			if atomic.LoadInt32(&watchFailing) == 1 {
+				return true, nil, errors.New("mock watch error")
+			}
+
+			return false, nil, nil
+		})
+
+		federator = fake.New()
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:                  "test",
+			SourceClient:          client,
+			SourceNamespace:       test.LocalNamespace,
+			RestMapper:            restMapper,
+			Federator:             federator,
+			ResourceType:          &corev1.Pod{},
+			WatchFailureThreshold: 300 * time.Millisecond,
+			QueueDrainThreshold:   300 * time.Millisecond,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(config)
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("the informer cache hasn't synced", func() {
+		var listBlocked chan struct{}
+
+		BeforeEach(func() {
+			wait := false
+			config.WaitForCacheSync = &wait
+
+			listBlocked = make(chan struct{})
+			client.PrependReactor("list", "pods", func(_ testing.Action) (bool, runtime.Object, error) {
+				<-listBlocked
+				return false, nil, nil
+			})
+		})
+
+		It("should not be ready until it has synced", func() {
+			Consistently(resourceSyncer.Ready, 300*time.Millisecond).Should(MatchError(ContainSubstring("hasn't synced")))
+			Expect(resourceSyncer.Healthy()).To(Succeed())
+
+			close(listBlocked)
+			Eventually(resourceSyncer.Ready).Should(Succeed())
+		})
+	})
+
+	When("the informer cache has synced and the watch is established", func() {
+		It("should be ready and healthy", func() {
+			Expect(resourceSyncer.Ready()).To(Succeed())
+			Consistently(resourceSyncer.Healthy, 500*time.Millisecond).Should(Succeed())
+		})
+	})
+
+	When("watching the source resources fails for longer than the threshold", func() {
+		BeforeEach(func() {
+			atomic.StoreInt32(&watchFailing, 1)
+		})
+
+		It("should report unhealthy until the watch is re-established", func() {
+			Expect(resourceSyncer.Ready()).To(Succeed())
+			Eventually(resourceSyncer.Healthy, 3).Should(MatchError(And(ContainSubstring("watch"),
+				ContainSubstring("mock watch error"))))
+
+			atomic.StoreInt32(&watchFailing, 0)
+			Eventually(resourceSyncer.Healthy, 10).Should(Succeed())
+		})
+	})
+
+	When("watching the source resources fails and a fake clock is set", func() {
+		var (
+			fakeClock  *clock.FakeClock
+			numWatches int32
+		)
+
+		BeforeEach(func() {
+			fakeClock = clock.NewFakeClock(time.Now())
+			config.Clock = fakeClock
+			config.WatchFailureThreshold = time.Minute
+			// Stepping the clock mustn't also trip the queue drain check.
+			config.QueueDrainThreshold = time.Hour
+
+			atomic.StoreInt32(&numWatches, 0)
+			client.PrependWatchReactor("*", func(_ testing.Action) (bool, watch.Interface, error) {
+				atomic.AddInt32(&numWatches, 1)
+				return false, nil, nil
+			})
+
+			atomic.StoreInt32(&watchFailing, 1)
+		})
+
+		It("should report unhealthy once the clock passes the threshold", func() {
+			// Once the watch is retried, the previous failure has been recorded.
+			Eventually(func() int32 {
+				return atomic.LoadInt32(&numWatches)
+			}, 5).Should(BeNumerically(">=", 2))
+
+			fakeClock.Step(config.WatchFailureThreshold - time.Second)
+			Expect(resourceSyncer.Healthy()).To(Succeed())
+
+			fakeClock.Step(2 * time.Second)
+			Expect(resourceSyncer.Healthy()).To(MatchError(ContainSubstring("mock watch error")))
+		})
+	})
+
+	When("a resource persistently fails to sync and the work queue doesn't drain", func() {
+		BeforeEach(func() {
+			federator.ResetOnFailure = false
+			federator.FailOnDistribute = errors.New("mock distribute error")
+		})
+
+		It("should report unhealthy until the work queue drains", func() {
+			Eventually(resourceSyncer.Healthy, 3).Should(MatchError(ContainSubstring("work queue hasn't drained")))
+			Expect(resourceSyncer.Ready()).To(Succeed())
+
+			federator.FailOnDistribute = nil
+			Eventually(resourceSyncer.Healthy, 5).Should(Succeed())
+		})
+	})
+
+	When("writes persistently fail and a circuit breaker is configured", func() {
+		var fakeClock *clock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = clock.NewFakeClock(time.Now())
+			config.Clock = fakeClock
+			config.QueueDrainThreshold = time.Hour
+			config.CircuitBreaker = &federate.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}
+
+			federator.ResetOnFailure = false
+			federator.FailOnDistribute = errors.New("mock distribute error")
+		})
+
+		It("should pause writes and report unhealthy until a probe write succeeds after the cooldown", func() {
+			Eventually(resourceSyncer.Healthy, 3).Should(MatchError(ContainSubstring("writes to the destination are paused")))
+			Expect(federator.NumCalls(fake.OpDistribute)).To(Equal(2))
+
+			Consistently(func() int {
+				return federator.NumCalls(fake.OpDistribute)
+			}, 300*time.Millisecond).Should(Equal(2))
+
+			federator.FailOnDistribute = nil
+			fakeClock.Step(time.Minute)
+
+			Eventually(resourceSyncer.Healthy, 5).Should(Succeed())
+			Expect(federator.NumCalls(fake.OpDistribute)).To(Equal(3))
+		})
+	})
+}
+
+func testResourceVersionExpired() {
+	var (
+		client         *fakeClient.FakeDynamicClient
+		podClient      dynamic.ResourceInterface
+		federator      *fake.Federator
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		firstWatcher   *watch.FakeWatcher
+		firstWatchErr  error
+		numLists       int32
+		numWatches     int32
+	)
+
+	BeforeEach(func() {
+		firstWatchErr = nil
+		atomic.StoreInt32(&numLists, 0)
+		atomic.StoreInt32(&numWatches, 0)
+
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		client = fakeClient.NewSimpleDynamicClient(scheme.Scheme,
+			test.PrepInitialClientObjs(test.LocalNamespace, "", test.NewPod(test.LocalNamespace))...)
+		podClient = client.Resource(*gvr).Namespace(test.LocalNamespace)
+
+		client.PrependReactor("list", "*", func(_ testing.Action) (bool, runtime.Object, error) {
+			atomic.AddInt32(&numLists, 1)
+			return false, nil, nil
+		})
+
+		// The first watch is controlled by the test. Subsequent watches are delegated to the default tracker reactor and
+		// counted after the tracker has registered the watch so no events are missed.
+		firstWatcher = watch.NewFakeWithChanSize(10, false)
+		defaultWatchChain := client.WatchReactionChain
+
+		client.PrependWatchReactor("*", func(action testing.Action) (bool, watch.Interface, error) {
+			if atomic.LoadInt32(&numWatches) == 0 {
+				atomic.AddInt32(&numWatches, 1)
+
+				if firstWatchErr != nil {
+					return true, nil, firstWatchErr
+				}
+
+				return true, firstWatcher, nil
+			}
+
+			for _, reactor := range defaultWatchChain {
+				if !reactor.Handles(action) {
+					continue
+				}
+
+				handled, w, err := reactor.React(action)
+				if handled {
+					atomic.AddInt32(&numWatches, 1)
+					return true, w, err
+				}
+			}
+
+			return false, nil, nil
+		})
+
+		federator = fake.New()
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:                  "test",
+			SourceClient:          client,
+			SourceNamespace:       test.LocalNamespace,
+			RestMapper:            restMapper,
+			Federator:             federator,
+			ResourceType:          &corev1.Pod{},
+			WatchFailureThreshold: 100 * time.Millisecond,
+		})
+		Expect(err).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+
+		Eventually(federator.Distributed).Should(HaveLen(1))
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	verifyRelistedAndResumed := func() {
+		Eventually(func() int32 {
+			return atomic.LoadInt32(&numWatches)
+		}, 5).Should(Equal(int32(2)))
+		Expect(atomic.LoadInt32(&numLists)).To(Equal(int32(2)))
+
+		Eventually(federator.Distributed).Should(HaveLen(2))
+
+		next := test.NewPod(test.LocalNamespace)
+		next.Name = "next-pod"
+		test.CreateResource(podClient, next)
+
+		Eventually(federator.Distributed).Should(HaveLen(3))
+		Expect(resourceSyncer.Healthy()).To(Succeed())
+	}
+
+	When("the watch is closed with a 410 Gone error event", func() {
+		It("should relist and continue delivering events", func() {
+			firstWatcher.Error(&apierrors.NewResourceExpired("too old resource version").ErrStatus)
+
+			missed := test.NewPod(test.LocalNamespace)
+			missed.Name = "missed-pod"
+			test.CreateResource(podClient, missed)
+
+			verifyRelistedAndResumed()
+		})
+	})
+
+	When("the watch request fails with a 410 Gone error", func() {
+		BeforeEach(func() {
+			firstWatchErr = apierrors.NewResourceExpired("too old resource version")
+		})
+
+		It("should relist and continue delivering events without reporting unhealthy", func() {
+			missed := test.NewPod(test.LocalNamespace)
+			missed.Name = "missed-pod"
+			test.CreateResource(podClient, missed)
+
+			time.Sleep(200 * time.Millisecond)
+			Expect(resourceSyncer.Healthy()).To(Succeed())
+
+			verifyRelistedAndResumed()
+		})
+	})
+}
+
+func testNamespaceFallback() {
+	const fallbackNamespace = "fallback-ns"
+
+	var (
+		client          *fakeClient.FakeDynamicClient
+		federator       *fake.Federator
+		config          *syncer.ResourceSyncerConfig
+		resourceSyncer  syncer.Interface
+		stopCh          chan struct{}
+		forbiddenVerbs  map[string]bool
+		forbidNamespace func(ns string) bool
+	)
+
+	newPod := func(namespace, name string) *corev1.Pod {
+		pod := test.NewPod(namespace)
+		pod.Name = name
+
+		return pod
+	}
+
+	BeforeEach(func() {
+		forbiddenVerbs = map[string]bool{"list": true, "watch": true}
+		forbidNamespace = func(ns string) bool {
+			return ns == metav1.NamespaceAll
+		}
+
+		client = fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "",
+			newPod(fallbackNamespace, "permitted"), newPod("other-ns", "not-permitted"))...)
+
+		client.PrependReactor("*", "*", func(action testing.Action) (bool, runtime.Object, error) {
+			if forbiddenVerbs[action.GetVerb()] && forbidNamespace(action.GetNamespace()) {
+				return true, nil, apierrors.NewForbidden(action.GetResource().GroupResource(), "", errors.New("RBAC denied"))
+			}
+
+			return false, nil, nil
+		})
+
+		client.PrependWatchReactor("*", func(action testing.Action) (bool, watch.Interface, error) {
+			if forbiddenVerbs["watch"] && forbidNamespace(action.GetNamespace()) {
+				return true, nil, apierrors.NewForbidden(action.GetResource().GroupResource(), "", errors.New("RBAC denied"))
+			}
+
+			return false, nil, nil
+		})
+
+		federator = fake.New()
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:                  "test",
+			SourceClient:          client,
+			SourceNamespace:       metav1.NamespaceAll,
+			FallbackNamespace:     fallbackNamespace,
+			RestMapper:            restMapper,
+			Federator:             federator,
+			ResourceType:          &corev1.Pod{},
+			WatchFailureThreshold: 100 * time.Millisecond,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(config)
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	verifyFallenBack := func() {
+		Eventually(func() bool {
+			_, found := federator.GetDistributed(fallbackNamespace + "/permitted")
+			return found
+		}, 5).Should(BeTrue())
+
+		test.CreateResource(client.Resource(*test.GetGroupVersionResourceFor(config.RestMapper, &corev1.Pod{})).
+			Namespace(fallbackNamespace), newPod(fallbackNamespace, "added"))
+
+		Eventually(func() bool {
+			_, found := federator.GetDistributed(fallbackNamespace + "/added")
+			return found
+		}, 5).Should(BeTrue())
+
+		_, found := federator.GetDistributed("other-ns/not-permitted")
+		Expect(found).To(BeFalse())
+		Expect(resourceSyncer.Healthy()).To(Succeed())
+	}
+
+	When("listing in all namespaces is forbidden", func() {
+		It("should fall back to listing and watching the fallback namespace", func() {
+			verifyFallenBack()
+		})
+	})
+
+	When("only watching in all namespaces is forbidden", func() {
+		BeforeEach(func() {
+			forbiddenVerbs["list"] = false
+		})
+
+		It("should fall back to listing and watching the fallback namespace", func() {
+			verifyFallenBack()
+		})
+	})
+
+	When("listing in the fallback namespace is also forbidden", func() {
+		BeforeEach(func() {
+			forbidNamespace = func(_ string) bool {
+				return true
+			}
+
+			wait := false
+			config.WaitForCacheSync = &wait
+		})
+
+		It("should report the missing permission", func() {
+			Eventually(resourceSyncer.Healthy, 3).Should(MatchError(ContainSubstring(
+				fmt.Sprintf("not permitted to list \"pods\" in all namespaces or in the fallback namespace %q", fallbackNamespace))))
+			Expect(federator.Distributed()).To(BeEmpty())
+		})
+	})
+
+	When("no fallback namespace is specified and listing in all namespaces is forbidden", func() {
+		BeforeEach(func() {
+			config.FallbackNamespace = ""
+
+			wait := false
+			config.WaitForCacheSync = &wait
+		})
+
+		It("should report the missing permission", func() {
+			Eventually(resourceSyncer.Healthy, 3).Should(MatchError(ContainSubstring(
+				"not permitted to list \"pods\" in all namespaces - grant the list and watch verbs")))
+		})
+	})
+}
+
+func testEnqueueRelated() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		related    *corev1.Pod
+		synced     chan string
+		mapRelated int32
+	)
+
+	BeforeEach(func() {
+		related = test.NewPod(test.LocalNamespace)
+		related.Name = "related-pod"
+
+		d.addInitialResource(d.resource)
+		d.addInitialResource(related)
+
+		synced = make(chan string, 50)
+		d.config.OnSuccessfulSync = func(obj runtime.Object, op syncer.Operation) {
+			synced <- resource.ToMeta(obj).GetName() + ":" + op.String()
+		}
+
+		atomic.StoreInt32(&mapRelated, 0)
+		d.config.EnqueueRelated = func(changed *unstructured.Unstructured) []string {
+			if atomic.LoadInt32(&mapRelated) == 0 || changed.GetName() != d.resource.Name {
+				return nil
+			}
+
+			return []string{
+				test.LocalNamespace + "/" + related.Name,
+				test.LocalNamespace + "/" + changed.GetName(),
+				test.LocalNamespace + "/missing",
+			}
+		}
+	})
+
+	JustBeforeEach(func() {
+		Eventually(synced).Should(Receive())
+		Eventually(synced).Should(Receive())
+		atomic.StoreInt32(&mapRelated, 1)
+	})
+
+	When("a resource is updated", func() {
+		It("should re-process the related resources", func() {
+			changed := test.GetResource(d.sourceClient, d.resource)
+			changed.SetLabels(map[string]string{"changed": "true"})
+			_, err := d.sourceClient.Update(context.TODO(), changed, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+
+			Eventually(synced).Should(Receive(Equal(d.resource.Name + ":update")))
+			Eventually(synced).Should(Receive(Equal(related.Name + ":update")))
+			Consistently(synced).ShouldNot(Receive())
+		})
+	})
+
+	When("a resource is deleted", func() {
+		It("should re-process the related resources", func() {
+			Expect(d.sourceClient.Delete(context.TODO(), d.resource.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			Eventually(synced).Should(Receive(Equal(d.resource.Name + ":delete")))
+			Eventually(synced).Should(Receive(Equal(related.Name + ":update")))
+			Consistently(synced).ShouldNot(Receive())
+		})
+	})
+}
+
+func testDependencies() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	const (
+		indexName           = "configMap"
+		configMapAnnotation = "example.io/config-map"
+	)
+
+	var (
+		configMap *corev1.ConfigMap
+		synced    chan string
+	)
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "shared-config",
+				Namespace: test.LocalNamespace,
+			},
+		}
+
+		d.addInitialResource(configMap)
+
+		for i, ref := range []string{configMap.Name, "other-config", configMap.Name} {
+			pod := test.NewPod(test.LocalNamespace)
+			pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+			pod.Annotations = map[string]string{configMapAnnotation: ref}
+
+			d.addInitialResource(pod)
+		}
+
+		synced = make(chan string, 50)
+		d.config.OnSuccessfulSync = func(obj runtime.Object, op syncer.Operation) {
+			synced <- resource.ToMeta(obj).GetName() + ":" + op.String()
+		}
+
+		d.config.Indexers = cache.Indexers{
+			indexName: func(obj interface{}) ([]string, error) {
+				pod := obj.(*unstructured.Unstructured)
+				return []string{pod.GetNamespace() + "/" + pod.GetAnnotations()[configMapAnnotation]}, nil
+			},
+		}
+
+		d.config.Dependencies = []syncer.Dependency{
+			{
+				ResourceType: &corev1.ConfigMap{},
+				IndexName:    indexName,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		for i := 0; i < 3; i++ {
+			Eventually(synced).Should(Receive(HaveSuffix(":create")))
+		}
+	})
+
+	When("a referenced resource is deleted", func() {
+		It("should re-process all the resources referencing it", func() {
+			configMapClient := d.config.SourceClient.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace(
+				test.LocalNamespace)
+			Expect(configMapClient.Delete(context.TODO(), configMap.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			var updated []string
+			for i := 0; i < 2; i++ {
+				var name string
+				Eventually(synced).Should(Receive(&name))
+				updated = append(updated, name)
+			}
+
+			Expect(updated).To(ConsistOf(d.resource.Name+"-0:update", d.resource.Name+"-2:update"))
+			Consistently(synced).ShouldNot(Receive())
+		})
+	})
+
+	When("the dependency's index isn't registered", func() {
+		It("should fail to create the syncer", func() {
+			d.config.Indexers = nil
+
+			_, err := syncer.NewResourceSyncer(&d.config)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func testDeleteTransform() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		other              *corev1.Pod
+		transformedDeletes int32
+	)
+
+	derivedName := func(name string) string {
+		return "derived-" + name
+	}
+
+	BeforeEach(func() {
+		other = test.NewPod(test.LocalNamespace)
+		other.Name = "other-pod"
+
+		d.addInitialResource(d.resource)
+		d.addInitialResource(other)
+
+		atomic.StoreInt32(&transformedDeletes, 0)
+
+		d.config.Transform = func(from runtime.Object, _ int, op syncer.Operation) (runtime.Object, bool, error) {
+			if op == syncer.Delete {
+				atomic.AddInt32(&transformedDeletes, 1)
+			}
+
+			pod := from.(*corev1.Pod).DeepCopy()
+			pod.Name = derivedName(pod.Name)
+
+			return pod, false, nil
+		}
+
+		d.config.DeleteTransform = func(from runtime.Object, _ int, op syncer.Operation) (runtime.Object, bool, error) {
+			defer GinkgoRecover()
+			Expect(op).To(Equal(syncer.Delete))
+
+			deleted := resource.ToMeta(from)
+
+			return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      derivedName(deleted.GetName()),
+				Namespace: deleted.GetNamespace(),
+			}}, false, nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		Eventually(d.federator.Distributed).Should(HaveLen(2))
+	})
+
+	When("a source resource whose derived resource has a different name is deleted", func() {
+		It("should delete the derived resource identified by the DeleteTransform function", func() {
+			Expect(d.sourceClient.Delete(context.TODO(), d.resource.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			Eventually(func() int {
+				return d.federator.NumCalls(fake.OpDelete)
+			}).Should(Equal(1))
+
+			_, found := d.federator.GetDistributed(test.LocalNamespace + "/" + derivedName(d.resource.Name))
+			Expect(found).To(BeFalse())
+
+			_, found = d.federator.GetDistributed(test.LocalNamespace + "/" + derivedName(other.Name))
+			Expect(found).To(BeTrue())
+
+			Expect(atomic.LoadInt32(&transformedDeletes)).To(BeZero())
+		})
+	})
+}
+
+func testFinalizer() {
+	const finalizerName = "test-finalizer"
+
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		sourceClient resource.Interface
+		failCleanup  int32
+		cleanedUp    chan string
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&failCleanup, 0)
+		cleanedUp = make(chan string, 10)
+
+		d.config.Finalizer = finalizerName
+		d.config.OnCleanup = func(obj runtime.Object) error {
+			if atomic.LoadInt32(&failCleanup) == 1 {
+				return errors.New("mock cleanup error")
+			}
+
+			cleanedUp <- resource.ToMeta(obj).GetName()
+
+			return nil
+		}
+
+		d.addInitialResource(d.resource)
+	})
+
+	JustBeforeEach(func() {
+		sourceClient = resource.ForDynamic(d.sourceClient)
+	})
+
+	markDeleted := func() {
+		obj := test.GetResource(d.sourceClient, d.resource)
+		now := metav1.Now()
+		obj.SetDeletionTimestamp(&now)
+
+		_, err := d.sourceClient.Update(context.TODO(), obj, metav1.UpdateOptions{})
+		Expect(err).To(Succeed())
+	}
+
+	When("a resource is first synced", func() {
+		It("should add the finalizer to the source resource", func() {
+			tests.AwaitFinalizer(sourceClient, d.resource.Name, finalizerName)
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+		})
+	})
+
+	When("a resource carrying the finalizer is being deleted", func() {
+		JustBeforeEach(func() {
+			tests.AwaitFinalizer(sourceClient, d.resource.Name, finalizerName)
+			markDeleted()
+		})
+
+		It("should invoke the cleanup function and then remove the finalizer", func() {
+			Eventually(cleanedUp).Should(Receive(Equal(d.resource.Name)))
+			tests.AwaitNoFinalizer(sourceClient, d.resource.Name, finalizerName)
+		})
+
+		Context("and the cleanup function fails", func() {
+			BeforeEach(func() {
+				atomic.StoreInt32(&failCleanup, 1)
+			})
+
+			It("should retain the finalizer and retry the cleanup", func() {
+				Consistently(func() []string {
+					return tests.GetFinalizers(sourceClient, d.resource.Name)
+				}, 300*time.Millisecond).Should(ContainElement(finalizerName))
+
+				atomic.StoreInt32(&failCleanup, 0)
+
+				Eventually(cleanedUp, 3).Should(Receive(Equal(d.resource.Name)))
+				tests.AwaitNoFinalizer(sourceClient, d.resource.Name, finalizerName)
+			})
+		})
+	})
+}
+
+func testExternalEnqueue() {
+	var (
+		client         *fakeClient.FakeDynamicClient
+		podClient      dynamic.ResourceInterface
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		pod            *corev1.Pod
+		transformed    chan string
+		triggers       chan interface{}
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		client = fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...)
+		podClient = client.Resource(*gvr).Namespace(test.LocalNamespace)
+
+		// Watch events aren't delivered so resources created later are only known via a live get.
+		client.PrependWatchReactor("*", func(_ testing.Action) (bool, watch.Interface, error) {
+			return true, watch.NewFake(), nil
+		})
+
+		transformed = make(chan string, 10)
+		triggers = make(chan interface{}, 10)
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    client,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       fake.New(),
+			ResourceType:    &corev1.Pod{},
+			Transform: func(from runtime.Object, _ int, op syncer.Operation) (runtime.Object, bool, error) {
+				transformed <- resource.ToMeta(from).GetName() + ":" + op.String()
+				return from, false, nil
+			},
+			ExternalTriggers: triggers,
+			ExternalTriggerKeys: func(trigger interface{}) []string {
+				if name, ok := trigger.(string); ok && name != "" {
+					return []string{test.LocalNamespace + "/" + name}
+				}
+
+				return nil
+			},
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+
+		Eventually(transformed).Should(Receive(Equal(pod.Name + ":create")))
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("a cached resource is enqueued", func() {
+		It("should process it", func() {
+			resourceSyncer.Enqueue(pod.Namespace, pod.Name)
+			Eventually(transformed).Should(Receive(Equal(pod.Name + ":update")))
+
+			resourceSyncer.EnqueueKey(pod.Namespace + "/" + pod.Name)
+			Eventually(transformed).Should(Receive(Equal(pod.Name + ":update")))
+		})
+	})
+
+	When("an external trigger is received for a cached resource", func() {
+		It("should process it as for an informer update event", func() {
+			triggers <- pod.Name
+			Eventually(transformed).Should(Receive(Equal(pod.Name + ":update")))
+			Consistently(transformed).ShouldNot(Receive())
+		})
+	})
+
+	When("an external trigger maps to no keys", func() {
+		It("should not process any resource", func() {
+			triggers <- ""
+			Consistently(transformed).ShouldNot(Receive())
+		})
+	})
+
+	When("a resource not in the cache is enqueued", func() {
+		It("should retrieve it live and process it", func() {
+			uncached := test.NewPod(test.LocalNamespace)
+			uncached.Name = "uncached-pod"
+			test.CreateResource(podClient, uncached)
+
+			resourceSyncer.Enqueue(uncached.Namespace, uncached.Name)
+			Eventually(transformed).Should(Receive(Equal(uncached.Name + ":update")))
+		})
+	})
+
+	When("a non-existent resource is enqueued", func() {
+		It("should not process it", func() {
+			resourceSyncer.EnqueueKey(test.LocalNamespace + "/missing")
+			Consistently(transformed).ShouldNot(Receive())
+		})
+	})
+}
+
+func testPendingKeys() {
+	var (
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		pod            *corev1.Pod
+		processing     chan string
+		release        chan struct{}
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		processing = make(chan string, 10)
+		release = make(chan struct{})
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...),
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       fake.New(),
+			ResourceType:    &corev1.Pod{},
+			Transform: func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				processing <- resource.ToMeta(from).GetName()
+				<-release
+
+				return from, false, nil
+			},
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+
+		Eventually(processing).Should(Receive(Equal(pod.Name)))
+	})
+
+	AfterEach(func() {
+		close(release)
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("keys are enqueued while the worker is busy", func() {
+		It("should return exactly the queued keys", func() {
+			Expect(resourceSyncer.PendingKeys()).To(BeEmpty())
+
+			resourceSyncer.Enqueue(test.LocalNamespace, "pod-b")
+			resourceSyncer.Enqueue(test.LocalNamespace, "pod-a")
+			resourceSyncer.EnqueueKey(test.LocalNamespace + "/pod-c")
+			resourceSyncer.Enqueue(test.LocalNamespace, "pod-a")
+
+			Expect(resourceSyncer.PendingKeys()).To(Equal([]string{
+				test.LocalNamespace + "/pod-a",
+				test.LocalNamespace + "/pod-b",
+				test.LocalNamespace + "/pod-c",
+			}))
+
+			Consistently(resourceSyncer.PendingKeys).Should(HaveLen(3))
+		})
+	})
+}
+
+type recordingWorkQueue struct {
+	k8sworkqueue.RateLimitingInterface
+	mutex sync.Mutex
+	calls []string
+}
+
+func newRecordingWorkQueue() *recordingWorkQueue {
+	return &recordingWorkQueue{RateLimitingInterface: k8sworkqueue.NewRateLimitingQueue(
+		k8sworkqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, time.Second))}
+}
+
+func (q *recordingWorkQueue) record(call string, item interface{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if item != nil {
+		call += " " + item.(string)
+	}
+
+	q.calls = append(q.calls, call)
+}
+
+func (q *recordingWorkQueue) getCalls() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return append([]string(nil), q.calls...)
+}
+
+func (q *recordingWorkQueue) Add(item interface{}) {
+	q.record("Add", item)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *recordingWorkQueue) AddRateLimited(item interface{}) {
+	q.record("AddRateLimited", item)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+func (q *recordingWorkQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if !shutdown {
+		q.record("Get", item)
+	}
+
+	return item, shutdown
+}
+
+func (q *recordingWorkQueue) Done(item interface{}) {
+	q.record("Done", item)
+	q.RateLimitingInterface.Done(item)
+}
+
+func (q *recordingWorkQueue) Forget(item interface{}) {
+	q.record("Forget", item)
+	q.RateLimitingInterface.Forget(item)
+}
+
+func (q *recordingWorkQueue) ShutDown() {
+	q.record("ShutDown", nil)
+	q.RateLimitingInterface.ShutDown()
+}
+
+func testCustomWorkQueue() {
+	var (
+		resourceSyncer syncer.Interface
+		federator      *fake.Federator
+		queue          *recordingWorkQueue
+		stopCh         chan struct{}
+		stopped        bool
+		pod            *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		federator = fake.New()
+		queue = newRecordingWorkQueue()
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...),
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			ResourceType:    &corev1.Pod{},
+			WorkQueue:       queue,
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		stopped = false
+	})
+
+	JustBeforeEach(func() {
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	stop := func() {
+		if !stopped {
+			stopped = true
+
+			close(stopCh)
+			resourceSyncer.AwaitStopped()
+		}
+	}
+
+	AfterEach(stop)
+
+	When("a resource is successfully synced", func() {
+		It("should drive the work queue with the expected call sequence", func() {
+			key := test.LocalNamespace + "/" + pod.Name
+
+			Eventually(func() []string {
+				return queue.getCalls()
+			}).Should(Equal([]string{"AddRateLimited " + key, "Get " + key, "Forget " + key, "Done " + key}))
+
+			_, found := federator.GetDistributed(key)
+			Expect(found).To(BeTrue())
+
+			stop()
+			Expect(queue.getCalls()).To(HaveLen(5))
+			Expect(queue.getCalls()[4]).To(Equal("ShutDown"))
+		})
+	})
+
+	When("a resource fails to sync and is retried", func() {
+		BeforeEach(func() {
+			federator.FailOnDistribute = errors.New("fake error")
+		})
+
+		It("should re-add it rate limited and forget it once processed successfully", func() {
+			key := test.LocalNamespace + "/" + pod.Name
+
+			Eventually(func() []string {
+				return queue.getCalls()
+			}, 3).Should(Equal([]string{
+				"AddRateLimited " + key, "Get " + key, "AddRateLimited " + key, "Done " + key,
+				"Get " + key, "Forget " + key, "Done " + key,
+			}))
+
+			Expect(resourceSyncer.PendingKeys()).To(BeEmpty())
+		})
+	})
+}
+
+func testDeleteAndRecreate() {
+	var (
+		podClient      dynamic.ResourceInterface
+		federator      *fake.Federator
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		pod            *corev1.Pod
+		blocker        *corev1.Pod
+		blocked        chan struct{}
+		release        chan struct{}
+		syncedOps      chan syncer.Operation
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		pod.UID = "original-uid"
+
+		blocker = test.NewPod(test.LocalNamespace)
+		blocker.Name = "blocker-pod"
+
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		client := fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...)
+		podClient = client.Resource(*gvr).Namespace(test.LocalNamespace)
+
+		federator = fake.New()
+		blocked = make(chan struct{})
+		release = make(chan struct{})
+		syncedOps = make(chan syncer.Operation, 10)
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    client,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			ResourceType:    &corev1.Pod{},
+			Transform: func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				if resource.ToMeta(from).GetName() == blocker.Name {
+					close(blocked)
+					<-release
+				}
+
+				return from, false, nil
+			},
+			OnSuccessfulSync: func(synced runtime.Object, op syncer.Operation) {
+				if resource.ToMeta(synced).GetName() == pod.Name {
+					syncedOps <- op
+				}
+			},
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+
+		Eventually(syncedOps).Should(Receive(Equal(syncer.Create)))
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("a resource is deleted and re-created before the delete is processed", func() {
+		It("should sync the re-created resource via an update without deleting it", func() {
+			// Occupy the worker so the delete and create are queued.
+			test.CreateResource(podClient, blocker)
+			Eventually(blocked).Should(BeClosed())
+
+			Expect(podClient.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})).To(Succeed())
+			Eventually(func() bool {
+				_, exists, _ := resourceSyncer.GetResource(pod.Name, pod.Namespace)
+				return exists
+			}).Should(BeFalse())
+
+			pod.UID = "recreated-uid"
+			test.CreateResource(podClient, pod)
+			Eventually(func() types.UID {
+				obj, _, _ := resourceSyncer.GetResource(pod.Name, pod.Namespace)
+				if obj == nil {
+					return ""
+				}
+
+				return resource.ToMeta(obj).GetUID()
+			}).Should(Equal(pod.UID))
+
+			// The informer adds a resource to its store before invoking the handler that queues it so wait for a subsequently
+			// created resource to ensure the re-create was queued.
+			marker := test.NewPod(test.LocalNamespace, test.WithName("marker-pod"))
+			test.CreateResource(podClient, marker)
+			Eventually(func() bool {
+				_, exists, _ := resourceSyncer.GetResource(marker.Name, marker.Namespace)
+				return exists
+			}).Should(BeTrue())
+
+			close(release)
+
+			Eventually(syncedOps).Should(Receive(Equal(syncer.Update)))
+
+			distributed, found := federator.GetDistributed(test.LocalNamespace + "/" + pod.Name)
+			Expect(found).To(BeTrue())
+			Expect(resource.ToMeta(distributed).GetUID()).To(Equal(pod.UID))
+
+			Consistently(func() int {
+				return federator.NumCalls(fake.OpDelete)
+			}).Should(BeZero())
+			Consistently(syncedOps).ShouldNot(Receive())
+		})
+	})
+}
+
+func testTransformTimeout() {
+	var (
+		config    *syncer.ResourceSyncerConfig
+		federator *fake.Federator
+		blocked   *corev1.Pod
+		other     *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		blocked = test.NewPod(test.LocalNamespace)
+		blocked.Name = "blocked-pod"
+
+		other = test.NewPod(test.LocalNamespace)
+		other.Name = "other-pod"
+
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		federator = fake.New()
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:             "test",
+			SourceClient:     fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", blocked, other)...),
+			SourceNamespace:  test.LocalNamespace,
+			RestMapper:       restMapper,
+			Federator:        federator,
+			ResourceType:     &corev1.Pod{},
+			TransformTimeout: 100 * time.Millisecond,
+		}
+	})
+
+	When("a context-aware transform blocks past the timeout", func() {
+		var (
+			resourceSyncer syncer.Interface
+			stopCh         chan struct{}
+			cancelled      chan error
+			exited         chan struct{}
+			invocations    int32
+			running        int32
+			overlapped     int32
+		)
+
+		BeforeEach(func() {
+			cancelled = make(chan error, 10)
+			exited = make(chan struct{})
+			atomic.StoreInt32(&invocations, 0)
+			atomic.StoreInt32(&running, 0)
+			atomic.StoreInt32(&overlapped, 0)
+
+			config.TransformWithContext = func(ctx context.Context, from runtime.Object, _ int, _ syncer.Operation,
+			) (runtime.Object, bool, error) {
+				if resource.ToMeta(from).GetName() != blocked.Name {
+					return from, false, nil
+				}
+
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.StoreInt32(&overlapped, 1)
+				}
+
+				defer atomic.AddInt32(&running, -1)
+
+				if atomic.AddInt32(&invocations, 1) == 1 {
+					defer close(exited)
+
+					<-ctx.Done()
+					cancelled <- ctx.Err()
+
+					// Linger after the cancellation to verify the retry doesn't run concurrently.
+					time.Sleep(200 * time.Millisecond)
+
+					return nil, false, ctx.Err()
+				}
+
+				return from, false, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+
+			resourceSyncer, err = syncer.NewResourceSyncer(config)
+			Expect(err).To(Succeed())
+
+			stopCh = make(chan struct{})
+			Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			close(stopCh)
+			resourceSyncer.AwaitStopped()
+		})
+
+		It("should cancel its context and re-queue the resource once it has returned", func() {
+			Eventually(cancelled).Should(Receive(Equal(context.DeadlineExceeded)))
+			Eventually(exited).Should(BeClosed())
+
+			Eventually(func() bool {
+				_, found := federator.GetDistributed(test.LocalNamespace + "/" + blocked.Name)
+				return found
+			}, 3).Should(BeTrue())
+			Expect(atomic.LoadInt32(&invocations)).To(Equal(int32(2)))
+			Expect(atomic.LoadInt32(&overlapped)).To(BeZero(), "The retried transform overlapped the timed-out one")
+			Expect(atomic.LoadInt32(&running)).To(BeZero())
+		})
+	})
+
+	When("the transform isn't context-aware", func() {
+		BeforeEach(func() {
+			config.Transform = func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				return from, false, nil
+			}
+		})
+
+		It("should return an error", func() {
+			_, err := syncer.NewResourceSyncer(config)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func testTracing() {
+	var (
+		config         *syncer.ResourceSyncerConfig
+		federator      *fake.Federator
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		pod            *corev1.Pod
+		recorder       *spanRecorder
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		pod.Annotations = map[string]string{syncer.TraceParentAnnotation: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+
+		recorder = &spanRecorder{}
+
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		federator = fake.New()
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...),
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			ResourceType:    &corev1.Pod{},
+			Direction:       syncer.LocalToRemote,
+			TracerProvider:  recorder,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(config)
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("a resource is successfully synced", func() {
+		It("should record a span with the expected attributes and an OK status", func() {
+			Eventually(recorder.ended).Should(HaveLen(1))
+
+			span := recorder.ended()[0]
+			Expect(span.tracerName).To(Equal(syncer.TracerName))
+			Expect(span.name).To(Equal(syncer.ReconcileSpanName))
+			Expect(span.linkedTraceParent).To(Equal(pod.Annotations[syncer.TraceParentAnnotation]))
+			Expect(span.attributes).To(Equal(map[string]string{
+				syncer.SpanSyncerNameAttribute: config.Name,
+				syncer.SpanResourceAttribute:   test.LocalNamespace + "/" + pod.Name,
+				syncer.SpanDirectionAttribute:  syncer.LocalToRemote.String(),
+				syncer.SpanOperationAttribute:  syncer.Create.String(),
+				syncer.SpanResultAttribute:     syncer.SpanResultSynced,
+			}))
+			Expect(span.status).To(Equal(syncer.SpanStatusOK))
+			Expect(span.errors).To(BeEmpty())
+		})
+	})
+
+	When("syncing a resource fails", func() {
+		BeforeEach(func() {
+			federator.FailDistributeFor(test.LocalNamespace+"/"+pod.Name, apierrors.NewBadRequest("fake error"))
+		})
+
+		It("should record a span with an error status", func() {
+			Eventually(recorder.ended).Should(HaveLen(1))
+
+			span := recorder.ended()[0]
+			Expect(span.attributes).To(HaveKeyWithValue(syncer.SpanResultAttribute, syncer.SpanResultFailed))
+			Expect(span.status).To(Equal(syncer.SpanStatusError))
+			Expect(span.statusDescription).To(ContainSubstring("fake error"))
+			Expect(span.errors).To(HaveLen(1))
+		})
+	})
+
+	When("a TracerProvider isn't specified", func() {
+		BeforeEach(func() {
+			config.TracerProvider = nil
+		})
+
+		It("should sync without recording spans", func() {
+			Eventually(func() bool {
+				_, found := federator.GetDistributed(test.LocalNamespace + "/" + pod.Name)
+				return found
+			}).Should(BeTrue())
+			Expect(recorder.ended()).To(BeEmpty())
+		})
+	})
+}
+
+type recordedSpan struct {
+	tracerName        string
+	name              string
+	linkedTraceParent string
+	attributes        map[string]string
+	errors            []error
+	status            syncer.SpanStatusCode
+	statusDescription string
+	recorder          *spanRecorder
+}
+
+func (s *recordedSpan) SetAttribute(key, value string) {
+	s.attributes[key] = value
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.errors = append(s.errors, err)
+}
+
+func (s *recordedSpan) SetStatus(code syncer.SpanStatusCode, description string) {
+	s.status = code
+	s.statusDescription = description
+}
+
+func (s *recordedSpan) End() {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.recorder.spans = append(s.recorder.spans, s)
+}
+
+type spanRecorder struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordingTracer struct {
+	name     string
+	recorder *spanRecorder
+}
+
+func (r *spanRecorder) Tracer(name string) syncer.Tracer {
+	return &recordingTracer{name: name, recorder: r}
+}
+
+func (r *spanRecorder) ended() []*recordedSpan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]*recordedSpan(nil), r.spans...)
+}
+
+func (t *recordingTracer) Start(_ context.Context, spanName, linkedTraceParent string, attributes map[string]string) syncer.Span {
+	span := &recordedSpan{
+		tracerName:        t.name,
+		name:              spanName,
+		linkedTraceParent: linkedTraceParent,
+		attributes:        map[string]string{},
+		recorder:          t.recorder,
+	}
+
+	for k, v := range attributes {
+		span.attributes[k] = v
+	}
+
+	return span
+}
+
+type fanOutResult struct {
+	op      syncer.Operation
+	results map[string]error
+}
+
+func testFanOutTransform() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		federator *distributeAllCountingFederator
+		results   chan fanOutResult
+	)
+
+	BeforeEach(func() {
+		federator = &distributeAllCountingFederator{Federator: d.federator}
+		d.config.Federator = federator
+
+		results = make(chan fanOutResult, 50)
+
+		d.config.FanOutTransform = func(from runtime.Object, _ int, _ syncer.Operation) ([]runtime.Object, bool, error) {
+			pod := from.(*corev1.Pod)
+
+			derived := make([]runtime.Object, 3)
+			for i := range derived {
+				p := pod.DeepCopy()
+				p.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+				derived[i] = p
+			}
+
+			return derived, false, nil
+		}
+
+		d.config.OnFanOutResult = func(_ runtime.Object, op syncer.Operation, r syncer.FanOutResults) {
+			byName := map[string]error{}
+			for obj, err := range r.Errors {
+				byName[resource.ToMeta(obj).GetName()] = err
+			}
+
+			results <- fanOutResult{op: op, results: byName}
+		}
+
+		d.addInitialResource(d.resource)
+	})
+
+	derivedKey := func(i int) string {
+		return fmt.Sprintf("%s/%s-%d", test.LocalNamespace, d.resource.Name, i)
+	}
+
+	When("a resource is created", func() {
+		It("should distribute the derived resources in a single DistributeAll call", func() {
+			Eventually(results).Should(Receive(Equal(fanOutResult{op: syncer.Create, results: map[string]error{
+				d.resource.Name + "-0": nil,
+				d.resource.Name + "-1": nil,
+				d.resource.Name + "-2": nil,
+			}})))
+
+			Expect(atomic.LoadInt32(&federator.distributeAllCalls)).To(Equal(int32(1)))
+			Expect(d.federator.NumCalls(fake.OpDistribute)).To(Equal(3))
+
+			for i := 0; i < 3; i++ {
+				_, found := d.federator.GetDistributed(derivedKey(i))
+				Expect(found).To(BeTrue())
+			}
+		})
+	})
+
+	When("distributing one of the derived resources fails", func() {
+		BeforeEach(func() {
+			d.federator.FailDistributeFor(derivedKey(1), errors.New("fake error"))
+		})
+
+		It("should report the per-resource results and retry", func() {
+			var result fanOutResult
+			Eventually(results).Should(Receive(&result))
+
+			Expect(result.results).To(HaveLen(3))
+			Expect(result.results[d.resource.Name+"-0"]).To(Succeed())
+			Expect(result.results[d.resource.Name+"-1"]).To(HaveOccurred())
+			Expect(result.results[d.resource.Name+"-2"]).To(Succeed())
+
+			d.federator.FailDistributeFor(derivedKey(1), nil)
+
+			Eventually(results, 3).Should(Receive(Equal(fanOutResult{op: syncer.Create, results: map[string]error{
+				d.resource.Name + "-0": nil,
+				d.resource.Name + "-1": nil,
+				d.resource.Name + "-2": nil,
+			}})))
+
+			Expect(atomic.LoadInt32(&federator.distributeAllCalls)).To(BeNumerically(">=", 2))
+		})
+	})
+
+	When("the derived resources are returned in a varying order", func() {
+		var ordered chan []string
+
+		BeforeEach(func() {
+			d.config.Federator = &concurrentFederator{Federator: d.federator}
+			ordered = make(chan []string, 50)
+			calls := int32(0)
+
+			d.config.FanOutTransform = func(from runtime.Object, _ int, _ syncer.Operation) ([]runtime.Object, bool, error) {
+				pod := from.(*corev1.Pod)
+				names := []string{"c", "a", "d", "b"}
+				n := int(atomic.AddInt32(&calls, 1))
+
+				derived := make([]runtime.Object, len(names))
+				for i := range names {
+					p := pod.DeepCopy()
+					p.Name = fmt.Sprintf("%s-%s", pod.Name, names[(i+n)%len(names)])
+					derived[i] = p
+				}
+
+				return derived, false, nil
+			}
+
+			d.config.OnFanOutResult = func(_ runtime.Object, _ syncer.Operation, r syncer.FanOutResults) {
+				names := []string{}
+				for _, obj := range r.Ordered {
+					names = append(names, resource.ToMeta(obj).GetName())
+				}
+
+				ordered <- names
+			}
+		})
+
+		It("should report them in a stable order", func() {
+			expected := []string{d.resource.Name + "-a", d.resource.Name + "-b", d.resource.Name + "-c", d.resource.Name + "-d"}
+
+			distributedNames := func() []string {
+				names := []string{}
+				for _, call := range d.federator.Calls() {
+					if call.Operation == fake.OpDistribute {
+						names = append(names, resource.ToMeta(call.Resource).GetName())
+					}
+				}
+
+				return names
+			}
+
+			Eventually(ordered).Should(Receive(Equal(expected)))
+			Expect(distributedNames()).To(ConsistOf(expected))
+
+			for i := 0; i < 3; i++ {
+				d.resource.Spec.Hostname = fmt.Sprintf("host-%d", i)
+				test.UpdateResource(d.sourceClient, d.resource)
+
+				Eventually(ordered).Should(Receive(Equal(expected)))
+				Expect(distributedNames()).To(HaveLen(len(expected) * (i + 2)))
+			}
+		})
+	})
+
+	When("a resource is deleted", func() {
+		It("should delete each derived resource", func() {
+			Eventually(results).Should(Receive())
+
+			Expect(d.sourceClient.Delete(context.TODO(), d.resource.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			Eventually(results).Should(Receive(Equal(fanOutResult{op: syncer.Delete, results: map[string]error{
+				d.resource.Name + "-0": nil,
+				d.resource.Name + "-1": nil,
+				d.resource.Name + "-2": nil,
+			}})))
+
+			Expect(d.federator.NumCalls(fake.OpDelete)).To(Equal(3))
+			Expect(d.federator.Distributed()).To(BeEmpty())
+		})
+	})
+}
+
+type distributeAllCountingFederator struct {
+	*fake.Federator
+	distributeAllCalls int32
+}
+
+func (f *distributeAllCountingFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	atomic.AddInt32(&f.distributeAllCalls, 1)
+	return f.Federator.DistributeAll(ctx, resources)
+}
+
+// concurrentFederator distributes the resources passed to DistributeAll concurrently, as the real Federators do.
+type concurrentFederator struct {
+	*fake.Federator
+}
+
+func (f *concurrentFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		wg.Add(1)
+
+		go func(obj runtime.Object) {
+			defer wg.Done()
+
+			if err := f.Distribute(ctx, obj); err != nil {
+				mutex.Lock()
+				errs[obj] = err
+				mutex.Unlock()
+			}
+		}(obj)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+func testResourceTypeWait() {
+	var (
+		restMapper     *delayedRESTMapper
+		config         *syncer.ResourceSyncerConfig
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+	)
+
+	BeforeEach(func() {
+		delegate, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+		restMapper = &delayedRESTMapper{
+			RESTMapper:           metaapi.NewDefaultRESTMapper(nil),
+			delegate:             delegate,
+			resetsUntilAvailable: 3,
+		}
+
+		config = &syncer.ResourceSyncerConfig{
+			Name: "test",
+			SourceClient: fakeClient.NewSimpleDynamicClient(scheme.Scheme,
+				test.PrepInitialClientObjs(test.LocalNamespace, "", test.NewPod(test.LocalNamespace))...),
+			SourceNamespace:         test.LocalNamespace,
+			RestMapper:              restMapper,
+			Federator:               fake.New(),
+			ResourceType:            &corev1.Pod{},
+			ResourceTypeWaitTimeout: 3 * time.Second,
+		}
+
+		stopCh = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+	})
+
+	When("the resource type becomes available after the syncer is created", func() {
+		It("should start successfully once it's available", func() {
+			var err error
+
+			resourceSyncer, err = syncer.NewResourceSyncer(config)
+			Expect(err).To(Succeed())
+			Expect(resourceSyncer.Ready()).ToNot(Succeed())
+
+			Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+			Expect(resourceSyncer.Ready()).To(Succeed())
+			Expect(resourceSyncer.ListResources()).To(HaveLen(1))
+		})
+	})
+
+	When("the syncer's methods are called before the resource type is available", func() {
+		It("should return an error or do nothing", func() {
+			var err error
+
+			resourceSyncer, err = syncer.NewResourceSyncer(config)
+			Expect(err).To(Succeed())
+
+			_, _, err = resourceSyncer.GetResource("test-pod", test.LocalNamespace)
+			Expect(err).To(MatchError(ContainSubstring("isn't yet available")))
+
+			_, _, err = resourceSyncer.GetLiveResource("test-pod", test.LocalNamespace)
+			Expect(err).To(MatchError(ContainSubstring("isn't yet available")))
+
+			_, err = resourceSyncer.ListResources()
+			Expect(err).To(MatchError(ContainSubstring("isn't yet available")))
+
+			_, err = resourceSyncer.ListResourcesBySelector(labels.Everything())
+			Expect(err).To(MatchError(ContainSubstring("isn't yet available")))
+
+			_, err = resourceSyncer.ByIndex("index", "value")
+			Expect(err).To(MatchError(ContainSubstring("isn't yet available")))
+
+			listed := make(chan struct{}, 1)
+			resourceSyncer.Reconcile(func() []runtime.Object {
+				listed <- struct{}{}
+				return nil
+			})
+			Consistently(listed).ShouldNot(Receive())
+
+			resourceSyncer.Resync()
+			Expect(resourceSyncer.PendingKeys()).To(BeEmpty())
+		})
+	})
+
+	When("the resource type doesn't become available within the timeout", func() {
+		var fakeClock *clock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = clock.NewFakeClock(time.Now())
+			config.Clock = fakeClock
+			restMapper.resetsUntilAvailable = 1000
+			config.ResourceTypeWaitTimeout = 300 * time.Millisecond
+		})
+
+		It("should fail to start", func() {
+			var err error
+
+			resourceSyncer, err = syncer.NewResourceSyncer(config)
+			Expect(err).To(Succeed())
+
+			started := make(chan error, 1)
+
+			go func() {
+				started <- resourceSyncer.Start(stopCh)
+			}()
+
+			Eventually(func() bool {
+				if fakeClock.HasWaiters() {
+					fakeClock.Step(100 * time.Millisecond)
+				}
+
+				select {
+				case err = <-started:
+					return true
+				default:
+					return false
+				}
+			}).Should(BeTrue())
+
+			Expect(err).To(MatchError(ContainSubstring("timed out waiting")))
+			resourceSyncer.AwaitStopped()
+		})
+	})
+
+	When("a fake clock is set", func() {
+		var fakeClock *clock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = clock.NewFakeClock(time.Now())
+			config.Clock = fakeClock
+			config.ResourceTypeWaitTimeout = time.Hour
+			restMapper.resetsUntilAvailable = 5
+		})
+
+		It("should back off between attempts as the clock advances", func() {
+			resourceSyncer, err := syncer.NewResourceSyncer(config)
+			Expect(err).To(Succeed())
+
+			started := make(chan error, 1)
+
+			go func() {
+				started <- resourceSyncer.Start(stopCh)
+			}()
+
+			for _, delay := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+				800 * time.Millisecond} {
+				Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+				fakeClock.Step(delay - time.Millisecond)
+				Expect(fakeClock.HasWaiters()).To(BeTrue())
+				Expect(started).ToNot(Receive())
+
+				fakeClock.Step(time.Millisecond)
+			}
+
+			Eventually(started).Should(Receive(Succeed()))
+			Expect(fakeClock.HasWaiters()).To(BeFalse())
+			Expect(resourceSyncer.ListResources()).To(HaveLen(1))
+		})
+	})
+
+	When("waiting for the resource type isn't enabled", func() {
+		BeforeEach(func() {
+			config.ResourceTypeWaitTimeout = 0
+		})
+
+		It("should fail to create the syncer", func() {
+			_, err := syncer.NewResourceSyncer(config)
+			Expect(err).To(MatchError(ContainSubstring("no matches for kind")))
+		})
+	})
+}
+
+// testWidgetV2 is the v2 representation of the testWidget, in which the size was renamed to replicas.
+type testWidgetV2 struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              testWidgetV2Spec `json:"spec,omitempty"`
+}
+
+type testWidgetV2Spec struct {
+	Replicas int64 `json:"replicas,omitempty"`
+}
+
+func (w *testWidgetV2) DeepCopyObject() runtime.Object {
+	c := *w
+	w.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+
+	return &c
+}
+
+func testPreferredVersion() {
+	widgetV1 := schema.GroupVersionKind{Group: "test.submariner.io", Version: "v1", Kind: "Widget"}
+	widgetV2 := schema.GroupVersionKind{Group: "test.submariner.io", Version: "v2", Kind: "Widget"}
+
+	var (
+		config    *syncer.ResourceSyncerConfig
+		federator *fake.Federator
+		stopCh    chan struct{}
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		scheme.AddKnownTypeWithName(widgetV1, &testWidget{})
+		scheme.AddKnownTypeWithName(widgetV2, &testWidgetV2{})
+		Expect(scheme.AddConversionFunc((*testWidgetV2)(nil), (*testWidget)(nil), func(a, b interface{}, _ conversion.Scope) error {
+			from := a.(*testWidgetV2)
+			to := b.(*testWidget)
+			to.ObjectMeta = from.ObjectMeta
+			to.Spec.Size = from.Spec.Replicas
+
+			return nil
+		})).To(Succeed())
+
+		// The discovery client serves both versions, with v2 preferred, as a cluster would while a CRD is being upgraded.
+		discovery := &fakediscovery.FakeDiscovery{Fake: &testing.Fake{Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: widgetV2.GroupVersion().String(),
+				APIResources: []metav1.APIResource{{Name: "widgets", Namespaced: true, Kind: widgetV2.Kind}},
+			},
+			{
+				GroupVersion: widgetV1.GroupVersion().String(),
+				APIResources: []metav1.APIResource{{Name: "widgets", Namespaced: true, Kind: widgetV1.Kind}},
+			},
+		}}}
+
+		groupResources, err := restmapper.GetAPIGroupResources(discovery)
+		Expect(err).To(Succeed())
+
+		resourceType := &unstructured.Unstructured{}
+		resourceType.SetGroupVersionKind(widgetV1)
+
+		federator = fake.New()
+		stopCh = make(chan struct{})
+
+		config = &syncer.ResourceSyncerConfig{
+			Name:                "test",
+			SourceClient:        fakeClient.NewSimpleDynamicClient(scheme),
+			SourceNamespace:     test.LocalNamespace,
+			RestMapper:          restmapper.NewDiscoveryRESTMapper(groupResources),
+			Federator:           federator,
+			ResourceType:        resourceType,
+			Scheme:              scheme,
+			UsePreferredVersion: true,
+		}
+	})
+
+	JustBeforeEach(func() {
+		// The resource is stored at the preferred version.
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(widgetV2)
+		obj.SetName("test-widget")
+		obj.SetNamespace(test.LocalNamespace)
+		Expect(unstructured.SetNestedField(obj.Object, int64(3), "spec", "replicas")).To(Succeed())
+
+		test.CreateResource(config.SourceClient.Resource(widgetV2.GroupVersion().WithResource("widgets")).Namespace(
+			test.LocalNamespace), obj)
+
+		resourceSyncer, err := syncer.NewResourceSyncer(config)
+		Expect(err).To(Succeed())
+
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+	})
+
+	expectSize := func(obj runtime.Object) {
+		u, err := resource.ToUnstructured(obj)
+		Expect(err).To(Succeed())
+		Expect(u.GroupVersionKind()).To(Equal(widgetV1))
+
+		size, _, _ := unstructured.NestedInt64(u.Object, "spec", "size")
+		Expect(size).To(Equal(int64(3)))
+	}
+
+	When("the ResourceType is Unstructured at a version other than the preferred version", func() {
+		It("should sync the resource converted to the ResourceType's version", func() {
+			Eventually(federator.Distributed).Should(HaveLen(1))
+			expectSize(federator.Distributed()[0])
+		})
+	})
+
+	When("the ResourceType is typed at a version other than the preferred version", func() {
+		var transformed chan runtime.Object
+
+		BeforeEach(func() {
+			transformed = make(chan runtime.Object, 10)
+
+			config.ResourceType = &testWidget{}
+			config.Transform = func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				transformed <- from
+				return from, false, nil
+			}
+		})
+
+		It("should pass the resource converted to the ResourceType to the transform function", func() {
+			var obj runtime.Object
+			Eventually(transformed).Should(Receive(&obj))
+			Expect(obj).To(BeAssignableToTypeOf(&testWidget{}))
+			Expect(obj.(*testWidget).Spec.Size).To(Equal(int64(3)))
+
+			Eventually(federator.Distributed).Should(HaveLen(1))
+			expectSize(federator.Distributed()[0])
+		})
+	})
+
+	When("UsePreferredVersion is disabled", func() {
+		BeforeEach(func() {
+			config.UsePreferredVersion = false
+		})
+
+		It("should miss the resource stored at the preferred version", func() {
+			federator.VerifyNoDistribute()
+		})
+	})
+}
+
+func testClusterScoped() {
+	var (
+		federator      *fake.Federator
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+	)
+
+	BeforeEach(func() {
+		restMapper := metaapi.NewDefaultRESTMapper(nil)
+		restMapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), metaapi.RESTScopeRoot)
+
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+		federator = fake.New()
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", namespace)...),
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			ResourceType:    &corev1.Namespace{},
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("a SourceNamespace is specified", func() {
+		It("should ignore it and sync the resources", func() {
+			Eventually(func() []runtime.Object {
+				return federator.Distributed()
+			}).Should(HaveLen(1))
+		})
+	})
+}
+
+func testVerifyOnStart() {
+	var (
+		client         *fakeClient.FakeDynamicClient
+		federator      *fake.Federator
+		resourceSyncer syncer.Interface
+		stopCh         chan struct{}
+		pod            *corev1.Pod
+		startErr       error
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		client = fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...)
+		federator = fake.New()
+		stopCh = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		restMapper, _ := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    client,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			ResourceType:    &corev1.Pod{},
+			VerifyOnStart:   true,
+		})
+		Expect(err).To(Succeed())
+
+		startErr = resourceSyncer.Start(stopCh)
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	When("the resource type isn't served", func() {
+		BeforeEach(func() {
+			client.PrependReactor("list", "pods", func(_ testing.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "")
+			})
+		})
+
+		It("should return an error from Start", func() {
+			Expect(startErr).To(HaveOccurred())
+			Expect(apierrors.IsNotFound(startErr)).To(BeTrue())
+		})
+	})
+
+	When("watching the resources is forbidden", func() {
+		BeforeEach(func() {
+			client.PrependWatchReactor("pods", func(_ testing.Action) (bool, watch.Interface, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC denied"))
+			})
+		})
+
+		It("should return an error from Start", func() {
+			Expect(startErr).To(MatchError(ContainSubstring("not permitted to watch")))
+			Expect(apierrors.IsForbidden(startErr)).To(BeTrue())
+		})
+	})
+
+	When("listing the resources initially fails transiently", func() {
+		var numLists int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&numLists, 0)
+			client.PrependReactor("list", "pods", func(_ testing.Action) (bool, runtime.Object, error) {
+				if atomic.AddInt32(&numLists, 1) <= 2 {
+					return true, nil, apierrors.NewServiceUnavailable("mock unavailable")
+				}
+
+				return false, nil, nil
+			})
+		})
+
+		It("should succeed and sync once the list recovers", func() {
+			Expect(startErr).To(Succeed())
+			Eventually(func() bool {
+				_, found := federator.GetDistributed(test.LocalNamespace + "/" + pod.Name)
+				return found
+			}, 5).Should(BeTrue())
+		})
+	})
+}
+
+func testMetadataOnly() {
+	var (
+		sourceClient   *fakeClient.FakeDynamicClient
+		metadataClient metadatafake.MetadataClient
+		federator      *fake.Federator
+		resourceSyncer syncer.Interface
+		transformed    chan *corev1.Pod
+		stopCh         chan struct{}
+		pod            *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod(test.LocalNamespace)
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(&corev1.Pod{})
+
+		sourceClient = fakeClient.NewSimpleDynamicClient(scheme.Scheme, test.PrepInitialClientObjs("", "", pod)...)
+
+		metadataScheme := runtime.NewScheme()
+		Expect(metav1.AddMetaToScheme(metadataScheme)).To(Succeed())
+
+		client := metadatafake.NewSimpleMetadataClient(metadataScheme, &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		})
+		metadataClient = client.Resource(*gvr).Namespace(test.LocalNamespace).(metadatafake.MetadataClient)
+
+		federator = fake.New()
+		transformed = make(chan *corev1.Pod, 10)
+
+		var err error
+
+		resourceSyncer, err = syncer.NewResourceSyncer(&syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceClient:    sourceClient,
+			MetadataClient:  client,
+			SourceNamespace: test.LocalNamespace,
+			RestMapper:      restMapper,
+			Federator:       federator,
+			ResourceType:    &corev1.Pod{},
+			Transform: func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+				p := from.(*corev1.Pod)
+				transformed <- p
+
+				if p.Labels["act"] != "true" {
+					return nil, false, nil
+				}
+
+				full, _, err := resourceSyncer.GetLiveResource(p.Name, p.Namespace)
+
+				return full, false, err
+			},
+		})
+		Expect(err).To(Succeed())
+
+		stopCh = make(chan struct{})
+		Expect(resourceSyncer.Start(stopCh)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		resourceSyncer.AwaitStopped()
+	})
+
+	getActions := func() int {
+		n := 0
+
+		for _, action := range sourceClient.Actions() {
+			if action.GetVerb() == "get" {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	updateLabels := func(labels map[string]string) {
+		_, err := metadataClient.UpdateFake(&metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, Labels: labels},
+		}, metav1.UpdateOptions{})
+		Expect(err).To(Succeed())
+	}
+
+	It("should deliver metadata-only resources", func() {
+		var received *corev1.Pod
+		Eventually(transformed).Should(Receive(&received))
+		Expect(received.Name).To(Equal(pod.Name))
+		Expect(received.Spec.Containers).To(BeEmpty())
+
+		_, found, err := resourceSyncer.GetResource(pod.Name, pod.Namespace)
+		Expect(err).To(Succeed())
+		Expect(found).To(BeTrue())
+	})
+
+	When("a resource's labels are updated", func() {
+		It("should deliver the label change without retrieving the full resource", func() {
+			Eventually(transformed).Should(Receive())
+
+			updateLabels(map[string]string{"foo": "bar"})
+
+			var received *corev1.Pod
+			Eventually(transformed).Should(Receive(&received))
+			Expect(received.Labels).To(Equal(map[string]string{"foo": "bar"}))
+
+			federator.VerifyNoDistribute()
+			Expect(getActions()).To(BeZero())
+		})
+	})
+
+	When("the transform function decides to act on a resource", func() {
+		It("should retrieve and distribute the full resource", func() {
+			Eventually(transformed).Should(Receive())
+
+			updateLabels(map[string]string{"act": "true"})
+
+			Eventually(federator.Distributed).Should(HaveLen(1))
+
+			containers, _, _ := unstructured.NestedSlice(federator.Distributed()[0].(*unstructured.Unstructured).Object,
+				"spec", "containers")
+			Expect(containers).ToNot(BeEmpty())
+			Expect(getActions()).To(Equal(1))
+		})
+	})
+}
+
+func testMaxConcurrentReconciles() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	const numWorkers = 3
+
+	var (
+		mutex      sync.Mutex
+		inProgress map[string]bool
+		numActive  int
+		maxActive  int
+		overlapped bool
+		numCalls   int
+		numSynced  int32
+		release    chan struct{}
+	)
+
+	BeforeEach(func() {
+		inProgress = map[string]bool{}
+		numActive = 0
+		maxActive = 0
+		overlapped = false
+		numCalls = 0
+		release = make(chan struct{})
+		atomic.StoreInt32(&numSynced, 0)
+		d.config.MaxConcurrentReconciles = numWorkers
+		d.config.OnSuccessfulSync = func(synced runtime.Object, op syncer.Operation) {
+			atomic.AddInt32(&numSynced, 1)
+		}
+	})
+
+	JustAfterEach(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+
+	transformFunc := func(reQueue func(calls int) bool) syncer.TransformFunc {
+		return func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+			name := from.(*corev1.Pod).Name
+
+			mutex.Lock()
+			overlapped = overlapped || inProgress[name]
+			inProgress[name] = true
+			numActive++
+			numCalls++
+			calls := numCalls
+
+			if numActive > maxActive {
+				maxActive = numActive
+			}
+			mutex.Unlock()
+
+			select {
+			case <-release:
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			mutex.Lock()
+			delete(inProgress, name)
+			numActive--
+			mutex.Unlock()
+
+			return from, reQueue(calls), nil
+		}
+	}
+
+	When("distinct resources are queued", func() {
+		BeforeEach(func() {
+			for i := 0; i < numWorkers; i++ {
+				pod := test.NewPod(d.config.SourceNamespace)
+				pod.Name = fmt.Sprintf("%s-%d", pod.Name, i)
+				d.addInitialResource(pod)
+			}
+
+			d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+				return transformFunc(func(_ int) bool {
+					<-release
+					return false
+				})(from, numRequeues, op)
+			}
+		})
+
+		It("should process them in parallel", func() {
+			Eventually(func() int {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				return maxActive
+			}).Should(Equal(numWorkers))
+
+			close(release)
+
+			Eventually(func() int {
+				return int(atomic.LoadInt32(&numSynced))
+			}).Should(Equal(numWorkers))
+		})
+	})
+
+	When("the same resource is repeatedly queued", func() {
+		const numRepeats = 5
+
+		BeforeEach(func() {
+			d.addInitialResource(d.resource)
+			d.config.Transform = transformFunc(func(calls int) bool {
+				return calls < numRepeats
+			})
+		})
+
+		It("should never process it concurrently", func() {
+			for i := 0; i < 3; i++ {
+				test.UpdateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, fmt.Sprintf("image-%d", i)))
+			}
+
+			Eventually(func() int {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				return numCalls
+			}, 5).Should(BeNumerically(">=", numRepeats))
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			Expect(overlapped).To(BeFalse())
+		})
+	})
+}
+
+func testStopCancellation() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var federator *slowFederator
+
+	BeforeEach(func() {
+		federator = &slowFederator{latency: time.Minute, returned: make(chan error, 10)}
+		d.config.Federator = federator
+	})
+
+	When("the syncer is stopped during a slow distribute", func() {
+		It("should abort the distribute promptly and not re-queue the resource", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			Eventually(federator.numCalls).Should(Equal(1))
+
+			close(d.stopCh)
+
+			Eventually(federator.returned, time.Second).Should(Receive(Equal(context.Canceled)))
+			Consistently(federator.numCalls).Should(Equal(1))
+			Consistently(d.handledError, 300*time.Millisecond).ShouldNot(Receive(), "Error was unexpectedly logged")
+		})
+	})
+
+	When("distribute fails with a deadline exceeded error while not stopping", func() {
+		BeforeEach(func() {
+			federator.latency = 0
+			federator.err = context.DeadlineExceeded
+		})
+
+		It("should re-queue the resource", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			Eventually(federator.numCalls).Should(BeNumerically(">", 1))
+			Eventually(d.handledError, 5).Should(Receive(ContainErrorSubstring(context.DeadlineExceeded)))
+		})
+	})
+}
+
+func testSyncMetrics() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var registry *prometheus.Registry
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		d.config.MetricsRegisterer = registry
+		d.config.Federator = &slowFederator{latency: 100 * time.Millisecond, returned: make(chan error, 10)}
+		d.config.SyncDurationOpts = &prometheus.HistogramOpts{
+			Name:    "sync_duration_seconds",
+			Buckets: []float64{0.05, 0.5, 5},
+		}
+		d.config.LastSyncTimeOpts = &prometheus.GaugeOpts{
+			Name: "last_sync_time_seconds",
+		}
+	})
+
+	getMetric := func(name string) *dto.Metric {
+		families, err := registry.Gather()
+		Expect(err).To(Succeed())
+
+		for _, family := range families {
+			if family.GetName() == name {
+				Expect(family.GetMetric()).To(HaveLen(1))
+				return family.GetMetric()[0]
+			}
+		}
+
+		return nil
+	}
+
+	When("a resource is synced", func() {
+		It("should record the sync duration and last sync time", func() {
+			before := float64(time.Now().Unix())
+
+			test.CreateResource(d.sourceClient, d.resource)
+
+			Eventually(func() uint64 {
+				m := getMetric("sync_duration_seconds")
+				if m == nil {
+					return 0
+				}
+
+				return m.GetHistogram().GetSampleCount()
+			}, 5).Should(Equal(uint64(1)))
+
+			buckets := getMetric("sync_duration_seconds").GetHistogram().GetBucket()
+			Expect(buckets).To(HaveLen(3))
+			Expect(buckets[0].GetCumulativeCount()).To(Equal(uint64(0)))
+			Expect(buckets[1].GetCumulativeCount()).To(Equal(uint64(1)))
+
+			lastSyncTime := getMetric("last_sync_time_seconds")
+			Expect(lastSyncTime).ToNot(BeNil())
+			Expect(lastSyncTime.GetGauge().GetValue()).To(BeNumerically(">=", before))
+
+			labels := map[string]string{}
+			for _, l := range lastSyncTime.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+
+			Expect(labels).To(Equal(map[string]string{
+				syncer.DirectionLabel:  syncer.LocalToRemote.String(),
+				syncer.SyncerNameLabel: d.config.Name,
+			}))
+		})
+	})
+
+	When("the syncer's Clock is specified", func() {
+		var fakeClock *clock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = clock.NewFakeClock(time.Now())
+			d.config.Clock = fakeClock
+			d.config.Federator = &clockSteppingFederator{Federator: fake.New(), clock: fakeClock, step: 2 * time.Second}
+		})
+
+		It("should record the sync duration and last sync time using it", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+
+			Eventually(func() uint64 {
+				m := getMetric("sync_duration_seconds")
+				if m == nil {
+					return 0
+				}
+
+				return m.GetHistogram().GetSampleCount()
+			}, 5).Should(Equal(uint64(1)))
+
+			buckets := getMetric("sync_duration_seconds").GetHistogram().GetBucket()
+			Expect(buckets[1].GetCumulativeCount()).To(Equal(uint64(0)))
+			Expect(buckets[2].GetCumulativeCount()).To(Equal(uint64(1)))
+
+			Expect(getMetric("last_sync_time_seconds").GetGauge().GetValue()).To(
+				BeNumerically("~", float64(fakeClock.Now().UnixNano())/1e9, 0.001))
+		})
+	})
+}
+
+func testSyncLag() {
+	const transformDelay = 3 * time.Second
+
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		registry  *prometheus.Registry
+		fakeClock *clock.FakeClock
+		startedAt time.Time
+	)
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		fakeClock = clock.NewFakeClock(time.Now())
+		startedAt = fakeClock.Now()
+
+		d.config.MetricsRegisterer = registry
+		d.config.Clock = fakeClock
+		d.config.QueueDrainThreshold = time.Hour
+		d.config.SyncLagOpts = &prometheus.HistogramOpts{Name: "sync_lag_seconds"}
+		d.config.Transform = func(from runtime.Object, _ int, _ syncer.Operation) (runtime.Object, bool, error) {
+			fakeClock.Step(transformDelay)
+			return from, false, nil
+		}
+	})
+
+	getLag := func() *dto.Histogram {
+		families, err := registry.Gather()
+		Expect(err).To(Succeed())
+
+		for _, family := range families {
+			if family.GetName() == "sync_lag_seconds" {
+				Expect(family.GetMetric()).To(HaveLen(1))
+				return family.GetMetric()[0].GetHistogram()
+			}
+		}
+
+		return nil
+	}
+
+	awaitLag := func() float64 {
+		Eventually(func() uint64 {
+			return getLag().GetSampleCount()
+		}, 5).Should(Equal(uint64(1)))
+
+		return getLag().GetSampleSum()
+	}
+
+	setChangeTime := func(t time.Time) {
+		d.resource.ManagedFields = []metav1.ManagedFieldsEntry{{
+			Manager:   "test",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			Time:      &metav1.Time{Time: t},
+		}}
+	}
+
+	When("a resource with a recorded change time is synced", func() {
+		It("should record the lag from the change time", func() {
+			setChangeTime(startedAt.Add(time.Second))
+			fakeClock.Step(5 * time.Second)
+
+			test.CreateResource(d.sourceClient, d.resource)
+
+			// The change time is truncated to seconds so the lag is between 7 and 8 seconds.
+			lag := awaitLag()
+			Expect(lag).To(BeNumerically(">", 7))
+			Expect(lag).To(BeNumerically("<=", 8))
+		})
+	})
+
+	When("a resource without a recorded change time is synced", func() {
+		It("should record the lag from when it was queued", func() {
+			fakeClock.Step(5 * time.Second)
+
+			test.CreateResource(d.sourceClient, d.resource)
+
+			Expect(awaitLag()).To(Equal(transformDelay.Seconds()))
+		})
+	})
+
+	When("a resource changed before the syncer started is synced", func() {
+		It("should record the lag from when it was queued", func() {
+			setChangeTime(startedAt.Add(-time.Hour))
+			fakeClock.Step(5 * time.Second)
+
+			test.CreateResource(d.sourceClient, d.resource)
+
+			Expect(awaitLag()).To(Equal(transformDelay.Seconds()))
+		})
+	})
+
+	When("a resource is enqueued explicitly", func() {
+		BeforeEach(func() {
+			setChangeTime(startedAt.Add(time.Second))
+			d.addInitialResource(d.resource)
+		})
+
+		It("should record the lag from when it was queued", func() {
+			initialLag := awaitLag()
+
+			fakeClock.Step(5 * time.Second)
+			d.syncer.EnqueueKey(test.LocalNamespace + "/" + d.resource.Name)
+
+			Eventually(func() uint64 {
+				return getLag().GetSampleCount()
+			}, 5).Should(Equal(uint64(2)))
+
+			Expect(getLag().GetSampleSum() - initialLag).To(BeNumerically("~", transformDelay.Seconds(), 0.001))
+		})
+	})
+}
+
+func testWorkQueueMetrics() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var registry *prometheus.Registry
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		d.config.MetricsRegisterer = registry
+		d.config.WorkQueueMetrics = true
+	})
+
+	getMetric := func(name string) *dto.Metric {
+		families, err := registry.Gather()
+		Expect(err).To(Succeed())
+
+		for _, family := range families {
+			if family.GetName() == name {
+				Expect(family.GetMetric()).To(HaveLen(1))
+				Expect(family.GetMetric()[0].GetLabel()).To(HaveLen(1))
+				Expect(family.GetMetric()[0].GetLabel()[0].GetName()).To(Equal(syncer.SyncerNameLabel))
+				Expect(family.GetMetric()[0].GetLabel()[0].GetValue()).To(Equal(d.config.Name))
+
+				return family.GetMetric()[0]
+			}
+		}
+
+		return nil
+	}
+
+	When("resources are processed with a retry", func() {
+		BeforeEach(func() {
+			d.federator.FailOnDistribute = errors.New("fake error")
+		})
+
+		It("should record the work queue metrics", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(test.ToUnstructured(d.resource))
+
+			d.resource = test.NewPodWithImage(d.config.SourceNamespace, "apache")
+			test.UpdateResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(test.ToUnstructured(d.resource))
+
+			Eventually(func() uint64 {
+				return getMetric(syncer.WorkQueueWorkDurationMetricName).GetHistogram().GetSampleCount()
+			}, 5).Should(Equal(uint64(3)))
+
+			Expect(getMetric(syncer.WorkQueueAddsMetricName).GetCounter().GetValue()).To(Equal(float64(2)))
+			Expect(getMetric(syncer.WorkQueueRetriesMetricName).GetCounter().GetValue()).To(Equal(float64(1)))
+			Expect(getMetric(syncer.WorkQueueDepthMetricName).GetGauge().GetValue()).To(BeZero())
+		})
+	})
+}
+
+func testPriority() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var classified chan syncer.Operation
+
+	BeforeEach(func() {
+		classified = make(chan syncer.Operation, 10)
+		d.config.Debounce = 3 * time.Second
+		d.config.Priority = func(key string, op syncer.Operation) int {
+			defer GinkgoRecover()
+
+			Expect(key).To(Equal(test.LocalNamespace + "/" + d.resource.Name))
+			classified <- op
+
+			if op == syncer.Update {
+				return 0
+			}
+
+			return 1
+		}
+	})
+
+	When("a high priority resource is created and deleted", func() {
+		It("should classify and distribute it without debouncing", func() {
+			start := time.Now()
+
+			test.CreateResource(d.sourceClient, d.resource)
+			Eventually(classified).Should(Receive(Equal(syncer.Create)))
+			d.federator.VerifyDistribute(test.ToUnstructured(d.resource))
+			Expect(time.Since(start)).To(BeNumerically("<", d.config.Debounce))
+
+			Expect(d.sourceClient.Delete(context.TODO(), d.resource.Name, metav1.DeleteOptions{})).To(Succeed())
+			Eventually(classified).Should(Receive(Equal(syncer.Delete)))
+			d.federator.VerifyDelete(test.ToUnstructured(d.resource))
+		})
+	})
+}
+
+func testOnCacheSynced() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		invocations int32
+		numCached   int32
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&invocations, 0)
+		atomic.StoreInt32(&numCached, -1)
+		d.config.OnCacheSynced = func(ctx context.Context) {
+			defer GinkgoRecover()
+
+			Expect(ctx).ToNot(BeNil())
+
+			list, err := d.syncer.ListResources()
+			Expect(err).To(Succeed())
+			atomic.CompareAndSwapInt32(&numCached, -1, int32(len(list)))
+
+			atomic.AddInt32(&invocations, 1)
+		}
+	})
+
+	getInvocations := func() int32 {
+		return atomic.LoadInt32(&invocations)
+	}
+
+	verifyInvokedOnce := func() {
+		Eventually(getInvocations).Should(Equal(int32(1)))
+
+		test.CreateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, "apache"))
+		Consistently(getInvocations).Should(Equal(int32(1)))
+	}
+
+	When("the initial list is empty", func() {
+		It("should invoke the callback exactly once", func() {
+			verifyInvokedOnce()
+			Expect(atomic.LoadInt32(&numCached)).To(BeZero())
+		})
+	})
+
+	When("the initial list has resources", func() {
+		BeforeEach(func() {
+			d.resource.Name = "initial-pod"
+			d.addInitialResource(d.resource)
+		})
+
+		It("should invoke the callback exactly once after the cache has synced", func() {
+			verifyInvokedOnce()
+			Expect(atomic.LoadInt32(&numCached)).To(Equal(int32(1)))
+		})
+	})
+}
+
+func testPruneOnSync() {
+	d := newTestDiver(test.LocalNamespace, "east", syncer.LocalToRemote)
+
+	var (
+		orphan  *unstructured.Unstructured
+		foreign *unstructured.Unstructured
+	)
+
+	newDestinationPod := func(name, clusterID string) *unstructured.Unstructured {
+		pod := test.NewPod(test.RemoteNamespace)
+		pod.Name = name
+
+		return test.PrepInitialClientObjs("", clusterID, pod)[0].(*unstructured.Unstructured)
+	}
+
+	BeforeEach(func() {
+		d.addInitialResource(d.resource)
+
+		orphan = newDestinationPod("orphan-pod", "east")
+		foreign = newDestinationPod("foreign-pod", "west")
+
+		d.config.PruneOnSync = &syncer.PruneConfig{
+			Client: fakeClient.NewSimpleDynamicClient(d.config.Scheme, newDestinationPod(d.resource.Name, "east"),
+				orphan, foreign),
+			Namespace:          test.RemoteNamespace,
+			OwnerLabelSelector: federate.ClusterIDLabelKey + "=east",
+		}
+	})
+
+	AfterEach(func() {
+		d.config.PruneOnSync = nil
+	})
+
+	When("the destination has an owned resource whose source no longer exists", func() {
+		It("should delete only that resource", func() {
+			d.federator.VerifyDelete(orphan)
+			d.federator.VerifyNoDelete()
+		})
+	})
+
+	When("the owner label selector isn't specified", func() {
+		It("should fail to create the syncer", func() {
+			d.config.PruneOnSync.OwnerLabelSelector = ""
+			_, err := syncer.NewResourceSyncer(&d.config)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func testDeletePropagationPolicy() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var destClient *dynamicfake.DynamicResourceClient
+
+	BeforeEach(func() {
+		dynClient := dynamicfake.NewDynamicClient(d.config.Scheme)
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(d.config.ResourceType)
+		destClient, _ = dynClient.Resource(*gvr).Namespace(test.RemoteNamespace).(*dynamicfake.DynamicResourceClient)
+
+		d.config.Federator = federate.NewCreateOrUpdateFederator(dynClient, restMapper, test.RemoteNamespace, "")
+		d.addInitialResource(d.resource)
+	})
+
+	AfterEach(func() {
+		d.config.DeletePropagationPolicy = nil
+	})
+
+	JustBeforeEach(func() {
+		test.AwaitResource(destClient, d.resource.Name)
+		Expect(d.sourceClient.Delete(context.TODO(), d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+		test.AwaitNoResource(destClient, d.resource.Name)
+	})
+
+	When("a propagation policy is specified", func() {
+		BeforeEach(func() {
+			policy := metav1.DeletePropagationForeground
+			d.config.DeletePropagationPolicy = &policy
+		})
+
+		It("should delete the destination resource with the propagation policy", func() {
+			options := destClient.DeleteOptionsFor(d.resource.Name)
+			Expect(options).ToNot(BeNil())
+			Expect(options.PropagationPolicy).To(Equal(d.config.DeletePropagationPolicy))
+		})
+	})
+
+	When("a propagation policy isn't specified", func() {
+		It("should delete the destination resource without a propagation policy", func() {
+			options := destClient.DeleteOptionsFor(d.resource.Name)
+			Expect(options).ToNot(BeNil())
+			Expect(options.PropagationPolicy).To(BeNil())
+		})
+	})
+}
+
+func testLastAppliedHashAnnotation() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var destClient *dynamicfake.DynamicResourceClient
+
+	BeforeEach(func() {
+		dynClient := dynamicfake.NewDynamicClient(d.config.Scheme)
+		restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(d.config.ResourceType)
+		destClient, _ = dynClient.Resource(*gvr).Namespace(test.RemoteNamespace).(*dynamicfake.DynamicResourceClient)
+
+		// Simulate the destination server defaulting a field on create.
+		destClient.MutateOnCreate = func(obj *unstructured.Unstructured) {
+			_ = unstructured.SetNestedField(obj.Object, string(corev1.RestartPolicyAlways), "spec", "restartPolicy")
+		}
+
+		d.config.Federator = federate.NewCreateOrUpdateFederatorWithOptions(dynClient, restMapper, test.RemoteNamespace, "",
+			federate.CreateOrUpdateFederatorOptions{LastAppliedHashAnnotation: "submariner-io/last-applied-hash"})
+		d.addInitialResource(d.resource)
+	})
+
+	JustBeforeEach(func() {
+		test.AwaitResource(destClient, d.resource.Name)
+	})
+
+	When("the source resource is re-processed unchanged", func() {
+		It("should not rewrite the destination resource with server-defaulted fields", func() {
+			d.syncer.Resync()
+			destClient.VerifyNoUpdate(d.resource.Name)
+		})
+	})
+
+	When("the source resource's spec changes", func() {
+		It("should rewrite the destination resource", func() {
+			d.resource.Spec.Containers[0].Image = "apache"
+			test.UpdateResource(d.sourceClient, d.resource)
+
+			Eventually(func() string {
+				return test.GetPod(destClient, d.resource).Spec.Containers[0].Image
+			}).Should(Equal("apache"))
+		})
+	})
+}
+
+func testContentHashLabel() {
+	const hashLabel = "test-content-hash"
+
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	BeforeEach(func() {
+		d.config.ContentHashLabel = hashLabel
+		d.addInitialResource(d.resource)
+	})
+
+	It("should stamp the content hash label on the distributed resource", func() {
+		Eventually(d.federator.Distributed).Should(HaveLen(1))
+
+		distributed := d.federator.Distributed()[0]
+		Expect(resource.ToMeta(distributed).GetLabels()).To(HaveKeyWithValue(hashLabel, resource.ContentHash(d.resource)))
+	})
+}
+
+func testBeforeWrite() {
+	const syncedOpAnnotation = "test-synced-op"
+
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var invokedOps chan syncer.Operation
+
+	BeforeEach(func() {
+		invokedOps = make(chan syncer.Operation, 10)
+
+		d.config.BeforeWrite = func(obj *unstructured.Unstructured, op syncer.Operation) {
+			invokedOps <- op
+
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+
+			annotations[syncedOpAnnotation] = op.String()
+			obj.SetAnnotations(annotations)
+		}
+	})
+
+	distributedAnnotations := func() map[string]string {
+		distributed, found := d.federator.GetDistributed(d.resource.Namespace + "/" + d.resource.Name)
+		if !found {
+			return nil
+		}
+
+		return resource.ToMeta(distributed).GetAnnotations()
+	}
+
+	It("should apply the mutations to the written resource on create and update but not on delete", func() {
+		test.CreateResource(d.sourceClient, d.resource)
+		Eventually(distributedAnnotations).Should(HaveKeyWithValue(syncedOpAnnotation, syncer.Create.String()))
+		Eventually(invokedOps).Should(Receive(Equal(syncer.Create)))
+		Expect(d.resource.Annotations).ToNot(HaveKey(syncedOpAnnotation))
+
+		d.resource.Spec.Containers[0].Image = "apache"
+		test.UpdateResource(d.sourceClient, d.resource)
+		Eventually(distributedAnnotations).Should(HaveKeyWithValue(syncedOpAnnotation, syncer.Update.String()))
+		Eventually(invokedOps).Should(Receive(Equal(syncer.Update)))
+
+		Expect(d.sourceClient.Delete(context.TODO(), d.resource.Name, metav1.DeleteOptions{})).To(Succeed())
+		Eventually(func() int {
+			return d.federator.NumCalls(fake.OpDelete)
+		}).Should(Equal(1))
+
+		calls := d.federator.Calls()
+		Expect(resource.ToMeta(calls[len(calls)-1].Resource).GetAnnotations()).ToNot(HaveKey(syncedOpAnnotation))
+		Consistently(invokedOps).ShouldNot(Receive())
+	})
+}
+
+func testPanicRecovery() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		logger   *logfake.Logger
+		registry *prometheus.Registry
+		badPod   *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		logger = logfake.New()
+		registry = prometheus.NewRegistry()
+		badPod = test.NewPod(d.config.SourceNamespace)
+		badPod.Name = "bad-pod"
+
+		d.config.Log = logger
+		d.config.MetricsRegisterer = registry
+		d.config.SyncErrorsOpts = &prometheus.CounterOpts{
+			Name: "sync_errors_total",
+		}
+
+		d.config.Transform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+			if from.(*corev1.Pod).Name == badPod.Name {
+				panic("bad pod")
+			}
+
+			return from, false, nil
+		}
+	})
+
+	When("the transform function panics on a resource", func() {
+		It("should survive, log the panic and continue processing other resources", func() {
+			test.CreateResource(d.sourceClient, badPod)
+			d.federator.VerifyDistribute(test.CreateResource(d.sourceClient, d.resource))
+
+			Eventually(func() int {
+				count := 0
+
+				for _, e := range logger.Entries() {
+					if e.Error != nil && strings.Contains(e.Error.Error(), "panicked: bad pod") &&
+						strings.Contains(e.Message, "goroutine") {
+						count++
+					}
+				}
+
+				return count
+			}, 5).Should(BeNumerically(">", 1), "Expected the panic to be logged with the stack and retried")
+
+			Eventually(func() float64 {
+				families, err := registry.Gather()
+				Expect(err).To(Succeed())
+
+				for _, family := range families {
+					if family.GetName() == "sync_errors_total" {
+						return family.GetMetric()[0].GetCounter().GetValue()
+					}
+				}
+
+				return 0
+			}, 5).Should(BeNumerically(">", 0))
+
+			test.UpdateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, "updated"))
+			d.federator.VerifyDistribute(test.GetResource(d.sourceClient, d.resource))
+		})
+	})
+}
+
+func testMultiClusterFederator() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var (
+		multiFederator  federate.MultiClusterFederator
+		destinations    map[string]*dynamicfake.DynamicResourceClient
+		addDestinations func(clusterIDs ...string)
+	)
+
+	BeforeEach(func() {
+		multiFederator = federate.NewMultiClusterFederator()
+		destinations = map[string]*dynamicfake.DynamicResourceClient{}
+
+		addDestinations = func(clusterIDs ...string) {
+			for _, clusterID := range clusterIDs {
+				dynClient := dynamicfake.NewDynamicClient(d.config.Scheme)
+				restMapper, gvr := test.GetRESTMapperAndGroupVersionResourceFor(d.config.ResourceType)
+				destinations[clusterID], _ = dynClient.Resource(*gvr).Namespace(test.RemoteNamespace).(*dynamicfake.DynamicResourceClient)
+				multiFederator.AddCluster(clusterID, federate.NewCreateOrUpdateFederator(dynClient, restMapper, test.RemoteNamespace, ""))
+			}
+		}
+
+		addDestinations("east", "west")
+		d.config.Federator = multiFederator
+	})
+
+	awaitImage := func(clusterID, image string) {
+		Eventually(func() string {
+			obj, err := test.GetResourceAndError(destinations[clusterID], d.resource)
+			if err != nil {
+				return ""
+			}
+
+			pod := &corev1.Pod{}
+			Expect(d.config.Scheme.Convert(obj, pod, nil)).To(Succeed())
+
+			return pod.Spec.Containers[0].Image
+		}, 5).Should(Equal(image), "Unexpected image in cluster %q", clusterID)
+	}
+
+	When("a resource is created, destinations change and it's deleted", func() {
+		It("should maintain the correct state in each destination", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			test.AwaitResource(destinations["east"], d.resource.Name)
+			test.AwaitResource(destinations["west"], d.resource.Name)
+
+			By("Adding a destination at runtime and resyncing")
+
+			addDestinations("north")
+			d.syncer.Resync()
+			test.AwaitResource(destinations["north"], d.resource.Name)
+
+			By("Removing a destination")
+
+			multiFederator.RemoveCluster("west")
+			test.UpdateResource(d.sourceClient, test.NewPodWithImage(d.config.SourceNamespace, "updated"))
+			awaitImage("east", "updated")
+			awaitImage("north", "updated")
+			awaitImage("west", d.resource.Spec.Containers[0].Image)
+
+			By("Deleting the source resource")
+
+			Expect(d.sourceClient.Delete(context.TODO(), d.resource.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			test.AwaitNoResource(destinations["east"], d.resource.Name)
+			test.AwaitNoResource(destinations["north"], d.resource.Name)
+			test.AwaitResource(destinations["west"], d.resource.Name)
+		})
+	})
+
+	When("writing to one destination fails", func() {
+		It("should still distribute to the others and retry the failed one", func() {
+			destinations["west"].FailOnCreate = errors.New("fake error")
+
+			test.CreateResource(d.sourceClient, d.resource)
+			test.AwaitResource(destinations["east"], d.resource.Name)
+			test.AwaitResource(destinations["west"], d.resource.Name)
+			Expect(multiFederator.DistributedTo(d.resource)).To(Equal([]string{"east", "west"}))
+		})
+	})
+}
+
+func testLogger() {
+	d := newTestDiver(test.LocalNamespace, "", syncer.LocalToRemote)
+
+	var logger *logfake.Logger
+
+	BeforeEach(func() {
+		logger = logfake.New()
+		d.config.Log = logger
+	})
+
+	When("a resource is synced", func() {
+		It("should log via the configured logger with the syncer name and resource key", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(test.ToUnstructured(d.resource))
+
+			msg := fmt.Sprintf("Syncer %q syncing resource %q", d.config.Name, d.resource.Name)
+
+			Eventually(func() []logfake.Entry {
+				return logger.FindEntries(msg)
+			}, 5).Should(HaveLen(1))
+
+			entry := logger.FindEntries(msg)[0]
+			Expect(entry.Value("syncer")).To(Equal(d.config.Name))
+			Expect(entry.Value("key")).To(Equal(d.resource.Namespace + "/" + d.resource.Name))
+		})
+	})
+
+	When("a resource is updated", func() {
+		It("should log a summary of the changes", func() {
+			test.CreateResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(test.ToUnstructured(d.resource))
+
+			updated := d.resource.DeepCopy()
+			updated.Spec.Containers[0].Image = "apache"
+			test.UpdateResource(d.sourceClient, updated)
+
+			msg := fmt.Sprintf("Syncer %q: resource %q updated: spec.containers[0].image: nginx→apache", d.config.Name,
+				d.resource.Namespace+"/"+d.resource.Name)
+
+			Eventually(func() []logfake.Entry {
+				return logger.FindEntries(msg)
+			}, 5).Should(HaveLen(1))
+		})
+	})
+
+	When("resources are reconciled", func() {
+		It("should log the lines of each reconcile with a correlation ID distinct from other reconciles", func() {
+			syncingMsg := fmt.Sprintf("Syncer %q syncing resource %q", d.config.Name, d.resource.Name)
+			syncedMsg := fmt.Sprintf("Syncer %q successfully synced %q", d.config.Name, d.resource.Name)
+
+			test.CreateResource(d.sourceClient, d.resource)
+			d.federator.VerifyDistribute(test.ToUnstructured(d.resource))
+
+			updated := d.resource.DeepCopy()
+			updated.Spec.Containers[0].Image = "apache"
+			test.UpdateResource(d.sourceClient, updated)
+			d.federator.VerifyDistribute(test.ToUnstructured(updated))
+
+			Eventually(func() []logfake.Entry {
+				return logger.FindEntries(syncedMsg)
+			}, 5).Should(HaveLen(2))
+
+			syncing := logger.FindEntries(syncingMsg)
+			synced := logger.FindEntries(syncedMsg)
+			Expect(syncing).To(HaveLen(2))
+
+			for i := range syncing {
+				Expect(syncing[i].Value(log.CorrelationIDKey)).ToNot(BeNil())
+				Expect(synced[i].Value(log.CorrelationIDKey)).To(Equal(syncing[i].Value(log.CorrelationIDKey)))
+			}
+
+			Expect(syncing[0].Value(log.CorrelationIDKey)).ToNot(Equal(syncing[1].Value(log.CorrelationIDKey)))
+		})
+	})
+}
+
+// clockSteppingFederator advances the given fake clock by the given step on each Distribute.
+type clockSteppingFederator struct {
+	*fake.Federator
+	clock *clock.FakeClock
+	step  time.Duration
+}
+
+func (f *clockSteppingFederator) Distribute(ctx context.Context, obj runtime.Object) error {
+	f.clock.Step(f.step)
+	return f.Federator.Distribute(ctx, obj)
+}
+
+type slowFederator struct {
+	latency  time.Duration
+	err      error
+	calls    int32
+	returned chan error
+}
+
+func (f *slowFederator) Distribute(ctx context.Context, _ runtime.Object) error {
+	atomic.AddInt32(&f.calls, 1)
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-time.After(f.latency):
+		err = f.err
+	}
+
+	select {
+	case f.returned <- err:
+	default:
+	}
+
+	return err
+}
+
+func (f *slowFederator) DistributeAll(ctx context.Context, resources []runtime.Object) map[runtime.Object]error {
+	errs := map[runtime.Object]error{}
+
+	for _, obj := range resources {
+		if err := f.Distribute(ctx, obj); err != nil {
+			errs[obj] = err
+		}
+	}
+
+	return errs
+}
+
+func (f *slowFederator) DistributeDryRun(ctx context.Context, obj runtime.Object) (util.OperationResult, error) {
+	return util.OperationResultNone, f.Distribute(ctx, obj)
+}
+
+func (f *slowFederator) Delete(ctx context.Context, obj runtime.Object) error {
+	return f.Distribute(ctx, obj)
+}
+
+func (f *slowFederator) DeleteAllFor(ctx context.Context, _ string, _ ...schema.GroupVersionResource) error {
+	return nil
+}
+
+func (f *slowFederator) numCalls() int {
+	return int(atomic.LoadInt32(&f.calls))
+}
+
+// delayedRESTMapper doesn't know any resource types until it's been reset the given number of times, simulating a CRD
+// that's established after the syncer is created.
+type delayedRESTMapper struct {
+	metaapi.RESTMapper
+	mutex                sync.Mutex
+	delegate             metaapi.RESTMapper
+	resetsUntilAvailable int
+}
+
+func (m *delayedRESTMapper) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.resetsUntilAvailable > 0 {
+		m.resetsUntilAvailable--
+	}
+}
+
+func (m *delayedRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*metaapi.RESTMapping, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.resetsUntilAvailable > 0 {
+		return m.RESTMapper.RESTMapping(gk, versions...) //nolint:wrapcheck // OK to return the error as is.
+	}
+
+	return m.delegate.RESTMapping(gk, versions...) //nolint:wrapcheck // OK to return the error as is.
+}
+
+type testDriver struct {
+	config             syncer.ResourceSyncerConfig
+	syncer             syncer.Interface
+	sourceClient       dynamic.ResourceInterface
+	federator          *fake.Federator
+	initialResources   []runtime.Object
+	stopCh             chan struct{}
+	resource           *corev1.Pod
+	savedErrorHandlers []func(error)
+	handledError       chan error
+}
+
+func newTestDiver(sourceNamespace, localClusterID string, syncDirection syncer.SyncDirection) *testDriver {
+	resourceType := &corev1.Pod{}
+	d := &testDriver{
+		config: syncer.ResourceSyncerConfig{
+			Name:            "test",
+			SourceNamespace: sourceNamespace,
+			LocalClusterID:  localClusterID,
+			ResourceType:    resourceType,
+			Direction:       syncDirection,
+		},
+	}
+
+	BeforeEach(func() {
+		d.federator = fake.New()
+		d.config.Federator = d.federator
+		d.initialResources = nil
+		d.resource = test.NewPod(sourceNamespace)
+		d.stopCh = make(chan struct{})
+		d.savedErrorHandlers = utilruntime.ErrorHandlers
+		d.handledError = make(chan error, 1000)
+		d.config.Scheme = runtime.NewScheme()
+		d.config.Transform = nil
+		d.config.OnSuccessfulSync = nil
+		d.config.ResourcesEquivalent = nil
+		d.config.EnqueueRelated = nil
+		d.config.Dependencies = nil
+		d.config.FanOutTransform = nil
+		d.config.DeleteTransform = nil
+		d.config.OnFanOutResult = nil
+
+		err := corev1.AddToScheme(d.config.Scheme)
+		Expect(err).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		initObjs := test.PrepInitialClientObjs(d.config.SourceNamespace, "", d.initialResources...)
+
+		resourceTypes := []runtime.Object{d.config.ResourceType}
+		for i := range d.config.Dependencies {
+			resourceTypes = append(resourceTypes, d.config.Dependencies[i].ResourceType)
+		}
+
+		restMapper := test.GetRESTMapperFor(resourceTypes...)
+		gvr := test.GetGroupVersionResourceFor(restMapper, d.config.ResourceType)
+
+		d.config.RestMapper = restMapper
+		d.config.SourceClient = fakeClient.NewSimpleDynamicClient(d.config.Scheme, initObjs...)
+
+		d.sourceClient = d.config.SourceClient.Resource(*gvr).Namespace(d.config.SourceNamespace)
+
+		var err error
+		d.syncer, err = syncer.NewResourceSyncer(&d.config)
+		Expect(err).To(Succeed())
+
+		utilruntime.ErrorHandlers = append(utilruntime.ErrorHandlers, func(err error) {
+			d.handledError <- err
+		})
+
+		Expect(d.syncer.Start(d.stopCh)).To(Succeed())
+	})
+
+	JustAfterEach(func() {
+		select {
+		case <-d.stopCh:
+		default:
+			close(d.stopCh)
+		}
 
-	JustAfterEach(func() {
-		close(d.stopCh)
 		d.syncer.AwaitStopped()
 		utilruntime.ErrorHandlers = d.savedErrorHandlers
 	})
@@ -0,0 +1,108 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type queuedChange struct {
+	at time.Time
+
+	// explicit indicates the resource was queued other than by an informer event, eg via EnqueueKey, in which case its
+	// timestamps don't reflect the change being synced.
+	explicit bool
+}
+
+// changeQueued records the time a change to the resource with the given key was first queued, if not already recorded,
+// as the fallback for the start of its sync lag.
+func (r *resourceSyncer) changeQueued(key string, explicit bool) {
+	if r.syncLag != nil {
+		r.changesQueued.LoadOrStore(key, queuedChange{at: r.config.Clock.Now(), explicit: explicit})
+	}
+}
+
+// recordSyncLag records the time from the change to the given source resource until now, ie the successful completion
+// of its downstream write. See ResourceSyncerConfig.SyncLagOpts.
+func (r *resourceSyncer) recordSyncLag(source *unstructured.Unstructured, key string, op Operation) {
+	if r.syncLag == nil {
+		return
+	}
+
+	var queued queuedChange
+
+	if v, found := r.changesQueued.LoadAndDelete(key); found {
+		queued, _ = v.(queuedChange)
+	}
+
+	var changedAt time.Time
+	if !queued.explicit {
+		changedAt = changeTime(source, op)
+	}
+
+	// The timestamps have a granularity of seconds so the start time is truncated likewise for comparison.
+	if changedAt.IsZero() || changedAt.Before(r.startedAt.Truncate(time.Second)) {
+		if queued.at.IsZero() {
+			return
+		}
+
+		changedAt = queued.at
+	}
+
+	lag := r.config.Clock.Since(changedAt)
+
+	// The API server's clock may be ahead of ours.
+	if lag < 0 {
+		lag = 0
+	}
+
+	r.syncLag.With(prometheus.Labels{
+		DirectionLabel:  r.config.Direction.String(),
+		SyncerNameLabel: r.config.Name,
+	}).Observe(lag.Seconds())
+}
+
+// changeTime returns the time of the last change to the given resource as recorded by the API server, or zero if it
+// isn't recorded or, for a resync, there's no change.
+func changeTime(resource *unstructured.Unstructured, op Operation) time.Time {
+	if op == Resync {
+		return time.Time{}
+	}
+
+	if op == Delete {
+		if deletedAt := resource.GetDeletionTimestamp(); deletedAt != nil {
+			return deletedAt.Time
+		}
+
+		return time.Time{}
+	}
+
+	changedAt := resource.GetCreationTimestamp().Time
+
+	for _, entry := range resource.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(changedAt) {
+			changedAt = entry.Time.Time
+		}
+	}
+
+	return changedAt
+}
@@ -18,12 +18,62 @@ limitations under the License.
 
 package syncer
 
-import "k8s.io/apimachinery/pkg/runtime"
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
 type Interface interface {
 	Start(stopCh <-chan struct{}) error
 	AwaitStopped()
 	GetResource(name, namespace string) (runtime.Object, bool, error)
+
+	// GetLiveResource retrieves the named resource directly from the source via the SourceClient, bypassing the informer
+	// cache, eg to obtain the full resource when the MetadataClient is used.
+	GetLiveResource(name, namespace string) (runtime.Object, bool, error)
 	ListResources() ([]runtime.Object, error)
+
+	// ListResourcesBySelector returns the resources in the informer cache whose labels match the given selector.
+	ListResourcesBySelector(selector labels.Selector) ([]runtime.Object, error)
+
+	// ByIndex returns the resources in the informer cache whose value for the given index, as registered via
+	// ResourceSyncerConfig.Indexers, matches the given value.
+	ByIndex(indexName, value string) ([]*unstructured.Unstructured, error)
 	Reconcile(resourceLister func() []runtime.Object)
+
+	// Resync re-queues every resource in the informer cache to be processed again as an update. It returns immediately
+	// without waiting for the resources to be processed.
+	Resync()
+
+	// Enqueue queues the resource with the given namespace and name to be processed as an update, as if an informer event
+	// occurred, eg on an external trigger. The resource is retrieved from the informer cache or, if not present, from the
+	// source via a live get. The namespace is empty for a cluster-scoped resource.
+	Enqueue(namespace, name string)
+
+	// EnqueueKey is like Enqueue but the resource is identified by its namespace/name key.
+	EnqueueKey(key string)
+
+	// PendingKeys returns a snapshot of the namespace/name keys of the resources currently queued to be processed,
+	// excluding those being processed, eg for a debug endpoint.
+	PendingKeys() []string
+
+	// Healthy returns an error describing the problem if the syncer isn't functioning, ie listing or watching the source
+	// resources has been failing for longer than the WatchFailureThreshold or the work queue hasn't drained within the
+	// QueueDrainThreshold. It's suitable for a liveness probe.
+	Healthy() error
+
+	// LastResourceVersion returns the highest resource version of the source resources seen by the informer, via a list
+	// or a watch event, including a bookmark, or an empty string if none yet, eg to diagnose a stale watch.
+	LastResourceVersion() string
+
+	// LastEventTime returns the time at which the informer last received a watch event, including a bookmark, or the
+	// zero time if none yet.
+	LastEventTime() time.Time
+
+	// Ready returns an error if the syncer isn't yet ready, ie its informer cache hasn't synced. It's suitable for a
+	// readiness probe.
+	Ready() error
 }
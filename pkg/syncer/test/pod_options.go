@@ -0,0 +1,82 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodOption customizes a test Pod created by NewPod.
+type PodOption func(pod *corev1.Pod)
+
+// WithName sets the Pod's name.
+func WithName(name string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Name = name
+	}
+}
+
+// WithNamespace sets the Pod's namespace, overriding the namespace passed to NewPod.
+func WithNamespace(namespace string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Namespace = namespace
+	}
+}
+
+// WithImage sets the image of the Pod's container.
+func WithImage(imageName string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Spec.Containers[0].Image = imageName
+	}
+}
+
+// WithLabels replaces the Pod's labels.
+func WithLabels(labels map[string]string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Labels = copyMap(labels)
+	}
+}
+
+// WithAnnotations replaces the Pod's annotations.
+func WithAnnotations(annotations map[string]string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Annotations = copyMap(annotations)
+	}
+}
+
+// WithOwnerReferences adds the given owner references to the Pod.
+func WithOwnerReferences(refs ...metav1.OwnerReference) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.OwnerReferences = append(pod.OwnerReferences, refs...)
+	}
+}
+
+func copyMap(from map[string]string) map[string]string {
+	if from == nil {
+		return nil
+	}
+
+	to := make(map[string]string, len(from))
+	for k, v := range from {
+		to[k] = v
+	}
+
+	return to
+}
@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NewPod", func() {
+	When("no options are specified", func() {
+		It("should return the default Pod", func() {
+			pod := test.NewPod(test.LocalNamespace)
+			Expect(pod.Name).To(Equal("test-pod"))
+			Expect(pod.Namespace).To(Equal(test.LocalNamespace))
+			Expect(pod.Labels).To(Equal(map[string]string{"app": "test"}))
+			Expect(pod.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+			Expect(pod.OwnerReferences).To(BeEmpty())
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+			Expect(pod.Spec.Containers[0].Image).To(Equal("nginx"))
+
+			Expect(test.NewPodWithImage(test.LocalNamespace, "apache").Spec.Containers[0].Image).To(Equal("apache"))
+		})
+	})
+
+	When("several options are specified", func() {
+		It("should return a Pod with all of them applied", func() {
+			labels := map[string]string{"role": "backend"}
+			owner := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "1234"}
+
+			pod := test.NewPod(test.LocalNamespace,
+				test.WithName("custom-pod"),
+				test.WithNamespace(test.RemoteNamespace),
+				test.WithImage("apache"),
+				test.WithLabels(labels),
+				test.WithAnnotations(map[string]string{"note": "custom"}),
+				test.WithOwnerReferences(owner))
+
+			Expect(pod.Name).To(Equal("custom-pod"))
+			Expect(pod.Namespace).To(Equal(test.RemoteNamespace))
+			Expect(pod.Spec.Containers[0].Image).To(Equal("apache"))
+			Expect(pod.Labels).To(Equal(map[string]string{"role": "backend"}))
+			Expect(pod.Annotations).To(Equal(map[string]string{"note": "custom"}))
+			Expect(pod.OwnerReferences).To(Equal([]metav1.OwnerReference{owner}))
+			Expect(pod.UID).ToNot(BeEmpty())
+
+			labels["role"] = "frontend"
+			Expect(pod.Labels).To(HaveKeyWithValue("role", "backend"))
+		})
+	})
+})
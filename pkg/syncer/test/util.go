@@ -107,12 +107,9 @@ func GetPod(resourceInterface dynamic.ResourceInterface, from *corev1.Pod) *core
 	return actual
 }
 
-func NewPod(namespace string) *corev1.Pod {
-	return NewPodWithImage(namespace, "nginx")
-}
-
-func NewPodWithImage(namespace, imageName string) *corev1.Pod {
-	return &corev1.Pod{
+// NewPod returns a test Pod in the given namespace, with the image "nginx", customized by the given options.
+func NewPod(namespace string, opts ...PodOption) *corev1.Pod {
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            "test-pod",
 			Namespace:       namespace,
@@ -124,12 +121,22 @@ func NewPodWithImage(namespace, imageName string) *corev1.Pod {
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
-					Image: imageName,
+					Image: "nginx",
 					Name:  "httpd",
 				},
 			},
 		},
 	}
+
+	for _, opt := range opts {
+		opt(pod)
+	}
+
+	return pod
+}
+
+func NewPodWithImage(namespace, imageName string) *corev1.Pod {
+	return NewPod(namespace, WithImage(imageName))
 }
 
 func GetRESTMapperAndGroupVersionResourceFor(obj runtime.Object) (metaapi.RESTMapper, *schema.GroupVersionResource) {
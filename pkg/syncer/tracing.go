@@ -0,0 +1,146 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// TracerName the instrumentation name passed to the TracerProvider.
+	TracerName = "github.com/submariner-io/admiral/pkg/syncer"
+
+	// ReconcileSpanName the name of the span created for each reconcile.
+	ReconcileSpanName = "syncer.reconcile"
+
+	// TraceParentAnnotation the annotation on a source resource carrying a W3C traceparent to which its reconcile spans
+	// are linked.
+	TraceParentAnnotation = "submariner-io/traceparent"
+
+	SpanSyncerNameAttribute = "syncer.name"
+	SpanResourceAttribute   = "syncer.resource"
+	SpanDirectionAttribute  = "syncer.direction"
+	SpanOperationAttribute  = "syncer.operation"
+	SpanResultAttribute     = "syncer.result"
+
+	// SpanResultSynced the SpanResultAttribute value when the resource was successfully processed.
+	SpanResultSynced = "synced"
+
+	// SpanResultRequeued the SpanResultAttribute value when the resource was re-queued without error, eg as requested
+	// by the Transform function.
+	SpanResultRequeued = "requeued"
+
+	// SpanResultFailed the SpanResultAttribute value when processing the resource failed.
+	SpanResultFailed = "failed"
+)
+
+// SpanStatusCode the status of a span, corresponding to the OpenTelemetry codes.Code values.
+type SpanStatusCode int
+
+const (
+	SpanStatusUnset SpanStatusCode = iota
+	SpanStatusError
+	SpanStatusOK
+)
+
+// TracerProvider provides the Tracer used by a syncer to create spans. It's modeled on the OpenTelemetry trace API so an
+// OpenTelemetry TracerProvider can be adapted with a thin wrapper that extracts the linked span context via the
+// TraceContext propagator and passes it with trace.WithLinks.
+type TracerProvider interface {
+	Tracer(instrumentationName string) Tracer
+}
+
+// Tracer creates spans.
+type Tracer interface {
+	// Start creates and starts a span with the given attributes. If linkedTraceParent isn't empty, it's a W3C traceparent
+	// identifying a span to which the new span should be linked.
+	Start(ctx context.Context, spanName, linkedTraceParent string, attributes map[string]string) Span
+}
+
+// Span a started span.
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	SetStatus(code SpanStatusCode, description string)
+	End()
+}
+
+// reconcileSpan wraps the Span, if any, for a reconcile. A nil reconcileSpan is a no-op.
+type reconcileSpan struct {
+	span Span
+}
+
+// startSpan starts the span for reconciling the resource with the given key or returns nil if tracing isn't enabled.
+// The resource is looked up, as it's later retrieved for processing, to determine the operation and linked trace
+// context.
+func (r *resourceSyncer) startSpan(ctx context.Context, key string) *reconcileSpan {
+	if r.tracer == nil {
+		return nil
+	}
+
+	op := Update
+
+	var resource *unstructured.Unstructured
+
+	if obj, exists, _ := r.store.GetByKey(key); exists {
+		resource = r.assertUnstructured(obj)
+
+		if _, found := r.created.Load(key); found {
+			op = Create
+		}
+	} else if obj, found := r.deleted.Load(key); found {
+		resource = r.assertUnstructured(obj)
+		op = Delete
+	}
+
+	traceParent := ""
+	if resource != nil {
+		traceParent = resource.GetAnnotations()[TraceParentAnnotation]
+	}
+
+	return &reconcileSpan{span: r.tracer.Start(ctx, ReconcileSpanName, traceParent, map[string]string{
+		SpanSyncerNameAttribute: r.config.Name,
+		SpanResourceAttribute:   key,
+		SpanDirectionAttribute:  r.config.Direction.String(),
+		SpanOperationAttribute:  op.String(),
+	})}
+}
+
+func (s *reconcileSpan) end(requeue bool, err error) {
+	if s == nil {
+		return
+	}
+
+	switch {
+	case err != nil:
+		s.span.SetAttribute(SpanResultAttribute, SpanResultFailed)
+		s.span.RecordError(err)
+		s.span.SetStatus(SpanStatusError, err.Error())
+	case requeue:
+		s.span.SetAttribute(SpanResultAttribute, SpanResultRequeued)
+		s.span.SetStatus(SpanStatusOK, "")
+	default:
+		s.span.SetAttribute(SpanResultAttribute, SpanResultSynced)
+		s.span.SetStatus(SpanStatusOK, "")
+	}
+
+	s.span.End()
+}
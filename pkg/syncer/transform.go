@@ -0,0 +1,100 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// invokeTransform invokes the configured transform function for the given resource. The TransformWithContext function
+// is bounded by the TransformTimeout, if specified, via its context. The transform runs on the calling worker so it
+// never overlaps a retry of the same resource.
+func (r *resourceSyncer) invokeTransform(ctx context.Context, key string, converted runtime.Object, op Operation,
+	useDeleteTransform bool,
+) (runtime.Object, bool, error) {
+	if r.config.TransformTimeout <= 0 || useDeleteTransform || r.config.TransformWithContext == nil {
+		return r.callTransform(ctx, key, converted, op, useDeleteTransform)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.TransformTimeout)
+	defer cancel()
+
+	transformed, requeue, err := r.callTransform(ctx, key, converted, op, useDeleteTransform)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, false, errors.Wrapf(err, "transform of resource %q timed out after %v", key, r.config.TransformTimeout)
+	}
+
+	return transformed, requeue, err
+}
+
+func (r *resourceSyncer) callTransform(ctx context.Context, key string, converted runtime.Object, op Operation,
+	useDeleteTransform bool,
+) (runtime.Object, bool, error) {
+	var (
+		transformed runtime.Object
+		requeue     bool
+	)
+
+	err := r.recoverPanic(ctx, key, "transform", func() error {
+		var err error
+
+		switch {
+		case useDeleteTransform:
+			transformed, requeue, err = r.config.DeleteTransform(converted, r.workQueue.NumRequeues(key), op)
+		case r.config.TransformWithContext != nil:
+			transformed, requeue, err = r.config.TransformWithContext(ctx, converted, r.workQueue.NumRequeues(key), op)
+		case r.config.TransformWithPrevious != nil:
+			transformed, requeue, err = r.config.TransformWithPrevious(converted, r.previousFor(key, op),
+				r.workQueue.NumRequeues(key), op)
+		default:
+			transformed, requeue, err = r.config.Transform(converted, r.workQueue.NumRequeues(key), op)
+		}
+
+		return err
+	})
+
+	return transformed, requeue, err
+}
+
+// convertForTransform converts the given resource to the representation passed to the transform functions. If
+// TypedTransform is set and the ResourceType is Unstructured, a resource whose kind is registered in the Scheme is
+// converted to its typed representation. Otherwise it's converted to the ResourceType. Nil is returned if the
+// conversion fails.
+func (r *resourceSyncer) convertForTransform(from *unstructured.Unstructured) runtime.Object {
+	if _, ok := r.config.ResourceType.(*unstructured.Unstructured); !ok || !r.config.TypedTransform {
+		return r.convertNoError(from)
+	}
+
+	typed, err := r.config.Scheme.New(from.GroupVersionKind())
+	if err != nil {
+		// Not registered so leave it unstructured.
+		return from.DeepCopy()
+	}
+
+	if err := r.converter.FromUnstructured(from, typed); err != nil {
+		r.log.Errorf(err, "Syncer %q: unable to convert %#v to %T", r.config.Name, from, typed)
+		return nil
+	}
+
+	return typed
+}
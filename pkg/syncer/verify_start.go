@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const verifyOnStartTimeout = 30 * time.Second
+
+// verifyListWatch attempts to list and then watch the source resources, as the informer would, returning an error if
+// either fails due to a fatal misconfiguration. Transient errors are logged and ignored.
+func (r *resourceSyncer) verifyListWatch() error {
+	ctx, cancel := context.WithTimeout(context.Background(), verifyOnStartTimeout)
+	defer cancel()
+
+	options := metav1.ListOptions{
+		LabelSelector: r.config.SourceLabelSelector,
+		FieldSelector: r.config.SourceFieldSelector,
+		Limit:         1,
+	}
+
+	list, err := r.listResources(ctx, options)
+	if err != nil {
+		return r.verifyFailed(err, "list")
+	}
+
+	options.Limit = 0
+	options.ResourceVersion = list.GetResourceVersion()
+
+	w, err := r.watchResources(ctx, options)
+	if err != nil {
+		return r.verifyFailed(err, "watch")
+	}
+
+	w.Stop()
+
+	return nil
+}
+
+func (r *resourceSyncer) verifyFailed(err error, verb string) error {
+	if !isFatalListWatchError(err) {
+		r.log.Warningf("Syncer %q: unable to %s %q on start - retrying in the background: %v", r.config.Name, verb,
+			r.gvr.Resource, err)
+
+		return nil
+	}
+
+	if apierrors.IsForbidden(err) {
+		return r.permissionError(err, verb)
+	}
+
+	return errors.Wrapf(err, "syncer %q: unable to %s %q", r.config.Name, verb, r.gvr.Resource)
+}
+
+func isFatalListWatchError(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) ||
+		apierrors.IsBadRequest(err) || apierrors.IsInvalid(err)
+}
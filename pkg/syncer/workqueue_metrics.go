@@ -0,0 +1,117 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	WorkQueueDepthMetricName        = "syncer_workqueue_depth"
+	WorkQueueAddsMetricName         = "syncer_workqueue_adds_total"
+	WorkQueueRetriesMetricName      = "syncer_workqueue_retries_total"
+	WorkQueueWorkDurationMetricName = "syncer_workqueue_work_duration_seconds"
+)
+
+// workQueueMetricsProvider is a client-go workqueue.MetricsProvider that creates prometheus metrics labeled by syncer
+// name. Only the depth, adds, retries and work duration metrics are used by our work queue.
+type workQueueMetricsProvider struct {
+	depth        *prometheus.GaugeVec
+	adds         *prometheus.CounterVec
+	retries      *prometheus.CounterVec
+	workDuration *prometheus.HistogramVec
+}
+
+// newWorkQueueMetricsProvider returns a provider whose metrics are registered with the given registerer. The metrics
+// are shared by all syncers so, if they're already registered, the existing ones are used.
+func newWorkQueueMetricsProvider(registerer prometheus.Registerer) *workQueueMetricsProvider {
+	labels := []string{SyncerNameLabel}
+
+	return &workQueueMetricsProvider{
+		depth: register(registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: WorkQueueDepthMetricName,
+			Help: "Current number of resources waiting to be processed by the syncer",
+		}, labels)).(*prometheus.GaugeVec),
+		adds: register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: WorkQueueAddsMetricName,
+			Help: "Total number of resources enqueued by the syncer",
+		}, labels)).(*prometheus.CounterVec),
+		retries: register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: WorkQueueRetriesMetricName,
+			Help: "Total number of resources re-queued by the syncer after processing",
+		}, labels)).(*prometheus.CounterVec),
+		workDuration: register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    WorkQueueWorkDurationMetricName,
+			Help:    "How long in seconds it takes the syncer to process a resource",
+			Buckets: prometheus.ExponentialBuckets(10e-9, 10, 10),
+		}, labels)).(*prometheus.HistogramVec),
+	}
+}
+
+func register(registerer prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	err := registerer.Register(collector)
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return alreadyRegistered.ExistingCollector
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return collector
+}
+
+func (p *workQueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.depth.WithLabelValues(name)
+}
+
+func (p *workQueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.adds.WithLabelValues(name)
+}
+
+func (p *workQueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return noopMetric{}
+}
+
+func (p *workQueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return p.workDuration.WithLabelValues(name)
+}
+
+func (p *workQueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (p *workQueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (p *workQueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.retries.WithLabelValues(name)
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Dec()            {}
+func (noopMetric) Set(float64)     {}
+func (noopMetric) Observe(float64) {}
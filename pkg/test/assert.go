@@ -21,6 +21,8 @@ package test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/onsi/gomega"
@@ -28,9 +30,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/testing"
 )
 
@@ -50,6 +56,48 @@ func EnsureNoActionsForResource(f *testing.Fake, resourceType string, expectedVe
 	}).Should(BeEmpty())
 }
 
+// ExpectActions asserts that the verbs of the actions recorded by the given Fake for the given resource type, eg
+// "pods", are exactly the expected verbs in order. Actions for other resource types are ignored. On mismatch, the test
+// fails with a line-by-line diff of the expected and actual verbs.
+func ExpectActions(f *testing.Fake, resourceType string, expectedVerbs ...string) {
+	actualVerbs := []string{}
+
+	actualActions := f.Actions()
+	for i := range actualActions {
+		if actualActions[i].GetResource().Resource == resourceType {
+			actualVerbs = append(actualVerbs, actualActions[i].GetVerb())
+		}
+	}
+
+	if expectedVerbs == nil {
+		expectedVerbs = []string{}
+	}
+
+	Expect(actualVerbs).To(Equal(expectedVerbs), func() string {
+		return fmt.Sprintf("Unexpected actions for resource %q (-expected +actual):\n%s", resourceType,
+			verbsDiff(expectedVerbs, actualVerbs))
+	})
+}
+
+func verbsDiff(expected, actual []string) string {
+	var diff strings.Builder
+
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			fmt.Fprintf(&diff, "- %d: %s\n", i, expected[i])
+		case i >= len(expected):
+			fmt.Fprintf(&diff, "+ %d: %s\n", i, actual[i])
+		case expected[i] != actual[i]:
+			fmt.Fprintf(&diff, "- %d: %s\n+ %d: %s\n", i, expected[i], i, actual[i])
+		default:
+			fmt.Fprintf(&diff, "  %d: %s\n", i, expected[i])
+		}
+	}
+
+	return diff.String()
+}
+
 func AwaitFinalizer(client resource.Interface, name, finalizer string) {
 	Eventually(func() []string {
 		return GetFinalizers(client, name)
@@ -126,6 +174,38 @@ func EnsureNoResource(client resource.Interface, name string) {
 	}, 3).Should(BeTrue(), "Found unexpected resource %q", name)
 }
 
+// AwaitCount polls until the number of resources of the given type in the given namespace whose labels match the given
+// selector equals the expected count and returns them. An expected count of zero awaits their absence. If the count
+// isn't reached, the test fails with a description of the resources that were found.
+func AwaitCount(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string, selector labels.Selector,
+	n int,
+) []unstructured.Unstructured {
+	var found []unstructured.Unstructured
+
+	Eventually(func() (int, error) {
+		list, err := client.Resource(gvr).Namespace(namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		found = list.Items
+
+		return len(found), nil
+	}, 3).Should(Equal(n), func() string {
+		names := make([]string, len(found))
+		for i := range found {
+			names[i] = found[i].GetNamespace() + "/" + found[i].GetName()
+		}
+
+		return fmt.Sprintf("Expected %d %q resources in namespace %q matching selector %q but found %d: [%s]", n,
+			gvr.Resource, namespace, selector.String(), len(found), strings.Join(names, ", "))
+	})
+
+	return found
+}
+
 func AwaitUpdateAction(f *testing.Fake, resourceType, name string) runtime.Object {
 	var retObj runtime.Object
 
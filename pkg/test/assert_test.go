@@ -0,0 +1,187 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	synctest "github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	fakeClient "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("AwaitCount", func() {
+	const namespace = "test-ns"
+
+	var (
+		client    dynamic.Interface
+		podClient dynamic.ResourceInterface
+		selector  labels.Selector
+	)
+
+	gvr := corev1.SchemeGroupVersion.WithResource("pods")
+
+	newPod := func(name string, podLabels map[string]string) *corev1.Pod {
+		pod := synctest.NewPod(namespace)
+		pod.Name = name
+		pod.Labels = podLabels
+
+		return pod
+	}
+
+	createLater := func(pods ...*corev1.Pod) {
+		go func() {
+			defer GinkgoRecover()
+
+			time.Sleep(200 * time.Millisecond)
+
+			for _, pod := range pods {
+				synctest.CreateResource(podClient, pod)
+			}
+		}()
+	}
+
+	BeforeEach(func() {
+		client = fake.NewDynamicClient(scheme.Scheme)
+		podClient = client.Resource(gvr).Namespace(namespace)
+		selector = labels.SelectorFromSet(map[string]string{"app": "match"})
+
+		synctest.CreateResource(podClient, newPod("not-matching", map[string]string{"app": "other"}))
+	})
+
+	When("the expected number of matching resources appear", func() {
+		It("should return them", func() {
+			createLater(newPod("match-1", map[string]string{"app": "match"}), newPod("match-2", map[string]string{"app": "match"}))
+
+			found := test.AwaitCount(client, gvr, namespace, selector, 2)
+			Expect(found).To(HaveLen(2))
+			Expect([]string{found[0].GetName(), found[1].GetName()}).To(ConsistOf("match-1", "match-2"))
+		})
+	})
+
+	When("the expected number isn't reached", func() {
+		It("should fail with a description of what was found", func() {
+			synctest.CreateResource(podClient, newPod("match-1", map[string]string{"app": "match"}))
+
+			failures := InterceptGomegaFailures(func() {
+				test.AwaitCount(client, gvr, namespace, selector, 2)
+			})
+
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring(
+				"Expected 2 \"pods\" resources in namespace \"test-ns\" matching selector \"app=match\" but found 1: [test-ns/match-1]"))
+		})
+	})
+
+	When("zero matching resources are expected", func() {
+		It("should await their absence", func() {
+			synctest.CreateResource(podClient, newPod("match-1", map[string]string{"app": "match"}))
+
+			go func() {
+				defer GinkgoRecover()
+
+				time.Sleep(200 * time.Millisecond)
+				Expect(podClient.Delete(context.TODO(), "match-1", metav1.DeleteOptions{})).To(Succeed())
+			}()
+
+			Expect(test.AwaitCount(client, gvr, namespace, selector, 0)).To(BeEmpty())
+		})
+	})
+
+	When("a resource in another namespace matches", func() {
+		It("should not count it", func() {
+			pod := newPod("match-1", map[string]string{"app": "match"})
+			pod.Namespace = "other-ns"
+			synctest.CreateResource(client.Resource(gvr).Namespace(pod.Namespace), pod)
+
+			Expect(test.AwaitCount(client, gvr, namespace, selector, 0)).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("ExpectActions", func() {
+	var (
+		dynClient *fakeClient.FakeDynamicClient
+		pod       *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		dynClient = fakeClient.NewSimpleDynamicClient(scheme.Scheme)
+		pods := dynClient.Resource(corev1.SchemeGroupVersion.WithResource("pods")).Namespace("test-ns")
+		pod = synctest.NewPod("test-ns")
+
+		_, err := pods.Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		Expect(err).To(HaveOccurred())
+
+		synctest.CreateResource(dynClient.Resource(corev1.SchemeGroupVersion.WithResource("services")).Namespace("test-ns"),
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-svc"}})
+		synctest.CreateResource(pods, pod)
+	})
+
+	When("the actions match the expected sequence", func() {
+		It("should succeed and ignore actions on other resources", func() {
+			test.ExpectActions(&dynClient.Fake, "pods", "get", "create")
+			test.ExpectActions(&dynClient.Fake, "services", "create")
+			test.ExpectActions(&dynClient.Fake, "nodes")
+		})
+	})
+
+	When("the actions are in a different order", func() {
+		It("should fail with a diff", func() {
+			failures := InterceptGomegaFailures(func() {
+				test.ExpectActions(&dynClient.Fake, "pods", "create", "get")
+			})
+
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring("Unexpected actions for resource \"pods\" (-expected +actual):\n" +
+				"- 0: create\n+ 0: get\n- 1: get\n+ 1: create\n"))
+		})
+	})
+
+	When("there's an extra action", func() {
+		It("should fail with a diff", func() {
+			failures := InterceptGomegaFailures(func() {
+				test.ExpectActions(&dynClient.Fake, "pods", "get")
+			})
+
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring("  0: get\n+ 1: create\n"))
+		})
+	})
+
+	When("an action is missing", func() {
+		It("should fail with a diff", func() {
+			failures := InterceptGomegaFailures(func() {
+				test.ExpectActions(&dynClient.Fake, "pods", "get", "create", "update")
+			})
+
+			Expect(failures).To(HaveLen(1))
+			Expect(failures[0]).To(ContainSubstring("  1: create\n- 2: update\n"))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// RelabelFn returns the labels with which an orphaned object is adopted, given a copy of its current labels.
+type RelabelFn func(labels map[string]string) map[string]string
+
+// AdoptOrphans lists the objects via the given client that match the given label selector, typically one that identified
+// objects managed by a previous version of a controller, and relabels each via the given RelabelFn so a syncer
+// configured with the new labels adopts them rather than creating duplicates. An object whose labels are already as
+// desired isn't updated so it's safe to run repeatedly. Objects that are being deleted are skipped. The number of
+// objects that were relabeled is returned.
+func AdoptOrphans(ctx context.Context, client dynamic.ResourceInterface, oldSelector string, relabel RelabelFn) (int, error) {
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: oldSelector})
+	if err != nil {
+		return 0, errors.Wrapf(err, "error listing orphaned resources with label selector %q", oldSelector)
+	}
+
+	resourceClient := resource.ForDynamic(client)
+	adopted := 0
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		result, err := maybeCreateOrUpdate(ctx, resourceClient, obj, func(existing runtime.Object) (runtime.Object, error) {
+			objMeta := resource.ToMeta(existing)
+
+			labels := map[string]string{}
+			for k, v := range objMeta.GetLabels() {
+				labels[k] = v
+			}
+
+			objMeta.SetLabels(relabel(labels))
+
+			return existing, nil
+		}, createOrUpdateOptions{update: resourceClient.Update})
+		if err != nil {
+			return adopted, errors.Wrapf(err, "error adopting resource %q", obj.GetName())
+		}
+
+		if result == OperationResultUpdated {
+			logger.V(log.LIBDEBUG).Infof("Adopted orphaned resource %q", obj.GetName())
+			adopted++
+		}
+	}
+
+	return adopted, nil
+}
@@ -0,0 +1,147 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	oldManagedByLabel = "old-controller/managed"
+	newManagedByLabel = "new-controller/managed"
+)
+
+var _ = Describe("AdoptOrphans", func() {
+	var (
+		client  *fake.DynamicResourceClient
+		relabel util.RelabelFn
+	)
+
+	newPod := func(name string, labels map[string]string) *corev1.Pod {
+		pod := test.NewPod("")
+		pod.Name = name
+		pod.Labels = labels
+
+		return pod
+	}
+
+	BeforeEach(func() {
+		client, _ = fake.NewDynamicClient(scheme.Scheme).Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test").(*fake.DynamicResourceClient)
+
+		relabel = func(labels map[string]string) map[string]string {
+			delete(labels, oldManagedByLabel)
+			labels[newManagedByLabel] = "true"
+
+			return labels
+		}
+
+		test.CreateResource(client, newPod("orphan-1", map[string]string{oldManagedByLabel: "true", "app": "one"}))
+		test.CreateResource(client, newPod("orphan-2", map[string]string{oldManagedByLabel: "true"}))
+		test.CreateResource(client, newPod("unrelated", map[string]string{"app": "other"}))
+	})
+
+	adoptOrphans := func() int {
+		n, err := util.AdoptOrphans(context.TODO(), client, oldManagedByLabel+"=true", relabel)
+		Expect(err).To(Succeed())
+
+		return n
+	}
+
+	listNames := func(selector string) []string {
+		list, err := client.List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+		Expect(err).To(Succeed())
+
+		names := []string{}
+		for i := range list.Items {
+			names = append(names, list.Items[i].GetName())
+		}
+
+		return names
+	}
+
+	It("should relabel the objects matching the old selector", func() {
+		Expect(adoptOrphans()).To(Equal(2))
+
+		Expect(listNames(newManagedByLabel)).To(ConsistOf("orphan-1", "orphan-2"))
+		Expect(listNames(oldManagedByLabel)).To(BeEmpty())
+		Expect(test.GetResource(client, newPod("orphan-1", nil)).GetLabels()).To(Equal(map[string]string{
+			newManagedByLabel: "true", "app": "one",
+		}))
+		Expect(test.GetResource(client, newPod("unrelated", nil)).GetLabels()).To(Equal(map[string]string{"app": "other"}))
+	})
+
+	It("should subsequently manage the adopted objects without duplication", func() {
+		adoptOrphans()
+
+		desired := newPod("orphan-1", map[string]string{newManagedByLabel: "true", "app": "one"})
+		desired.Spec.Hostname = "adopted"
+
+		result, err := util.CreateOrUpdate(context.TODO(), resource.ForDynamic(client), desired, util.Replace(desired))
+		Expect(err).To(Succeed())
+		Expect(result).To(Equal(util.OperationResultUpdated))
+
+		Expect(listNames("")).To(HaveLen(3))
+		Expect(listNames(newManagedByLabel)).To(ConsistOf("orphan-1", "orphan-2"))
+
+		actual := &corev1.Pod{}
+		Expect(scheme.Scheme.Convert(test.GetResource(client, desired), actual, nil)).To(Succeed())
+		Expect(actual.Spec.Hostname).To(Equal("adopted"))
+	})
+
+	When("run repeatedly", func() {
+		It("should not update already adopted objects", func() {
+			Expect(adoptOrphans()).To(Equal(2))
+			Expect(adoptOrphans()).To(Equal(0))
+
+			Expect(listNames(newManagedByLabel)).To(ConsistOf("orphan-1", "orphan-2"))
+		})
+	})
+
+	When("the relabeled objects still match the old selector", func() {
+		BeforeEach(func() {
+			relabel = func(labels map[string]string) map[string]string {
+				labels[newManagedByLabel] = "true"
+
+				return labels
+			}
+		})
+
+		It("should not update them on subsequent runs", func() {
+			Expect(adoptOrphans()).To(Equal(2))
+			Expect(adoptOrphans()).To(Equal(0))
+
+			Expect(listNames(oldManagedByLabel + "," + newManagedByLabel)).To(ConsistOf("orphan-1", "orphan-2"))
+		})
+	})
+})
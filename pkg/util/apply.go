@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultApplyFieldManager the field manager used by Apply for server-side apply if none is set via SetFieldManager.
+const DefaultApplyFieldManager = "admiral"
+
+// Apply writes the given resource via server-side apply, forcing ownership of any conflicting fields, if the given gate,
+// eg created with the ServerSideApplyMinVersion, is enabled. Otherwise, it falls back to CreateOrUpdate with the given
+// mutate function, which defaults to replacing the existing resource with the given one. A nil gate means server-side
+// apply is always used.
+func Apply(ctx context.Context, client resource.Interface, obj runtime.Object, gate *MinServerVersion, mutate MutateFn) error {
+	if gate != nil && !gate.Enabled() {
+		if mutate == nil {
+			mutate = Replace(obj)
+		}
+
+		_, err := CreateOrUpdate(ctx, client, obj, mutate)
+
+		return err
+	}
+
+	applied, err := resource.ToUnstructured(obj)
+	if err != nil {
+		return err //nolint:wrapcheck // ok to return as is
+	}
+
+	// These are set by the server and mustn't be specified in an apply request.
+	applied.SetResourceVersion("")
+	applied.SetManagedFields(nil)
+
+	data, err := json.Marshal(applied)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling resource %q", applied.GetName())
+	}
+
+	manager := fieldManager
+	if manager == "" {
+		manager = DefaultApplyFieldManager
+	}
+
+	force := true
+
+	logger.V(log.LIBTRACE).Infof("Applying resource %q: %s", applied.GetName(), data)
+
+	_, err = client.Patch(ctx, applied.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: manager,
+		Force:        &force,
+	})
+
+	return errors.Wrapf(err, "error applying resource %q", applied.GetName())
+}
@@ -0,0 +1,144 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	discoveryFake "k8s.io/client-go/discovery/fake"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("Apply", func() {
+	var (
+		dynClient *dynamicFake.FakeDynamicClient
+		discovery *discoveryFake.FakeDiscovery
+		gate      *util.MinServerVersion
+		pod       *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		dynClient = dynamicFake.NewSimpleDynamicClient(scheme.Scheme)
+		discovery = &discoveryFake.FakeDiscovery{Fake: &testing.Fake{}}
+		pod = test.NewPod("test")
+
+		// The fake object tracker doesn't support apply patches.
+		dynClient.PrependReactor("patch", "pods", func(action testing.Action) (bool, runtime.Object, error) {
+			return action.(testing.PatchAction).GetPatchType() == types.ApplyPatchType, nil, nil
+		})
+	})
+
+	JustBeforeEach(func() {
+		gate = util.NewMinServerVersion(discovery, "server-side apply", util.ServerSideApplyMinVersion)
+	})
+
+	apply := func() {
+		client := resource.ForDynamic(dynClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("test"))
+		Expect(util.Apply(context.TODO(), client, pod, gate, nil)).To(Succeed())
+	}
+
+	verbs := func() []string {
+		v := []string{}
+		for _, action := range dynClient.Actions() {
+			v = append(v, action.GetVerb())
+		}
+
+		return v
+	}
+
+	When("the server version supports server-side apply", func() {
+		BeforeEach(func() {
+			discovery.FakedServerVersion = &version.Info{GitVersion: "v1.24.3+k3s1"}
+		})
+
+		It("should apply the resource", func() {
+			Expect(gate.Enabled()).To(BeTrue())
+
+			apply()
+
+			Expect(verbs()).To(Equal([]string{"patch"}))
+
+			patch := dynClient.Actions()[0].(testing.PatchAction)
+			Expect(patch.GetPatchType()).To(Equal(types.ApplyPatchType))
+			Expect(string(patch.GetPatch())).To(ContainSubstring(pod.Name))
+		})
+	})
+
+	When("the server version is older than required", func() {
+		BeforeEach(func() {
+			discovery.FakedServerVersion = &version.Info{GitVersion: "v1.19.16"}
+		})
+
+		It("should fall back to CreateOrUpdate", func() {
+			Expect(gate.Enabled()).To(BeFalse())
+
+			apply()
+
+			Expect(verbs()).To(Equal([]string{"get", "create"}))
+		})
+	})
+
+})
+
+var _ = Describe("ServerVersionAtLeast", func() {
+	discovery := &discoveryFake.FakeDiscovery{Fake: &testing.Fake{}, FakedServerVersion: &version.Info{GitVersion: "v1.22.1"}}
+
+	It("should correctly compare the server version", func() {
+		Expect(util.ServerVersionAtLeast(discovery, "v1.22.0")).To(BeTrue())
+		Expect(util.ServerVersionAtLeast(discovery, "1.22.1")).To(BeTrue())
+		Expect(util.ServerVersionAtLeast(discovery, "v1.23.0")).To(BeFalse())
+	})
+
+	It("should return an error for an invalid version", func() {
+		_, err := util.ServerVersionAtLeast(discovery, "bogus")
+		Expect(err).To(HaveOccurred())
+	})
+
+	When("the server version can't be retrieved", func() {
+		It("should return an error and disable the gated feature", func() {
+			failing := serverVersionFunc(func() (*version.Info, error) {
+				return nil, errors.New("fake error")
+			})
+
+			_, err := util.ServerVersionAtLeast(failing, "v1.22.0")
+			Expect(err).To(HaveOccurred())
+
+			Expect(util.NewMinServerVersion(failing, "feature", "v1.22.0").Enabled()).To(BeFalse())
+		})
+	})
+})
+
+type serverVersionFunc func() (*version.Info, error)
+
+func (f serverVersionFunc) ServerVersion() (*version.Info, error) {
+	return f()
+}
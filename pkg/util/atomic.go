@@ -0,0 +1,98 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// AtomicObject specifies an object to apply via ApplyAtomic and the client via which it's applied.
+type AtomicObject struct {
+	Client resource.Interface
+	Obj    runtime.Object
+
+	// Mutate if specified, the MutateFn with which a pre-existing object is updated. By default, it's replaced by Obj.
+	Mutate MutateFn
+}
+
+// RollbackFn compensates for an object that ApplyAtomic created prior to a subsequent failure.
+type RollbackFn func(ctx context.Context, client resource.Interface, created runtime.Object) error
+
+// DeleteCreated is a RollbackFn that deletes the created object, if it still exists.
+func DeleteCreated(ctx context.Context, client resource.Interface, created runtime.Object) error {
+	return EnsureAbsent(ctx, client, resource.ToMeta(created).GetName())
+}
+
+// ApplyAtomic applies the given objects in order via CreateOrUpdate, stopping at the first failure. As Kubernetes
+// doesn't support transactions, this is best-effort: on failure, the given rollback function is invoked, in reverse
+// order, for each object that was created, so partial state is cleaned up. Objects that already existed, and were thus
+// updated, aren't rolled back. If the rollback function is nil, DeleteCreated is used. The returned error includes any
+// rollback failures.
+func ApplyAtomic(ctx context.Context, objs []AtomicObject, rollback RollbackFn) error {
+	if rollback == nil {
+		rollback = DeleteCreated
+	}
+
+	created := make([]AtomicObject, 0, len(objs))
+
+	for i := range objs {
+		mutate := objs[i].Mutate
+		if mutate == nil {
+			mutate = Replace(objs[i].Obj)
+		}
+
+		result, err := CreateOrUpdate(ctx, objs[i].Client, objs[i].Obj, mutate)
+		if err != nil {
+			err = errors.Wrapf(err, "error applying object %d of %d (%q)", i+1, len(objs), resource.ToMeta(objs[i].Obj).GetName())
+			return rollbackCreated(ctx, created, rollback, err)
+		}
+
+		if result == OperationResultCreated {
+			created = append(created, objs[i])
+		}
+	}
+
+	return nil
+}
+
+func rollbackCreated(ctx context.Context, created []AtomicObject, rollback RollbackFn, applyErr error) error {
+	errs := []error{applyErr}
+
+	for i := len(created) - 1; i >= 0; i-- {
+		name := resource.ToMeta(created[i].Obj).GetName()
+
+		logger.V(log.LIBDEBUG).Infof("Rolling back created object %q", name)
+
+		if err := rollback(ctx, created[i].Client, created[i].Obj); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error rolling back created object %q", name))
+		}
+	}
+
+	if len(errs) == 1 {
+		return applyErr
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
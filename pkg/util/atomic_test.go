@@ -0,0 +1,150 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("ApplyAtomic", func() {
+	var (
+		client   *fake.DynamicResourceClient
+		pods     []*corev1.Pod
+		existing *corev1.Pod
+		failErr  error
+	)
+
+	newPod := func(name string) *corev1.Pod {
+		pod := test.NewPod("")
+		pod.Name = name
+
+		return pod
+	}
+
+	BeforeEach(func() {
+		dynClient := fake.NewDynamicClient(scheme.Scheme)
+
+		client, _ = dynClient.Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test").(*fake.DynamicResourceClient)
+
+		failErr = errors.New("fake create error")
+
+		dynClient.PrependReactor("create", "pods", func(action testing.Action) (bool, runtime.Object, error) {
+			obj := action.(testing.CreateAction).GetObject()
+			if resource.ToMeta(obj).GetName() == "failing-pod" {
+				return true, nil, failErr
+			}
+
+			return false, nil, nil
+		})
+
+		existing = newPod("existing-pod")
+		test.CreateResource(client, existing)
+	})
+
+	applyAtomic := func(rollback util.RollbackFn) error {
+		objs := make([]util.AtomicObject, 0, len(pods))
+		for _, pod := range pods {
+			objs = append(objs, util.AtomicObject{Client: resource.ForDynamic(client), Obj: pod})
+		}
+
+		return util.ApplyAtomic(context.TODO(), objs, rollback)
+	}
+
+	exists := func(name string) bool {
+		_, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+
+		Expect(err).To(Succeed())
+
+		return true
+	}
+
+	When("all the applies succeed", func() {
+		BeforeEach(func() {
+			pods = []*corev1.Pod{newPod("pod-1"), existing, newPod("pod-2")}
+		})
+
+		It("should apply all the objects", func() {
+			Expect(applyAtomic(nil)).To(Succeed())
+			Expect(exists("pod-1")).To(BeTrue())
+			Expect(exists("pod-2")).To(BeTrue())
+			Expect(exists(existing.Name)).To(BeTrue())
+		})
+	})
+
+	When("the third of three applies fails", func() {
+		BeforeEach(func() {
+			pods = []*corev1.Pod{newPod("pod-1"), newPod("pod-2"), newPod("failing-pod")}
+		})
+
+		It("should roll back the two created objects", func() {
+			Expect(applyAtomic(nil)).To(MatchError(ContainSubstring(failErr.Error())))
+			Expect(exists("pod-1")).To(BeFalse())
+			Expect(exists("pod-2")).To(BeFalse())
+		})
+	})
+
+	When("an apply fails after a pre-existing object is updated", func() {
+		BeforeEach(func() {
+			existing.Spec.Containers[0].Image = "updated"
+			pods = []*corev1.Pod{existing, newPod("pod-1"), newPod("failing-pod")}
+		})
+
+		It("should roll back the created object but not the pre-existing one", func() {
+			Expect(applyAtomic(nil)).To(MatchError(ContainSubstring(failErr.Error())))
+			Expect(exists("pod-1")).To(BeFalse())
+			Expect(exists(existing.Name)).To(BeTrue())
+		})
+	})
+
+	When("a rollback fails", func() {
+		BeforeEach(func() {
+			pods = []*corev1.Pod{newPod("pod-1"), newPod("failing-pod")}
+		})
+
+		It("should return both the apply and rollback errors", func() {
+			err := applyAtomic(func(_ context.Context, _ resource.Interface, _ runtime.Object) error {
+				return errors.New("fake rollback error")
+			})
+
+			Expect(err).To(MatchError(And(ContainSubstring(failErr.Error()), ContainSubstring("fake rollback error"))))
+		})
+	})
+})
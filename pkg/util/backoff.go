@@ -0,0 +1,113 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultBackoffJitter the jitter factor of the default backoff used by the retry loops in this package. Jitter spreads
+// out the retries of many clients that fail at the same time so they don't hit the API server in lockstep.
+const DefaultBackoffJitter = 0.1
+
+var retryClock clock.Clock = clock.RealClock{}
+
+// SetClock sets the clock used to wait between attempts by the retry loops in this package, eg CreateAnew and
+// RetryOnConflict, and returns the previous clock. This is provided for unit tests to advance a fake clock rather than
+// sleep. By default, the real clock is used.
+func SetClock(c clock.Clock) clock.Clock {
+	prev := retryClock
+	retryClock = c
+
+	return prev
+}
+
+// retryWithBackoff is like wait.ExponentialBackoffWithContext except the delay between attempts, including any jitter,
+// never exceeds the backoff's Cap, if set.
+func retryWithBackoff(ctx context.Context, backoff wait.Backoff, condition wait.ConditionFunc) error {
+	for backoff.Steps > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if ok, err := condition(); err != nil || ok {
+			return err
+		}
+
+		if backoff.Steps == 1 {
+			break
+		}
+
+		delay := backoff.Step()
+		if backoff.Cap > 0 && delay > backoff.Cap {
+			delay = backoff.Cap
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-retryClock.After(delay):
+		}
+	}
+
+	return wait.ErrWaitTimeout
+}
+
+// RetryOnConflict invokes the given function, retrying with the given backoff while it returns a Conflict error, eg to
+// wrap an update of a subresource. As with retry.RetryOnConflict, the function is invoked up to the backoff's Steps
+// times. Unlike retry.RetryOnConflict, the delay between attempts never exceeds the backoff's Cap, if set, and it stops
+// retrying and returns the context error as soon as the context is done. If the retries are exhausted, the last
+// conflict error is returned. Any other error is returned immediately.
+func RetryOnConflict(ctx context.Context, backoff wait.Backoff, fn func() error) error {
+	return retryOnConflict(ctx, backoff, backoff.Steps-1, fn)
+}
+
+// retryOnConflict is like retry.RetryOnConflict except the number of retries is given by maxRetries rather than the
+// backoff's Steps, which only determine how many times the delay increases, and it stops retrying and returns the
+// context error as soon as the context is done. If the retries are exhausted, the last conflict error is returned.
+func retryOnConflict(ctx context.Context, backoff wait.Backoff, maxRetries int, fn func() error) error {
+	for retries := 0; ; retries++ {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck // OK to return the context error as is.
+		}
+
+		err := fn()
+		if err == nil || !apierrors.IsConflict(err) || retries >= maxRetries {
+			return err
+		}
+
+		// Once the Steps are exhausted, Step returns the last delay.
+		delay := backoff.Step()
+		if backoff.Cap > 0 && delay > backoff.Cap {
+			delay = backoff.Cap
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck // OK to return the context error as is.
+		case <-retryClock.After(delay):
+		}
+	}
+}
@@ -0,0 +1,375 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Backoff", func() {
+	const (
+		jitter = 1.0
+		maxGap = 50 * time.Millisecond
+		// Allowance for scheduling latency on top of the expected delay.
+		slack = 10 * time.Millisecond
+	)
+
+	var (
+		client      dynamic.ResourceInterface
+		pod         *corev1.Pod
+		backoff     wait.Backoff
+		origBackoff wait.Backoff
+		attempts    []time.Time
+	)
+
+	BeforeEach(func() {
+		attempts = nil
+		pod = test.NewPod("test")
+
+		client = fake.NewDynamicClient(scheme.Scheme).Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test")
+
+		test.CreateResource(client, pod)
+
+		backoff = wait.Backoff{
+			Steps:    10,
+			Duration: 20 * time.Millisecond,
+			Factor:   1.2,
+			Jitter:   jitter,
+			Cap:      maxGap,
+		}
+
+		origBackoff = util.SetBackoff(backoff)
+	})
+
+	AfterEach(func() {
+		util.SetBackoff(origBackoff)
+	})
+
+	It("should apply jitter by default", func() {
+		Expect(origBackoff.Jitter).To(Equal(util.DefaultBackoffJitter))
+	})
+
+	It("should keep successive retry delays within the jittered bounds and the cap", func() {
+		err := util.PollUntil(context.TODO(), client, pod.Name, func(_ *unstructured.Unstructured) (bool, error) {
+			attempts = append(attempts, time.Now())
+			return false, nil
+		})
+		Expect(errors.Is(err, wait.ErrWaitTimeout)).To(BeTrue())
+
+		// The backoff stops once the un-jittered delay would exceed the cap so the observed delays are 20ms, 24ms, 28.8ms,
+		// 34.56ms and 41.47ms, the last two of which may exceed the cap after jitter is applied.
+		Expect(attempts).To(HaveLen(6))
+
+		expected := backoff.Duration
+
+		for i := 1; i < len(attempts); i++ {
+			gap := attempts[i].Sub(attempts[i-1])
+
+			upper := time.Duration(float64(expected) * (1 + jitter))
+			if upper > maxGap {
+				upper = maxGap
+			}
+
+			Expect(gap).To(BeNumerically(">=", expected), "Retry %d delay", i)
+			Expect(gap).To(BeNumerically("<=", upper+slack), "Retry %d delay", i)
+
+			expected = time.Duration(float64(expected) * backoff.Factor)
+		}
+	})
+})
+
+var _ = Describe("SetConflictBackoff", func() {
+	const (
+		jitter      = 0.5
+		maxGap      = 500 * time.Millisecond
+		conflicts   = 5
+		granularity = 5 * time.Millisecond
+	)
+
+	var (
+		client       *fake.DynamicResourceClient
+		pod          *corev1.Pod
+		backoff      wait.Backoff
+		origBackoff  wait.Backoff
+		fakeClock    *clock.FakeClock
+		origClock    clock.Clock
+		attemptTimes chan time.Duration
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod("test")
+
+		client, _ = fake.NewDynamicClient(scheme.Scheme).Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test").(*fake.DynamicResourceClient)
+
+		test.CreateResource(client, pod)
+
+		backoff = wait.Backoff{
+			Steps:    conflicts + 1,
+			Duration: 100 * time.Millisecond,
+			Factor:   2,
+			Jitter:   jitter,
+			Cap:      maxGap,
+		}
+
+		attemptTimes = make(chan time.Duration, conflicts+1)
+		fakeClock = clock.NewFakeClock(time.Now())
+		origClock = util.SetClock(fakeClock)
+		origBackoff = util.SetConflictBackoff(backoff)
+	})
+
+	AfterEach(func() {
+		util.SetConflictBackoff(origBackoff)
+		util.SetClock(origClock)
+	})
+
+	It("should apply jitter and a cap by default", func() {
+		Expect(origBackoff.Jitter).To(Equal(util.DefaultBackoffJitter))
+		Expect(origBackoff.Cap).To(BeNumerically(">", 0))
+	})
+
+	It("should keep successive conflict retry delays within the jittered bounds and the cap", func() {
+		client.ConflictOnUpdate(pod.Name, conflicts)
+
+		start := fakeClock.Now()
+		done := make(chan error, 1)
+
+		go func() {
+			_, err := util.CreateOrUpdate(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+				func(existing runtime.Object) (runtime.Object, error) {
+					attemptTimes <- fakeClock.Since(start)
+
+					obj := existing.DeepCopyObject().(*unstructured.Unstructured)
+					obj.SetLabels(map[string]string{"updated": "true"})
+
+					return obj, nil
+				})
+			done <- err
+		}()
+
+		// Advance the clock in small increments until each pending retry fires so the observed delays are accurate to
+		// within the increment.
+		for i := 0; i < conflicts; i++ {
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+			for fakeClock.HasWaiters() {
+				fakeClock.Step(granularity)
+			}
+		}
+
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(test.GetPod(client, pod).Labels).To(HaveKeyWithValue("updated", "true"))
+
+		close(attemptTimes)
+
+		attempts := []time.Duration{}
+		for a := range attemptTimes {
+			attempts = append(attempts, a)
+		}
+
+		Expect(attempts).To(HaveLen(conflicts + 1))
+
+		expected := backoff.Duration
+
+		for i := 1; i < len(attempts); i++ {
+			gap := attempts[i] - attempts[i-1]
+
+			lower := expected
+			if lower > maxGap {
+				lower = maxGap
+			}
+
+			upper := time.Duration(float64(expected) * (1 + jitter))
+			if upper > maxGap {
+				upper = maxGap
+			}
+
+			Expect(gap).To(BeNumerically(">=", lower), "Retry %d delay", i)
+			Expect(gap).To(BeNumerically("<", upper+granularity), "Retry %d delay", i)
+			Expect(gap).To(BeNumerically("<=", maxGap), "Retry %d delay", i)
+
+			expected = time.Duration(float64(expected) * backoff.Factor)
+		}
+	})
+})
+
+var _ = Describe("RetryOnConflict", func() {
+	var (
+		backoff  wait.Backoff
+		attempts int
+		errs     []error
+	)
+
+	BeforeEach(func() {
+		attempts = 0
+		errs = nil
+		backoff = wait.Backoff{
+			Steps:    5,
+			Duration: 5 * time.Millisecond,
+			Factor:   1.0,
+		}
+	})
+
+	retry := func(ctx context.Context) error {
+		return util.RetryOnConflict(ctx, backoff, func() error {
+			attempts++
+
+			if len(errs) == 0 {
+				return nil
+			}
+
+			err := errs[0]
+			errs = errs[1:]
+
+			return err
+		})
+	}
+
+	conflictErr := func() error {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "test", errors.New("fake conflict"))
+	}
+
+	When("the function returns Conflict twice and then succeeds", func() {
+		It("should retry and succeed", func() {
+			errs = []error{conflictErr(), conflictErr()}
+
+			Expect(retry(context.TODO())).To(Succeed())
+			Expect(attempts).To(Equal(3))
+		})
+	})
+
+	When("the function returns a non-conflict error", func() {
+		It("should return it immediately", func() {
+			errs = []error{errors.New("fake error"), conflictErr()}
+
+			Expect(retry(context.TODO())).To(MatchError("fake error"))
+			Expect(attempts).To(Equal(1))
+		})
+	})
+
+	When("the function continuously returns Conflict", func() {
+		It("should return the conflict error once the backoff's Steps are exhausted", func() {
+			for i := 0; i < 10; i++ {
+				errs = append(errs, conflictErr())
+			}
+
+			err := retry(context.TODO())
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+			Expect(attempts).To(Equal(backoff.Steps))
+		})
+	})
+
+	When("the context is done", func() {
+		It("should stop retrying and return the context error", func() {
+			errs = []error{conflictErr(), conflictErr()}
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+
+			Expect(retry(ctx)).To(MatchError(context.Canceled))
+			Expect(attempts).To(BeZero())
+		})
+	})
+})
+
+var _ = Describe("SetClock", func() {
+	var (
+		fakeClock *clock.FakeClock
+		origClock clock.Clock
+	)
+
+	BeforeEach(func() {
+		fakeClock = clock.NewFakeClock(time.Now())
+		origClock = util.SetClock(fakeClock)
+	})
+
+	AfterEach(func() {
+		util.SetClock(origClock)
+	})
+
+	It("should drive the delays between retries by the given clock", func() {
+		start := fakeClock.Now()
+		attempts := make(chan time.Duration, 10)
+		done := make(chan error, 1)
+
+		go func() {
+			done <- util.RetryOnConflict(context.TODO(), wait.Backoff{
+				Steps:    4,
+				Duration: time.Second,
+				Factor:   2,
+				Cap:      3 * time.Second,
+			}, func() error {
+				attempts <- fakeClock.Since(start)
+				return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "test", errors.New("fake conflict"))
+			})
+		}()
+
+		// The last delay is capped.
+		expectedDelays := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+		expectedAttempts := []time.Duration{0}
+
+		for _, delay := range expectedDelays {
+			Eventually(fakeClock.HasWaiters).Should(BeTrue())
+
+			fakeClock.Step(delay - time.Millisecond)
+			Expect(fakeClock.HasWaiters()).To(BeTrue())
+
+			fakeClock.Step(time.Millisecond)
+			expectedAttempts = append(expectedAttempts, expectedAttempts[len(expectedAttempts)-1]+delay)
+		}
+
+		var err error
+		Eventually(done).Should(Receive(&err))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		close(attempts)
+
+		actual := []time.Duration{}
+		for a := range attempts {
+			actual = append(actual, a)
+		}
+
+		Expect(actual).To(Equal(expectedAttempts))
+	})
+})
@@ -20,8 +20,10 @@ package util
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/log"
 	"github.com/submariner-io/admiral/pkg/resource"
@@ -42,6 +44,7 @@ const (
 	OperationResultNone    OperationResult = "unchanged"
 	OperationResultCreated OperationResult = "created"
 	OperationResultUpdated OperationResult = "updated"
+	OperationResultDeleted OperationResult = "deleted"
 )
 
 type MutateFn func(existing runtime.Object) (runtime.Object, error)
@@ -50,38 +53,183 @@ var backOff wait.Backoff = wait.Backoff{
 	Steps:    20,
 	Duration: time.Second,
 	Factor:   1.3,
+	Jitter:   DefaultBackoffJitter,
 	Cap:      40 * time.Second,
 }
 
+var conflictBackOff wait.Backoff = wait.Backoff{
+	Steps:    retry.DefaultRetry.Steps,
+	Duration: retry.DefaultRetry.Duration,
+	Factor:   retry.DefaultRetry.Factor,
+	Jitter:   DefaultBackoffJitter,
+	Cap:      time.Second,
+}
+
+var fieldManager string
+
 var logger = log.Logger{Logger: logf.Log}
 
+// SetLogger sets the logger used by the functions in this package. By default, the controller-runtime logger is used.
+// This should be called on initialization prior to using the package functions.
+func SetLogger(l logr.Logger) {
+	logger = log.Logger{Logger: l}
+}
+
+type updateFn func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error)
+
+// EqualFn compares an existing resource with the desired resource returned by a MutateFn. If true is returned, the
+// change is not considered meaningful and the desired resource is not written.
+type EqualFn func(existing, desired runtime.Object) bool
+
+// CacheReader retrieves the named resource from a cache, eg a warm informer cache. The second return value indicates
+// whether the resource was found in the cache.
+type CacheReader func(name string) (runtime.Object, bool)
+
+// CreateOrUpdateOptions specifies how CreateOrUpdateWithOptions retries on conflict.
+type CreateOrUpdateOptions struct {
+	// ConflictBackoff the backoff between retries on conflict. Once its Steps are exhausted, further retries are made at
+	// the last delay. Default is the backoff set via SetConflictBackoff.
+	ConflictBackoff *wait.Backoff
+
+	// MaxConflictRetries the maximum number of times to retry on conflict, independent of the ConflictBackoff's Steps.
+	// Default is one less than the ConflictBackoff's Steps, ie one retry after each delay of the backoff.
+	MaxConflictRetries int
+}
+
+type createOrUpdateOptions struct {
+	update        updateFn
+	doCreate      bool
+	dryRun        []string
+	equal         EqualFn
+	cache         CacheReader
+	conflictRetry CreateOrUpdateOptions
+}
+
+// CreateOrUpdate creates the resource if it doesn't exist, otherwise applies the mutate function to the existing resource
+// and updates it if it changed. Conflicts are retried, unless the context is done, in which case the context error is
+// returned.
 func CreateOrUpdate(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn) (OperationResult, error) {
-	return maybeCreateOrUpdate(ctx, client, obj, mutate, true)
+	return maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{update: client.Update, doCreate: true})
+}
+
+// CreateOrUpdateWithOptions is like CreateOrUpdate but conflicts are retried as specified by the given options.
+func CreateOrUpdateWithOptions(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn,
+	options CreateOrUpdateOptions,
+) (OperationResult, error) {
+	return maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{
+		update:        client.Update,
+		doCreate:      true,
+		conflictRetry: options,
+	})
+}
+
+// CreateOrUpdateFromCache is like CreateOrUpdate except the existing resource is initially read via the given
+// CacheReader to save an API round-trip. On a cache miss, the resource is retrieved from the API server. Writes are
+// always live. If the cached resource is stale, the write fails with a conflict and is retried with a live read. If
+// the cached resource is already as desired, nothing is written.
+func CreateOrUpdateFromCache(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn,
+	cache CacheReader,
+) (OperationResult, error) {
+	return maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{update: client.Update, doCreate: true, cache: cache})
+}
+
+// CreateOrUpdateDryRun is like CreateOrUpdate except the create or update request is sent with the DryRunAll option
+// so nothing is persisted. The returned OperationResult indicates what would have been done.
+func CreateOrUpdateDryRun(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn,
+) (OperationResult, error) {
+	return maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{
+		update:   client.Update,
+		doCreate: true,
+		dryRun:   []string{metav1.DryRunAll},
+	})
 }
 
 func Update(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn) error {
-	_, err := maybeCreateOrUpdate(ctx, client, obj, mutate, false)
+	_, err := maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{update: client.Update})
 	return err
 }
 
+// UpdateDryRun is like Update except the update request is sent with the DryRunAll option so nothing is persisted.
+// The returned OperationResult indicates whether the resource would have been updated.
+func UpdateDryRun(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn) (OperationResult, error) {
+	return maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{
+		update: client.Update,
+		dryRun: []string{metav1.DryRunAll},
+	})
+}
+
+// CompareAndUpdate retrieves the resource and applies the mutate function. The result is only written if the given
+// equal function returns false when comparing it with the existing resource, giving the caller full control over what
+// constitutes a meaningful change. The update is retried on conflict. If the resource doesn't exist, nothing is done.
+func CompareAndUpdate(ctx context.Context, client resource.Interface, obj runtime.Object, equal EqualFn, mutate MutateFn,
+) (OperationResult, error) {
+	return maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{update: client.Update, equal: equal})
+}
+
+// UpdateStatus retrieves the resource, applies the mutate function and, if the result differs, writes it via the
+// status subresource. The update is retried on conflict. If the resource doesn't exist, nothing is done.
+func UpdateStatus(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn) error {
+	_, err := maybeCreateOrUpdate(ctx, client, obj, mutate, createOrUpdateOptions{update: client.UpdateStatus})
+	return err
+}
+
+// EnsureExists creates the resource if it doesn't exist. An existing resource is never updated, regardless of its
+// content. The returned OperationResult indicates whether the resource was created.
+func EnsureExists(ctx context.Context, client resource.Interface, obj runtime.Object) (OperationResult, error) {
+	_, err := client.Create(ctx, obj, metav1.CreateOptions{FieldManager: fieldManager})
+	if apierrors.IsAlreadyExists(err) {
+		logger.V(log.LIBTRACE).Infof("Resource %q already exists", resource.ToMeta(obj).GetName())
+		return OperationResultNone, nil
+	}
+
+	if err != nil {
+		return OperationResultNone, errors.Wrapf(err, "error creating %#v", obj)
+	}
+
+	return OperationResultCreated, nil
+}
+
 func maybeCreateOrUpdate(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn,
-	doCreate bool,
+	options createOrUpdateOptions,
 ) (OperationResult, error) {
 	result := OperationResultNone
 
 	objMeta := resource.ToMeta(obj)
 
-	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		existing, err := client.Get(ctx, objMeta.GetName(), metav1.GetOptions{})
+	equal := options.equal
+	if equal == nil {
+		equal = func(existing, desired runtime.Object) bool {
+			return equality.Semantic.DeepEqual(desired, existing)
+		}
+	}
+
+	cache := options.cache
+
+	backoff := conflictBackOff
+	if options.conflictRetry.ConflictBackoff != nil {
+		backoff = *options.conflictRetry.ConflictBackoff
+	}
+
+	maxRetries := options.conflictRetry.MaxConflictRetries
+	if maxRetries == 0 {
+		maxRetries = backoff.Steps - 1
+	}
+
+	err := retryOnConflict(ctx, backoff, maxRetries, func() error {
+		existing, fromCache, err := getExisting(ctx, client, objMeta.GetName(), cache)
+
+		// Only the initial read may come from the cache - retries on conflict re-read live.
+		cache = nil
+
 		if apierrors.IsNotFound(err) {
-			if !doCreate {
+			if !options.doCreate {
 				logger.V(log.LIBTRACE).Infof("Resource %q does not exist - not updating", objMeta.GetName())
 				return nil
 			}
 
 			logger.V(log.LIBTRACE).Infof("Creating resource: %#v", obj)
 
-			_, err := client.Create(ctx, obj, metav1.CreateOptions{})
+			_, err := client.Create(ctx, obj, metav1.CreateOptions{DryRun: options.dryRun, FieldManager: fieldManager})
 			if apierrors.IsAlreadyExists(err) {
 				logger.V(log.LIBDEBUG).Infof("Resource %q already exists - retrying", objMeta.GetName())
 				return apierrors.NewConflict(schema.GroupResource{}, objMeta.GetName(), err)
@@ -109,14 +257,19 @@ func maybeCreateOrUpdate(ctx context.Context, client resource.Interface, obj run
 
 		resource.ToMeta(toUpdate).SetResourceVersion(resourceVersion)
 
-		if equality.Semantic.DeepEqual(toUpdate, orig) {
+		if equal(orig, toUpdate) {
 			return nil
 		}
 
 		logger.V(log.LIBTRACE).Infof("Updating resource: %#v", obj)
 
 		result = OperationResultUpdated
-		_, err = client.Update(ctx, toUpdate, metav1.UpdateOptions{})
+		_, err = options.update(ctx, toUpdate, metav1.UpdateOptions{DryRun: options.dryRun, FieldManager: fieldManager})
+
+		if apierrors.IsNotFound(err) && fromCache {
+			logger.V(log.LIBDEBUG).Infof("Cached resource %q no longer exists - retrying", objMeta.GetName())
+			return apierrors.NewConflict(schema.GroupResource{}, objMeta.GetName(), err)
+		}
 
 		return errors.Wrapf(err, "error updating %#v", toUpdate)
 	})
@@ -127,25 +280,61 @@ func maybeCreateOrUpdate(ctx context.Context, client resource.Interface, obj run
 	return result, nil
 }
 
+// NormalizeFn normalizes a resource prior to comparison, eg to fill in fields that would otherwise be defaulted by the
+// server, so that differences which would disappear once applied aren't considered meaningful.
+type NormalizeFn func(obj *unstructured.Unstructured)
+
+// CreateAnewOptions specifies how CreateAnewWithOptions compares the new resource with a pre-existing instance.
+type CreateAnewOptions struct {
+	// Normalize if specified, applied to copies of both the pre-existing and the new resource before they're compared
+	// so that spurious differences, eg in server-defaulted fields, don't cause the pre-existing instance to be deleted
+	// and recreated.
+	Normalize NormalizeFn
+
+	// Backoff the backoff with which the create is retried while it fails because the pre-existing instance still exists,
+	// eg while its deletion completes. Once its Steps are exhausted, the last AlreadyExists error is returned. Default is
+	// the backoff set via SetBackoff.
+	Backoff *wait.Backoff
+}
+
 // CreateAnew creates a resource, first deleting an existing instance if one exists.
 // If the delete options specify that deletion should be propagated in the foreground,
 // this will wait for the deletion to be complete before creating the new object:
 // with foreground propagation, Get will continue to return the object being deleted
 // and Create will fail with “already exists” until deletion is complete.
+// The create is retried while it fails with “already exists”, with the backoff set via SetBackoff, after which the
+// AlreadyExists error is returned. The wait is abandoned with the context error as soon as the context is done.
 func CreateAnew(ctx context.Context, client resource.Interface, obj runtime.Object,
 	createOptions metav1.CreateOptions,
 	deleteOptions metav1.DeleteOptions) (runtime.Object, error, // nolint:gocritic // Match K8s API
 ) {
+	return CreateAnewWithOptions(ctx, client, obj, createOptions, deleteOptions, CreateAnewOptions{})
+}
+
+// CreateAnewWithOptions is like CreateAnew but the new resource is compared with a pre-existing instance as specified by
+// the given options.
+func CreateAnewWithOptions(ctx context.Context, client resource.Interface, obj runtime.Object,
+	createOptions metav1.CreateOptions, deleteOptions metav1.DeleteOptions,
+	options CreateAnewOptions,
+) (runtime.Object, error) {
 	name := resource.ToMeta(obj).GetName()
 
-	var retObj runtime.Object
+	backoff := backOff
+	if options.Backoff != nil {
+		backoff = *options.Backoff
+	}
 
-	err := wait.ExponentialBackoff(backOff, func() (bool, error) {
+	var (
+		retObj    runtime.Object
+		createErr error
+	)
+
+	err := retryWithBackoff(ctx, backoff, func() (bool, error) {
 		var err error
 
-		retObj, err = client.Create(ctx, obj, createOptions)
-		if !apierrors.IsAlreadyExists(err) {
-			return true, errors.Wrapf(err, "error creating %#v", obj)
+		retObj, createErr = client.Create(ctx, obj, createOptions)
+		if !apierrors.IsAlreadyExists(createErr) {
+			return true, errors.Wrapf(createErr, "error creating %#v", obj)
 		}
 
 		retObj, err = client.Get(ctx, resource.ToMeta(obj).GetName(), metav1.GetOptions{})
@@ -154,7 +343,13 @@ func CreateAnew(ctx context.Context, client resource.Interface, obj runtime.Obje
 				return false, errors.Wrapf(err, "failed to retrieve pre-existing instance %q", name)
 			}
 
-			if mutableFieldsEqual(retObj, obj) {
+			// The pre-existing instance is still being deleted, eg with foreground propagation, so wait for it to go.
+			if resource.ToMeta(retObj).GetDeletionTimestamp() != nil {
+				logger.V(log.LIBTRACE).Infof("Pre-existing instance %q is being deleted - retrying", name)
+				return false, nil
+			}
+
+			if mutableFieldsEqual(retObj, obj, options.Normalize) {
 				return true, nil
 			}
 		}
@@ -167,10 +362,15 @@ func CreateAnew(ctx context.Context, client resource.Interface, obj runtime.Obje
 		return false, errors.Wrapf(err, "failed to delete pre-existing instance %q", name)
 	})
 
+	// Distinguish a pre-existing instance that persists beyond the backoff from a transient AlreadyExists.
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		err = errors.Wrapf(createErr, "pre-existing instance %q still exists after %d attempts", name, backoff.Steps)
+	}
+
 	return retObj, errors.Wrap(err, "error creating resource anew")
 }
 
-func mutableFieldsEqual(existingObj, newObj runtime.Object) bool {
+func mutableFieldsEqual(existingObj, newObj runtime.Object, normalize NormalizeFn) bool {
 	existingU, err := resource.ToUnstructured(existingObj)
 	if err != nil {
 		panic(err)
@@ -187,9 +387,31 @@ func mutableFieldsEqual(existingObj, newObj runtime.Object) bool {
 	unstructured.RemoveNestedField(existingU.Object, StatusField)
 	unstructured.RemoveNestedField(newU.Object, StatusField)
 
+	if normalize != nil {
+		normalize(existingU)
+		normalize(newU)
+	}
+
 	return equality.Semantic.DeepEqual(existingU, newU)
 }
 
+func getExisting(ctx context.Context, client resource.Interface, name string, cache CacheReader,
+) (runtime.Object, bool, error) {
+	if cache != nil {
+		if cached, found := cache(name); found {
+			return cached.DeepCopyObject(), true, nil
+		}
+	}
+
+	existing, err := client.Get(ctx, name, metav1.GetOptions{})
+
+	return existing, false, err //nolint:wrapcheck // The caller wraps it.
+}
+
+// SetBackoff sets the backoff used by the retry loops in this package, eg CreateAnew and PollUntil, and returns the
+// previous backoff. The given backoff is used as is, ie jitter is only applied if its Jitter field is set. Unlike
+// wait.Backoff, the delay between retries, including jitter, never exceeds the Cap, if set. By default, the jitter
+// factor is DefaultBackoffJitter.
 func SetBackoff(b wait.Backoff) wait.Backoff {
 	prev := backOff
 	backOff = b
@@ -197,8 +419,86 @@ func SetBackoff(b wait.Backoff) wait.Backoff {
 	return prev
 }
 
+// SetConflictBackoff sets the backoff between retries on conflict used by the CreateOrUpdate family of functions when
+// no ConflictBackoff is specified, and returns the previous backoff. As with SetBackoff, the given backoff is used as
+// is and the delay between retries, including jitter, never exceeds the Cap, if set. By default, the timing is that of
+// retry.DefaultRetry with a jitter factor of DefaultBackoffJitter and a one second cap.
+func SetConflictBackoff(b wait.Backoff) wait.Backoff {
+	prev := conflictBackOff
+	conflictBackOff = b
+
+	return prev
+}
+
+// SetFieldManager sets the field manager name passed on the create and update requests issued by the CreateOrUpdate
+// family of functions and returns the previous name. By default, no field manager is set.
+func SetFieldManager(name string) string {
+	prev := fieldManager
+	fieldManager = name
+
+	return prev
+}
+
 func Replace(with runtime.Object) MutateFn {
 	return func(existing runtime.Object) (runtime.Object, error) {
 		return with, nil
 	}
 }
+
+// ChainMutate returns a MutateFn that applies the given functions in order, each passed the result of the previous one,
+// and returns the result of the last. The first error is returned as is and the remaining functions aren't applied. With
+// no functions, the existing resource is returned unchanged.
+func ChainMutate(fns ...MutateFn) MutateFn {
+	return func(existing runtime.Object) (runtime.Object, error) {
+		result := existing
+
+		for _, fn := range fns {
+			var err error
+
+			result, err = fn(result)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
+}
+
+// MergeAnnotations returns a MutateFn that replaces the existing resource with the given resource except that its labels
+// and annotations are merged into the existing ones rather than replacing them wholesale, so keys added by other
+// controllers are preserved. An existing key with the given owned prefix that isn't present in the given resource is
+// removed. If the owned prefix is empty, no existing keys are removed.
+func MergeAnnotations(with runtime.Object, ownedPrefix string) MutateFn {
+	return func(existing runtime.Object) (runtime.Object, error) {
+		existingMeta := resource.ToMeta(existing)
+
+		merged := with.DeepCopyObject()
+		mergedMeta := resource.ToMeta(merged)
+
+		mergedMeta.SetLabels(mergeOwnedKeys(existingMeta.GetLabels(), mergedMeta.GetLabels(), ownedPrefix))
+		mergedMeta.SetAnnotations(mergeOwnedKeys(existingMeta.GetAnnotations(), mergedMeta.GetAnnotations(), ownedPrefix))
+
+		return merged, nil
+	}
+}
+
+func mergeOwnedKeys(existing, desired map[string]string, ownedPrefix string) map[string]string {
+	if len(existing) == 0 && len(desired) == 0 {
+		return desired
+	}
+
+	merged := map[string]string{}
+
+	for k, v := range existing {
+		if ownedPrefix == "" || !strings.HasPrefix(k, ownedPrefix) {
+			merged[k] = v
+		}
+	}
+
+	for k, v := range desired {
+		merged[k] = v
+	}
+
+	return merged
+}
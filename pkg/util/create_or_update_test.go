@@ -19,6 +19,7 @@ package util_test
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -26,6 +27,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/submariner-io/admiral/pkg/fake"
 	. "github.com/submariner-io/admiral/pkg/gomega"
+	logfake "github.com/submariner-io/admiral/pkg/log/fake"
 	"github.com/submariner-io/admiral/pkg/resource"
 	"github.com/submariner-io/admiral/pkg/syncer/test"
 	tests "github.com/submariner-io/admiral/pkg/test"
@@ -33,12 +35,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/testing"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var _ = Describe("", func() {
@@ -115,6 +120,27 @@ var _ = Describe("", func() {
 					comparePods(createAnewSuccess(), pod)
 				})
 
+				It("should assign the recreated resource the next UID", func() {
+					Expect(test.GetPod(client, pod).UID).To(Equal(fake.DeterministicUID(1)))
+					Expect(createAnewSuccess().UID).To(Equal(fake.DeterministicUID(2)))
+					Expect(test.GetPod(client, pod).UID).To(Equal(fake.DeterministicUID(2)))
+
+					pod.Spec.Containers[0].Image = "updated-again"
+					Expect(createAnewSuccess().UID).To(Equal(fake.DeterministicUID(3)))
+				})
+
+				Context("and a UID generator is set", func() {
+					BeforeEach(func() {
+						client.UIDGenerator = func() types.UID {
+							return "custom-uid"
+						}
+					})
+
+					It("should assign the generated UID to the recreated resource", func() {
+						Expect(createAnewSuccess().UID).To(Equal(types.UID("custom-uid")))
+					})
+				})
+
 				Context("and Delete returns not found", func() {
 					BeforeEach(func() {
 						client.FailOnDelete = apierrors.NewNotFound(schema.GroupResource{}, pod.Name)
@@ -141,8 +167,76 @@ var _ = Describe("", func() {
 						client.PersistentFailOnCreate.Store(apierrors.NewAlreadyExists(schema.GroupResource{}, pod.Name))
 					})
 
-					It("should return an error", func() {
-						Expect(createAnewError()).ToNot(Succeed())
+					It("should return the AlreadyExists error once the backoff is exhausted", func() {
+						err := createAnewError()
+						Expect(err).To(HaveOccurred())
+						Expect(apierrors.IsAlreadyExists(err)).To(BeTrue(), "Expected an AlreadyExists error: %v", err)
+					})
+
+					Context("and the context expires while waiting", func() {
+						BeforeEach(func() {
+							util.SetBackoff(wait.Backoff{
+								Steps:    20,
+								Duration: time.Second,
+							})
+						})
+
+						It("should return the context error promptly", func() {
+							ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+							defer cancel()
+
+							started := time.Now()
+							_, err := util.CreateAnew(ctx, resource.ForDynamic(client), pod, metav1.CreateOptions{}, metav1.DeleteOptions{})
+							Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue(), "Expected the context error: %v", err)
+							Expect(time.Since(started)).To(BeNumerically("<", time.Second))
+						})
+					})
+				})
+
+				Context("and the recreate persistently fails with AlreadyExists and a backoff is specified", func() {
+					It("should attempt the create as many times as its Steps", func() {
+						creates := 0
+						client.AddReactor("create", func(action testing.Action) (bool, runtime.Object, error) {
+							creates++
+							return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{}, pod.Name)
+						})
+
+						_, err := util.CreateAnewWithOptions(context.TODO(), resource.ForDynamic(client), pod, metav1.CreateOptions{},
+							metav1.DeleteOptions{}, util.CreateAnewOptions{Backoff: &wait.Backoff{Steps: 3, Duration: time.Millisecond}})
+						Expect(apierrors.IsAlreadyExists(err)).To(BeTrue(), "Expected an AlreadyExists error: %v", err)
+						Expect(creates).To(Equal(3))
+					})
+				})
+
+				Context("and the recreate transiently fails with AlreadyExists", func() {
+					BeforeEach(func() {
+						attempts := 0
+
+						client.AddReactor("create", func(action testing.Action) (bool, runtime.Object, error) {
+							attempts++
+							if attempts <= 2 {
+								return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{}, pod.Name)
+							}
+
+							return false, nil, nil
+						})
+					})
+
+					It("should keep retrying and successfully create the resource", func() {
+						comparePods(createAnewSuccess(), pod)
+						Expect(test.GetPod(client, pod).Spec.Containers[0].Image).To(Equal("updated"))
+					})
+				})
+
+				Context("and the pre-existing instance is being deleted", func() {
+					BeforeEach(func() {
+						client.PersistentFailOnCreate.Store(apierrors.NewAlreadyExists(schema.GroupResource{}, pod.Name))
+						tests.SetDeleting(resource.ForDynamic(client), pod.Name)
+					})
+
+					It("should wait for the deletion rather than deleting it again", func() {
+						Expect(createAnewError()).To(HaveOccurred())
+						tests.EnsureNoActionsForResource(testingFake, "pods", "delete")
 					})
 				})
 			})
@@ -157,6 +251,47 @@ var _ = Describe("", func() {
 					tests.EnsureNoActionsForResource(testingFake, "pods", "delete")
 				})
 			})
+
+			Context("and the new resource spec only differs in a server-defaulted field", func() {
+				var options util.CreateAnewOptions
+
+				BeforeEach(func() {
+					existing := test.GetPod(client, pod)
+					existing.Spec.RestartPolicy = corev1.RestartPolicyAlways
+					test.UpdateResource(client, existing)
+
+					options = util.CreateAnewOptions{}
+				})
+
+				createAnewWithOptions := func() {
+					_, err := util.CreateAnewWithOptions(context.TODO(), resource.ForDynamic(client), pod, metav1.CreateOptions{},
+						metav1.DeleteOptions{}, options)
+					Expect(err).To(Succeed())
+				}
+
+				It("should recreate it", func() {
+					createAnewWithOptions()
+					Expect(test.GetPod(client, pod).UID).To(Equal(fake.DeterministicUID(2)))
+				})
+
+				Context("and a normalization function is specified that defaults the field", func() {
+					BeforeEach(func() {
+						options.Normalize = func(obj *unstructured.Unstructured) {
+							if _, found, _ := unstructured.NestedString(obj.Object, "spec", "restartPolicy"); !found {
+								Expect(unstructured.SetNestedField(obj.Object, string(corev1.RestartPolicyAlways),
+									"spec", "restartPolicy")).To(Succeed())
+							}
+						}
+					})
+
+					It("should not recreate it", func() {
+						createAnewWithOptions()
+						tests.EnsureNoActionsForResource(testingFake, "pods", "delete")
+						Expect(test.GetPod(client, pod).UID).To(Equal(fake.DeterministicUID(1)))
+						Expect(test.GetPod(client, pod).Spec.RestartPolicy).To(Equal(corev1.RestartPolicyAlways))
+					})
+				})
+			})
 		})
 
 		When("Create fails", func() {
@@ -198,6 +333,30 @@ var _ = Describe("", func() {
 				verifyPod(client, pod)
 			})
 
+			Context("and a logger is set", func() {
+				var logger *logfake.Logger
+
+				BeforeEach(func() {
+					logger = logfake.New()
+					util.SetLogger(logger)
+				})
+
+				AfterEach(func() {
+					util.SetLogger(logf.Log)
+				})
+
+				It("should log via the configured logger", func() {
+					Expect(createOrUpdate()).To(Equal(util.OperationResultCreated))
+
+					var messages []string
+					for _, e := range logger.Entries() {
+						messages = append(messages, e.Message)
+					}
+
+					Expect(messages).To(ContainElement(HavePrefix("Creating resource")))
+				})
+			})
+
 			Context("and Create fails", func() {
 				JustBeforeEach(func() {
 					client.FailOnCreate = apierrors.NewServiceUnavailable("fake")
@@ -299,8 +458,655 @@ var _ = Describe("", func() {
 			})
 		})
 	})
+
+	Describe("CreateOrUpdateDryRun function", func() {
+		createOrUpdateDryRun := func() (util.OperationResult, error) {
+			return util.CreateOrUpdateDryRun(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+				util.Replace(test.ToUnstructured(pod)))
+		}
+
+		When("the resource doesn't exist", func() {
+			It("should report Created and not persist the resource", func() {
+				Expect(createOrUpdateDryRun()).To(Equal(util.OperationResultCreated))
+
+				_, err := test.GetResourceAndError(client, pod)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		When("the resource exists", func() {
+			var existing *corev1.Pod
+
+			BeforeEach(func() {
+				existing = pod.DeepCopy()
+				test.CreateResource(client, existing)
+				pod.Spec.Containers[0].Image = "apache"
+			})
+
+			It("should report Updated and not persist the update", func() {
+				Expect(createOrUpdateDryRun()).To(Equal(util.OperationResultUpdated))
+				Expect(test.GetPod(client, pod).Spec).To(Equal(existing.Spec))
+			})
+		})
+	})
+
+	Describe("Update function", func() {
+		When("a conflict is armed for one of two resources", func() {
+			var (
+				other      *corev1.Pod
+				mutateRuns map[string]int
+			)
+
+			BeforeEach(func() {
+				other = test.NewPod("")
+				other.Name = "other-pod"
+
+				test.CreateResource(client, pod)
+				test.CreateResource(client, other)
+
+				mutateRuns = map[string]int{}
+				client.ConflictOnUpdate(pod.Name, 2)
+			})
+
+			update := func(p *corev1.Pod) error {
+				return util.Update(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(p),
+					func(existing runtime.Object) (runtime.Object, error) {
+						mutateRuns[p.Name]++
+
+						obj := existing.DeepCopyObject().(*unstructured.Unstructured)
+						obj.SetLabels(map[string]string{"updated": "true"})
+
+						return obj, nil
+					})
+			}
+
+			It("should only retry the update of the armed resource", func() {
+				Expect(update(pod)).To(Succeed())
+				Expect(update(other)).To(Succeed())
+
+				Expect(mutateRuns[pod.Name]).To(Equal(3))
+				Expect(mutateRuns[other.Name]).To(Equal(1))
+
+				Expect(test.GetPod(client, pod).Labels).To(HaveKeyWithValue("updated", "true"))
+				Expect(test.GetPod(client, other).Labels).To(HaveKeyWithValue("updated", "true"))
+			})
+
+			It("should return a Conflict error when retries are exhausted", func() {
+				client.ConflictOnUpdate(pod.Name, 100)
+
+				err := update(pod)
+				Expect(apierrors.IsConflict(errors.Cause(err))).To(BeTrue(), "Expected a Conflict error: %v", err)
+			})
+
+			It("should stop retrying and return the context error when the context is cancelled", func() {
+				client.ConflictOnUpdate(pod.Name, 100)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				err := util.Update(ctx, resource.ForDynamic(client), test.ToUnstructured(pod),
+					func(existing runtime.Object) (runtime.Object, error) {
+						mutateRuns[pod.Name]++
+						if mutateRuns[pod.Name] == 2 {
+							cancel()
+						}
+
+						obj := existing.DeepCopyObject().(*unstructured.Unstructured)
+						obj.SetLabels(map[string]string{"updated": "true"})
+
+						return obj, nil
+					})
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue(), "Expected the context error: %v", err)
+				Expect(mutateRuns[pod.Name]).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("CreateOrUpdateWithOptions function", func() {
+		const maxRetries = 10
+
+		var mutateRuns int
+
+		BeforeEach(func() {
+			test.CreateResource(client, pod)
+			mutateRuns = 0
+		})
+
+		createOrUpdate := func() error {
+			_, err := util.CreateOrUpdateWithOptions(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+				func(existing runtime.Object) (runtime.Object, error) {
+					mutateRuns++
+
+					obj := existing.DeepCopyObject().(*unstructured.Unstructured)
+					obj.SetLabels(map[string]string{"updated": "true"})
+
+					return obj, nil
+				}, util.CreateOrUpdateOptions{
+					ConflictBackoff:    &wait.Backoff{Steps: 2, Duration: time.Millisecond, Factor: 1.0},
+					MaxConflictRetries: maxRetries,
+				})
+
+			return err
+		}
+
+		When("fewer conflicts than the maximum retries occur", func() {
+			It("should succeed", func() {
+				client.ConflictOnUpdate(pod.Name, maxRetries-1)
+
+				Expect(createOrUpdate()).To(Succeed())
+				Expect(mutateRuns).To(Equal(maxRetries))
+				Expect(test.GetPod(client, pod).Labels).To(HaveKeyWithValue("updated", "true"))
+			})
+		})
+
+		When("as many conflicts as the maximum retries occur", func() {
+			It("should succeed on the last retry", func() {
+				client.ConflictOnUpdate(pod.Name, maxRetries)
+
+				Expect(createOrUpdate()).To(Succeed())
+				Expect(mutateRuns).To(Equal(maxRetries + 1))
+			})
+		})
+
+		When("more conflicts than the maximum retries occur", func() {
+			It("should return the Conflict error", func() {
+				client.ConflictOnUpdate(pod.Name, maxRetries+1)
+
+				err := createOrUpdate()
+				Expect(apierrors.IsConflict(errors.Cause(err))).To(BeTrue(), "Expected a Conflict error: %v", err)
+				Expect(mutateRuns).To(Equal(maxRetries + 1))
+				Expect(test.GetPod(client, pod).Labels).ToNot(HaveKey("updated"))
+			})
+		})
+	})
+
+	Describe("CompareAndUpdate function", func() {
+		var (
+			equal  util.EqualFn
+			mutate util.MutateFn
+		)
+
+		BeforeEach(func() {
+			pod.Annotations = map[string]string{"key": "value"}
+			test.CreateResource(client, pod)
+
+			mutate = func(existing runtime.Object) (runtime.Object, error) {
+				obj := existing.(*unstructured.Unstructured)
+				obj.SetAnnotations(map[string]string{"key": "value", "other": "changed"})
+
+				return obj, nil
+			}
+		})
+
+		compareAndUpdate := func() util.OperationResult {
+			testingFake.ClearActions()
+
+			result, err := util.CompareAndUpdate(context.TODO(), resource.ForDynamic(client), pod, equal, mutate)
+			Expect(err).To(Succeed())
+
+			return result
+		}
+
+		When("the equal function always returns true", func() {
+			BeforeEach(func() {
+				equal = func(_, _ runtime.Object) bool {
+					return true
+				}
+			})
+
+			It("should not update the resource", func() {
+				Expect(compareAndUpdate()).To(Equal(util.OperationResultNone))
+				Expect(updateActions(testingFake, "")).To(BeEmpty())
+				Expect(test.GetPod(client, pod).Annotations).ToNot(HaveKey("other"))
+			})
+		})
+
+		When("the equal function always returns false", func() {
+			BeforeEach(func() {
+				equal = func(_, _ runtime.Object) bool {
+					return false
+				}
+
+				mutate = func(existing runtime.Object) (runtime.Object, error) {
+					return existing, nil
+				}
+			})
+
+			It("should always update the resource", func() {
+				Expect(compareAndUpdate()).To(Equal(util.OperationResultUpdated))
+				Expect(updateActions(testingFake, "")).To(HaveLen(1))
+
+				Expect(compareAndUpdate()).To(Equal(util.OperationResultUpdated))
+				Expect(updateActions(testingFake, "")).To(HaveLen(1))
+			})
+		})
+
+		When("the equal function compares a specific annotation", func() {
+			BeforeEach(func() {
+				equal = func(existing, desired runtime.Object) bool {
+					return resource.ToMeta(existing).GetAnnotations()["key"] == resource.ToMeta(desired).GetAnnotations()["key"]
+				}
+			})
+
+			Context("and the annotation is unchanged", func() {
+				It("should not update the resource", func() {
+					Expect(compareAndUpdate()).To(Equal(util.OperationResultNone))
+					Expect(updateActions(testingFake, "")).To(BeEmpty())
+				})
+			})
+
+			Context("and the annotation is changed", func() {
+				BeforeEach(func() {
+					mutate = func(existing runtime.Object) (runtime.Object, error) {
+						obj := existing.(*unstructured.Unstructured)
+						obj.SetAnnotations(map[string]string{"key": "new-value"})
+
+						return obj, nil
+					}
+				})
+
+				It("should update the resource", func() {
+					Expect(compareAndUpdate()).To(Equal(util.OperationResultUpdated))
+					Expect(test.GetPod(client, pod).Annotations).To(Equal(map[string]string{"key": "new-value"}))
+				})
+			})
+
+			Context("and the update initially fails due to conflict", func() {
+				BeforeEach(func() {
+					mutate = func(existing runtime.Object) (runtime.Object, error) {
+						obj := existing.(*unstructured.Unstructured)
+						obj.SetAnnotations(map[string]string{"key": "new-value"})
+
+						return obj, nil
+					}
+
+					client.ConflictOnUpdate(pod.Name, 1)
+				})
+
+				It("should retry until it succeeds", func() {
+					Expect(compareAndUpdate()).To(Equal(util.OperationResultUpdated))
+					Expect(updateActions(testingFake, "")).To(HaveLen(1))
+					Expect(test.GetPod(client, pod).Annotations).To(Equal(map[string]string{"key": "new-value"}))
+				})
+			})
+		})
+
+		When("the resource doesn't exist", func() {
+			BeforeEach(func() {
+				equal = nil
+				Expect(client.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})).To(Succeed())
+			})
+
+			It("should not create it", func() {
+				Expect(compareAndUpdate()).To(Equal(util.OperationResultNone))
+				_, err := test.GetResourceAndError(client, pod)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("MergeAnnotations function", func() {
+		const ownedPrefix = "submariner.io/"
+
+		BeforeEach(func() {
+			pod.Annotations = map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				ownedPrefix + "updated":                            "old",
+				ownedPrefix + "removed":                            "old",
+			}
+			pod.Labels = map[string]string{"foreign": "label", ownedPrefix + "removed": "old"}
+
+			test.CreateResource(client, pod)
+
+			pod = pod.DeepCopy()
+			pod.Annotations = map[string]string{ownedPrefix + "updated": "new", ownedPrefix + "added": "new"}
+			pod.Labels = map[string]string{ownedPrefix + "added": "new"}
+			pod.Spec.Containers[0].Image = "updated"
+		})
+
+		It("should preserve foreign keys and update or remove owned keys", func() {
+			Expect(util.CreateOrUpdate(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+				util.MergeAnnotations(test.ToUnstructured(pod), ownedPrefix))).To(Equal(util.OperationResultUpdated))
+
+			actual := test.GetPod(client, pod)
+			Expect(actual.Annotations).To(Equal(map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				ownedPrefix + "updated":                            "new",
+				ownedPrefix + "added":                              "new",
+			}))
+			Expect(actual.Labels).To(Equal(map[string]string{"foreign": "label", ownedPrefix + "added": "new"}))
+			Expect(actual.Spec).To(Equal(pod.Spec))
+		})
+
+		Context("with no owned prefix", func() {
+			It("should not remove any existing keys", func() {
+				Expect(util.CreateOrUpdate(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+					util.MergeAnnotations(test.ToUnstructured(pod), ""))).To(Equal(util.OperationResultUpdated))
+
+				actual := test.GetPod(client, pod)
+				Expect(actual.Annotations).To(HaveKeyWithValue(ownedPrefix+"removed", "old"))
+				Expect(actual.Annotations).To(HaveKeyWithValue(ownedPrefix+"updated", "new"))
+				Expect(actual.Labels).To(HaveKeyWithValue(ownedPrefix+"removed", "old"))
+			})
+		})
+	})
+
+	Describe("ChainMutate function", func() {
+		setLabel := func(key string, applied *[]string) util.MutateFn {
+			return func(existing runtime.Object) (runtime.Object, error) {
+				*applied = append(*applied, key)
+
+				obj := test.ToUnstructured(existing)
+				labels := obj.GetLabels()
+				if labels == nil {
+					labels = map[string]string{}
+				}
+
+				labels[key] = strconv.Itoa(len(labels))
+				obj.SetLabels(labels)
+
+				return obj, nil
+			}
+		}
+
+		It("should apply the functions in order, passing each the previous result", func() {
+			var applied []string
+
+			result, err := util.ChainMutate(setLabel("first", &applied), setLabel("second", &applied),
+				setLabel("third", &applied))(test.ToUnstructured(pod))
+			Expect(err).To(Succeed())
+			Expect(applied).To(Equal([]string{"first", "second", "third"}))
+			Expect(resource.ToMeta(result).GetLabels()).To(Equal(map[string]string{
+				"app": "test", "first": "1", "second": "2", "third": "3",
+			}))
+		})
+
+		It("should apply the chain via CreateOrUpdate", func() {
+			var applied []string
+
+			test.CreateResource(client, pod)
+
+			Expect(util.CreateOrUpdate(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod),
+				util.ChainMutate(util.Replace(test.ToUnstructured(pod)), setLabel("added", &applied)))).To(
+				Equal(util.OperationResultUpdated))
+			Expect(test.GetPod(client, pod).Labels).To(HaveKeyWithValue("added", "1"))
+		})
+
+		When("a function fails", func() {
+			It("should return its error and not apply the remaining functions", func() {
+				var applied []string
+
+				result, err := util.ChainMutate(setLabel("first", &applied), func(_ runtime.Object) (runtime.Object, error) {
+					return nil, errors.New("mutate failed")
+				}, setLabel("third", &applied))(test.ToUnstructured(pod))
+				Expect(err).To(MatchError("mutate failed"))
+				Expect(result).To(BeNil())
+				Expect(applied).To(Equal([]string{"first"}))
+			})
+		})
+
+		When("the chain is empty", func() {
+			It("should return the input unchanged", func() {
+				existing := test.ToUnstructured(pod)
+
+				result, err := util.ChainMutate()(existing)
+				Expect(err).To(Succeed())
+				Expect(result).To(BeIdenticalTo(existing))
+			})
+		})
+	})
+
+	Describe("UpdateStatus function", func() {
+		var mutateFn util.MutateFn
+
+		BeforeEach(func() {
+			mutateFn = func(existing runtime.Object) (runtime.Object, error) {
+				obj := test.ToUnstructured(existing)
+				util.SetNestedField(obj.Object, string(corev1.PodRunning), util.StatusField, "phase")
+
+				return obj, nil
+			}
+		})
+
+		updateStatus := func() error {
+			return util.UpdateStatus(context.TODO(), resource.ForDynamic(client), pod, mutateFn)
+		}
+
+		When("the resource exists", func() {
+			BeforeEach(func() {
+				test.CreateResource(client, pod)
+			})
+
+			It("should update the resource via the status subresource", func() {
+				Expect(updateStatus()).To(Succeed())
+				Expect(test.GetPod(client, pod).Status.Phase).To(Equal(corev1.PodRunning))
+				Expect(updateActions(testingFake, "status")).To(HaveLen(1))
+				Expect(updateActions(testingFake, "")).To(BeEmpty())
+			})
+
+			Context("and the mutate function also changes the spec", func() {
+				BeforeEach(func() {
+					mutateFn = func(existing runtime.Object) (runtime.Object, error) {
+						obj := test.ToUnstructured(existing)
+						util.SetNestedField(obj.Object, string(corev1.PodRunning), util.StatusField, "phase")
+						util.SetNestedField(obj.Object, "mutated", "spec", "hostname")
+
+						return obj, nil
+					}
+				})
+
+				It("should only persist the status", func() {
+					Expect(updateStatus()).To(Succeed())
+
+					updated := test.GetPod(client, pod)
+					Expect(updated.Status.Phase).To(Equal(corev1.PodRunning))
+					Expect(updated.Spec).To(Equal(pod.Spec))
+				})
+			})
+
+			Context("and the status is unchanged", func() {
+				BeforeEach(func() {
+					mutateFn = func(existing runtime.Object) (runtime.Object, error) {
+						return existing, nil
+					}
+				})
+
+				It("should not update the resource", func() {
+					Expect(updateStatus()).To(Succeed())
+					Expect(updateActions(testingFake, "status")).To(BeEmpty())
+				})
+			})
+		})
+
+		When("the resource doesn't exist", func() {
+			It("should not create the resource", func() {
+				Expect(updateStatus()).To(Succeed())
+				tests.EnsureNoResource(resource.ForDynamic(client), pod.GetName())
+			})
+		})
+	})
+
+	Describe("CreateOrUpdateFromCache function", func() {
+		var (
+			cached   runtime.Object
+			liveGets int
+		)
+
+		BeforeEach(func() {
+			cached = nil
+			liveGets = 0
+		})
+
+		createOrUpdate := func() (util.OperationResult, error) {
+			dynClient := resource.ForDynamic(client)
+
+			return util.CreateOrUpdateFromCache(context.TODO(), &resource.InterfaceFuncs{
+				GetFunc: func(ctx context.Context, name string, options metav1.GetOptions) (runtime.Object, error) {
+					liveGets++
+					return dynClient.Get(ctx, name, options)
+				},
+				CreateFunc: dynClient.Create,
+				UpdateFunc: dynClient.Update,
+			}, test.ToUnstructured(pod), util.Replace(test.ToUnstructured(pod)), func(name string) (runtime.Object, bool) {
+				return cached, cached != nil
+			})
+		}
+
+		When("the resource isn't in the cache", func() {
+			It("should retrieve it live and create the resource", func() {
+				Expect(createOrUpdate()).To(Equal(util.OperationResultCreated))
+				Expect(liveGets).To(Equal(1))
+				verifyPod(client, pod)
+			})
+		})
+
+		When("the resource is in the cache", func() {
+			BeforeEach(func() {
+				cached = test.CreateResource(client, pod)
+				pod = test.NewPodWithImage("", "apache")
+			})
+
+			It("should update the resource without a live read", func() {
+				Expect(createOrUpdate()).To(Equal(util.OperationResultUpdated))
+				Expect(liveGets).To(BeZero())
+				Expect(test.GetPod(client, pod).Spec).To(Equal(pod.Spec))
+			})
+
+			Context("and the cached resource is stale", func() {
+				BeforeEach(func() {
+					updated := test.NewPodWithImage("", "updated")
+					updated.ResourceVersion = resource.ToMeta(cached).GetResourceVersion()
+					test.UpdateResource(client, updated)
+				})
+
+				It("should recover from the conflict with a live read and update the resource", func() {
+					Expect(createOrUpdate()).To(Equal(util.OperationResultUpdated))
+					Expect(liveGets).To(Equal(1))
+					Expect(test.GetPod(client, pod).Spec).To(Equal(pod.Spec))
+				})
+			})
+		})
+	})
+
+	Describe("EnsureExists function", func() {
+		ensureExists := func() (util.OperationResult, error) {
+			return util.EnsureExists(context.TODO(), resource.ForDynamic(client), test.ToUnstructured(pod))
+		}
+
+		When("the resource doesn't exist", func() {
+			It("should create the resource", func() {
+				Expect(ensureExists()).To(Equal(util.OperationResultCreated))
+				verifyPod(client, pod)
+			})
+		})
+
+		When("the resource already exists", func() {
+			var existing *corev1.Pod
+
+			BeforeEach(func() {
+				existing = pod
+				test.CreateResource(client, existing)
+				pod = test.NewPodWithImage("", "apache")
+			})
+
+			It("should not update the resource", func() {
+				Expect(ensureExists()).To(Equal(util.OperationResultNone))
+				Expect(test.GetPod(client, pod).Spec).To(Equal(existing.Spec))
+				Expect(updateActions(testingFake, "")).To(BeEmpty())
+			})
+		})
+
+		When("Create fails", func() {
+			JustBeforeEach(func() {
+				client.FailOnCreate = apierrors.NewServiceUnavailable("fake")
+			})
+
+			It("should return an error", func() {
+				_, err := ensureExists()
+				Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+			})
+		})
+	})
+})
+
+var _ = Describe("SetFieldManager", func() {
+	var (
+		pod           *corev1.Pod
+		client        resource.Interface
+		createOptions []metav1.CreateOptions
+		updateOptions []metav1.UpdateOptions
+		origManager   string
+	)
+
+	BeforeEach(func() {
+		pod = test.NewPod("")
+		createOptions = nil
+		updateOptions = nil
+
+		dynClient := fake.NewDynamicClient(scheme.Scheme)
+		delegate := resource.ForDynamic(dynClient.Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test"))
+
+		client = &resource.InterfaceFuncs{
+			GetFunc: delegate.Get,
+			CreateFunc: func(ctx context.Context, obj runtime.Object, options metav1.CreateOptions) (runtime.Object, error) {
+				createOptions = append(createOptions, options)
+				return delegate.Create(ctx, obj, options)
+			},
+			UpdateFunc: func(ctx context.Context, obj runtime.Object, options metav1.UpdateOptions) (runtime.Object, error) {
+				updateOptions = append(updateOptions, options)
+				return delegate.Update(ctx, obj, options)
+			},
+		}
+
+		origManager = util.SetFieldManager("test-manager")
+	})
+
+	AfterEach(func() {
+		util.SetFieldManager(origManager)
+	})
+
+	It("should pass the field manager on create and update", func() {
+		_, err := util.CreateOrUpdate(context.TODO(), client, pod, util.Replace(pod))
+		Expect(err).To(Succeed())
+		Expect(createOptions).To(HaveLen(1))
+		Expect(createOptions[0].FieldManager).To(Equal("test-manager"))
+
+		pod.Spec.Containers[0].Image = "updated"
+
+		_, err = util.CreateOrUpdate(context.TODO(), client, pod, util.Replace(pod))
+		Expect(err).To(Succeed())
+		Expect(updateOptions).To(HaveLen(1))
+		Expect(updateOptions[0].FieldManager).To(Equal("test-manager"))
+	})
+
+	When("the field manager is reset", func() {
+		It("should not pass a field manager", func() {
+			util.SetFieldManager("")
+
+			_, err := util.CreateOrUpdate(context.TODO(), client, pod, util.Replace(pod))
+			Expect(err).To(Succeed())
+			Expect(createOptions).To(HaveLen(1))
+			Expect(createOptions[0].FieldManager).To(BeEmpty())
+		})
+	})
 })
 
+func updateActions(f *testing.Fake, subresource string) []testing.Action {
+	var found []testing.Action
+
+	for _, action := range f.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == subresource {
+			found = append(found, action)
+		}
+	}
+
+	return found
+}
+
 func verifyPod(client dynamic.ResourceInterface, expected *corev1.Pod) {
 	comparePods(test.GetPod(client, expected), expected)
 }
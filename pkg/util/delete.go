@@ -0,0 +1,58 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ForceDelete deletes the named resource immediately, ie with a grace period of zero, using the given propagation
+// policy for dependents. If the resource doesn't exist, nil is returned.
+func ForceDelete(ctx context.Context, client resource.Interface, name string, propagationPolicy metav1.DeletionPropagation) error {
+	gracePeriod := int64(0)
+
+	logger.V(log.LIBTRACE).Infof("Force deleting resource %q with propagation policy %q", name, propagationPolicy)
+
+	err := client.Delete(ctx, name, metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+		PropagationPolicy:  &propagationPolicy,
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return errors.Wrapf(err, "error force deleting %q", name)
+}
+
+// EnsureAbsent deletes the named resource if it exists. If the resource doesn't exist, nil is returned.
+func EnsureAbsent(ctx context.Context, client resource.Interface, name string) error {
+	err := client.Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		logger.V(log.LIBTRACE).Infof("Resource %q does not exist", name)
+		return nil
+	}
+
+	return errors.Wrapf(err, "error deleting %q", name)
+}
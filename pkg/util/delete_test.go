@@ -0,0 +1,135 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("ForceDelete function", func() {
+	var (
+		deleteErr   error
+		deletedName string
+		options     *metav1.DeleteOptions
+		client      resource.Interface
+	)
+
+	BeforeEach(func() {
+		deleteErr = nil
+		deletedName = ""
+		options = nil
+
+		client = &resource.InterfaceFuncs{
+			DeleteFunc: func(ctx context.Context, name string, o metav1.DeleteOptions) error {
+				deletedName = name
+				options = &o
+
+				return deleteErr
+			},
+		}
+	})
+
+	It("should delete the resource with a zero grace period and the given propagation policy", func() {
+		Expect(util.ForceDelete(context.TODO(), client, "test-pod", metav1.DeletePropagationOrphan)).To(Succeed())
+		Expect(deletedName).To(Equal("test-pod"))
+		Expect(options).ToNot(BeNil())
+		Expect(options.GracePeriodSeconds).To(HaveValue(BeZero()))
+		Expect(options.PropagationPolicy).To(HaveValue(Equal(metav1.DeletePropagationOrphan)))
+
+		Expect(util.ForceDelete(context.TODO(), client, "test-pod", metav1.DeletePropagationBackground)).To(Succeed())
+		Expect(options.PropagationPolicy).To(HaveValue(Equal(metav1.DeletePropagationBackground)))
+	})
+
+	When("the resource doesn't exist", func() {
+		BeforeEach(func() {
+			deleteErr = apierrors.NewNotFound(schema.GroupResource{}, "test-pod")
+		})
+
+		It("should succeed", func() {
+			Expect(util.ForceDelete(context.TODO(), client, "test-pod", metav1.DeletePropagationOrphan)).To(Succeed())
+		})
+	})
+
+	When("delete fails", func() {
+		BeforeEach(func() {
+			deleteErr = apierrors.NewServiceUnavailable("fake")
+		})
+
+		It("should return an error", func() {
+			err := util.ForceDelete(context.TODO(), client, "test-pod", metav1.DeletePropagationOrphan)
+			Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("EnsureAbsent function", func() {
+	var (
+		deleteErr   error
+		deletedName string
+		client      resource.Interface
+	)
+
+	BeforeEach(func() {
+		deleteErr = nil
+		deletedName = ""
+
+		client = &resource.InterfaceFuncs{
+			DeleteFunc: func(ctx context.Context, name string, o metav1.DeleteOptions) error {
+				deletedName = name
+				return deleteErr
+			},
+		}
+	})
+
+	When("the resource exists", func() {
+		It("should delete it", func() {
+			Expect(util.EnsureAbsent(context.TODO(), client, "test-pod")).To(Succeed())
+			Expect(deletedName).To(Equal("test-pod"))
+		})
+	})
+
+	When("the resource is already absent", func() {
+		BeforeEach(func() {
+			deleteErr = apierrors.NewNotFound(schema.GroupResource{}, "test-pod")
+		})
+
+		It("should succeed", func() {
+			Expect(util.EnsureAbsent(context.TODO(), client, "test-pod")).To(Succeed())
+		})
+	})
+
+	When("delete fails", func() {
+		BeforeEach(func() {
+			deleteErr = apierrors.NewServiceUnavailable("fake")
+		})
+
+		It("should return an error", func() {
+			err := util.EnsureAbsent(context.TODO(), client, "test-pod")
+			Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+		})
+	})
+})
@@ -19,6 +19,9 @@ limitations under the License.
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -131,3 +134,20 @@ func CopyImmutableMetadata(from, to *unstructured.Unstructured) *unstructured.Un
 
 	return to
 }
+
+// LastAppliedHash returns a hash of the given resource's content, excluding its status, suitable for recording on a
+// written resource to later determine whether the desired content changed without comparing against fields set by the
+// server, eg defaulted fields.
+func LastAppliedHash(obj *unstructured.Unstructured) (string, error) {
+	content := obj.DeepCopy()
+	unstructured.RemoveNestedField(content.Object, StatusField)
+
+	data, err := json.Marshal(content.Object)
+	if err != nil {
+		return "", errors.Wrapf(err, "error marshalling %#v", obj)
+	}
+
+	h := sha256.Sum256(data)
+
+	return hex.EncodeToString(h[:]), nil
+}
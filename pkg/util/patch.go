@@ -0,0 +1,257 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	"github.com/submariner-io/admiral/pkg/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	JSONPatchOpAdd     = "add"
+	JSONPatchOpRemove  = "remove"
+	JSONPatchOpReplace = "replace"
+	JSONPatchOpMove    = "move"
+	JSONPatchOpCopy    = "copy"
+	JSONPatchOpTest    = "test"
+)
+
+// JSONPatchOperation is a JSON patch (RFC 6902) operation. The Path and From are JSON pointers, eg "/metadata/labels/app".
+type JSONPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+	From  string      `json:"from,omitempty"`
+}
+
+// PatchStatus applies the mutate function to a copy of the given resource and patches the status subresource with a
+// JSON merge patch of the resulting status changes. Unlike UpdateStatus, the resource isn't retrieved first and the
+// patch doesn't depend on the resource version, so it doesn't conflict with concurrent updates. If the status is
+// unchanged, nothing is done.
+func PatchStatus(ctx context.Context, client resource.Interface, obj runtime.Object, mutate MutateFn) error {
+	name := resource.ToMeta(obj).GetName()
+
+	mutated, err := mutate(obj.DeepCopyObject())
+	if err != nil {
+		return err
+	}
+
+	original, err := statusOf(obj)
+	if err != nil {
+		return err
+	}
+
+	modified, err := statusOf(mutated)
+	if err != nil {
+		return err
+	}
+
+	patch, err := CreateMergePatch(original, modified)
+	if err != nil {
+		return err
+	}
+
+	if isEmptyPatch(patch) {
+		logger.V(log.LIBTRACE).Infof("Status for resource %q is unchanged - not patching", name)
+		return nil
+	}
+
+	logger.V(log.LIBTRACE).Infof("Patching status for resource %q: %s", name, patch)
+
+	_, err = client.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+
+	return errors.Wrapf(err, "error patching status for %q", name)
+}
+
+// CreateMergePatch returns a JSON merge patch (RFC 7386) that transforms the original object into the modified object.
+func CreateMergePatch(original, modified interface{}) ([]byte, error) {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error marshalling original %#v", original)
+	}
+
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error marshalling modified %#v", modified)
+	}
+
+	patch, err := jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
+
+	return patch, errors.Wrap(err, "error creating merge patch")
+}
+
+// StrategicMergePatch patches the resource with the changes that transform the original object into the modified object
+// and returns the patched resource. For types registered in the k8s scheme, ie the core, apps etc types, a strategic
+// merge patch is used so lists keyed by a merge key, eg a Pod's containers keyed by name, are merged rather than
+// replaced, ie changing one container doesn't drop the others. For other types, eg custom resources, which have no
+// strategic merge metadata, a JSON merge patch is used. If there are no changes, nothing is done and the
+// OperationResult is None.
+func StrategicMergePatch(ctx context.Context, client resource.Interface, original, modified runtime.Object,
+) (runtime.Object, OperationResult, error) {
+	name := resource.ToMeta(original).GetName()
+
+	patch, patchType, err := CreateStrategicMergePatch(original, modified)
+	if err != nil {
+		return nil, OperationResultNone, err
+	}
+
+	if isEmptyPatch(patch) {
+		logger.V(log.LIBTRACE).Infof("Resource %q is unchanged - not patching", name)
+		return original, OperationResultNone, nil
+	}
+
+	logger.V(log.LIBTRACE).Infof("Patching resource %q with %s: %s", name, patchType, patch)
+
+	obj, err := client.Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, OperationResultNone, errors.Wrapf(err, "error patching %q", name)
+	}
+
+	return obj, OperationResultUpdated, nil
+}
+
+// CreateStrategicMergePatch returns a patch that transforms the original object into the modified object along with its
+// type. A strategic merge patch is returned for types registered in the k8s scheme, otherwise a JSON merge patch.
+func CreateStrategicMergePatch(original, modified runtime.Object) ([]byte, types.PatchType, error) {
+	dataStruct := strategicMergeDataStructFor(original)
+	if dataStruct == nil {
+		patch, err := CreateMergePatch(original, modified)
+		return patch, types.MergePatchType, err
+	}
+
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error marshalling original %#v", original)
+	}
+
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error marshalling modified %#v", modified)
+	}
+
+	patch, err := strategicpatch.CreateTwoWayMergePatch(originalJSON, modifiedJSON, dataStruct)
+
+	return patch, types.StrategicMergePatchType, errors.Wrap(err, "error creating strategic merge patch")
+}
+
+// strategicMergeDataStructFor returns a new instance of the typed struct registered in the k8s scheme for the given
+// object's kind, from which the strategic merge metadata is obtained, or nil if there's none.
+func strategicMergeDataStructFor(obj runtime.Object) runtime.Object {
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return nil
+	}
+
+	typed, err := scheme.Scheme.New(gvks[0])
+	if err != nil {
+		return nil
+	}
+
+	if _, ok := typed.(runtime.Unstructured); ok {
+		return nil
+	}
+
+	return typed
+}
+
+func statusOf(obj runtime.Object) (map[string]interface{}, error) {
+	u, err := resource.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "error obtaining the status")
+	}
+
+	return map[string]interface{}{StatusField: GetNestedField(u, StatusField)}, nil
+}
+
+func isEmptyPatch(patch []byte) bool {
+	return string(patch) == "{}"
+}
+
+// JSONPatch applies the given JSON patch (RFC 6902) operations to the named resource and returns the patched resource.
+// The operations are applied atomically, ie if any fails, none are applied. A failed "test" operation is returned as a
+// Conflict error so conditional updates can be handled like a resource version conflict. The OperationResult is
+// Updated unless there are no operations or only "test" operations, in which case nothing is written.
+func JSONPatch(ctx context.Context, client resource.Interface, name string, ops []JSONPatchOperation,
+) (runtime.Object, OperationResult, error) {
+	if len(ops) == 0 {
+		return nil, OperationResultNone, nil
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return nil, OperationResultNone, errors.Wrap(err, "error marshalling the JSON patch")
+	}
+
+	logger.V(log.LIBTRACE).Infof("JSON patching resource %q: %s", name, patch)
+
+	obj, err := client.Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	if isJSONPatchTestFailed(err) {
+		return nil, OperationResultNone, apierrors.NewConflict(schema.GroupResource{}, name, err)
+	}
+
+	if err != nil {
+		return nil, OperationResultNone, errors.Wrapf(err, "error JSON patching %q", name)
+	}
+
+	for i := range ops {
+		if ops[i].Op != JSONPatchOpTest {
+			return obj, OperationResultUpdated, nil
+		}
+	}
+
+	return obj, OperationResultNone, nil
+}
+
+// isJSONPatchTestFailed returns true if the error indicates a JSON patch "test" operation failed. The API server
+// rejects the patch with an Invalid (422) error whose cause contains the underlying error message.
+func isJSONPatchTestFailed(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return true
+	}
+
+	var status apierrors.APIStatus
+	if !apierrors.IsInvalid(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+
+	for _, cause := range status.Status().Details.Causes {
+		if strings.Contains(cause.Message, jsonpatch.ErrTestFailed.Error()) {
+			return true
+		}
+	}
+
+	return false
+}
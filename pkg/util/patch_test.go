@@ -0,0 +1,313 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/resource"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("PatchStatus function", func() {
+	var (
+		pod         *corev1.Pod
+		testingFake *testing.Fake
+		client      *fake.DynamicResourceClient
+		mutateFn    util.MutateFn
+	)
+
+	BeforeEach(func() {
+		dynClient := fake.NewDynamicClient(scheme.Scheme)
+		testingFake = &dynClient.Fake
+
+		client, _ = dynClient.Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test").(*fake.DynamicResourceClient)
+
+		pod = test.NewPod("test")
+		test.CreateResource(client, pod)
+
+		mutateFn = func(existing runtime.Object) (runtime.Object, error) {
+			existing.(*corev1.Pod).Status.Phase = corev1.PodRunning
+			return existing, nil
+		}
+	})
+
+	patchStatus := func() error {
+		return util.PatchStatus(context.TODO(), resource.ForDynamic(client), pod, mutateFn)
+	}
+
+	It("should patch the status subresource", func() {
+		Expect(patchStatus()).To(Succeed())
+		Expect(test.GetPod(client, pod).Status.Phase).To(Equal(corev1.PodRunning))
+
+		actions := patchActions(testingFake)
+		Expect(actions).To(HaveLen(1))
+		Expect(actions[0].GetSubresource()).To(Equal("status"))
+		Expect(actions[0].GetPatchType()).To(Equal(types.MergePatchType))
+		Expect(string(actions[0].GetPatch())).To(Equal(`{"status":{"phase":"Running"}}`))
+	})
+
+	It("should not modify the given resource", func() {
+		Expect(patchStatus()).To(Succeed())
+		Expect(pod.Status.Phase).To(BeEmpty())
+	})
+
+	When("the status is unchanged", func() {
+		BeforeEach(func() {
+			mutateFn = func(existing runtime.Object) (runtime.Object, error) {
+				existing.(*corev1.Pod).Spec.Containers[0].Image = "updated"
+				return existing, nil
+			}
+		})
+
+		It("should not patch the resource", func() {
+			Expect(patchStatus()).To(Succeed())
+			Expect(patchActions(testingFake)).To(BeEmpty())
+		})
+	})
+
+	When("the mutated resource can't be converted to unstructured", func() {
+		BeforeEach(func() {
+			mutateFn = func(existing runtime.Object) (runtime.Object, error) {
+				return &unregisteredPod{Pod: *existing.(*corev1.Pod)}, nil
+			}
+		})
+
+		It("should return an error", func() {
+			Expect(patchStatus()).ToNot(Succeed())
+			Expect(patchActions(testingFake)).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("JSONPatch function", func() {
+	var (
+		pod         *corev1.Pod
+		testingFake *testing.Fake
+		client      *fake.DynamicResourceClient
+	)
+
+	BeforeEach(func() {
+		dynClient := fake.NewDynamicClient(scheme.Scheme)
+		testingFake = &dynClient.Fake
+
+		client, _ = dynClient.Resource(corev1.SchemeGroupVersion.WithResource("pods")).Namespace("test").(*fake.DynamicResourceClient)
+
+		pod = test.NewPod("test")
+		test.CreateResource(client, pod)
+	})
+
+	jsonPatch := func(ops ...util.JSONPatchOperation) (util.OperationResult, error) {
+		_, result, err := util.JSONPatch(context.TODO(), resource.ForDynamic(client), pod.Name, ops)
+		return result, err
+	}
+
+	When("an add operation is specified", func() {
+		It("should add the field", func() {
+			Expect(jsonPatch(util.JSONPatchOperation{
+				Op:    util.JSONPatchOpAdd,
+				Path:  "/metadata/labels/added",
+				Value: "true",
+			})).To(Equal(util.OperationResultUpdated))
+
+			Expect(test.GetPod(client, pod).Labels).To(HaveKeyWithValue("added", "true"))
+
+			actions := patchActions(testingFake)
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].GetPatchType()).To(Equal(types.JSONPatchType))
+			Expect(string(actions[0].GetPatch())).To(Equal(`[{"op":"add","path":"/metadata/labels/added","value":"true"}]`))
+		})
+	})
+
+	When("a replace operation is specified", func() {
+		It("should replace the field and return the patched resource", func() {
+			obj, result, err := util.JSONPatch(context.TODO(), resource.ForDynamic(client), pod.Name, []util.JSONPatchOperation{
+				{Op: util.JSONPatchOpReplace, Path: "/spec/containers/0/image", Value: "replaced"},
+			})
+			Expect(err).To(Succeed())
+			Expect(result).To(Equal(util.OperationResultUpdated))
+			Expect(test.ToUnstructured(obj).GetName()).To(Equal(pod.Name))
+
+			Expect(test.GetPod(client, pod).Spec.Containers[0].Image).To(Equal("replaced"))
+		})
+	})
+
+	When("a test operation succeeds", func() {
+		It("should apply the subsequent operations", func() {
+			Expect(jsonPatch(
+				util.JSONPatchOperation{Op: util.JSONPatchOpTest, Path: "/spec/containers/0/image", Value: pod.Spec.Containers[0].Image},
+				util.JSONPatchOperation{Op: util.JSONPatchOpReplace, Path: "/spec/containers/0/image", Value: "replaced"},
+			)).To(Equal(util.OperationResultUpdated))
+
+			Expect(test.GetPod(client, pod).Spec.Containers[0].Image).To(Equal("replaced"))
+		})
+	})
+
+	When("a test operation fails", func() {
+		It("should return a Conflict error and not apply any operations", func() {
+			_, err := jsonPatch(
+				util.JSONPatchOperation{Op: util.JSONPatchOpTest, Path: "/spec/containers/0/image", Value: "other"},
+				util.JSONPatchOperation{Op: util.JSONPatchOpReplace, Path: "/spec/containers/0/image", Value: "replaced"},
+			)
+			Expect(apierrors.IsConflict(err)).To(BeTrue(), "Expected a Conflict error: %v", err)
+
+			Expect(test.GetPod(client, pod).Spec.Containers[0].Image).To(Equal(pod.Spec.Containers[0].Image))
+		})
+	})
+
+	When("no operations are specified", func() {
+		It("should not patch the resource", func() {
+			Expect(jsonPatch()).To(Equal(util.OperationResultNone))
+			Expect(patchActions(testingFake)).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("StrategicMergePatch function", func() {
+	var (
+		testingFake *testing.Fake
+		dynClient   *fake.DynamicClient
+		client      *fake.DynamicResourceClient
+	)
+
+	BeforeEach(func() {
+		dynClient = fake.NewDynamicClient(scheme.Scheme)
+		testingFake = &dynClient.Fake
+	})
+
+	When("the type has strategic merge metadata", func() {
+		var (
+			pod      *corev1.Pod
+			original *corev1.Pod
+		)
+
+		BeforeEach(func() {
+			client, _ = dynClient.Resource(corev1.SchemeGroupVersion.WithResource("pods")).Namespace("test").(*fake.DynamicResourceClient)
+
+			pod = test.NewPod("test")
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "sidecar", Image: "sidecar"})
+			test.CreateResource(client, pod)
+
+			original = pod.DeepCopy()
+
+			// Simulate a container being added concurrently, eg by an admission webhook, after the original was read.
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "injected", Image: "injected"})
+			test.UpdateResource(client, pod)
+		})
+
+		It("should merge the containers list rather than replace it", func() {
+			modified := original.DeepCopy()
+			modified.Spec.Containers[0].Image = "apache"
+
+			_, result, err := util.StrategicMergePatch(context.TODO(), resource.ForDynamic(client), original, modified)
+			Expect(err).To(Succeed())
+			Expect(result).To(Equal(util.OperationResultUpdated))
+
+			containers := test.GetPod(client, pod).Spec.Containers
+			Expect(containers).To(HaveLen(3))
+			Expect(containers[0].Name).To(Equal("httpd"))
+			Expect(containers[0].Image).To(Equal("apache"))
+			Expect(containers[1].Name).To(Equal("sidecar"))
+			Expect(containers[1].Image).To(Equal("sidecar"))
+			Expect(containers[2].Name).To(Equal("injected"))
+			Expect(containers[2].Image).To(Equal("injected"))
+
+			actions := patchActions(testingFake)
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].GetPatchType()).To(Equal(types.StrategicMergePatchType))
+		})
+
+		Context("and there are no changes", func() {
+			It("should not patch", func() {
+				_, result, err := util.StrategicMergePatch(context.TODO(), resource.ForDynamic(client), original, original.DeepCopy())
+				Expect(err).To(Succeed())
+				Expect(result).To(Equal(util.OperationResultNone))
+				Expect(patchActions(testingFake)).To(BeEmpty())
+			})
+		})
+	})
+
+	When("the type has no strategic merge metadata", func() {
+		var original *unstructured.Unstructured
+
+		BeforeEach(func() {
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "widgets"}
+			client, _ = dynClient.Resource(gvr).Namespace("test").(*fake.DynamicResourceClient)
+
+			original = &unstructured.Unstructured{}
+			original.SetAPIVersion("test.io/v1")
+			original.SetKind("Widget")
+			original.SetNamespace("test")
+			original.SetName("widget")
+			_ = unstructured.SetNestedField(original.Object, "one", "spec", "value")
+
+			test.CreateResource(client, original)
+		})
+
+		It("should fall back to a JSON merge patch", func() {
+			modified := original.DeepCopy()
+			_ = unstructured.SetNestedField(modified.Object, "two", "spec", "value")
+
+			_, result, err := util.StrategicMergePatch(context.TODO(), resource.ForDynamic(client), original, modified)
+			Expect(err).To(Succeed())
+			Expect(result).To(Equal(util.OperationResultUpdated))
+
+			value, _, _ := unstructured.NestedString(test.GetResource(client, original).Object, "spec", "value")
+			Expect(value).To(Equal("two"))
+
+			actions := patchActions(testingFake)
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].GetPatchType()).To(Equal(types.MergePatchType))
+			Expect(string(actions[0].GetPatch())).To(Equal(`{"spec":{"value":"two"}}`))
+		})
+	})
+})
+
+func patchActions(f *testing.Fake) []testing.PatchAction {
+	var found []testing.PatchAction
+
+	for _, action := range f.Actions() {
+		if action.GetVerb() == "patch" {
+			found = append(found, action.(testing.PatchAction))
+		}
+	}
+
+	return found
+}
+
+// unregisteredPod is a Pod whose type isn't registered in the scheme.
+type unregisteredPod struct {
+	corev1.Pod
+}
@@ -0,0 +1,68 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+type PollConditionFn func(obj *unstructured.Unstructured) (bool, error)
+
+type PollOptions struct {
+	// FailOnNotFound if true, polling fails if the resource doesn't exist. By default, a missing resource is treated as
+	// the condition not yet being met.
+	FailOnNotFound bool
+}
+
+// PollUntil retrieves the named resource on the configured backoff (see SetBackoff) until the given condition returns
+// true, the condition returns an error or the context is done. A missing resource is treated as the condition not yet
+// being met, as are other errors from retrieving the resource.
+func PollUntil(ctx context.Context, client dynamic.ResourceInterface, name string, cond PollConditionFn) error {
+	return PollUntilWithOptions(ctx, client, name, PollOptions{}, cond)
+}
+
+// PollUntilWithOptions is like PollUntil but with the given options.
+func PollUntilWithOptions(ctx context.Context, client dynamic.ResourceInterface, name string, options PollOptions,
+	cond PollConditionFn,
+) error {
+	err := retryWithBackoff(ctx, backOff, func() (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && options.FailOnNotFound {
+			return false, errors.Wrapf(err, "resource %q not found", name)
+		}
+
+		if err != nil {
+			logger.V(log.LIBTRACE).Infof("Error retrieving resource %q while polling: %v", name, err)
+			return false, nil
+		}
+
+		met, err := cond(obj)
+
+		return met, errors.Wrapf(err, "error evaluating the condition for resource %q", name)
+	})
+
+	return errors.Wrapf(err, "error polling resource %q", name)
+}
@@ -0,0 +1,160 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/submariner-io/admiral/pkg/fake"
+	"github.com/submariner-io/admiral/pkg/syncer/test"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("PollUntil", func() {
+	var (
+		client      dynamic.ResourceInterface
+		pod         *corev1.Pod
+		numCalls    int
+		origBackoff wait.Backoff
+	)
+
+	ctx := context.TODO()
+
+	isRunning := func(obj *unstructured.Unstructured) (bool, error) {
+		numCalls++
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+
+		return corev1.PodPhase(phase) == corev1.PodRunning, nil
+	}
+
+	BeforeEach(func() {
+		numCalls = 0
+		pod = test.NewPod("test")
+
+		client = fake.NewDynamicClient(scheme.Scheme).Resource(schema.GroupVersionResource{
+			Group:    corev1.SchemeGroupVersion.Group,
+			Version:  corev1.SchemeGroupVersion.Version,
+			Resource: "pods",
+		}).Namespace("test")
+
+		origBackoff = util.SetBackoff(wait.Backoff{
+			Steps:    5,
+			Duration: 30 * time.Millisecond,
+		})
+	})
+
+	AfterEach(func() {
+		util.SetBackoff(origBackoff)
+	})
+
+	When("the condition becomes true", func() {
+		It("should succeed", func() {
+			test.CreateResource(client, pod)
+
+			go func(client dynamic.ResourceInterface) {
+				defer GinkgoRecover()
+
+				time.Sleep(50 * time.Millisecond)
+
+				pod.Status.Phase = corev1.PodRunning
+				test.UpdateResource(client, pod)
+			}(client)
+
+			Expect(util.PollUntil(ctx, client, pod.Name, isRunning)).To(Succeed())
+			Expect(numCalls).To(BeNumerically(">", 1))
+		})
+	})
+
+	When("the resource is initially missing", func() {
+		It("should wait for the resource and succeed", func() {
+			pod.Status.Phase = corev1.PodRunning
+
+			go func(client dynamic.ResourceInterface) {
+				defer GinkgoRecover()
+
+				time.Sleep(50 * time.Millisecond)
+				test.CreateResource(client, pod)
+			}(client)
+
+			Expect(util.PollUntil(ctx, client, pod.Name, isRunning)).To(Succeed())
+			Expect(numCalls).To(Equal(1))
+		})
+
+		Context("and FailOnNotFound is set", func() {
+			It("should return a NotFound error", func() {
+				err := util.PollUntilWithOptions(ctx, client, pod.Name, util.PollOptions{FailOnNotFound: true}, isRunning)
+				Expect(apierrors.IsNotFound(errors.Cause(err))).To(BeTrue())
+				Expect(numCalls).To(BeZero())
+			})
+		})
+	})
+
+	When("the condition never becomes true", func() {
+		It("should return a timeout error", func() {
+			test.CreateResource(client, pod)
+
+			err := util.PollUntil(ctx, client, pod.Name, isRunning)
+			Expect(errors.Is(err, wait.ErrWaitTimeout)).To(BeTrue())
+			Expect(numCalls).To(Equal(5))
+		})
+	})
+
+	When("the context is cancelled", func() {
+		It("should return the context error", func() {
+			test.CreateResource(client, pod)
+
+			cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+
+			util.SetBackoff(wait.Backoff{
+				Steps:    100,
+				Duration: 30 * time.Millisecond,
+			})
+
+			err := util.PollUntil(cancelCtx, client, pod.Name, isRunning)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
+
+	When("the condition returns an error", func() {
+		It("should return the error immediately", func() {
+			test.CreateResource(client, pod)
+
+			condErr := errors.New("fake error")
+
+			err := util.PollUntil(ctx, client, pod.Name, func(obj *unstructured.Unstructured) (bool, error) {
+				numCalls++
+				return false, condErr
+			})
+			Expect(errors.Is(err, condErr)).To(BeTrue())
+			Expect(numCalls).To(Equal(1))
+		})
+	})
+})
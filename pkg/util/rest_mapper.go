@@ -0,0 +1,138 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// DefaultRestMapperRefreshInterval is the interval at which a cached REST mapper built by BuildCachedRestMapper
+// re-discovers the API resources by default.
+const DefaultRestMapperRefreshInterval = 5 * time.Minute
+
+// ResettableRESTMapper is a RESTMapper whose cached discovery information can be invalidated via Reset so the next
+// mapping request re-discovers the API resources, eg after a CRD is installed.
+type ResettableRESTMapper interface {
+	meta.RESTMapper
+	Reset()
+}
+
+type cachedRestMapper struct {
+	*restmapper.DeferredDiscoveryRESTMapper
+	refreshInterval time.Duration
+	mutex           sync.Mutex
+	lastRefresh     time.Time
+}
+
+// BuildCachedRestMapper returns a RESTMapper built from the given REST config that lazily discovers the API resources
+// and caches them. Unlike BuildRestMapper, the discovery information is refreshed at the given interval, if positive,
+// to pick up newly-installed resource types. It can also be refreshed on demand via Reset.
+func BuildCachedRestMapper(restConfig *rest.Config, refreshInterval time.Duration) (ResettableRESTMapper, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating discovery client")
+	}
+
+	return NewCachedRestMapper(discoveryClient, refreshInterval), nil
+}
+
+// NewCachedRestMapper returns a RESTMapper that caches the API resources discovered via the given client and refreshes
+// them at the given interval, if positive. The discovery is performed on the first mapping request.
+func NewCachedRestMapper(discoveryClient discovery.DiscoveryInterface, refreshInterval time.Duration) ResettableRESTMapper {
+	return &cachedRestMapper{
+		DeferredDiscoveryRESTMapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		refreshInterval:             refreshInterval,
+		lastRefresh:                 time.Now(),
+	}
+}
+
+// refreshIfStale resets the cached discovery information if the refresh interval has elapsed since it was last reset.
+func (m *cachedRestMapper) refreshIfStale() {
+	if m.refreshInterval <= 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if time.Since(m.lastRefresh) < m.refreshInterval {
+		return
+	}
+
+	m.DeferredDiscoveryRESTMapper.Reset()
+	m.lastRefresh = time.Now()
+}
+
+func (m *cachedRestMapper) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.DeferredDiscoveryRESTMapper.Reset()
+	m.lastRefresh = time.Now()
+}
+
+func (m *cachedRestMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	m.refreshIfStale()
+	return m.DeferredDiscoveryRESTMapper.KindFor(resource) //nolint:wrapcheck // No need to wrap
+}
+
+func (m *cachedRestMapper) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	m.refreshIfStale()
+	return m.DeferredDiscoveryRESTMapper.KindsFor(resource) //nolint:wrapcheck // No need to wrap
+}
+
+func (m *cachedRestMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	m.refreshIfStale()
+	return m.DeferredDiscoveryRESTMapper.ResourceFor(input) //nolint:wrapcheck // No need to wrap
+}
+
+func (m *cachedRestMapper) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	m.refreshIfStale()
+	return m.DeferredDiscoveryRESTMapper.ResourcesFor(input) //nolint:wrapcheck // No need to wrap
+}
+
+func (m *cachedRestMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	m.refreshIfStale()
+	return m.DeferredDiscoveryRESTMapper.RESTMapping(gk, versions...) //nolint:wrapcheck // No need to wrap
+}
+
+func (m *cachedRestMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	m.refreshIfStale()
+	return m.DeferredDiscoveryRESTMapper.RESTMappings(gk, versions...) //nolint:wrapcheck // No need to wrap
+}
+
+// IsNamespaced returns true if the resource type with the given GroupVersionKind is namespaced, as determined via the
+// given RESTMapper.
+func IsNamespaced(restMapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting REST mapping for %#v", gvk)
+	}
+
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
@@ -0,0 +1,129 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/testing"
+)
+
+var _ = Describe("CachedRestMapper", func() {
+	var (
+		discovery       *fakediscovery.FakeDiscovery
+		restMapper      util.ResettableRESTMapper
+		refreshInterval time.Duration
+	)
+
+	widgetGVK := schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "Widget"}
+
+	BeforeEach(func() {
+		refreshInterval = 0
+
+		discovery = &fakediscovery.FakeDiscovery{Fake: &testing.Fake{}}
+		discovery.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: corev1.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Kind: "Pod", Namespaced: true},
+					{Name: "namespaces", Kind: "Namespace"},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		restMapper = util.NewCachedRestMapper(discovery, refreshInterval)
+	})
+
+	addWidgets := func() {
+		discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+			GroupVersion: widgetGVK.GroupVersion().String(),
+			APIResources: []metav1.APIResource{{Name: "widgets", Kind: widgetGVK.Kind, Namespaced: true}},
+		})
+	}
+
+	It("should resolve a GroupVersionKind to its GroupVersionResource", func() {
+		mapping, err := restMapper.RESTMapping(schema.GroupKind{Kind: "Pod"}, "v1")
+		Expect(err).To(Succeed())
+		Expect(mapping.Resource).To(Equal(corev1.SchemeGroupVersion.WithResource("pods")))
+		Expect(mapping.Scope.Name()).To(Equal(meta.RESTScopeNameNamespace))
+	})
+
+	It("should determine the scope of a resource type", func() {
+		namespaced, err := util.IsNamespaced(restMapper, corev1.SchemeGroupVersion.WithKind("Pod"))
+		Expect(err).To(Succeed())
+		Expect(namespaced).To(BeTrue())
+
+		namespaced, err = util.IsNamespaced(restMapper, corev1.SchemeGroupVersion.WithKind("Namespace"))
+		Expect(err).To(Succeed())
+		Expect(namespaced).To(BeFalse())
+	})
+
+	It("should return a NoMatch error for an unknown resource type", func() {
+		_, err := restMapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+		Expect(meta.IsNoMatchError(err)).To(BeTrue())
+	})
+
+	When("a new resource type appears", func() {
+		Context("and the refresh interval is set", func() {
+			BeforeEach(func() {
+				refreshInterval = 100 * time.Millisecond
+			})
+
+			It("should eventually resolve it", func() {
+				_, err := restMapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+				Expect(err).To(HaveOccurred())
+
+				addWidgets()
+
+				Eventually(func() error {
+					_, err := restMapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+					return err
+				}, 2).Should(Succeed())
+			})
+		})
+
+		Context("and the refresh interval isn't set", func() {
+			It("should resolve it after Reset", func() {
+				_, err := restMapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+				Expect(err).To(HaveOccurred())
+
+				addWidgets()
+
+				_, err = restMapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+				Expect(err).To(HaveOccurred())
+
+				restMapper.Reset()
+
+				mapping, err := restMapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+				Expect(err).To(Succeed())
+				Expect(mapping.Resource).To(Equal(widgetGVK.GroupVersion().WithResource("widgets")))
+			})
+		})
+	})
+})
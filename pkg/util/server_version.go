@@ -0,0 +1,93 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+)
+
+// ServerSideApplyMinVersion the minimum API server version with which Apply uses server-side apply, ie the version in
+// which it became generally available.
+const ServerSideApplyMinVersion = "v1.22.0"
+
+// ServerVersionAtLeast returns true if the version of the API server, as reported by the given client, is at least the
+// given minimum version, eg "v1.22.0". Any pre-release or build metadata in the server version is ignored.
+func ServerVersionAtLeast(client discovery.ServerVersionInterface, minVersion string) (bool, error) {
+	min, err := version.ParseGeneric(minVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid minimum version %q", minVersion)
+	}
+
+	info, err := client.ServerVersion()
+	if err != nil {
+		return false, errors.Wrap(err, "error retrieving the server version")
+	}
+
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to parse the server version %q", info.GitVersion)
+	}
+
+	return serverVersion.AtLeast(min), nil
+}
+
+// MinServerVersion gates a feature on a minimum version of the API server, so the feature degrades gracefully on older
+// servers instead of failing. The server version is queried once, on the first call to Enabled, and the result cached.
+type MinServerVersion struct {
+	client     discovery.ServerVersionInterface
+	feature    string
+	minVersion string
+	once       sync.Once
+	enabled    bool
+}
+
+// NewMinServerVersion returns a MinServerVersion gating the named feature on the given minimum version of the API
+// server reported by the given client.
+func NewMinServerVersion(client discovery.ServerVersionInterface, feature, minVersion string) *MinServerVersion {
+	return &MinServerVersion{
+		client:     client,
+		feature:    feature,
+		minVersion: minVersion,
+	}
+}
+
+// Enabled returns true if the API server's version is at least the minimum version. Otherwise, or if the server version
+// can't be determined, a warning is logged and false is returned.
+func (m *MinServerVersion) Enabled() bool {
+	m.once.Do(func() {
+		enabled, err := ServerVersionAtLeast(m.client, m.minVersion)
+		if err != nil {
+			logger.Warningf("Unable to determine if the API server supports %s - disabling it: %v", m.feature, err)
+			return
+		}
+
+		if !enabled {
+			logger.Warningf("The API server's version is older than %s, which is required for %s - disabling it",
+				m.minVersion, m.feature)
+		}
+
+		m.enabled = enabled
+	})
+
+	return m.enabled
+}
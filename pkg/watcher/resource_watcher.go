@@ -21,7 +21,9 @@ limitations under the License.
 package watcher
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +38,16 @@ import (
 
 type Interface interface {
 	Start(stopCh <-chan struct{}) error
+
+	// Resync re-delivers every cached resource to the OnUpdate handler, eg after a change that affects how resources
+	// are handled. It's safe to call while the watcher is running and doesn't block event delivery.
+	Resync()
+
+	// Stop stops the watcher's informers and blocks until their goroutines have exited and any in-progress handler
+	// invocations have returned, or the context is done, in which case the context error is returned. No handler is
+	// invoked once Stop is called. It's safe to call more than once and concurrently with closing the stop channel
+	// passed to Start.
+	Stop(ctx context.Context) error
 }
 
 // EventHandler can handle notifications of events that happen to a resource. The bool return value from each event
@@ -88,7 +100,7 @@ type Config struct {
 	RestConfig *rest.Config
 
 	// RestMapper used to obtain GroupVersionResources. This is optional and is provided for unit testing in lieu of the
-	// RestConfig. If not specified, one is created from the RestConfig.
+	// RestConfig. If not specified, a cached one that periodically refreshes is created from the RestConfig.
 	RestMapper meta.RESTMapper
 
 	// Client the client used to access the resources to watch. This is optional and is provided for unit testing in lieu
@@ -109,7 +121,14 @@ type Config struct {
 }
 
 type resourceWatcher struct {
-	syncers []Interface
+	syncers  []syncer.Interface
+	mutex    sync.Mutex
+	started  []syncer.Interface
+	stopped  bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	handlers sync.WaitGroup
 }
 
 func New(config *Config) (Interface, error) {
@@ -121,7 +140,7 @@ func New(config *Config) (Interface, error) {
 
 	restMapper := config.RestMapper
 	if restMapper == nil {
-		if restMapper, err = util.BuildRestMapper(config.RestConfig); err != nil {
+		if restMapper, err = util.BuildCachedRestMapper(config.RestConfig, util.DefaultRestMapperRefreshInterval); err != nil {
 			return nil, errors.Wrap(err, "error building the REST mapper")
 		}
 	}
@@ -133,7 +152,11 @@ func New(config *Config) (Interface, error) {
 		}
 	}
 
-	watcher := &resourceWatcher{syncers: []Interface{}}
+	watcher := &resourceWatcher{
+		syncers: []syncer.Interface{},
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
 
 	for _, rc := range config.ResourceConfigs {
 		handler := rc.Handler
@@ -147,17 +170,19 @@ func New(config *Config) (Interface, error) {
 			RestMapper:          restMapper,
 			Federator:           federate.NewNoopFederator(),
 			ResourceType:        rc.ResourceType,
-			Transform: func(obj runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool) {
-				switch op {
-				case syncer.Create:
-					return nil, handler.OnCreate(obj, numRequeues)
-				case syncer.Update:
-					return nil, handler.OnUpdate(obj, numRequeues)
-				case syncer.Delete:
-					return nil, handler.OnDelete(obj, numRequeues)
-				}
-
-				return nil, false
+			Transform: func(obj runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
+				return nil, watcher.invokeHandler(func() bool {
+					switch op {
+					case syncer.Create:
+						return handler.OnCreate(obj, numRequeues)
+					case syncer.Update:
+						return handler.OnUpdate(obj, numRequeues)
+					case syncer.Delete:
+						return handler.OnDelete(obj, numRequeues)
+					}
+
+					return false
+				}), nil
 			},
 			ResourcesEquivalent: rc.ResourcesEquivalent,
 			ShouldProcess:       rc.ShouldProcess,
@@ -176,8 +201,25 @@ func New(config *Config) (Interface, error) {
 }
 
 func (r *resourceWatcher) Start(stopCh <-chan struct{}) error {
+	go func() {
+		select {
+		case <-stopCh:
+			r.stop()
+		case <-r.stopCh:
+		}
+	}()
+
 	for _, syncer := range r.syncers {
-		err := syncer.Start(stopCh)
+		r.mutex.Lock()
+		if r.stopped {
+			r.mutex.Unlock()
+			break
+		}
+
+		r.started = append(r.started, syncer)
+		r.mutex.Unlock()
+
+		err := syncer.Start(r.stopCh)
 		if err != nil {
 			return err //nolint:wrapcheck // OK to return the error as is.
 		}
@@ -186,6 +228,62 @@ func (r *resourceWatcher) Start(stopCh <-chan struct{}) error {
 	return nil
 }
 
+func (r *resourceWatcher) Stop(ctx context.Context) error {
+	r.stop()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "timed out waiting for the watcher to stop")
+	}
+}
+
+// stop stops the started syncers and prevents further handler invocations. The done channel is closed once the
+// syncers have stopped and the in-progress handler invocations have returned.
+func (r *resourceWatcher) stop() {
+	r.stopOnce.Do(func() {
+		r.mutex.Lock()
+		r.stopped = true
+		started := r.started
+		r.mutex.Unlock()
+
+		close(r.stopCh)
+
+		go func() {
+			for _, syncer := range started {
+				syncer.AwaitStopped()
+			}
+
+			r.handlers.Wait()
+			close(r.done)
+		}()
+	})
+}
+
+// invokeHandler invokes the given handler function unless the watcher is stopped, in which case false is returned.
+func (r *resourceWatcher) invokeHandler(handle func() bool) bool {
+	r.mutex.Lock()
+
+	if r.stopped {
+		r.mutex.Unlock()
+		return false
+	}
+
+	r.handlers.Add(1)
+	r.mutex.Unlock()
+
+	defer r.handlers.Done()
+
+	return handle()
+}
+
+func (r *resourceWatcher) Resync() {
+	for _, syncer := range r.syncers {
+		syncer.Resync()
+	}
+}
+
 func (r EventHandlerFuncs) OnCreate(obj runtime.Object, numRequeues int) bool {
 	if r.OnCreateFunc != nil {
 		return r.OnCreateFunc(obj, numRequeues)
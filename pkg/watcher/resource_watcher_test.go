@@ -19,6 +19,8 @@ package watcher_test
 
 import (
 	"context"
+	goruntime "runtime"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -47,6 +49,7 @@ var _ = Describe("Resource Watcher", func() {
 		deletedPods     chan *corev1.Pod
 		createdServices chan *corev1.Service
 		stopCh          chan struct{}
+		resourceWatcher watcher.Interface
 	)
 
 	BeforeEach(func() {
@@ -107,7 +110,9 @@ var _ = Describe("Resource Watcher", func() {
 		services = config.Client.Resource(*test.GetGroupVersionResourceFor(config.RestMapper, &corev1.Service{})).Namespace(
 			config.ResourceConfigs[0].SourceNamespace)
 
-		resourceWatcher, err := watcher.New(config)
+		var err error
+
+		resourceWatcher, err = watcher.New(config)
 		Expect(err).To(Succeed())
 
 		Expect(resourceWatcher.Start(stopCh)).To(Succeed())
@@ -192,4 +197,93 @@ var _ = Describe("Resource Watcher", func() {
 			})
 		})
 	})
+
+	When("Resync is invoked", func() {
+		var (
+			mutex       sync.Mutex
+			imageFilter string
+			resynced    chan string
+		)
+
+		BeforeEach(func() {
+			imageFilter = "nginx"
+			resynced = make(chan string, 100)
+
+			config.ResourceConfigs[0].Handler = watcher.EventHandlerFuncs{
+				OnCreateFunc: func(obj runtime.Object, numRequeues int) bool {
+					createdPods <- obj.(*corev1.Pod)
+					return false
+				},
+				OnUpdateFunc: func(obj runtime.Object, numRequeues int) bool {
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					p := obj.(*corev1.Pod)
+					if p.Spec.Containers[0].Image == imageFilter {
+						resynced <- p.Name
+					}
+
+					return false
+				},
+			}
+		})
+
+		It("should re-deliver every cached resource to the update handler", func() {
+			names := []string{"pod1", "pod2", "pod3"}
+			for _, name := range names {
+				p := test.NewPodWithImage("", "apache")
+				p.Name = name
+				test.CreateResource(pods, p)
+				Eventually(createdPods).Should(Receive())
+			}
+
+			mutex.Lock()
+			imageFilter = "apache"
+			mutex.Unlock()
+
+			resourceWatcher.Resync()
+
+			received := []string{}
+
+			for range names {
+				var name string
+				Eventually(resynced).Should(Receive(&name))
+				received = append(received, name)
+			}
+
+			Expect(received).To(ConsistOf(names))
+			Consistently(resynced).ShouldNot(Receive())
+		})
+	})
+
+	When("Stop is invoked", func() {
+		var baseline int
+
+		BeforeEach(func() {
+			baseline = goruntime.NumGoroutine()
+		})
+
+		It("should stop delivering events and not leave any goroutines running", func() {
+			test.CreateResource(pods, pod)
+			Eventually(createdPods).Should(Receive())
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+
+			Expect(resourceWatcher.Stop(ctx)).To(Succeed())
+			Expect(goruntime.NumGoroutine()).To(BeNumerically("<=", baseline))
+
+			pod.Spec.Containers[0].Image = "apache"
+			test.UpdateResource(pods, pod)
+			Expect(pods.Delete(context.TODO(), pod.Name, v1.DeleteOptions{})).To(Succeed())
+
+			Consistently(updatedPods, 300*time.Millisecond).ShouldNot(Receive())
+			Consistently(deletedPods).ShouldNot(Receive())
+		})
+
+		It("should be idempotent", func() {
+			Expect(resourceWatcher.Stop(context.TODO())).To(Succeed())
+			Expect(resourceWatcher.Stop(context.TODO())).To(Succeed())
+		})
+	})
 })
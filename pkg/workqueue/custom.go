@@ -0,0 +1,109 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// WorkQueue is the minimal queue that backs a work queue created via NewWithWorkQueue, eg a persistent or distributed
+// queue for crash-durable processing. The semantics are those of the client-go rate limiting queue: an item is only
+// queued once until it's handed out by Get, an item that's added while it's being processed is only handed out again
+// once Done is called for it, AddRateLimited adds an item after a per-item backoff that Forget resets and Get blocks
+// until an item is available, returning true once the queue is shut down.
+type WorkQueue interface {
+	Add(item interface{})
+	AddRateLimited(item interface{})
+	Get() (item interface{}, shutdown bool)
+	Done(item interface{})
+	Forget(item interface{})
+	Len() int
+	ShutDown()
+}
+
+// NewWithWorkQueue returns a work queue, as for NewBounded, that's backed by the given WorkQueue in lieu of the default
+// in-memory queue. If the metrics provider is non-nil, metrics are exported as for NewBoundedWithMetrics.
+func NewWithWorkQueue(name string, maxDepth int, queue WorkQueue, provider workqueue.MetricsProvider) Interface {
+	return newQueue(name, maxDepth, newQueueMetrics(name, provider), &workQueueAdapter{
+		WorkQueue: queue,
+		requeues:  map[interface{}]int{},
+	})
+}
+
+// workQueueAdapter implements the parts of workqueue.RateLimitingInterface that aren't provided by a WorkQueue.
+type workQueueAdapter struct {
+	WorkQueue
+	mutex        sync.Mutex
+	requeues     map[interface{}]int
+	shuttingDown bool
+}
+
+func (q *workQueueAdapter) AddRateLimited(item interface{}) {
+	q.mutex.Lock()
+	q.requeues[item]++
+	q.mutex.Unlock()
+
+	q.WorkQueue.AddRateLimited(item)
+}
+
+func (q *workQueueAdapter) Forget(item interface{}) {
+	q.mutex.Lock()
+	delete(q.requeues, item)
+	q.mutex.Unlock()
+
+	q.WorkQueue.Forget(item)
+}
+
+func (q *workQueueAdapter) NumRequeues(item interface{}) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.requeues[item]
+}
+
+func (q *workQueueAdapter) AddAfter(item interface{}, duration time.Duration) {
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+
+	time.AfterFunc(duration, func() {
+		if !q.ShuttingDown() {
+			q.Add(item)
+		}
+	})
+}
+
+func (q *workQueueAdapter) ShutDown() {
+	q.mutex.Lock()
+	q.shuttingDown = true
+	q.mutex.Unlock()
+
+	q.WorkQueue.ShutDown()
+}
+
+func (q *workQueueAdapter) ShuttingDown() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.shuttingDown
+}
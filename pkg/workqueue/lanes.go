@@ -0,0 +1,241 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// DefaultPriorityFairness the default maximum number of consecutive high priority keys dequeued while normal keys are
+// waiting.
+const DefaultPriorityFairness = 10
+
+// laneQueue is a workqueue.RateLimitingInterface with a high and a normal priority lane. Like the client-go queues, an
+// item is only ever queued once, in this case in the higher of the lanes in which it was added, and an item added while
+// it's being processed is re-queued when it's done.
+type laneQueue struct {
+	itemLimiter  workqueue.RateLimiter
+	limiter      workqueue.RateLimiter
+	fairness     int
+	mutex        sync.Mutex
+	cond         *sync.Cond
+	high         []interface{}
+	normal       []interface{}
+	queued       map[interface{}]bool
+	processing   map[interface{}]bool
+	dirty        map[interface{}]bool
+	highPriority map[interface{}]bool
+	highStreak   int
+	shuttingDown bool
+}
+
+func newLaneQueue(fairness int) *laneQueue {
+	if fairness <= 0 {
+		fairness = DefaultPriorityFairness
+	}
+
+	itemLimiter := workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 30*time.Second)
+
+	q := &laneQueue{
+		itemLimiter: itemLimiter,
+		limiter: workqueue.NewMaxOfRateLimiter(itemLimiter,
+			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)}),
+		fairness:     fairness,
+		queued:       map[interface{}]bool{},
+		processing:   map[interface{}]bool{},
+		dirty:        map[interface{}]bool{},
+		highPriority: map[interface{}]bool{},
+	}
+
+	q.cond = sync.NewCond(&q.mutex)
+
+	return q
+}
+
+// setPriority records the lane in which the item is subsequently added, including when re-queued, until it's forgotten.
+func (q *laneQueue) setPriority(item interface{}, priority int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.highPriority[item] = priority > 0
+}
+
+func (q *laneQueue) isHighPriority(item interface{}) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.highPriority[item]
+}
+
+func (q *laneQueue) Add(item interface{}) {
+	q.add(item, q.isHighPriority(item))
+}
+
+func (q *laneQueue) add(item interface{}, high bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+
+	if q.processing[item] {
+		q.dirty[item] = q.dirty[item] || high
+		return
+	}
+
+	if queuedHigh, found := q.queued[item]; found {
+		if high && !queuedHigh {
+			q.normal = remove(q.normal, item)
+			q.high = append(q.high, item)
+			q.queued[item] = true
+		}
+
+		return
+	}
+
+	q.push(item, high)
+}
+
+// push must be called with the mutex held.
+func (q *laneQueue) push(item interface{}, high bool) {
+	if high {
+		q.high = append(q.high, item)
+	} else {
+		q.normal = append(q.normal, item)
+	}
+
+	q.queued[item] = high
+	q.cond.Signal()
+}
+
+func remove(items []interface{}, item interface{}) []interface{} {
+	for i := range items {
+		if items[i] == item {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+
+	return items
+}
+
+func (q *laneQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.high) + len(q.normal)
+}
+
+func (q *laneQueue) Get() (interface{}, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.high)+len(q.normal) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+
+	if len(q.high)+len(q.normal) == 0 {
+		return nil, true
+	}
+
+	var item interface{}
+
+	if len(q.high) > 0 && (len(q.normal) == 0 || q.highStreak < q.fairness) {
+		item, q.high = q.high[0], q.high[1:]
+		q.highStreak++
+	} else {
+		item, q.normal = q.normal[0], q.normal[1:]
+		q.highStreak = 0
+	}
+
+	delete(q.queued, item)
+	q.processing[item] = true
+
+	return item, false
+}
+
+func (q *laneQueue) Done(item interface{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.processing, item)
+
+	if high, found := q.dirty[item]; found {
+		delete(q.dirty, item)
+		q.push(item, high)
+	}
+}
+
+func (q *laneQueue) ShutDown() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func (q *laneQueue) ShuttingDown() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.shuttingDown
+}
+
+func (q *laneQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.addAfter(item, q.isHighPriority(item), duration)
+}
+
+// addAfter adds the item to the lane determined now, ie its priority may change before it's added.
+func (q *laneQueue) addAfter(item interface{}, high bool, duration time.Duration) {
+	if duration <= 0 {
+		q.add(item, high)
+		return
+	}
+
+	time.AfterFunc(duration, func() {
+		q.add(item, high)
+	})
+}
+
+// AddRateLimited adds the item after the rate limiter says it's ok. High priority items aren't subject to the overall
+// rate limit so they aren't held up behind a backlog of normal items.
+func (q *laneQueue) AddRateLimited(item interface{}) {
+	if q.isHighPriority(item) {
+		q.addAfter(item, true, q.itemLimiter.When(item))
+	} else {
+		q.addAfter(item, false, q.limiter.When(item))
+	}
+}
+
+func (q *laneQueue) Forget(item interface{}) {
+	q.limiter.Forget(item)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delete(q.highPriority, item)
+}
+
+func (q *laneQueue) NumRequeues(item interface{}) int {
+	return q.limiter.NumRequeues(item)
+}
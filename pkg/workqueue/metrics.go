@@ -0,0 +1,76 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// queueMetrics holds the metrics exported for a queue. A nil *queueMetrics records nothing.
+type queueMetrics struct {
+	depth        workqueue.GaugeMetric
+	adds         workqueue.CounterMetric
+	retries      workqueue.CounterMetric
+	workDuration workqueue.HistogramMetric
+}
+
+func newQueueMetrics(name string, provider workqueue.MetricsProvider) *queueMetrics {
+	if provider == nil {
+		return nil
+	}
+
+	return &queueMetrics{
+		depth:        provider.NewDepthMetric(name),
+		adds:         provider.NewAddsMetric(name),
+		retries:      provider.NewRetriesMetric(name),
+		workDuration: provider.NewWorkDurationMetric(name),
+	}
+}
+
+func (m *queueMetrics) depthChanged(delta int) {
+	if m == nil {
+		return
+	}
+
+	if delta > 0 {
+		m.depth.Inc()
+	} else {
+		m.depth.Dec()
+	}
+}
+
+func (m *queueMetrics) added() {
+	if m != nil {
+		m.adds.Inc()
+	}
+}
+
+func (m *queueMetrics) retried() {
+	if m != nil {
+		m.retries.Inc()
+	}
+}
+
+func (m *queueMetrics) processed(started time.Time) {
+	if m != nil {
+		m.workDuration.Observe(time.Since(started).Seconds())
+	}
+}
@@ -21,6 +21,8 @@ package workqueue
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/submariner-io/admiral/pkg/log"
@@ -36,29 +38,87 @@ type ProcessFunc func(key, name, namespace string) (bool, error)
 
 type Interface interface {
 	Enqueue(obj interface{})
+	EnqueueAfter(obj interface{}, delay time.Duration)
+	// EnqueueWithPriority is like Enqueue but, for a queue created via NewPriority, a positive priority places the key
+	// in the high priority lane. Other queues ignore the priority.
+	EnqueueWithPriority(obj interface{}, priority int)
 	NumRequeues(key string) int
+	// Len returns the number of keys waiting to be processed, including those waiting to be re-queued.
+	Len() int
+	// PendingKeys returns a sorted snapshot of the keys waiting to be processed, including those waiting to be re-queued
+	// but not those currently being processed.
+	PendingKeys() []string
 	Run(stopCh <-chan struct{}, process ProcessFunc)
 	ShutDown()
+	// AwaitWorkers blocks until the worker goroutines started via Run have exited, ie after the queue is shut down and
+	// the stop channel is closed.
+	AwaitWorkers()
 }
 
 type queueType struct {
 	workqueue.RateLimitingInterface
 
-	name string
+	name         string
+	maxDepth     int
+	mutex        sync.Mutex
+	hasCapacity  *sync.Cond
+	pending      map[string]bool
+	shuttingDown bool
+	metrics      *queueMetrics
+	workers      sync.WaitGroup
 }
 
+const backpressureWarningInterval = 10 * time.Second
+
 var logger = log.Logger{Logger: logf.Log.WithName("WorkQueue")}
 
 func New(name string) Interface {
-	return &queueType{
-		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(workqueue.NewMaxOfRateLimiter(
-			// exponential per-item rate limiter
-			workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 30*time.Second),
-			// overall rate limiter (not per item)
-			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
-		), name),
-		name: name,
+	return NewBounded(name, 0)
+}
+
+// NewBounded returns a work queue that holds at most maxDepth keys waiting to be processed. Once the limit is reached,
+// enqueueing a new key blocks until a key is dequeued for processing, applying backpressure to the caller. A maxDepth
+// of 0 means unbounded.
+func NewBounded(name string, maxDepth int) Interface {
+	return newQueue(name, maxDepth, nil, newRateLimitingQueue(name))
+}
+
+// NewBoundedWithMetrics is like NewBounded but also exports the queue's depth, the number of keys added and retried
+// and how long processing takes via metrics, named by the queue name, obtained from the given provider.
+func NewBoundedWithMetrics(name string, maxDepth int, provider workqueue.MetricsProvider) Interface {
+	return newQueue(name, maxDepth, newQueueMetrics(name, provider), newRateLimitingQueue(name))
+}
+
+// NewPriority returns a bounded work queue, as for NewBounded, with two lanes. Keys enqueued via EnqueueWithPriority
+// with a positive priority, and their retries, are dequeued before other keys and aren't subject to the overall rate
+// limit. A key is only ever queued once, in the higher priority lane in which it was enqueued. To avoid starving the
+// normal lane, a normal key is dequeued after at most fairness consecutive high priority keys - a fairness of 0 means
+// DefaultPriorityFairness. If the metrics provider is non-nil, metrics are exported as for NewBoundedWithMetrics.
+func NewPriority(name string, maxDepth, fairness int, provider workqueue.MetricsProvider) Interface {
+	return newQueue(name, maxDepth, newQueueMetrics(name, provider), newLaneQueue(fairness))
+}
+
+func newRateLimitingQueue(name string) workqueue.RateLimitingInterface {
+	return workqueue.NewNamedRateLimitingQueue(workqueue.NewMaxOfRateLimiter(
+		// exponential per-item rate limiter
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 30*time.Second),
+		// overall rate limiter (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	), name)
+}
+
+func newQueue(name string, maxDepth int, metrics *queueMetrics, queue workqueue.RateLimitingInterface) *queueType {
+	q := &queueType{
+		RateLimitingInterface: queue,
+		name:                  name,
+		maxDepth:              maxDepth,
+		pending:               map[string]bool{},
+		metrics:               metrics,
 	}
+
+	q.hasCapacity = sync.NewCond(&q.mutex)
+
+	return q
 }
 
 func (q *queueType) Enqueue(obj interface{}) {
@@ -69,15 +129,146 @@ func (q *queueType) Enqueue(obj interface{}) {
 		return
 	}
 
+	q.reserve(key)
+	q.metrics.added()
+
 	logger.V(log.LIBTRACE).Infof("%s: enqueueing key %q for %T object", q.name, key, obj)
 	q.AddRateLimited(key)
 }
 
-func (q *queueType) Run(stopCh <-chan struct{}, process ProcessFunc) {
-	go wait.Until(func() {
-		for q.processNextWorkItem(process) {
+func (q *queueType) EnqueueWithPriority(obj interface{}, priority int) {
+	lanes, ok := q.RateLimitingInterface.(*laneQueue)
+	if !ok {
+		q.Enqueue(obj)
+		return
+	}
+
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	q.reserve(key)
+	q.metrics.added()
+
+	logger.V(log.LIBTRACE).Infof("%s: enqueueing key %q for %T object with priority %d", q.name, key, obj, priority)
+	lanes.setPriority(key, priority)
+	q.AddRateLimited(key)
+}
+
+func (q *queueType) EnqueueAfter(obj interface{}, delay time.Duration) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	q.reserve(key)
+	q.metrics.added()
+
+	logger.V(log.LIBTRACE).Infof("%s: enqueueing key %q for %T object after %v", q.name, key, obj, delay)
+	q.AddAfter(key, delay)
+}
+
+// reserve records the key as pending, first waiting for capacity if the queue is bounded and full. A key that's
+// already pending doesn't add to the depth so never waits.
+func (q *queueType) reserve(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.isFull(key) {
+		start := time.Now()
+		timer := time.AfterFunc(backpressureWarningInterval, q.hasCapacity.Broadcast)
+
+		for q.isFull(key) {
+			q.hasCapacity.Wait()
+
+			if time.Since(start) >= backpressureWarningInterval && q.isFull(key) {
+				logger.Warningf("%s: work queue has been at its maximum depth of %d for %v - current depth: %d",
+					q.name, q.maxDepth, time.Since(start).Round(time.Second), len(q.pending))
+
+				start = time.Now()
+				timer.Reset(backpressureWarningInterval)
+			}
 		}
-	}, time.Second, stopCh)
+
+		timer.Stop()
+	}
+
+	q.markPending(key)
+}
+
+// markPending must be called with the mutex held.
+func (q *queueType) markPending(key string) {
+	if !q.pending[key] {
+		q.pending[key] = true
+		q.metrics.depthChanged(1)
+	}
+}
+
+func (q *queueType) isFull(key string) bool {
+	return q.maxDepth > 0 && !q.shuttingDown && len(q.pending) >= q.maxDepth && !q.pending[key]
+}
+
+func (q *queueType) release(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.pending[key] {
+		delete(q.pending, key)
+		q.metrics.depthChanged(-1)
+	}
+
+	q.hasCapacity.Broadcast()
+}
+
+func (q *queueType) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.pending)
+}
+
+func (q *queueType) PendingKeys() []string {
+	q.mutex.Lock()
+	keys := make([]string, 0, len(q.pending))
+
+	for key := range q.pending {
+		keys = append(keys, key)
+	}
+
+	q.mutex.Unlock()
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (q *queueType) ShutDown() {
+	q.mutex.Lock()
+	q.shuttingDown = true
+	q.hasCapacity.Broadcast()
+	q.mutex.Unlock()
+
+	q.RateLimitingInterface.ShutDown()
+}
+
+func (q *queueType) Run(stopCh <-chan struct{}, process ProcessFunc) {
+	q.workers.Add(1)
+
+	go func() {
+		defer q.workers.Done()
+
+		wait.Until(func() {
+			for q.processNextWorkItem(process) {
+			}
+		}, time.Second, stopCh)
+	}()
+}
+
+func (q *queueType) AwaitWorkers() {
+	q.workers.Wait()
 }
 
 func (q *queueType) processNextWorkItem(process ProcessFunc) bool {
@@ -93,6 +284,10 @@ func (q *queueType) processNextWorkItem(process ProcessFunc) bool {
 
 	defer q.Done(key)
 
+	q.release(key)
+
+	started := time.Now()
+
 	requeue, err := func() (bool, error) {
 		ns, name, err := cache.SplitMetaNamespaceKey(key)
 		if err != nil {
@@ -101,14 +296,23 @@ func (q *queueType) processNextWorkItem(process ProcessFunc) bool {
 
 		return process(key, name, ns)
 	}()
+
+	q.metrics.processed(started)
 	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("%s: Failed to process object with key %q: %w", q.name, key, err))
 	}
 
 	if requeue {
+		// Re-queueing must not wait for capacity as that could block the worker indefinitely.
+		q.mutex.Lock()
+		q.markPending(key)
+		q.mutex.Unlock()
+
+		q.metrics.retried()
 		q.AddRateLimited(key)
 		logger.V(log.LIBDEBUG).Infof("%s: enqueued %q for retry - # of times re-queued: %d", q.name, key, q.NumRequeues(key))
 	} else {
+		// Forget the key so its rate limiter backoff is reset and a subsequent failure is retried after the base delay.
 		q.Forget(key)
 	}
 
@@ -0,0 +1,371 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/submariner-io/admiral/pkg/workqueue"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sworkqueue "k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("Bounded work queue", func() {
+	const (
+		maxDepth = 3
+		numItems = 20
+	)
+
+	var (
+		queue     workqueue.Interface
+		stopCh    chan struct{}
+		processed int32
+		maxSeen   int32
+	)
+
+	BeforeEach(func() {
+		queue = workqueue.NewBounded("test", maxDepth)
+		stopCh = make(chan struct{})
+		atomic.StoreInt32(&processed, 0)
+		atomic.StoreInt32(&maxSeen, 0)
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		queue.ShutDown()
+	})
+
+	recordDepth := func() {
+		depth := int32(queue.Len())
+		for {
+			seen := atomic.LoadInt32(&maxSeen)
+			if depth <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, depth) {
+				return
+			}
+		}
+	}
+
+	It("should never exceed the bound and eventually process all items", func() {
+		queue.Run(stopCh, func(key, name, namespace string) (bool, error) {
+			recordDepth()
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&processed, 1)
+
+			return false, nil
+		})
+
+		go func() {
+			for i := 0; i < numItems; i++ {
+				queue.Enqueue(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "test"}})
+				recordDepth()
+			}
+		}()
+
+		Eventually(func() int {
+			return int(atomic.LoadInt32(&processed))
+		}, 5).Should(Equal(numItems))
+
+		Expect(int(atomic.LoadInt32(&maxSeen))).To(BeNumerically("<=", maxDepth))
+		Expect(int(atomic.LoadInt32(&maxSeen))).To(BeNumerically(">", 0))
+	})
+
+	It("should not block enqueueing a key that is already pending", func() {
+		for i := 0; i < maxDepth; i++ {
+			queue.Enqueue(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "test"}})
+		}
+
+		done := make(chan struct{})
+
+		go func() {
+			queue.Enqueue(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-0", Namespace: "test"}})
+			close(done)
+		}()
+
+		Eventually(done).Should(BeClosed())
+		Expect(queue.Len()).To(Equal(maxDepth))
+	})
+
+	It("should unblock a waiting enqueue on shut down", func() {
+		for i := 0; i < maxDepth; i++ {
+			queue.Enqueue(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "test"}})
+		}
+
+		done := make(chan struct{})
+
+		go func() {
+			queue.Enqueue(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "another", Namespace: "test"}})
+			close(done)
+		}()
+
+		Consistently(done, 200*time.Millisecond).ShouldNot(BeClosed())
+		queue.ShutDown()
+		Eventually(done).Should(BeClosed())
+	})
+})
+
+var _ = Describe("Priority work queue", func() {
+	const fairness = 3
+
+	var (
+		queue     workqueue.Interface
+		stopCh    chan struct{}
+		mutex     sync.Mutex
+		processed []string
+	)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	}
+
+	BeforeEach(func() {
+		queue = workqueue.NewPriority("test", 0, fairness, nil)
+		stopCh = make(chan struct{})
+		processed = nil
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		queue.ShutDown()
+	})
+
+	run := func() {
+		queue.Run(stopCh, func(key, name, namespace string) (bool, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			processed = append(processed, name)
+
+			return false, nil
+		})
+	}
+
+	getProcessed := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return append([]string(nil), processed...)
+	}
+
+	// Items are added after the initial rate limiter delay so wait for them to be ready before processing.
+	awaitQueued := func() {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	It("should process a high priority key enqueued behind normal keys first", func() {
+		for i := 0; i < 20; i++ {
+			queue.Enqueue(newPod(fmt.Sprintf("normal-%d", i)))
+		}
+
+		awaitQueued()
+
+		queue.EnqueueWithPriority(newPod("high"), 1)
+
+		awaitQueued()
+		run()
+
+		Eventually(getProcessed, 5).Should(HaveLen(21))
+		Expect(getProcessed()[0]).To(Equal("high"))
+	})
+
+	It("should not starve normal keys", func() {
+		for i := 0; i < 5; i++ {
+			queue.Enqueue(newPod(fmt.Sprintf("normal-%d", i)))
+		}
+
+		for i := 0; i < 20; i++ {
+			queue.EnqueueWithPriority(newPod(fmt.Sprintf("high-%d", i)), 1)
+		}
+
+		awaitQueued()
+		run()
+
+		Eventually(getProcessed, 5).Should(HaveLen(25))
+
+		order := getProcessed()
+		Expect(order[:fairness]).To(HaveEach(HavePrefix("high-")))
+		Expect(order[fairness]).To(Equal("normal-0"))
+		Expect(order[fairness+1 : 2*fairness+1]).To(HaveEach(HavePrefix("high-")))
+		Expect(order[2*fairness+1]).To(Equal("normal-1"))
+	})
+
+	It("should promote a pending normal key enqueued with a high priority", func() {
+		for i := 0; i < 5; i++ {
+			queue.Enqueue(newPod(fmt.Sprintf("normal-%d", i)))
+		}
+
+		awaitQueued()
+
+		queue.EnqueueWithPriority(newPod("normal-4"), 1)
+		Expect(queue.Len()).To(Equal(5))
+
+		awaitQueued()
+		run()
+
+		Eventually(getProcessed, 5).Should(HaveLen(5))
+		Expect(getProcessed()[0]).To(Equal("normal-4"))
+
+		Consistently(getProcessed, 200*time.Millisecond).Should(HaveLen(5))
+	})
+})
+
+var _ = Describe("Retry backoff", func() {
+	Context("for a bounded work queue", func() {
+		testBackoffReset(func() workqueue.Interface {
+			return workqueue.NewBounded("test", 0)
+		})
+	})
+
+	Context("for a priority work queue", func() {
+		testBackoffReset(func() workqueue.Interface {
+			return workqueue.NewPriority("test", 0, 0, nil)
+		})
+	})
+})
+
+func testBackoffReset(newQueue func() workqueue.Interface) {
+	const numFailures = 5
+
+	type attempt struct {
+		at          time.Time
+		numRequeues int
+	}
+
+	var (
+		queue    workqueue.Interface
+		stopCh   chan struct{}
+		attempts chan attempt
+		failing  int32
+	)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test"}}
+
+	BeforeEach(func() {
+		queue = newQueue()
+		stopCh = make(chan struct{})
+		attempts = make(chan attempt, 100)
+		atomic.StoreInt32(&failing, numFailures)
+
+		queue.Run(stopCh, func(key, name, namespace string) (bool, error) {
+			attempts <- attempt{at: time.Now(), numRequeues: queue.NumRequeues(key)}
+
+			if atomic.AddInt32(&failing, -1) >= 0 {
+				return true, fmt.Errorf("mock failure")
+			}
+
+			return false, nil
+		})
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		queue.ShutDown()
+	})
+
+	awaitAttempts := func(n int) []attempt {
+		received := make([]attempt, n)
+		for i := range received {
+			Eventually(attempts, 5).Should(Receive(&received[i]))
+		}
+
+		return received
+	}
+
+	It("should reset the backoff delay after the key is successfully processed", func() {
+		queue.Enqueue(pod)
+
+		// The initial attempt, the failed retries with growing delays and the final successful attempt.
+		first := awaitAttempts(numFailures + 1)
+		grownDelay := first[numFailures].at.Sub(first[numFailures-1].at)
+		Eventually(func() int {
+			return queue.NumRequeues("test/pod")
+		}).Should(BeZero())
+
+		atomic.StoreInt32(&failing, 1)
+		queue.Enqueue(pod)
+
+		second := awaitAttempts(2)
+		Expect(second[0].numRequeues).To(Equal(first[0].numRequeues))
+		Expect(second[1].numRequeues).To(Equal(first[1].numRequeues))
+		Expect(second[1].at.Sub(second[0].at)).To(BeNumerically("<", grownDelay))
+	})
+}
+
+var _ = Describe("Work queue with a custom WorkQueue", func() {
+	var (
+		queue   workqueue.Interface
+		backing k8sworkqueue.RateLimitingInterface
+		stopCh  chan struct{}
+	)
+
+	BeforeEach(func() {
+		backing = k8sworkqueue.NewRateLimitingQueue(k8sworkqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond,
+			time.Second))
+		queue = workqueue.NewWithWorkQueue("test", 0, backing, nil)
+		stopCh = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		queue.ShutDown()
+	})
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	}
+
+	It("should process the keys via the custom WorkQueue and track the re-queues", func() {
+		processed := make(chan string, 10)
+		requeues := make(chan int, 10)
+
+		queue.Run(stopCh, func(key, _, _ string) (bool, error) {
+			if key == "test/pod-1" {
+				n := queue.NumRequeues(key)
+				requeues <- n
+
+				if n < 2 {
+					return true, nil
+				}
+			}
+
+			processed <- key
+
+			return false, nil
+		})
+
+		queue.Enqueue(newPod("pod-1"))
+		queue.EnqueueAfter(newPod("pod-2"), 50*time.Millisecond)
+
+		Eventually(processed).Should(Receive(Equal("test/pod-1")))
+		Eventually(processed).Should(Receive(Equal("test/pod-2")))
+
+		// As for the default queue, the initial enqueue is rate limited so counts as a re-queue.
+		Expect(requeues).To(HaveLen(2))
+		Expect([]int{<-requeues, <-requeues}).To(Equal([]int{1, 2}))
+
+		Expect(queue.NumRequeues("test/pod-1")).To(BeZero())
+		Expect(queue.Len()).To(BeZero())
+		Expect(backing.Len()).To(BeZero())
+	})
+})
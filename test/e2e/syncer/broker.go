@@ -72,7 +72,7 @@ func testWithLocalTransform() {
 
 	BeforeEach(func() {
 		t.brokerResourceType = &testV1.ExportedToaster{}
-		t.localTransform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool) {
+		t.localTransform = func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error) {
 			toaster, ok := from.(*testV1.Toaster)
 			Expect(ok).To(BeTrue())
 
@@ -81,7 +81,7 @@ func testWithLocalTransform() {
 					Name: toaster.GetName(),
 				},
 				Spec: toaster.Spec,
-			}, false
+			}, false, nil
 		}
 	})
 
@@ -149,7 +149,7 @@ func testWithFieldSelector() {
 type testDriver struct {
 	framework            *framework.Framework
 	localSourceNamespace string
-	localTransform       func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool)
+	localTransform       func(from runtime.Object, numRequeues int, op syncer.Operation) (runtime.Object, bool, error)
 	brokerResourceType   runtime.Object
 	labelSelector        string
 	fieldSelector        string